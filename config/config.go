@@ -33,6 +33,11 @@ type Config struct {
 	Admin                   AdminConfig                              `yaml:"Admin,omitempty"`        // Admin-specific settings (e.g., authorized domains)
 	FrontendURL             string                                   `yaml:"FrontendURL,omitempty"`  // Base URL for the frontend (used in emails, redirects)
 
+	// TrustedProxies lists the addresses or CIDR ranges of the reverse proxies in front of
+	// the server. Their X-Forwarded-For header names the client IP; without any the client
+	// IP is the address of the connection and forwarding headers are ignored.
+	TrustedProxies []string `yaml:"TrustedProxies,omitempty"`
+
 	// EnabledModules specifies which optional modules should be initialized.
 	// The consuming application provides the names of the modules it wants to use.
 	EnabledModules []string `yaml:"EnabledModules,omitempty"`
@@ -45,14 +50,24 @@ type Config struct {
 type AuthConfig struct {
 	AccessSecret            string
 	AccessExpire            int64
+	RefreshExpire           int64  `yaml:"RefreshExpire,omitempty"`     // Refresh token lifetime in seconds (defaults to 30 days)
+	RefreshCookieName       string `yaml:"RefreshCookieName,omitempty"` // Defaults to "refresh"
 	AccountCookieName       string
 	UserCookieName          string
 	SessionCookieName       string
-	SecretKey               string // General secret key (e.g., for session encryption)
-	GoogleOAuthStateString  string `yaml:"GoogleOAuthStateString,omitempty"`
-	GoogleOAuthClientID     string `yaml:"GoogleOAuthClientID,omitempty"`
-	GoogleOAuthClientSecret string `yaml:"GoogleOAuthClientSecret,omitempty"`
-	GoogleOAuthRedirectURL  string `yaml:"GoogleOAuthRedirectURL,omitempty"`
+	SecretKey               string   // General secret key (e.g., for session encryption)
+	GoogleOAuthStateString  string   `yaml:"GoogleOAuthStateString,omitempty"` // Unused; OAuth state is now generated per request
+	GoogleOAuthClientID     string   `yaml:"GoogleOAuthClientID,omitempty"`
+	GoogleOAuthClientSecret string   `yaml:"GoogleOAuthClientSecret,omitempty"`
+	GoogleOAuthRedirectURL  string   `yaml:"GoogleOAuthRedirectURL,omitempty"`
+	WebAuthnRPID            string   `yaml:"WebAuthnRPID,omitempty"`         // Passkey relying party ID (defaults to the FrontendURL host)
+	WebAuthnOrigins         []string `yaml:"WebAuthnOrigins,omitempty"`      // Allowed passkey origins (defaults to FrontendURL)
+	SSORedirectURL          string   `yaml:"SSORedirectURL,omitempty"`       // Team SSO callback registered at the IdPs (defaults to FrontendURL + /api/auth/sso/callback)
+	SigningAlgorithm        string   `yaml:"SigningAlgorithm,omitempty"`     // Access token signing: RS256 (default), EdDSA, or HS256 to keep signing with AccessSecret
+	KeyGracePeriod          int64    `yaml:"KeyGracePeriod,omitempty"`       // Seconds a rotated-out signing key still verifies tokens (defaults to 24 hours)
+	KeyEncryptionSecret     string   `yaml:"KeyEncryptionSecret,omitempty"`  // Encrypts signing private keys in the database (AES-GCM); without it they are stored as plain PEM
+	DeletionGraceDays       int      `yaml:"DeletionGraceDays,omitempty"`    // Days before a deleted account is erased; it can be restored until then (defaults to 30)
+	BreachedPasswordsDir    string   `yaml:"BreachedPasswordsDir,omitempty"` // Directory of k-anonymity range files of breached password hashes (screening is off when empty)
 }
 
 // NatsConfig holds NATS connection details
@@ -0,0 +1,312 @@
+// Package accountdeletion lets users delete their account. A deletion is scheduled first
+// and can be cancelled during a grace period; afterwards a background job erases the account.
+package accountdeletion
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	stripe "github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/subscription"
+	"gorm.io/gorm"
+)
+
+// DefaultGracePeriod is how long a scheduled deletion can be cancelled unless configured otherwise.
+const DefaultGracePeriod = 30 * 24 * time.Hour
+
+// purgeBatchSize limits how many accounts one run of Purge erases.
+const purgeBatchSize = 100
+
+var (
+	// ErrAdministrator is returned for administrators, who must be demoted before deleting their account.
+	ErrAdministrator = errors.New("administrators cannot delete their account")
+	// ErrAlreadyScheduled is returned when the account is already scheduled for deletion.
+	ErrAlreadyScheduled = errors.New("account deletion already scheduled")
+	// ErrBillingUnavailable is returned when subscriptions cannot be stopped because Stripe is not configured.
+	ErrBillingUnavailable = errors.New("billing is not configured")
+)
+
+// OwnedTeamsError is returned when the user owns teams with other members and did not
+// name a new owner for them.
+type OwnedTeamsError struct {
+	Teams []models.Team
+}
+
+func (e *OwnedTeamsError) Error() string {
+	return fmt.Sprintf("user owns %d teams with other members", len(e.Teams))
+}
+
+// TransferError reports an ownership transfer that cannot be made. Its message is safe to show to the user.
+type TransferError struct {
+	Message string
+}
+
+func (e *TransferError) Error() string {
+	return e.Message
+}
+
+// GracePeriod returns how long a scheduled deletion can be cancelled.
+func GracePeriod(cfg *config.Config) time.Duration {
+	if cfg.Auth.DeletionGraceDays > 0 {
+		return time.Duration(cfg.Auth.DeletionGraceDays) * 24 * time.Hour
+	}
+	return DefaultGracePeriod
+}
+
+// Schedule marks the user's account for deletion after the grace period and returns when
+// it will be erased. Teams the user owns with other members go to the member named in
+// transfers, keyed by team ID; teams without other members are deleted with the account.
+// Subscriptions are set to end with their current period so they do not renew.
+func Schedule(db *gorm.DB, cfg *config.Config, user *models.User, transfers map[uuid.UUID]uuid.UUID) (time.Time, error) {
+	if user.Role == models.SystemRoleAdmin {
+		return time.Time{}, ErrAdministrator
+	}
+	if user.DeletionScheduledAt != nil {
+		return time.Time{}, ErrAlreadyScheduled
+	}
+
+	// 1. Every team with other members needs a new owner
+	teams, err := models.FindTeamsOwnedByUser(db, user.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	owned := make(map[uuid.UUID]bool, len(teams))
+	var blocked []models.Team
+	for _, team := range teams {
+		owned[team.ID] = true
+		others, err := otherMembers(db, team.ID, user.ID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		newOwner, ok := transfers[team.ID]
+		if len(others) == 0 {
+			if ok {
+				return time.Time{}, &TransferError{fmt.Sprintf("%s has no other members to take it over", team.Name)}
+			}
+			continue
+		}
+		if !ok {
+			blocked = append(blocked, team)
+			continue
+		}
+		if !hasMember(others, newOwner) {
+			return time.Time{}, &TransferError{fmt.Sprintf("The new owner of %s must be one of its members", team.Name)}
+		}
+	}
+	for teamID := range transfers {
+		if !owned[teamID] {
+			return time.Time{}, &TransferError{"You can only transfer teams you own"}
+		}
+	}
+	if len(blocked) > 0 {
+		return time.Time{}, &OwnedTeamsError{Teams: blocked}
+	}
+
+	// 2. Stop subscriptions from renewing
+	if err := stopRenewals(db, cfg.Stripe.SecretKey, user.ID); err != nil {
+		return time.Time{}, err
+	}
+
+	// 3. Transfer the teams and schedule the deletion together
+	deleteAt := time.Now().Add(GracePeriod(cfg))
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for teamID, newOwner := range transfers {
+			if err := models.TransferTeamOwnership(tx, teamID, user.ID, newOwner); err != nil {
+				return fmt.Errorf("failed to transfer team %s: %w", teamID, err)
+			}
+		}
+		return models.ScheduleUserDeletion(tx, user.ID, deleteAt)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	user.DeletionScheduledAt = &deleteAt
+	return deleteAt, nil
+}
+
+// Cancel withdraws a scheduled deletion. Subscriptions stay set to end with their period;
+// the user resumes them from billing. It reports whether a deletion was scheduled.
+func Cancel(db *gorm.DB, user *models.User) (bool, error) {
+	cancelled, err := models.CancelUserDeletion(db, user.ID)
+	if err != nil {
+		return false, err
+	}
+	user.DeletionScheduledAt = nil
+	return cancelled, nil
+}
+
+// Purge erases the accounts whose grace period ended before now and returns how many it
+// erased. erased, if set, is called with the address of every erased account so the
+// user can be told.
+func Purge(db *gorm.DB, cfg *config.Config, now time.Time, erased func(email string)) (int, error) {
+	ids, err := models.FindUserIDsDueForDeletion(db, now, purgeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find accounts due for deletion: %w", err)
+	}
+
+	count := 0
+	for _, id := range ids {
+		email, err := purgeUser(db, cfg.Stripe.SecretKey, id, now)
+		if err != nil {
+			log.Printf("ERROR: Failed to erase account %s: %v", id, err)
+			continue
+		}
+		if email == "" {
+			continue // Cancelled meanwhile or handled by another instance
+		}
+		count++
+		if erased != nil {
+			erased(email)
+		}
+	}
+	return count, nil
+}
+
+func purgeUser(db *gorm.DB, stripeKey string, userID uuid.UUID, now time.Time) (string, error) {
+	user, err := models.FindUserByID(db, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.Role == models.SystemRoleAdmin {
+		log.Printf("WARN: Not erasing account %s: it was made an administrator after deletion was requested", user.ID)
+		return "", nil
+	}
+
+	// Subscriptions end now rather than with their period
+	if err := cancelSubscriptions(db, stripeKey, models.BillingSubjectUser, user.ID); err != nil {
+		return "", err
+	}
+
+	email := ""
+	err = db.Transaction(func(tx *gorm.DB) error {
+		locked, err := models.FindUserDueForDeletionForUpdate(tx, userID, now)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		// Members who joined during the grace period keep their team
+		teams, err := models.FindTeamsOwnedByUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		for _, team := range teams {
+			others, err := otherMembers(tx, team.ID, userID)
+			if err != nil {
+				return err
+			}
+			if len(others) == 0 {
+				if err := cancelSubscriptions(tx, stripeKey, models.BillingSubjectTeam, team.ID); err != nil {
+					return fmt.Errorf("failed to cancel subscriptions of team %s: %w", team.ID, err)
+				}
+				if err := models.PurgeTeam(tx, team.ID); err != nil {
+					return fmt.Errorf("failed to delete team %s: %w", team.ID, err)
+				}
+				continue
+			}
+			if err := models.TransferTeamOwnership(tx, team.ID, userID, successor(others).UserID); err != nil {
+				return fmt.Errorf("failed to transfer team %s: %w", team.ID, err)
+			}
+		}
+
+		if err := models.PurgeUser(tx, userID); err != nil {
+			return err
+		}
+		email = locked.Email
+		return nil
+	})
+	return email, err
+}
+
+func otherMembers(db *gorm.DB, teamID, userID uuid.UUID) ([]models.Membership, error) {
+	memberships, err := models.FindMembershipsByTeam(db, teamID)
+	if err != nil {
+		return nil, err
+	}
+	others := memberships[:0]
+	for _, m := range memberships {
+		if m.UserID != userID {
+			others = append(others, m)
+		}
+	}
+	return others, nil
+}
+
+func hasMember(memberships []models.Membership, userID uuid.UUID) bool {
+	for _, m := range memberships {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// successor picks the member who takes over a team: its longest-standing admin, or its
+// longest-standing member when it has no admin.
+func successor(memberships []models.Membership) models.Membership {
+	sort.SliceStable(memberships, func(i, j int) bool {
+		ai, aj := memberships[i].Role == models.RoleAdmin, memberships[j].Role == models.RoleAdmin
+		if ai != aj {
+			return ai
+		}
+		return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+	})
+	return memberships[0]
+}
+
+func stopRenewals(db *gorm.DB, stripeKey string, userID uuid.UUID) error {
+	subscriptions, err := models.FindOpenSubscriptionsByUser(db, userID)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subscriptions {
+		if sub.CancelAtPeriodEnd {
+			continue
+		}
+		if stripeKey == "" {
+			return ErrBillingUnavailable
+		}
+		stripe.Key = stripeKey
+		params := &stripe.SubscriptionParams{CancelAtPeriodEnd: stripe.Bool(true)}
+		if _, err := subscription.Update(sub.StripeSubscriptionID, params); err != nil && !missing(err) {
+			return fmt.Errorf("failed to stop renewal of subscription %s: %w", sub.StripeSubscriptionID, err)
+		}
+		if err := models.UpdateSubscriptionCancelAtPeriodEnd(db, sub.ID, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cancelSubscriptions(db *gorm.DB, stripeKey string, subjectType models.BillingSubjectType, subjectID uuid.UUID) error {
+	subscriptions, err := models.FindOpenSubscriptionsBySubject(db, subjectType, subjectID)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subscriptions {
+		if stripeKey == "" {
+			return ErrBillingUnavailable
+		}
+		stripe.Key = stripeKey
+		if _, err := subscription.Cancel(sub.StripeSubscriptionID, nil); err != nil && !missing(err) {
+			return fmt.Errorf("failed to cancel subscription %s: %w", sub.StripeSubscriptionID, err)
+		}
+	}
+	return nil
+}
+
+// missing reports whether Stripe no longer knows the subscription, e.g. because it was
+// already cancelled there.
+func missing(err error) bool {
+	var stripeErr *stripe.Error
+	return errors.As(err, &stripeErr) && stripeErr.Code == stripe.ErrorCodeResourceMissing
+}
@@ -0,0 +1,39 @@
+package accountdeletion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSuccessor(t *testing.T) {
+	now := time.Now()
+	oldMember := models.Membership{UserID: uuid.New(), Role: models.RoleMember}
+	oldMember.CreatedAt = now.Add(-48 * time.Hour)
+	newAdmin := models.Membership{UserID: uuid.New(), Role: models.RoleAdmin}
+	newAdmin.CreatedAt = now
+	oldAdmin := models.Membership{UserID: uuid.New(), Role: models.RoleAdmin}
+	oldAdmin.CreatedAt = now.Add(-time.Hour)
+
+	if got := successor([]models.Membership{oldMember, newAdmin, oldAdmin}); got.UserID != oldAdmin.UserID {
+		t.Errorf("successor = %s, want the longest-standing admin", got.UserID)
+	}
+	if got := successor([]models.Membership{oldMember}); got.UserID != oldMember.UserID {
+		t.Errorf("successor = %s, want the only member", got.UserID)
+	}
+}
+
+func TestGracePeriod(t *testing.T) {
+	cfg := &config.Config{}
+	if got := GracePeriod(cfg); got != DefaultGracePeriod {
+		t.Errorf("default grace period = %s", got)
+	}
+	cfg.Auth.DeletionGraceDays = 7
+	if got := GracePeriod(cfg); got != 7*24*time.Hour {
+		t.Errorf("configured grace period = %s", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Package apitoken implements personal and team access tokens: opaque bearer tokens
+// stored as hashes, limited by scopes, an expiry and an optional IP allowlist.
+package apitoken
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/security"
+)
+
+const (
+	// Prefix starts every access token so leaked tokens are easy to recognise.
+	Prefix = "stab_pat_"
+
+	tokenLength = 40
+	// displayLength is how much of a token is kept in plain text to tell tokens apart.
+	displayLength = len(Prefix) + 6
+)
+
+// Access levels of a scope. Write access includes read access.
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+// Resources are the API areas a token can be scoped to. Scopes are written as
+// "<resource>:<access>", e.g. "billing:read" or "teams:write".
+const (
+	ResourceProfile       = "profile"
+	ResourceBilling       = "billing"
+	ResourceTeams         = "teams"
+	ResourceNotifications = "notifications"
+	ResourceDeveloper     = "developer"
+	ResourceKnowledgebase = "knowledgebase"
+	ResourceAdmin         = "admin"
+)
+
+var resources = []string{
+	ResourceProfile,
+	ResourceBilling,
+	ResourceTeams,
+	ResourceNotifications,
+	ResourceDeveloper,
+	ResourceKnowledgebase,
+	ResourceAdmin,
+}
+
+// NewToken returns a new access token, the prefix to display for it and the hash to store.
+func NewToken() (token, display, hash string) {
+	token = Prefix + security.RandomString(tokenLength)
+	return token, token[:displayLength], HashToken(token)
+}
+
+// HashToken returns the stored form of an access token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsToken reports whether s looks like an access token rather than a JWT.
+func IsToken(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// Scope returns the scope granting access to a resource.
+func Scope(resource, access string) string {
+	return resource + ":" + access
+}
+
+// RequiredScope returns the scope a request needs: read access for safe methods,
+// write access for everything else.
+func RequiredScope(resource, method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return Scope(resource, AccessRead)
+	}
+	return Scope(resource, AccessWrite)
+}
+
+// ParseScopes validates the requested scopes and returns them sorted without duplicates.
+func ParseScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var parsed []string
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		resource, access, ok := strings.Cut(s, ":")
+		if !ok || !isResource(resource) || (access != AccessRead && access != AccessWrite) {
+			return nil, fmt.Errorf("unknown scope %q", s)
+		}
+		if !seen[s] {
+			seen[s] = true
+			parsed = append(parsed, s)
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	sort.Strings(parsed)
+	return parsed, nil
+}
+
+// ScopesFor returns the read and write scopes of the given resources, or of every
+// resource when none are given.
+func ScopesFor(resourceList ...string) []string {
+	if len(resourceList) == 0 {
+		resourceList = resources
+	}
+	scopes := make([]string, 0, 2*len(resourceList))
+	for _, r := range resourceList {
+		scopes = append(scopes, Scope(r, AccessRead), Scope(r, AccessWrite))
+	}
+	return scopes
+}
+
+func isResource(resource string) bool {
+	for _, r := range resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the granted scopes include the required one.
+func Allows(granted []string, required string) bool {
+	resource, access, _ := strings.Cut(required, ":")
+	for _, g := range granted {
+		if g == required || (access == AccessRead && g == Scope(resource, AccessWrite)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAllowlist validates IP addresses and CIDR ranges and returns them in CIDR form.
+func ParseAllowlist(entries []string) ([]string, error) {
+	var parsed []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP range %q", entry)
+		}
+		parsed = append(parsed, network.String())
+	}
+	return parsed, nil
+}
+
+// AllowsIP reports whether the address is inside the allowlist. An empty allowlist allows any address.
+func AllowsIP(allowlist []string, addr string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range allowlist {
+		if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
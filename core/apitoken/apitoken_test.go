@@ -0,0 +1,77 @@
+package apitoken
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNewToken(t *testing.T) {
+	token, display, hash := NewToken()
+	if !IsToken(token) || len(display) != displayLength || token[:displayLength] != display {
+		t.Fatalf("unexpected token %q with display %q", token, display)
+	}
+	if hash != HashToken(token) || hash == HashToken(token+"x") {
+		t.Fatal("hash does not identify the token")
+	}
+	if IsToken("eyJhbGciOiJSUzI1NiJ9.e30.sig") {
+		t.Fatal("a JWT is not an access token")
+	}
+}
+
+func TestScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"Teams:Write", "billing:read", "teams:write"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scopes) != 2 || scopes[0] != "billing:read" || scopes[1] != "teams:write" {
+		t.Fatalf("unexpected scopes %v", scopes)
+	}
+	for _, invalid := range [][]string{nil, {"billing"}, {"billing:delete"}, {"unknown:read"}} {
+		if _, err := ParseScopes(invalid); err == nil {
+			t.Errorf("expected %v to be rejected", invalid)
+		}
+	}
+
+	cases := []struct {
+		resource, method string
+		want             bool
+	}{
+		{ResourceBilling, http.MethodGet, true},
+		{ResourceBilling, http.MethodPost, false},
+		{ResourceTeams, http.MethodGet, true}, // write includes read
+		{ResourceTeams, http.MethodDelete, true},
+		{ResourceProfile, http.MethodGet, false},
+	}
+	for _, tc := range cases {
+		if got := Allows(scopes, RequiredScope(tc.resource, tc.method)); got != tc.want {
+			t.Errorf("%s %s allowed = %v, want %v", tc.method, tc.resource, got, tc.want)
+		}
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	allowlist, err := ParseAllowlist([]string{"203.0.113.7", " 10.0.0.0/8 ", "", "2001:db8::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allowlist) != 3 || allowlist[0] != "203.0.113.7/32" || allowlist[2] != "2001:db8::1/128" {
+		t.Fatalf("unexpected allowlist %v", allowlist)
+	}
+	for addr, want := range map[string]bool{
+		"203.0.113.7": true,
+		"203.0.113.8": false,
+		"10.20.30.40": true,
+		"2001:db8::1": true,
+		"not-an-ip":   false,
+	} {
+		if got := AllowsIP(allowlist, addr); got != want {
+			t.Errorf("AllowsIP(%q) = %v, want %v", addr, got, want)
+		}
+	}
+	if !AllowsIP(nil, "198.51.100.1") {
+		t.Fatal("an empty allowlist allows any address")
+	}
+	if _, err := ParseAllowlist([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("expected an invalid range to be rejected")
+	}
+}
@@ -0,0 +1,121 @@
+package apitoken
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxLifetimeDays caps the lifetime of tokens that expire.
+const MaxLifetimeDays = 366
+
+// Spec describes a token to issue.
+type Spec struct {
+	Name          string
+	Scopes        []string
+	ExpiresInDays int // 0 never expires
+	IPAllowlist   []string
+}
+
+// ValidationError reports a Spec that cannot be issued. Its message is safe to show to the user.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Issue validates the spec and stores a new token for the user. A non-nil teamID makes it a
+// team token. allowed reports whether the caller may grant a scope. The secret is returned
+// once; only its hash is stored.
+func Issue(db *gorm.DB, userID uuid.UUID, teamID *uuid.UUID, spec Spec, allowed func(scope string) bool) (*models.APIToken, string, error) {
+	name := strings.TrimSpace(spec.Name)
+	if name == "" || len(name) > 100 {
+		return nil, "", &ValidationError{"Token name must be between 1 and 100 characters"}
+	}
+	scopes, err := ParseScopes(spec.Scopes)
+	if err != nil {
+		return nil, "", &ValidationError{"Invalid scopes: " + err.Error()}
+	}
+	for _, scope := range scopes {
+		if !allowed(scope) {
+			return nil, "", &ValidationError{fmt.Sprintf("You cannot grant the %s scope", scope)}
+		}
+	}
+	allowlist, err := ParseAllowlist(spec.IPAllowlist)
+	if err != nil {
+		return nil, "", &ValidationError{"Invalid IP allowlist: " + err.Error()}
+	}
+	if spec.ExpiresInDays < 0 || spec.ExpiresInDays > MaxLifetimeDays {
+		return nil, "", &ValidationError{fmt.Sprintf("Expiry must be between 1 and %d days, or 0 for no expiry", MaxLifetimeDays)}
+	}
+
+	scopesJSON, err := json.Marshal(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if allowlist == nil {
+		allowlist = []string{}
+	}
+	allowlistJSON, err := json.Marshal(allowlist)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, prefix, hash := NewToken()
+	token := &models.APIToken{
+		UserID:      userID,
+		TeamID:      teamID,
+		Name:        name,
+		TokenHash:   hash,
+		Prefix:      prefix,
+		Scopes:      scopesJSON,
+		IPAllowlist: allowlistJSON,
+	}
+	if spec.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, spec.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := models.CreateAPIToken(db, token); err != nil {
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+// Describe converts a token for API responses.
+func Describe(t models.APIToken) types.APIToken {
+	token := types.APIToken{
+		ID:          t.ID.String(),
+		Name:        t.Name,
+		Prefix:      t.Prefix,
+		Scopes:      t.ScopeList(),
+		IPAllowlist: t.AllowedIPs(),
+		Expired:     t.Expired(),
+		LastUsedIP:  t.LastUsedIP,
+		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+	}
+	if token.Scopes == nil {
+		token.Scopes = []string{}
+	}
+	if token.IPAllowlist == nil {
+		token.IPAllowlist = []string{}
+	}
+	if t.TeamID != nil {
+		token.TeamID = t.TeamID.String()
+	}
+	if t.ExpiresAt != nil {
+		token.ExpiresAt = t.ExpiresAt.Format(time.RFC3339)
+	}
+	if t.LastUsedAt != nil {
+		token.LastUsedAt = t.LastUsedAt.Format(time.RFC3339)
+	}
+	return token
+}
@@ -0,0 +1,353 @@
+// Package authsession manages signed-in devices: short-lived access tokens bound to a
+// server-side session plus rotating refresh tokens with reuse detection.
+package authsession
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultRefreshExpire is used when Auth.RefreshExpire is not configured (30 days).
+	DefaultRefreshExpire int64 = 30 * 24 * 60 * 60
+
+	// DefaultRefreshCookieName is used when Auth.RefreshCookieName is not configured.
+	DefaultRefreshCookieName = "refresh"
+
+	// reuseGracePeriod tolerates a rotated token being presented again shortly after rotation,
+	// e.g. by two browser tabs refreshing at once, without treating it as theft.
+	reuseGracePeriod = 10 * time.Second
+
+	// touchInterval limits how often request activity is written to the session.
+	touchInterval = time.Minute
+
+	// refreshTokenLength gives ~285 bits of entropy with the alphanumeric alphabet.
+	refreshTokenLength = 48
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or just-rotated refresh tokens.
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is replayed.
+	// The session is revoked because the token chain has been compromised.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+	// ErrSessionRevoked is returned when an access token belongs to a revoked or expired session.
+	ErrSessionRevoked = errors.New("session revoked or expired")
+)
+
+// Tokens is the result of starting or refreshing a session.
+type Tokens struct {
+	SessionID    uuid.UUID
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // Access token lifetime in seconds
+}
+
+// Client describes the device a session belongs to.
+type Client struct {
+	IPAddress string
+	UserAgent string
+}
+
+// ClientFromContext extracts the client description from the request.
+func ClientFromContext(c echo.Context) Client {
+	userAgent := c.Request().UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	return Client{IPAddress: c.RealIP(), UserAgent: userAgent}
+}
+
+func refreshExpire(cfg *config.Config) int64 {
+	if cfg.Auth.RefreshExpire > 0 {
+		return cfg.Auth.RefreshExpire
+	}
+	return DefaultRefreshExpire
+}
+
+func refreshCookieName(cfg *config.Config) string {
+	if cfg.Auth.RefreshCookieName != "" {
+		return cfg.Auth.RefreshCookieName
+	}
+	return DefaultRefreshCookieName
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshToken() string {
+	return security.RandomString(refreshTokenLength)
+}
+
+// NewAccessToken signs an access token for the user bound to the given session.
+func NewAccessToken(cfg *config.Config, keys *keyring.Ring, user *models.User, sessionID uuid.UUID) (string, error) {
+	claims, err := NewClaims(user, sessionID).Map()
+	if err != nil {
+		return "", err
+	}
+	return keys.Sign(claims, cfg.Auth.AccessExpire)
+}
+
+// Start creates a session for a freshly authenticated user and issues its first token pair.
+func Start(db *gorm.DB, cfg *config.Config, keys *keyring.Ring, user *models.User, client Client) (*Tokens, error) {
+	refreshToken := newRefreshToken()
+	now := time.Now()
+	sess := &models.UserSession{
+		UserID:     user.ID,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Duration(refreshExpire(cfg)) * time.Second),
+	}
+	if err := models.CreateUserSession(db, sess, hashToken(refreshToken)); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	accessToken, err := NewAccessToken(cfg, keys, user, sess.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		SessionID:    sess.ID,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    cfg.Auth.AccessExpire,
+	}, nil
+}
+
+// Impersonate starts a session in which the admin acts as the user. The access token
+// names the admin in its act claim and lives as long as the session; there is no
+// refresh token, so the impersonation ends when the token expires or is revoked.
+func Impersonate(db *gorm.DB, keys *keyring.Ring, admin, user *models.User, duration time.Duration, client Client) (*Tokens, error) {
+	now := time.Now()
+	sess := &models.UserSession{
+		UserID:         user.ID,
+		IPAddress:      client.IPAddress,
+		UserAgent:      client.UserAgent,
+		LastSeenAt:     now,
+		ExpiresAt:      now.Add(duration),
+		ImpersonatorID: &admin.ID,
+	}
+	if err := models.CreateImpersonationSession(db, sess); err != nil {
+		return nil, fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+
+	claims := NewClaims(user, sess.ID)
+	claims.Act = &Actor{Subject: admin.ID.String(), Email: admin.Email}
+	payload, err := claims.Map()
+	if err != nil {
+		return nil, err
+	}
+	seconds := int64(duration / time.Second)
+	accessToken, err := keys.Sign(payload, seconds)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		SessionID:   sess.ID,
+		AccessToken: accessToken,
+		ExpiresIn:   seconds,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+// Each refresh token can be used once; replaying a rotated token revokes the whole session.
+func Refresh(db *gorm.DB, cfg *config.Config, keys *keyring.Ring, refreshToken string, client Client) (*Tokens, *models.User, error) {
+	if refreshToken == "" {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	nextToken := newRefreshToken()
+	var sess *models.UserSession
+	err := db.Transaction(func(tx *gorm.DB) error {
+		token, err := models.FindRefreshTokenByHashForUpdate(tx, hashToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		sess, err = models.FindUserSessionByID(tx, token.SessionID)
+		if err != nil {
+			return err
+		}
+		if !sess.Active() {
+			return ErrInvalidRefreshToken
+		}
+		if token.RotatedAt != nil {
+			if time.Since(*token.RotatedAt) < reuseGracePeriod {
+				return ErrInvalidRefreshToken
+			}
+			return ErrRefreshTokenReused
+		}
+		if err := models.RotateRefreshToken(tx, token, hashToken(nextToken)); err != nil {
+			return err
+		}
+		return models.TouchUserSession(tx, sess.ID, client.IPAddress, client.UserAgent)
+	})
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			// Revoke outside the rolled back transaction
+			if revokeErr := models.RevokeUserSession(db, sess.ID, sess.UserID, models.SessionRevokedTokenReuse); revokeErr != nil && !errors.Is(revokeErr, gorm.ErrRecordNotFound) {
+				return nil, nil, fmt.Errorf("failed to revoke session after token reuse: %w", revokeErr)
+			}
+		}
+		return nil, nil, err
+	}
+
+	user, err := models.FindUserByID(db, sess.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	accessToken, err := NewAccessToken(cfg, keys, user, sess.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Tokens{
+		SessionID:    sess.ID,
+		AccessToken:  accessToken,
+		RefreshToken: nextToken,
+		ExpiresIn:    cfg.Auth.AccessExpire,
+	}, user, nil
+}
+
+// Validate checks that the session an access token is bound to is still active
+// and records the activity.
+func Validate(db *gorm.DB, sessionID, userID uuid.UUID, client Client) error {
+	sess, err := models.FindUserSessionByID(db, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+	if !sess.Active() || sess.UserID != userID {
+		return ErrSessionRevoked
+	}
+	if time.Since(sess.LastSeenAt) > touchInterval {
+		if err := models.TouchUserSession(db, sess.ID, client.IPAddress, client.UserAgent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Revoke ends the session identified by a refresh token, if any.
+func Revoke(db *gorm.DB, refreshToken, reason string) error {
+	token, err := models.FindRefreshTokenByHash(db, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	sess, err := models.FindUserSessionByID(db, token.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := models.RevokeUserSession(db, sess.ID, sess.UserID, reason); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// RevokeRequest ends the session of the current request, identified by the refresh
+// cookie or, failing that, by the access token's session claim.
+func RevokeRequest(db *gorm.DB, cfg *config.Config, keys *keyring.Ring, c echo.Context, reason string) error {
+	if cookie, err := c.Cookie(refreshCookieName(cfg)); err == nil && cookie.Value != "" {
+		return Revoke(db, cookie.Value, reason)
+	}
+
+	token := ""
+	if cookie, err := c.Cookie(cfg.Auth.UserCookieName); err == nil {
+		token = cookie.Value
+	}
+	if bearer, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer "); ok {
+		token = bearer
+	}
+	if token == "" {
+		return nil
+	}
+	claims, err := ParseClaims(keys, token)
+	if err != nil {
+		return nil // Nothing to revoke for an invalid or expired token
+	}
+	sessionID, err := claims.Session()
+	if err != nil {
+		return nil
+	}
+	userID, err := claims.User()
+	if err != nil {
+		return nil
+	}
+	if err := models.RevokeUserSession(db, sessionID, userID, reason); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// SetCookies stores the token pair in HTTP-only cookies.
+// The refresh cookie is scoped to the auth endpoints so it is not sent with every request.
+func SetCookies(cfg *config.Config, c echo.Context, tokens *Tokens) {
+	secure := c.Request().URL.Scheme == "https" || c.Scheme() == "https"
+	c.SetCookie(&http.Cookie{
+		Name:     cfg.Auth.UserCookieName,
+		Value:    tokens.AccessToken,
+		Path:     "/",
+		Secure:   secure,
+		HttpOnly: true,
+		MaxAge:   int(cfg.Auth.AccessExpire),
+	})
+	c.SetCookie(&http.Cookie{
+		Name:     refreshCookieName(cfg),
+		Value:    tokens.RefreshToken,
+		Path:     "/api/auth",
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(refreshExpire(cfg)),
+	})
+}
+
+// RefreshTokenFromCookie returns the refresh token cookie value, if present.
+func RefreshTokenFromCookie(cfg *config.Config, c echo.Context) string {
+	cookie, err := c.Cookie(refreshCookieName(cfg))
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// ClearCookies removes the access and refresh cookies.
+func ClearCookies(cfg *config.Config, c echo.Context) {
+	for _, cookie := range []*http.Cookie{
+		{Name: cfg.Auth.UserCookieName, Path: "/"},
+		{Name: refreshCookieName(cfg), Path: "/api/auth"},
+	} {
+		cookie.Secure = true
+		cookie.HttpOnly = true
+		cookie.MaxAge = -1
+		c.SetCookie(cookie)
+	}
+}
@@ -0,0 +1,129 @@
+package authsession
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// Token types, set in the typ claim, keep tokens signed with the same keys from being
+// accepted in place of one another.
+const (
+	TypeAccess  = "access"  // User access token, see Claims
+	TypeAccount = "account" // Selects the team the user acts for, see tokens.SetAccountToken
+)
+
+// Claims is the payload of a user access token. Tokens are issued and read only through
+// this type so that issuers and the authenticator agree on the claim names.
+type Claims struct {
+	Type      string            `json:"typ"` // Always TypeAccess
+	UserID    string            `json:"id"`
+	Email     string            `json:"email"`
+	Role      models.SystemRole `json:"role"`
+	FirstName string            `json:"first_name,omitempty"`
+	LastName  string            `json:"last_name,omitempty"`
+	SessionID string            `json:"sid"`
+	Act       *Actor            `json:"act,omitempty"` // Set while an admin impersonates the user
+	jwt.RegisteredClaims
+}
+
+// Actor names the party acting on behalf of the token's subject (RFC 8693 section 4.1).
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+}
+
+// ActorID returns the ID of the admin impersonating the user, if any.
+func (c *Claims) ActorID() (uuid.UUID, bool) {
+	if c.Act == nil {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Act.Subject)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// NewClaims returns the claims of an access token for the user, bound to the session.
+// The expiry is added when the token is signed.
+func NewClaims(user *models.User, sessionID uuid.UUID) *Claims {
+	var profile types.UserProfileData
+	if user.ProfileData != nil {
+		_ = json.Unmarshal(user.ProfileData, &profile) // Names are informational only
+	}
+
+	claims := &Claims{
+		Type:             TypeAccess,
+		UserID:           user.ID.String(),
+		Email:            user.Email,
+		Role:             user.Role,
+		FirstName:        profile.FirstName,
+		LastName:         profile.LastName,
+		SessionID:        sessionID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{Subject: user.ID.String()},
+	}
+	return claims
+}
+
+// Map converts the claims for keyring.Ring.Sign.
+func (c *Claims) Map() (jwt.MapClaims, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var m jwt.MapClaims
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// User returns the ID of the user the token was issued to.
+func (c *Claims) User() (uuid.UUID, error) {
+	id, err := uuid.Parse(c.UserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user claim: %w", err)
+	}
+	return id, nil
+}
+
+// Session returns the session the token is bound to.
+func (c *Claims) Session() (uuid.UUID, error) {
+	id, err := uuid.Parse(c.SessionID)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, errors.New("token is not bound to a session")
+	}
+	return id, nil
+}
+
+// ParseClaims verifies an access token and returns its claims. Tokens without a
+// session, which revoking sessions could not end, are rejected.
+func ParseClaims(keys *keyring.Ring, token string) (*Claims, error) {
+	m, err := keys.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var claims Claims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	if claims.Type != TypeAccess || claims.UserID == "" {
+		return nil, errors.New("token is not a user access token")
+	}
+	if _, err := claims.Session(); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
@@ -0,0 +1,71 @@
+package authsession
+
+import (
+	"context"
+	"testing"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+func TestClaimsRoundTrip(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.AccessSecret = "test-secret"
+	cfg.Auth.AccessExpire = 60
+	cfg.Auth.SigningAlgorithm = keyring.AlgorithmHS256
+	keys, err := keyring.New(context.Background(), nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{
+		ID:          uuid.New(),
+		Email:       "ada@example.com",
+		Role:        models.SystemRoleAdmin,
+		ProfileData: []byte(`{"firstName": "Ada", "lastName": "Lovelace"}`),
+	}
+	sessionID := uuid.New()
+	token, err := NewAccessToken(cfg, keys, user, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ParseClaims(keys, token)
+	if err != nil {
+		t.Fatalf("ParseClaims: %v", err)
+	}
+	if id, err := claims.User(); err != nil || id != user.ID {
+		t.Fatalf("unexpected user %v (%v)", id, err)
+	}
+	if sid, err := claims.Session(); err != nil || sid != sessionID {
+		t.Fatalf("unexpected session %v", sid)
+	}
+	if claims.Role != models.SystemRoleAdmin || claims.Email != user.Email || claims.FirstName != "Ada" || claims.ExpiresAt == nil {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+
+	// Tokens without a session would outlive revoking it
+	unbound, _ := NewAccessToken(cfg, keys, user, uuid.Nil)
+	if _, err := ParseClaims(keys, unbound); err == nil {
+		t.Fatal("expected a token without a session to be rejected")
+	}
+
+	if _, err := ParseClaims(keys, token+"x"); err == nil {
+		t.Fatal("expected a tampered token to be rejected")
+	}
+
+	// Tokens selecting a team carry an ID too but are not access tokens
+	for _, typ := range []any{TypeAccount, nil} {
+		other, err := keys.Sign(jwt.MapClaims{"typ": typ, "id": user.ID.String(), "userID": uuid.NewString()}, 60)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseClaims(keys, other); err == nil {
+			t.Fatalf("expected a token of type %v to be rejected", typ)
+		}
+	}
+}
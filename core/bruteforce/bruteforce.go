@@ -0,0 +1,225 @@
+// Package bruteforce throttles repeated authentication failures per account and per client IP.
+//
+// After a number of free attempts each further failure doubles the wait before the next
+// attempt is accepted, and reaching the lockout threshold blocks the key for a fixed time.
+package bruteforce
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Scopes separate the counters of unrelated endpoints.
+const (
+	ScopeLogin        = "login"         // Wrong passwords
+	ScopeMFA          = "mfa"           // Wrong second-factor codes; only a verified code resets it
+	ScopeLoginCode    = "login_code"    // Wrong login codes
+	ScopeEmailToken   = "email_token"   // Bad links from emails, counted per client IP
+	ScopeEmailRevert  = "email_revert"  // Bad email change revert links, kept apart so other links cannot block them
+	ScopeEmailRequest = "email_request" // Password reset and login code emails; every request counts
+)
+
+var scopes = []string{ScopeLogin, ScopeMFA, ScopeLoginCode, ScopeEmailToken, ScopeEmailRevert, ScopeEmailRequest}
+
+// Policy describes how failures on a key are throttled.
+type Policy struct {
+	FreeAttempts     int           // Failures accepted without delay
+	BaseDelay        time.Duration // Delay after the first failure beyond FreeAttempts, doubled per failure
+	MaxDelay         time.Duration
+	LockoutThreshold int           // Failures that lock the key; 0 disables lockout
+	LockoutDuration  time.Duration // How long a lockout lasts
+	Window           time.Duration // Counters reset after this long without failures
+}
+
+// wait returns how long the caller must wait before the next attempt, and whether the key is locked.
+func (p Policy) wait(r Record, now time.Time) (time.Duration, bool) {
+	if now.Before(r.LockedUntil) {
+		return r.LockedUntil.Sub(now), true
+	}
+	over := r.Failures - p.FreeAttempts
+	if over <= 0 {
+		return 0, false
+	}
+	delay := p.MaxDelay
+	if over <= 16 {
+		delay = min(p.BaseDelay<<(over-1), p.MaxDelay)
+	}
+	if next := r.LastFailure.Add(delay); now.Before(next) {
+		return next.Sub(now), false
+	}
+	return 0, false
+}
+
+// Policies pairs the account and client IP policy of a scope.
+type Policies struct {
+	Account Policy
+	IP      Policy
+}
+
+// DefaultPolicies holds the throttling policy of each scope.
+var DefaultPolicies = map[string]Policies{
+	ScopeLogin: {
+		Account: Policy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutThreshold: 10, LockoutDuration: 15 * time.Minute, Window: time.Hour},
+		IP:      Policy{FreeAttempts: 20, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutThreshold: 100, LockoutDuration: 15 * time.Minute, Window: time.Hour},
+	},
+	// Counted separately from passwords, which a second-factor attacker already knows
+	ScopeMFA: {
+		Account: Policy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutThreshold: 10, LockoutDuration: 15 * time.Minute, Window: time.Hour},
+		IP:      Policy{FreeAttempts: 20, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutThreshold: 100, LockoutDuration: 15 * time.Minute, Window: time.Hour},
+	},
+	ScopeLoginCode: {
+		Account: Policy{FreeAttempts: 5, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutThreshold: 15, LockoutDuration: 15 * time.Minute, Window: time.Hour},
+		IP:      Policy{FreeAttempts: 20, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutThreshold: 100, LockoutDuration: 15 * time.Minute, Window: time.Hour},
+	},
+	// Links carry long random tokens and name no account, so only the IP is limited
+	ScopeEmailToken: {
+		IP: Policy{FreeAttempts: 20, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutThreshold: 100, LockoutDuration: 15 * time.Minute, Window: time.Hour},
+	},
+	ScopeEmailRevert: {
+		IP: Policy{FreeAttempts: 20, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutThreshold: 100, LockoutDuration: 15 * time.Minute, Window: time.Hour},
+	},
+	// Every request counts, so this limits outgoing emails rather than guesses
+	ScopeEmailRequest: {
+		Account: Policy{FreeAttempts: 3, BaseDelay: time.Minute, MaxDelay: 15 * time.Minute, LockoutThreshold: 10, LockoutDuration: time.Hour, Window: 24 * time.Hour},
+		IP:      Policy{FreeAttempts: 10, BaseDelay: 10 * time.Second, MaxDelay: 5 * time.Minute, LockoutThreshold: 50, LockoutDuration: time.Hour, Window: 24 * time.Hour},
+	},
+}
+
+// LimitError is returned by Check when an attempt must be refused.
+type LimitError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LimitError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts, locked for %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Limiter tracks failures for a set of scopes.
+type Limiter struct {
+	store    Store
+	policies map[string]Policies
+	now      func() time.Time
+}
+
+// NewLimiter returns a limiter backed by Redis when a client is available,
+// falling back to process memory otherwise.
+func NewLimiter(redisClient *redis.Client) *Limiter {
+	var store Store
+	if redisClient != nil {
+		store = &redisStore{client: redisClient}
+	} else {
+		store = newMemoryStore()
+	}
+	return &Limiter{store: store, policies: DefaultPolicies, now: time.Now}
+}
+
+func accountKey(scope, account string) string {
+	return "bruteforce:" + scope + ":account:" + strings.ToLower(strings.TrimSpace(account))
+}
+
+func ipKey(scope, ip string) string {
+	return "bruteforce:" + scope + ":ip:" + ip
+}
+
+// Check returns a *LimitError when the account or IP must wait before trying again.
+// Empty account or ip values are skipped.
+func (l *Limiter) Check(ctx context.Context, scope, account, ip string) error {
+	policies := l.policies[scope]
+	now := l.now()
+	var limit *LimitError
+	check := func(key string, p Policy) error {
+		r, err := l.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if wait, locked := p.wait(r, now); wait > 0 && (limit == nil || wait > limit.RetryAfter) {
+			limit = &LimitError{RetryAfter: wait, Locked: locked}
+		}
+		return nil
+	}
+	if account != "" {
+		if err := check(accountKey(scope, account), policies.Account); err != nil {
+			return err
+		}
+	}
+	if ip != "" {
+		if err := check(ipKey(scope, ip), policies.IP); err != nil {
+			return err
+		}
+	}
+	if limit != nil {
+		return limit
+	}
+	return nil
+}
+
+// Fail records a failed attempt. It reports whether this failure locked the account,
+// so the caller can warn its owner.
+func (l *Limiter) Fail(ctx context.Context, scope, account, ip string) (bool, error) {
+	policies := l.policies[scope]
+	now := l.now()
+	fail := func(key string, p Policy) (bool, error) {
+		r, err := l.store.RecordFailure(ctx, key, now, p.Window)
+		if err != nil {
+			return false, err
+		}
+		if p.LockoutThreshold == 0 || r.Failures < p.LockoutThreshold || now.Before(r.LockedUntil) {
+			return false, nil
+		}
+		until := now.Add(p.LockoutDuration)
+		return true, l.store.Lock(ctx, key, until, max(p.Window, p.LockoutDuration))
+	}
+
+	locked := false
+	if account != "" {
+		var err error
+		if locked, err = fail(accountKey(scope, account), policies.Account); err != nil {
+			return false, err
+		}
+	}
+	if ip != "" {
+		if _, err := fail(ipKey(scope, ip), policies.IP); err != nil {
+			return locked, err
+		}
+	}
+	return locked, nil
+}
+
+// Succeed clears the account's counter after a successful attempt.
+// The IP counter is kept so a client cannot reset it with an account it controls.
+func (l *Limiter) Succeed(ctx context.Context, scope, account string) error {
+	return l.store.Reset(ctx, accountKey(scope, account))
+}
+
+// Unlock clears the account's counters in every scope.
+func (l *Limiter) Unlock(ctx context.Context, account string) error {
+	keys := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		keys = append(keys, accountKey(scope, account))
+	}
+	return l.store.Reset(ctx, keys...)
+}
+
+// Locked reports whether the account is locked out of any scope, and until when.
+func (l *Limiter) Locked(ctx context.Context, account string) (time.Time, bool, error) {
+	now := l.now()
+	var until time.Time
+	for _, scope := range scopes {
+		r, err := l.store.Get(ctx, accountKey(scope, account))
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if r.LockedUntil.After(now) && r.LockedUntil.After(until) {
+			until = r.LockedUntil
+		}
+	}
+	return until, !until.IsZero(), nil
+}
@@ -0,0 +1,109 @@
+package bruteforce
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(now *time.Time) *Limiter {
+	l := NewLimiter(nil)
+	l.policies = map[string]Policies{
+		ScopeLogin: {
+			Account: Policy{FreeAttempts: 2, BaseDelay: time.Second, MaxDelay: 4 * time.Second, LockoutThreshold: 5, LockoutDuration: time.Minute, Window: time.Hour},
+			IP:      Policy{FreeAttempts: 100, Window: time.Hour},
+		},
+	}
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestProgressiveDelayAndLockout(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := newTestLimiter(&now)
+
+	fail := func() bool {
+		t.Helper()
+		locked, err := l.Fail(ctx, ScopeLogin, "User@Example.com", "10.0.0.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return locked
+	}
+	retryAfter := func() time.Duration {
+		t.Helper()
+		err := l.Check(ctx, ScopeLogin, "user@example.com", "10.0.0.2")
+		if err == nil {
+			return 0
+		}
+		var limit *LimitError
+		if !errors.As(err, &limit) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return limit.RetryAfter
+	}
+
+	// Free attempts
+	fail()
+	fail()
+	if d := retryAfter(); d != 0 {
+		t.Fatalf("expected no delay after free attempts, got %s", d)
+	}
+
+	// Delays double and are capped
+	for _, want := range []time.Duration{time.Second, 2 * time.Second} {
+		if fail() {
+			t.Fatal("locked too early")
+		}
+		if d := retryAfter(); d != want {
+			t.Fatalf("expected delay %s, got %s", want, d)
+		}
+		now = now.Add(want)
+	}
+
+	// Reaching the threshold locks the account
+	if !fail() {
+		t.Fatal("expected the fifth failure to lock the account")
+	}
+	if d := retryAfter(); d != time.Minute {
+		t.Fatalf("expected lockout of 1m, got %s", d)
+	}
+	if _, locked, _ := l.Locked(ctx, "user@example.com"); !locked {
+		t.Fatal("expected account to be reported as locked")
+	}
+
+	// Admin unlock clears everything
+	if err := l.Unlock(ctx, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if d := retryAfter(); d != 0 {
+		t.Fatalf("expected no delay after unlock, got %s", d)
+	}
+}
+
+func TestIPCounterSurvivesSuccess(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := newTestLimiter(&now)
+	l.policies[ScopeLogin] = Policies{
+		Account: Policy{FreeAttempts: 100, Window: time.Hour},
+		IP:      Policy{FreeAttempts: 1, BaseDelay: time.Second, MaxDelay: time.Second, Window: time.Hour},
+	}
+
+	for _, account := range []string{"a@example.com", "b@example.com"} {
+		if _, err := l.Fail(ctx, ScopeLogin, account, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Succeed(ctx, ScopeLogin, account); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Check(ctx, ScopeLogin, "c@example.com", "10.0.0.1"); err == nil {
+		t.Fatal("expected the IP to be throttled")
+	}
+	if err := l.Check(ctx, ScopeLogin, "c@example.com", "10.0.0.9"); err != nil {
+		t.Fatalf("expected other IPs to be unaffected, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package bruteforce
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Record is the failure state of one key.
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Store persists failure records. Records expire after their window (or lockout) passes.
+type Store interface {
+	Get(ctx context.Context, key string) (Record, error)
+	RecordFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Record, error)
+	Lock(ctx context.Context, key string, until time.Time, ttl time.Duration) error
+	Reset(ctx context.Context, keys ...string) error
+}
+
+type redisStore struct {
+	client *redis.Client
+}
+
+func parseRecord(values map[string]string) Record {
+	var r Record
+	r.Failures, _ = strconv.Atoi(values["failures"])
+	if v, err := strconv.ParseInt(values["last"], 10, 64); err == nil {
+		r.LastFailure = time.Unix(0, v)
+	}
+	if v, err := strconv.ParseInt(values["locked_until"], 10, 64); err == nil {
+		r.LockedUntil = time.Unix(0, v)
+	}
+	return r
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (Record, error) {
+	values, err := s.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to load failure counter: %w", err)
+	}
+	return parseRecord(values), nil
+}
+
+func (s *redisStore) RecordFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Record, error) {
+	var values *redis.MapStringStringCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, key, "failures", 1)
+		pipe.HSet(ctx, key, "last", at.UnixNano())
+		pipe.Expire(ctx, key, ttl)
+		values = pipe.HGetAll(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to record failure: %w", err)
+	}
+	return parseRecord(values.Val()), nil
+}
+
+func (s *redisStore) Lock(ctx context.Context, key string, until time.Time, ttl time.Duration) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "locked_until", until.UnixNano())
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock key: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) Reset(ctx context.Context, keys ...string) error {
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to reset failure counter: %w", err)
+	}
+	return nil
+}
+
+// memoryStore keeps records in process memory. Counters are per instance,
+// so limits are only approximate when several instances run without Redis.
+type memoryStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryRecord
+	lastSweep time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+const sweepInterval = time.Minute
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]memoryRecord)}
+}
+
+// get must be called with the mutex held.
+func (s *memoryStore) get(key string) memoryRecord {
+	r, ok := s.records[key]
+	if !ok || time.Now().After(r.expiresAt) {
+		delete(s.records, key)
+		return memoryRecord{}
+	}
+	return r
+}
+
+// sweep drops expired records. It must be called with the mutex held.
+func (s *memoryStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, r := range s.records {
+		if now.After(r.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(key).Record, nil
+}
+
+func (s *memoryStore) RecordFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	r := s.get(key)
+	r.Failures++
+	r.LastFailure = at
+	r.expiresAt = time.Now().Add(ttl)
+	s.records[key] = r
+	return r.Record, nil
+}
+
+func (s *memoryStore) Lock(ctx context.Context, key string, until time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.get(key)
+	r.LockedUntil = until
+	r.expiresAt = time.Now().Add(ttl)
+	s.records[key] = r
+	return nil
+}
+
+func (s *memoryStore) Reset(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.records, key)
+	}
+	return nil
+}
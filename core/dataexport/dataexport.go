@@ -0,0 +1,210 @@
+// Package dataexport builds the archive a user downloads to get a copy of their data,
+// and signs the links it is delivered by.
+package dataexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+
+	stripe "github.com/stripe/stripe-go/v76"
+	invoiceapi "github.com/stripe/stripe-go/v76/invoice"
+	"gorm.io/gorm"
+)
+
+const (
+	// TTL is how long a finished export can be downloaded.
+	TTL = 7 * 24 * time.Hour
+
+	// MinInterval is how long a user waits between two exports.
+	MinInterval = 24 * time.Hour
+)
+
+type profile struct {
+	ID               string          `json:"id"`
+	Email            string          `json:"email"`
+	EmailVerifiedAt  *time.Time      `json:"emailVerifiedAt"`
+	Role             string          `json:"role"`
+	AccountStatus    string          `json:"accountStatus"`
+	Plan             string          `json:"plan"`
+	DefaultSubdomain string          `json:"defaultSubdomain"`
+	Profile          json.RawMessage `json:"profile,omitempty"`
+	Settings         json.RawMessage `json:"settings,omitempty"`
+	CreatedAt        time.Time       `json:"createdAt"`
+	UpdatedAt        time.Time       `json:"updatedAt"`
+}
+
+type membership struct {
+	TeamID   string    `json:"teamId"`
+	TeamName string    `json:"teamName"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+type notification struct {
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type communication struct {
+	Type      string     `json:"type"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Status    string     `json:"status"`
+	SentAt    *time.Time `json:"sentAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type subscription struct {
+	PlanID             string    `json:"planId"`
+	Status             string    `json:"status"`
+	CurrentPeriodStart time.Time `json:"currentPeriodStart"`
+	CurrentPeriodEnd   time.Time `json:"currentPeriodEnd"`
+	CancelAtPeriodEnd  bool      `json:"cancelAtPeriodEnd"`
+	CreatedAt          time.Time `json:"createdAt"`
+}
+
+type invoice struct {
+	ID        string    `json:"id"`
+	Number    string    `json:"number"`
+	Status    string    `json:"status"`
+	Currency  string    `json:"currency"`
+	AmountDue float64   `json:"amountDue"`
+	Paid      float64   `json:"amountPaid"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Build collects the user's data and returns it as a ZIP archive of JSON files.
+// Invoices are read from Stripe when stripeKey is set and the user is a customer.
+func Build(db *gorm.DB, stripeKey string, user *models.User) ([]byte, error) {
+	files := map[string]interface{}{
+		"profile.json": profile{
+			ID:               user.ID.String(),
+			Email:            user.Email,
+			EmailVerifiedAt:  user.EmailVerifiedAt,
+			Role:             string(user.Role),
+			AccountStatus:    string(user.AccountStatus),
+			Plan:             user.Plan,
+			DefaultSubdomain: user.DefaultSubdomain,
+			Profile:          json.RawMessage(user.ProfileData),
+			Settings:         json.RawMessage(user.Settings),
+			CreatedAt:        user.CreatedAt,
+			UpdatedAt:        user.UpdatedAt,
+		},
+	}
+
+	var memberships []models.Membership
+	if err := db.Preload("Team").Where("user_id = ?", user.ID).Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to load memberships: %w", err)
+	}
+	teams := make([]membership, 0, len(memberships))
+	for _, m := range memberships {
+		teams = append(teams, membership{TeamID: m.TeamID.String(), TeamName: m.Team.Name, Role: string(m.Role), JoinedAt: m.CreatedAt})
+	}
+	files["memberships.json"] = teams
+
+	var notificationRows []models.Notification
+	if err := db.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&notificationRows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notifications: %w", err)
+	}
+	notifications := make([]notification, 0, len(notificationRows))
+	for _, n := range notificationRows {
+		notifications = append(notifications, notification{Type: n.Type, Title: n.Title, Body: n.Body, Read: n.IsRead, ReadAt: n.ReadAt, CreatedAt: n.CreatedAt})
+	}
+	files["notifications.json"] = notifications
+
+	communicationRows, err := models.FindCommunicationsByUser(db, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load communications: %w", err)
+	}
+	communications := make([]communication, 0, len(communicationRows))
+	for _, c := range communicationRows {
+		communications = append(communications, communication{Type: string(c.Type), Subject: c.Subject, Body: c.Body, Status: c.Status, SentAt: c.SentAt, CreatedAt: c.CreatedAt})
+	}
+	files["communications.json"] = communications
+
+	var subscriptionRows []models.Subscription
+	if err := db.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&subscriptionRows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+	subscriptions := make([]subscription, 0, len(subscriptionRows))
+	for _, s := range subscriptionRows {
+		subscriptions = append(subscriptions, subscription{
+			PlanID:             s.PlanID,
+			Status:             s.Status,
+			CurrentPeriodStart: s.CurrentPeriodStart,
+			CurrentPeriodEnd:   s.CurrentPeriodEnd,
+			CancelAtPeriodEnd:  s.CancelAtPeriodEnd,
+			CreatedAt:          s.CreatedAt,
+		})
+	}
+	files["subscriptions.json"] = subscriptions
+
+	invoices, err := listInvoices(stripeKey, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invoices: %w", err)
+	}
+	files["invoices.json"] = invoices
+
+	return writeArchive(files)
+}
+
+func listInvoices(stripeKey string, user *models.User) ([]invoice, error) {
+	invoices := []invoice{}
+	if stripeKey == "" || user.StripeCustomerID == nil || *user.StripeCustomerID == "" {
+		return invoices, nil
+	}
+
+	stripe.Key = stripeKey
+	params := &stripe.InvoiceListParams{Customer: stripe.String(*user.StripeCustomerID)}
+	i := invoiceapi.List(params)
+	for i.Next() {
+		inv := i.Invoice()
+		invoices = append(invoices, invoice{
+			ID:        inv.ID,
+			Number:    inv.Number,
+			Status:    string(inv.Status),
+			Currency:  string(inv.Currency),
+			AmountDue: float64(inv.AmountDue) / 100.0,
+			Paid:      float64(inv.AmountPaid) / 100.0,
+			CreatedAt: time.Unix(inv.Created, 0).UTC(),
+		})
+	}
+	return invoices, i.Err()
+}
+
+// writeArchive encodes each value as indented JSON into a ZIP file under its name.
+func writeArchive(files map[string]interface{}) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(files[name]); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
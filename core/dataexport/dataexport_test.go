@@ -0,0 +1,72 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWriteArchive(t *testing.T) {
+	archive, err := writeArchive(map[string]interface{}{
+		"profile.json":       profile{Email: "user@example.com"},
+		"notifications.json": []notification{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zr.File) != 2 || zr.File[0].Name != "notifications.json" || zr.File[1].Name != "profile.json" {
+		t.Fatalf("unexpected files in archive: %v", zr.File)
+	}
+	f, err := zr.File[1].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var p profile
+	if err := json.NewDecoder(f).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Email != "user@example.com" {
+		t.Errorf("profile email = %q", p.Email)
+	}
+}
+
+func TestLink(t *testing.T) {
+	id := uuid.New()
+	link := Link("https://app.example.com/", "secret", id, time.Now().Add(time.Hour))
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(u.Path, "/api/auth/data-export/"+id.String()) {
+		t.Fatalf("unexpected link path %q", u.Path)
+	}
+	expires, sig := u.Query().Get("expires"), u.Query().Get("signature")
+
+	if err := VerifyLink("secret", id, expires, sig); err != nil {
+		t.Errorf("valid link rejected: %v", err)
+	}
+	if err := VerifyLink("other", id, expires, sig); err != ErrInvalidLink {
+		t.Errorf("link verified with the wrong secret")
+	}
+	if err := VerifyLink("secret", uuid.New(), expires, sig); err != ErrInvalidLink {
+		t.Errorf("link verified for another export")
+	}
+
+	expired := Link("https://app.example.com", "secret", id, time.Now().Add(-time.Minute))
+	u, _ = url.Parse(expired)
+	if err := VerifyLink("secret", id, u.Query().Get("expires"), u.Query().Get("signature")); err != ErrInvalidLink {
+		t.Errorf("expired link accepted")
+	}
+}
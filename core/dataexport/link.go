@@ -0,0 +1,45 @@
+package dataexport
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidLink is returned for download links that are tampered with or expired.
+var ErrInvalidLink = errors.New("invalid or expired download link")
+
+func signature(secret string, id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "data-export:%s:%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Link returns the signed download URL of an export, valid until expiresAt.
+func Link(baseURL, secret string, id uuid.UUID, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signature(secret, id, expires))
+	return strings.TrimRight(baseURL, "/") + "/api/auth/data-export/" + id.String() + "?" + query.Encode()
+}
+
+// VerifyLink checks the expiry and signature of a download link.
+func VerifyLink(secret string, id uuid.UUID, expires, sig string) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return ErrInvalidLink
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, id, exp))) {
+		return ErrInvalidLink
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package dataexport
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+
+	"gorm.io/gorm"
+)
+
+// staleAfter is how long an export may stay in processing before it is retried.
+const staleAfter = 30 * time.Minute
+
+// Process builds queued exports until none is left. ready is called for every export
+// that can now be downloaded, e.g. to email the user the link.
+func Process(db *gorm.DB, stripeKey string, ready func(user *models.User, export *models.DataExport)) error {
+	if n, err := models.RequeueStaleDataExports(db, time.Now().Add(-staleAfter)); err != nil {
+		return fmt.Errorf("failed to requeue stale exports: %w", err)
+	} else if n > 0 {
+		log.Printf("WARN: Requeued %d stale data exports", n)
+	}
+
+	for {
+		export, err := models.ClaimPendingDataExport(db)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to claim data export: %w", err)
+		}
+
+		user, err := models.FindUserByID(db, export.UserID)
+		if err != nil {
+			log.Printf("ERROR: Data export %s: failed to load user %s: %v", export.ID, export.UserID, err)
+			_ = models.FailDataExport(db, export.ID, "The account could not be loaded")
+			continue
+		}
+		archive, err := Build(db, stripeKey, user)
+		if err != nil {
+			log.Printf("ERROR: Data export %s for user %s failed: %v", export.ID, user.ID, err)
+			_ = models.FailDataExport(db, export.ID, "The export could not be built, please try again later")
+			continue
+		}
+		expiresAt := time.Now().Add(TTL)
+		if err := models.CompleteDataExport(db, export.ID, archive, expiresAt); err != nil {
+			return fmt.Errorf("failed to store data export %s: %w", export.ID, err)
+		}
+		export.Status = models.DataExportReady
+		export.Size = int64(len(archive))
+		export.ExpiresAt = &expiresAt
+		log.Printf("INFO: Data export %s for user %s is ready (%d bytes)", export.ID, user.ID, len(archive))
+		if ready != nil {
+			ready(user, export)
+		}
+	}
+}
@@ -41,12 +41,33 @@ func MigrateAndSeed(db *gorm.DB) error { // Changed signature, renamed function
 		&models.Team{},
 		&models.Membership{},
 		&models.Invitation{},
+		&models.InvitationImport{},
 		&models.Notification{},
 		&models.BlogPost{},
 		&models.Tag{},
 		&models.Category{},
 		&models.Communication{},
 		&models.Setting{},
+		&models.LoginCode{},
+		&models.RecoveryCode{},
+		&models.WebAuthnCredential{},
+		&models.WebAuthnSession{},
+		&models.UserSession{},
+		&models.RefreshToken{},
+		&models.EmailVerificationToken{},
+		&models.EmailChange{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.TeamDomain{},
+		&models.SCIMToken{},
+		&models.SigningKey{},
+		&models.APIToken{},
+		&models.ImpersonationLog{},
+		&models.DataExport{},
+		&models.PasswordHistory{},
+		&models.LoginEvent{},
+		&models.TeamRole{},
+		&models.JoinRequest{},
 		// Add other core models here
 	}
 
@@ -64,6 +85,18 @@ func MigrateAndSeed(db *gorm.DB) error { // Changed signature, renamed function
 	}
 	log.Println("Database migration completed successfully.")
 
+	// Plaintext API keys were replaced by hashed access tokens
+	if db.Migrator().HasColumn(&models.User{}, "api_key") {
+		if err = db.Migrator().DropColumn(&models.User{}, "api_key"); err != nil {
+			log.Printf("WARN: Failed to drop legacy api_key column: %v", err)
+		}
+	}
+
+	// Subscriptions used to belong to users only
+	if err = models.BackfillSubscriptionSubjects(db); err != nil {
+		log.Printf("WARN: Failed to backfill subscription subjects: %v", err)
+	}
+
 	// Seed default settings keys (does not overwrite values)
 	// Use the passed 'db' directly now
 	if err = models.SeedDefaultSettings(db, models.DefaultSettings); err != nil {
@@ -96,6 +129,7 @@ func syncPlansWithStripe(db *gorm.DB) {
 			Name:         "Free",
 			Features:     models.MarshalJSONFeatures([]string{"Basic Features", "Limited Usage"}),
 			PriceMonthly: 0.00,
+			Limits:       models.PlanLimits{MaxSeats: 1},
 			Active:       true, // Ensure Active is true here
 		},
 		{
@@ -104,6 +138,7 @@ func syncPlansWithStripe(db *gorm.DB) {
 			Features:     models.MarshalJSONFeatures([]string{"All Free Features", "Increased Usage Limits", "Team Collaboration (Up to 5 members)"}),
 			PriceMonthly: 7.00,
 			PriceYearly:  floatPtr(70.00),
+			Limits:       models.PlanLimits{MaxSeats: 5},
 			Active:       true, // Ensure Active is true here
 		},
 		{
@@ -148,6 +183,10 @@ func syncPlansWithStripe(db *gorm.DB) {
 				existingPlan.Name = desiredPlan.Name
 				needsUpdate = true
 			}
+			if existingPlan.Limits != desiredPlan.Limits {
+				existingPlan.Limits = desiredPlan.Limits
+				needsUpdate = true
+			}
 			// Add other field comparisons if necessary (Features, PriceMonthly, PriceYearly)
 			// Note: Comparing JSON and float pointers requires careful handling if strict updates are needed.
 			// For seeding, ensuring Active=true and Name matches might be sufficient.
@@ -424,16 +463,6 @@ func createInitialAdminUser(db *gorm.DB) {
 		return
 	}
 
-	// Generate API Key separately and handle error
-	var apiKey string                         // Declare apiKey
-	apiKey, keyErr := models.GenerateAPIKey() // Use := as keyErr is new
-	if keyErr != nil {
-		log.Printf("ERROR: Failed to generate API key for initial admin: %v", keyErr)
-		// Decide if creation should fail or proceed without API key. Let's proceed.
-		apiKey = "" // Ensure apiKey is empty string if generation failed
-	}
-	adminUser.ApiKey = apiKey // Assign generated key (or empty string)
-
 	// Generate Default Subdomain if needed (using the existing function)
 	// adminUser.DefaultSubdomain = generateRandomSubdomain()
 
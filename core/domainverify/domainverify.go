@@ -0,0 +1,75 @@
+// Package domainverify proves that a team controls an email domain through a DNS TXT record.
+package domainverify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/security"
+)
+
+const (
+	// RecordPrefix starts the TXT record value that carries the verification token.
+	RecordPrefix = "stab-domain-verification="
+
+	// tokenLength gives ~190 bits of entropy with the alphanumeric alphabet.
+	tokenLength = 32
+)
+
+// ErrInvalidDomain is returned for values that are not a plain domain name.
+var ErrInvalidDomain = errors.New("invalid domain name")
+
+// lookupTXT is replaced in tests.
+var lookupTXT = net.DefaultResolver.LookupTXT
+
+// NewToken returns a fresh verification token.
+func NewToken() string {
+	return security.RandomString(tokenLength)
+}
+
+// Record returns the TXT record value the domain owner has to publish.
+func Record(token string) string {
+	return RecordPrefix + token
+}
+
+// Normalize lowercases a domain name and checks that it is a plausible hostname,
+// such as "example.com". A leading "@" is dropped so email suffixes are accepted.
+func Normalize(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@")), ".")
+	if len(domain) > 253 || !strings.Contains(domain, ".") {
+		return "", ErrInvalidDomain
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", ErrInvalidDomain
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return "", ErrInvalidDomain
+			}
+		}
+	}
+	return domain, nil
+}
+
+// Check reports whether the domain publishes the verification record for token.
+// A missing record is not an error.
+func Check(ctx context.Context, domain, token string) (bool, error) {
+	records, err := lookupTXT(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	want := Record(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,44 @@
+package domainverify
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"Example.COM":       "example.com",
+		" @corp.example.io": "corp.example.io",
+		"example.com.":      "example.com",
+	} {
+		if got, err := Normalize(in); err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "localhost", "exa mple.com", "-bad.com", "a..b", "user@example.com"} {
+		if _, err := Normalize(in); err == nil {
+			t.Errorf("Normalize(%q) should fail", in)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	defer func(orig func(context.Context, string) ([]string, error)) { lookupTXT = orig }(lookupTXT)
+	lookupTXT = func(ctx context.Context, domain string) ([]string, error) {
+		if domain == "missing.example.com" {
+			return nil, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+		}
+		return []string{"v=spf1 -all", Record("tok")}, nil
+	}
+
+	if ok, err := Check(context.Background(), "example.com", "tok"); !ok || err != nil {
+		t.Fatalf("expected record to be found, got %v %v", ok, err)
+	}
+	if ok, _ := Check(context.Background(), "example.com", "other"); ok {
+		t.Fatal("expected a different token to fail")
+	}
+	if ok, err := Check(context.Background(), "missing.example.com", "tok"); ok || err != nil {
+		t.Fatalf("expected a missing domain to fail without error, got %v %v", ok, err)
+	}
+}
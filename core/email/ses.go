@@ -2,6 +2,7 @@ package email
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -16,6 +17,9 @@ type EmailSender interface {
 	SendEmail(ctx context.Context, to, subject, body string) error
 }
 
+// ErrNoEmailSender is returned when an email is sent without a configured sender.
+var ErrNoEmailSender = errors.New("email sender not configured")
+
 // SESEmailSender implements EmailSender using AWS SES.
 type SESEmailSender struct {
 	sesClient *ses.Client
@@ -0,0 +1,272 @@
+// Package emailchange implements the confirmed email change flow: the new address must confirm
+// a change before it is applied, and the old address can cancel or undo it for a while.
+package emailchange
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// ConfirmTTL is how long the approval and confirmation links stay valid.
+	ConfirmTTL = 24 * time.Hour
+
+	// RevertTTL is how long the old address can cancel or undo the change.
+	RevertTTL = 7 * 24 * time.Hour
+
+	// RequestInterval is the minimum time between two change requests of a user.
+	RequestInterval = time.Minute
+
+	// tokenLength gives ~285 bits of entropy with the alphanumeric alphabet.
+	tokenLength = 48
+)
+
+// Actions name the links sent during a change; they are also the frontend paths.
+const (
+	ActionApprove = "approve"
+	ActionConfirm = "confirm"
+	ActionRevert  = "revert"
+)
+
+var (
+	// ErrInvalidToken is returned for unknown, expired, used or outdated tokens.
+	ErrInvalidToken = errors.New("invalid or expired email change token")
+
+	// ErrEmailTaken is returned when the target address belongs to another account.
+	ErrEmailTaken = errors.New("email address already in use")
+
+	// ErrRequestTooSoon is returned when a change is requested within RequestInterval of the previous one.
+	ErrRequestTooSoon = errors.New("email change requested too recently")
+)
+
+// Tokens holds the plain text tokens of a new change. Confirm is empty until the change is approved.
+type Tokens struct {
+	Approve string
+	Confirm string
+	Revert  string
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (string, *string) {
+	token := security.RandomString(tokenLength)
+	hash := hashToken(token)
+	return token, &hash
+}
+
+// emailInUse reports whether an account other than userID owns the address.
+func emailInUse(db *gorm.DB, email string, userID uuid.UUID) (bool, error) {
+	existing, err := models.FindUserByEmail(db, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return existing.ID != userID, nil
+}
+
+// Start records a requested change, replacing any earlier unapplied one.
+// A password-confirmed request is approved right away; otherwise the old address must approve it first.
+func Start(db *gorm.DB, user *models.User, newEmail string, approved bool) (*models.EmailChange, *Tokens, error) {
+	if pending, err := models.FindPendingEmailChangeByUser(db, user.ID); err == nil {
+		if time.Since(pending.CreatedAt) < RequestInterval {
+			return nil, nil, ErrRequestTooSoon
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	inUse, err := emailInUse(db, newEmail, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if inUse {
+		return nil, nil, ErrEmailTaken
+	}
+
+	now := time.Now()
+	tokens := &Tokens{}
+	change := &models.EmailChange{
+		UserID:          user.ID,
+		OldEmail:        user.Email,
+		NewEmail:        newEmail,
+		ExpiresAt:       now.Add(ConfirmTTL),
+		RevertExpiresAt: now.Add(RevertTTL),
+	}
+	var revertHash *string
+	tokens.Revert, revertHash = newToken()
+	change.RevertTokenHash = *revertHash
+	if approved {
+		tokens.Confirm, change.ConfirmTokenHash = newToken()
+		change.ApprovedAt = &now
+	} else {
+		tokens.Approve, change.ApproveTokenHash = newToken()
+	}
+
+	if err := models.ReplaceEmailChange(db, change); err != nil {
+		return nil, nil, fmt.Errorf("failed to store email change: %w", err)
+	}
+	return change, tokens, nil
+}
+
+// Approve accepts a change from the old address and returns the confirmation token for the new one.
+func Approve(db *gorm.DB, token string) (*models.EmailChange, string, error) {
+	if token == "" {
+		return nil, "", ErrInvalidToken
+	}
+
+	var change *models.EmailChange
+	confirmToken, confirmHash := newToken()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		change, err = models.FindEmailChangeByTokenHashForUpdate(tx, ActionApprove, hashToken(token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		if !change.Pending() || change.ApprovedAt != nil {
+			return ErrInvalidToken
+		}
+		now := time.Now()
+		change.ApprovedAt = &now
+		change.ApproveTokenHash = nil
+		change.ConfirmTokenHash = confirmHash
+		change.ExpiresAt = now.Add(ConfirmTTL)
+		return models.UpdateEmailChange(tx, change)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return change, confirmToken, nil
+}
+
+// Confirm applies a change from the new address. The new address counts as verified and
+// every session of the user is signed out.
+func Confirm(db *gorm.DB, token string) (*models.EmailChange, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	var change *models.EmailChange
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		change, err = models.FindEmailChangeByTokenHashForUpdate(tx, ActionConfirm, hashToken(token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		if !change.Pending() || change.ApprovedAt == nil {
+			return ErrInvalidToken
+		}
+		user, err := models.FindUserByID(tx, change.UserID)
+		if err != nil {
+			return err
+		}
+		// The address changed some other way since the request
+		if !strings.EqualFold(user.Email, change.OldEmail) {
+			return ErrInvalidToken
+		}
+		inUse, err := emailInUse(tx, change.NewEmail, user.ID)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrEmailTaken
+		}
+
+		now := time.Now()
+		if err := models.UpdateUserEmail(tx, user.ID, change.NewEmail, &now); err != nil {
+			return err
+		}
+		change.ConfirmedAt = &now
+		change.ConfirmTokenHash = nil
+		if err := models.UpdateEmailChange(tx, change); err != nil {
+			return err
+		}
+		_, err = models.RevokeUserSessions(tx, user.ID, uuid.Nil, models.SessionRevokedEmailChange)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Revert cancels a pending change or, once applied, restores the old address.
+// Restoring signs out every session since the account may have been taken over.
+func Revert(db *gorm.DB, token string) (*models.EmailChange, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	var change *models.EmailChange
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		change, err = models.FindEmailChangeByTokenHashForUpdate(tx, ActionRevert, hashToken(token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		if change.RevertedAt != nil || time.Now().After(change.RevertExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		now := time.Now()
+		if change.ConfirmedAt != nil {
+			user, err := models.FindUserByID(tx, change.UserID)
+			if err != nil {
+				return err
+			}
+			if !strings.EqualFold(user.Email, change.NewEmail) {
+				return ErrInvalidToken
+			}
+			inUse, err := emailInUse(tx, change.OldEmail, user.ID)
+			if err != nil {
+				return err
+			}
+			if inUse {
+				return ErrEmailTaken
+			}
+			if err := models.UpdateUserEmail(tx, user.ID, change.OldEmail, &now); err != nil {
+				return err
+			}
+			if _, err := models.RevokeUserSessions(tx, user.ID, uuid.Nil, models.SessionRevokedEmailChange); err != nil {
+				return err
+			}
+		}
+		change.RevertedAt = &now
+		change.ApproveTokenHash = nil
+		change.ConfirmTokenHash = nil
+		return models.UpdateEmailChange(tx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Link builds the frontend URL for one of the change actions.
+func Link(frontendURL, action, token string) string {
+	return fmt.Sprintf("%s/email-change/%s?token=%s", strings.TrimRight(frontendURL, "/"), action, url.QueryEscape(token))
+}
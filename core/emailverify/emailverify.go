@@ -0,0 +1,98 @@
+// Package emailverify issues and redeems the single-use links that prove a user owns their email address.
+package emailverify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TokenTTL is how long a verification link stays valid.
+	TokenTTL = 24 * time.Hour
+
+	// tokenLength gives ~285 bits of entropy with the alphanumeric alphabet.
+	tokenLength = 48
+)
+
+// ErrInvalidToken is returned for unknown, expired, used or outdated verification tokens.
+var ErrInvalidToken = errors.New("invalid or expired verification token")
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue creates a verification token for the user's current email address, replacing
+// any earlier one, and returns it in plain text.
+func Issue(db *gorm.DB, user *models.User) (string, error) {
+	token := security.RandomString(tokenLength)
+	record := &models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(TokenTTL),
+	}
+	if err := models.ReplaceEmailVerificationToken(db, record); err != nil {
+		return "", fmt.Errorf("failed to store verification token: %w", err)
+	}
+	return token, nil
+}
+
+// Verify redeems a token and marks the user's email address as verified.
+func Verify(db *gorm.DB, token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	var user *models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		record, err := models.FindEmailVerificationTokenByHashForUpdate(tx, hashToken(token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+			return ErrInvalidToken
+		}
+		user, err = models.FindUserByID(tx, record.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		// The address changed since the link was sent
+		if !strings.EqualFold(user.Email, record.Email) {
+			return ErrInvalidToken
+		}
+		if err := models.MarkEmailVerificationTokenUsed(tx, record); err != nil {
+			return err
+		}
+		return models.MarkUserEmailVerified(tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return user, nil
+}
+
+// Link builds the frontend URL that completes verification.
+func Link(frontendURL, token string) string {
+	return fmt.Sprintf("%s/verify-email?token=%s", strings.TrimRight(frontendURL, "/"), url.QueryEscape(token))
+}
@@ -0,0 +1,56 @@
+package invitations
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAlreadyMember is returned when the email belongs to a member of the team.
+	ErrAlreadyMember = errors.New("This user is already a member of the team")
+
+	// ErrAlreadyInvited is returned when the email has a pending invitation to the team;
+	// it can be resent instead.
+	ErrAlreadyInvited = errors.New("An invitation for this email address is already pending")
+)
+
+// ValidRole reports whether people can be invited with the role. Ownership is transferred,
+// never offered in an invitation.
+func ValidRole(role string) bool {
+	return role == string(models.RoleAdmin) || role == string(models.RoleMember)
+}
+
+// Check returns why the email cannot be invited to the team: ErrAlreadyMember,
+// ErrAlreadyInvited, or a *seats.LimitError when the team has no seat left. Any other
+// error comes from the database.
+func Check(db *gorm.DB, team *models.Team, email string) error {
+	user, err := models.FindUserByEmail(db, email)
+	switch {
+	case err == nil:
+		_, err := models.FindMembershipByUserAndTeam(db, user.ID, team.ID)
+		if err == nil {
+			return ErrAlreadyMember
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to check membership of user %s in team %s: %w", user.ID, team.ID, err)
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("failed to look up user %s: %w", email, err)
+	}
+
+	_, err = models.FindPendingInvitationByTeamAndEmail(db, team.ID, email)
+	if err == nil {
+		return ErrAlreadyInvited
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check invitations of %s to team %s: %w", email, team.ID, err)
+	}
+
+	// The invitation takes a seat on the team's plan
+	return seats.CheckInvite(db, team)
+}
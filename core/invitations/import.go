@@ -0,0 +1,270 @@
+package invitations
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxImportSize caps the size of an uploaded CSV file.
+	MaxImportSize = 1 << 20
+
+	// MaxImportRows caps how many people one file can invite.
+	MaxImportRows = 1000
+
+	// staleImportAfter is how long an import may stay in processing before it is retried.
+	staleImportAfter = 30 * time.Minute
+)
+
+var (
+	// ErrEmptyImport is returned when a CSV file lists nobody to invite.
+	ErrEmptyImport = errors.New("The file lists nobody to invite")
+
+	// ErrImportTooLarge is returned when a CSV file lists more than MaxImportRows people.
+	ErrImportTooLarge = fmt.Errorf("A file can invite at most %d people", MaxImportRows)
+)
+
+// ImportRow is one person listed in an invitation CSV file.
+type ImportRow struct {
+	Line  int
+	Email string
+	Role  string
+}
+
+// ParseCSV reads the people listed in an invitation CSV file. Each record holds an email
+// address and optionally a role; people without a role are invited as members. A first
+// row naming the columns, e.g. "email,role", is optional and may list them in any order.
+func ParseCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	emailCol, roleCol := 0, 1
+	var rows []ImportRow
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("The file is not a valid CSV file: %w", err)
+		}
+		if first {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff") // Byte order mark written by spreadsheets
+			if e, r, ok := parseHeader(record); ok {
+				emailCol, roleCol = e, r
+				continue
+			}
+		}
+		line, _ := reader.FieldPos(0)
+		row := ImportRow{
+			Line:  line,
+			Email: strings.TrimSpace(field(record, emailCol)),
+			Role:  strings.ToLower(strings.TrimSpace(field(record, roleCol))),
+		}
+		if row.Email == "" && row.Role == "" {
+			continue
+		}
+		if row.Role == "" {
+			row.Role = string(models.RoleMember)
+		}
+		if len(rows) == MaxImportRows {
+			return nil, ErrImportTooLarge
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmptyImport
+	}
+	return rows, nil
+}
+
+// parseHeader returns the columns of a header row naming an email column. The role
+// column is -1 when the header has none.
+func parseHeader(record []string) (emailCol, roleCol int, ok bool) {
+	emailCol, roleCol = -1, -1
+	for i, name := range record {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "email", "e-mail", "email address":
+			emailCol = i
+		case "role":
+			roleCol = i
+		}
+	}
+	return emailCol, roleCol, emailCol >= 0
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// rowProblem returns why the row cannot be invited regardless of the team, or "" when it
+// can be checked against the team.
+func rowProblem(row ImportRow) string {
+	if row.Email == "" {
+		return "The email address is missing"
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		return "Invalid email address"
+	}
+	if !ValidRole(row.Role) {
+		return fmt.Sprintf("Invalid role %q. Must be 'admin' or 'member'", row.Role)
+	}
+	return ""
+}
+
+// Import invites the listed people to the team with the same checks as a single
+// invitation. People who are already members or invited, or listed twice, are skipped;
+// rows offering a role with permissions the inviter does not hold fail. invited is called
+// for every invitation created, e.g. to email it.
+func Import(db *gorm.DB, team *models.Team, inviter *models.User, rows []ImportRow, invited func(invitation *models.Invitation)) ([]models.InvitationImportRow, error) {
+	held, err := permissions.Effective(context.Background(), db, inviter, team)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions of user %s in team %s: %w", inviter.ID, team.ID, err)
+	}
+	results := make([]models.InvitationImportRow, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		result := models.InvitationImportRow{Line: row.Line, Email: row.Email, Role: row.Role}
+		key := strings.ToLower(row.Email)
+		if problem := rowProblem(row); problem != "" {
+			result.Status, result.Message = models.ImportRowFailed, problem
+			results = append(results, result)
+			continue
+		}
+		if !permissions.Includes(held, permissions.RolePermissions(models.Role(row.Role))) {
+			result.Status, result.Message = models.ImportRowFailed, "You cannot invite people with a role that has permissions you do not have"
+			results = append(results, result)
+			continue
+		}
+		if seen[key] {
+			result.Status, result.Message = models.ImportRowSkipped, "Listed more than once in the file"
+			results = append(results, result)
+			continue
+		}
+		seen[key] = true
+
+		err := Check(db, team, row.Email)
+		var limitErr *seats.LimitError
+		switch {
+		case errors.Is(err, ErrAlreadyMember), errors.Is(err, ErrAlreadyInvited):
+			result.Status, result.Message = models.ImportRowSkipped, err.Error()
+		case errors.As(err, &limitErr):
+			result.Status, result.Message = models.ImportRowFailed, limitErr.Error()
+		case err != nil:
+			return nil, err
+		default:
+			invitation := &models.Invitation{
+				Email:     row.Email,
+				TeamID:    team.ID,
+				InviterID: inviter.ID,
+				Role:      models.Role(row.Role),
+				Status:    models.StatusPending,
+			}
+			if err := models.CreateInvitation(db, invitation); err != nil {
+				return nil, fmt.Errorf("failed to invite %s to team %s: %w", row.Email, team.ID, err)
+			}
+			result.Status = models.ImportRowInvited
+			if invited != nil {
+				invited(invitation)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ProcessImports works through queued invitation imports until none is left. invited is
+// called for every invitation created and done for every import that completed or failed,
+// e.g. to tell the uploader.
+func ProcessImports(db *gorm.DB, invited func(invitation *models.Invitation, team *models.Team, inviter *models.User), done func(imp *models.InvitationImport, team *models.Team)) error {
+	if n, err := models.RequeueStaleInvitationImports(db, time.Now().Add(-staleImportAfter)); err != nil {
+		return fmt.Errorf("failed to requeue stale imports: %w", err)
+	} else if n > 0 {
+		log.Printf("WARN: Requeued %d stale invitation imports", n)
+	}
+
+	for {
+		imp, err := models.ClaimPendingInvitationImport(db)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to claim invitation import: %w", err)
+		}
+
+		team, err := models.FindTeamByID(db, imp.TeamID)
+		if err != nil {
+			log.Printf("ERROR: Invitation import %s: failed to load team %s: %v", imp.ID, imp.TeamID, err)
+			_ = models.FailInvitationImport(db, imp.ID, "The team could not be loaded")
+			continue
+		}
+
+		results, reason := processImport(db, imp, team, invited)
+		if reason != "" {
+			if err := models.FailInvitationImport(db, imp.ID, reason); err != nil {
+				return fmt.Errorf("failed to store invitation import %s: %w", imp.ID, err)
+			}
+			imp.Status = models.InvitationImportFailed
+			imp.Error = reason
+		} else {
+			if err := models.CompleteInvitationImport(db, imp, results); err != nil {
+				return fmt.Errorf("failed to store invitation import %s: %w", imp.ID, err)
+			}
+			log.Printf("INFO: Invitation import %s for team %s: %d invited, %d skipped, %d failed", imp.ID, team.ID, imp.Invited, imp.Skipped, imp.Failed)
+		}
+		if done != nil {
+			done(imp, team)
+		}
+	}
+}
+
+// processImport invites the people in the import's file. It returns why the import
+// failed as a whole, or "" along with the outcome of every row.
+func processImport(db *gorm.DB, imp *models.InvitationImport, team *models.Team, invited func(invitation *models.Invitation, team *models.Team, inviter *models.User)) ([]models.InvitationImportRow, string) {
+	inviter, err := models.FindUserByID(db, imp.InviterID)
+	if err != nil {
+		log.Printf("ERROR: Invitation import %s: failed to load user %s: %v", imp.ID, imp.InviterID, err)
+		return nil, "The account that uploaded the file could not be loaded"
+	}
+	// The uploader may have lost the permission since
+	if err := permissions.Authorize(context.Background(), db, inviter, team, permissions.MembersInvite); err != nil {
+		if !errors.Is(err, permissions.ErrNotMember) && !errors.Is(err, permissions.ErrForbidden) {
+			log.Printf("ERROR: Invitation import %s: failed to check permissions of user %s: %v", imp.ID, inviter.ID, err)
+			return nil, "The import could not be completed, please try again later"
+		}
+		return nil, "You no longer have permission to invite members to this team"
+	}
+	rows, err := ParseCSV(bytes.NewReader(imp.File))
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	results, err := Import(db, team, inviter, rows, func(invitation *models.Invitation) {
+		if invited != nil {
+			invited(invitation, team, inviter)
+		}
+	})
+	if err != nil {
+		log.Printf("ERROR: Invitation import %s for team %s failed: %v", imp.ID, team.ID, err)
+		return nil, "The import could not be completed, please try again later"
+	}
+	return results, ""
+}
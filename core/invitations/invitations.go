@@ -0,0 +1,104 @@
+// Package invitations checks and writes the emails that invite people to a team, invites
+// people listed in uploaded CSV files, and expires the invitations nobody answered.
+package invitations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/types"
+
+	"gorm.io/gorm"
+)
+
+// expireBatch caps how many invitations one run of Expire handles.
+const expireBatch = 500
+
+var emailTemplate = template.Must(template.New("invitation").Parse(
+	`{{.InviterName}} has invited you to join {{.TeamName}} as {{if eq .Role "admin"}}an admin{{else}}a member{{end}}.
+
+Accept the invitation from the link below:
+
+{{.Link}}
+
+The link expires on {{.ExpiresAt}}. If you weren't expecting this invitation, you can ignore this email.`))
+
+// Email is the content of an invitation email.
+type Email struct {
+	Subject string
+	Body    string
+}
+
+// Link builds the frontend URL where the invitation is accepted.
+func Link(frontendURL, token string) string {
+	return fmt.Sprintf("%s/accept-invite/%s", strings.TrimRight(frontendURL, "/"), url.PathEscape(token))
+}
+
+// DisplayName returns the user's full name from their profile, or their email address
+// when the profile has no name.
+func DisplayName(user *models.User) string {
+	var profile types.UserProfileData
+	if user.ProfileData != nil {
+		_ = json.Unmarshal(user.ProfileData, &profile) // Names are informational only
+	}
+	if name := strings.TrimSpace(profile.FirstName + " " + profile.LastName); name != "" {
+		return name
+	}
+	return user.Email
+}
+
+// Compose writes the email inviting someone to the team.
+func Compose(frontendURL string, invitation *models.Invitation, team *models.Team, inviter *models.User) (Email, error) {
+	var body bytes.Buffer
+	err := emailTemplate.Execute(&body, struct {
+		InviterName string
+		TeamName    string
+		Role        models.Role
+		Link        string
+		ExpiresAt   string
+	}{
+		InviterName: DisplayName(inviter),
+		TeamName:    team.Name,
+		Role:        invitation.Role,
+		Link:        Link(frontendURL, invitation.Token),
+		ExpiresAt:   invitation.ExpiresAt.UTC().Format(time.RFC1123),
+	})
+	if err != nil {
+		return Email{}, err
+	}
+	return Email{
+		Subject: fmt.Sprintf("You're Invited to Join %s", team.Name),
+		Body:    body.String(),
+	}, nil
+}
+
+// Expire marks pending invitations whose link expired before now as expired and calls
+// notify for each one. It returns how many invitations it expired.
+func Expire(db *gorm.DB, now time.Time, notify func(invitation *models.Invitation)) (int, error) {
+	invitations, err := models.FindExpiredPendingInvitations(db, now, expireBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load expired invitations: %w", err)
+	}
+	expired := 0
+	for i := range invitations {
+		ok, err := models.ExpireInvitation(db, invitations[i].ID)
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire invitation %s: %w", invitations[i].ID, err)
+		}
+		if !ok {
+			continue
+		}
+		expired++
+		invitations[i].Status = models.StatusExpired
+		if notify != nil {
+			notify(&invitations[i])
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,96 @@
+package invitations
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+)
+
+func TestCompose(t *testing.T) {
+	invitation := &models.Invitation{
+		Role:      models.RoleAdmin,
+		Token:     "abc123",
+		ExpiresAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	team := &models.Team{Name: "Acme"}
+	inviter := &models.User{Email: "ada@example.com", ProfileData: []byte(`{"firstName": "Ada", "lastName": "Lovelace"}`)}
+
+	email, err := Compose("https://app.example.com/", invitation, team, inviter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email.Subject != "You're Invited to Join Acme" {
+		t.Errorf("Subject = %q", email.Subject)
+	}
+	for _, want := range []string{
+		"Ada Lovelace has invited you to join Acme as an admin.",
+		"https://app.example.com/accept-invite/abc123",
+		"Sun, 01 Mar 2026 12:00:00 UTC",
+	} {
+		if !strings.Contains(email.Body, want) {
+			t.Errorf("Body misses %q:\n%s", want, email.Body)
+		}
+	}
+}
+
+func TestDisplayNameFallsBackToEmail(t *testing.T) {
+	if got := DisplayName(&models.User{Email: "ada@example.com"}); got != "ada@example.com" {
+		t.Errorf("DisplayName = %q", got)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []ImportRow
+	}{
+		{
+			name: "without header",
+			csv:  "ada@example.com,admin\ngrace@example.com\n",
+			want: []ImportRow{{1, "ada@example.com", "admin"}, {2, "grace@example.com", "member"}},
+		},
+		{
+			name: "header in any order",
+			csv:  "\ufeffRole,Name,Email\nAdmin,Ada,ada@example.com\n\n,Grace,grace@example.com\n",
+			want: []ImportRow{{2, "ada@example.com", "admin"}, {4, "grace@example.com", "member"}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ParseCSV(strings.NewReader(tt.csv))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseCSV = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := ParseCSV(strings.NewReader("email,role\n")); err != ErrEmptyImport {
+		t.Errorf("header only: err = %v, want ErrEmptyImport", err)
+	}
+	if _, err := ParseCSV(strings.NewReader(strings.Repeat("ada@example.com\n", MaxImportRows+1))); err != ErrImportTooLarge {
+		t.Errorf("too many rows: err = %v, want ErrImportTooLarge", err)
+	}
+}
+
+func TestRowProblem(t *testing.T) {
+	tests := []struct {
+		row ImportRow
+		ok  bool
+	}{
+		{ImportRow{Email: "ada@example.com", Role: "admin"}, true},
+		{ImportRow{Email: "ada@example.com", Role: "owner"}, false},
+		{ImportRow{Email: "Ada <ada@example.com>", Role: "member"}, false},
+		{ImportRow{Email: "not an email", Role: "member"}, false},
+		{ImportRow{Role: "member"}, false},
+	}
+	for _, tt := range tests {
+		if got := rowProblem(tt.row); (got == "") != tt.ok {
+			t.Errorf("rowProblem(%+v) = %q", tt.row, got)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+// Package keyring signs and verifies access tokens with asymmetric keys identified by
+// a "kid" header. Rotating the keys keeps the previous ones verifying for a grace
+// window, so signed-in users stay signed in, and other services can verify tokens
+// with the public keys published as a JWKS.
+package keyring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultGracePeriod is how long a rotated-out key keeps verifying tokens.
+	DefaultGracePeriod = 24 * time.Hour
+
+	// reloadInterval is how often keys rotated by other instances are picked up.
+	reloadInterval = 5 * time.Minute
+	// missReloadInterval limits reloads caused by tokens with an unknown kid.
+	missReloadInterval = 30 * time.Second
+)
+
+var (
+	// ErrUnknownKey is returned for tokens signed with a key the ring does not hold.
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrSymmetric is returned when rotating while signing with the shared secret.
+	ErrSymmetric = errors.New("tokens are signed with the shared secret; set Auth.SigningAlgorithm to RS256 or EdDSA")
+)
+
+// Store persists signing keys.
+type Store interface {
+	// Load returns the keys that have not expired, newest first.
+	Load(ctx context.Context) ([]models.SigningKey, error)
+	// Create stores a new key.
+	Create(ctx context.Context, key *models.SigningKey) error
+	// Retire retires every key except the given one; they expire at expiresAt.
+	Retire(ctx context.Context, exceptID uuid.UUID, expiresAt time.Time) error
+	// SetPrivateKey replaces the stored private key, e.g. with its encrypted form.
+	SetPrivateKey(ctx context.Context, id uuid.UUID, privateKey string) error
+}
+
+// Ring holds the current signing key and the keys still accepted for verification.
+type Ring struct {
+	store     Store
+	algorithm string
+	grace     time.Duration
+	secret    string  // Shared secret of the HS256 mode
+	sealer    *sealer // Encrypts private keys at rest; nil stores them as plain PEM
+
+	mu         sync.RWMutex
+	current    *key
+	keys       map[string]*key
+	loadedAt   time.Time
+	missLoaded time.Time
+}
+
+// New loads the key ring, creating the first key when there is none for the configured
+// algorithm. With Auth.SigningAlgorithm HS256 tokens keep being signed with Auth.AccessSecret.
+func New(ctx context.Context, store Store, cfg *config.Config) (*Ring, error) {
+	r := &Ring{
+		store:     store,
+		algorithm: cfg.Auth.SigningAlgorithm,
+		grace:     time.Duration(cfg.Auth.KeyGracePeriod) * time.Second,
+		secret:    cfg.Auth.AccessSecret,
+		keys:      make(map[string]*key),
+	}
+	if r.algorithm == "" {
+		r.algorithm = AlgorithmRS256
+	}
+	switch r.algorithm {
+	case AlgorithmHS256:
+		return r, nil
+	case AlgorithmRS256, AlgorithmEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", r.algorithm)
+	}
+
+	sealer, err := newSealer(cfg.Auth.KeyEncryptionSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up signing key encryption: %w", err)
+	}
+	if sealer == nil {
+		log.Printf("WARN: Auth.KeyEncryptionSecret is not set; signing keys are stored unencrypted")
+	}
+	r.sealer = sealer
+
+	// Tokens signed just before a rotation must outlive it, including on instances
+	// that have not reloaded yet
+	if r.grace <= 0 {
+		r.grace = DefaultGracePeriod
+	}
+	if minimum := time.Duration(cfg.Auth.AccessExpire)*time.Second + reloadInterval; r.grace < minimum {
+		r.grace = minimum
+	}
+
+	if err := r.reload(ctx); err != nil {
+		return nil, err
+	}
+	if r.current == nil || r.current.algorithm != r.algorithm {
+		if _, err := r.Rotate(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Algorithm returns the algorithm new tokens are signed with.
+func (r *Ring) Algorithm() string {
+	return r.algorithm
+}
+
+func (r *Ring) symmetric() bool {
+	return r.algorithm == AlgorithmHS256
+}
+
+// reload replaces the keys with the stored ones.
+func (r *Ring) reload(ctx context.Context) error {
+	stored, err := r.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make(map[string]*key, len(stored))
+	var current *key
+	for _, s := range stored {
+		privatePEM, err := r.sealer.open(s.KID, s.PrivateKey)
+		if err != nil {
+			log.Printf("WARN: Skipping unreadable signing key %s: %v", s.KID, err)
+			continue
+		}
+		private, err := parsePrivateKey(privatePEM)
+		if err != nil {
+			log.Printf("WARN: Skipping unreadable signing key %s: %v", s.KID, err)
+			continue
+		}
+		if r.sealer != nil && !sealed(s.PrivateKey) {
+			r.sealKey(ctx, s.ID, s.KID, privatePEM)
+		}
+		k := &key{kid: s.KID, algorithm: s.Algorithm, private: private, public: private.Public(), retired: s.RetiredAt != nil}
+		keys[k.kid] = k
+		if current == nil && !k.retired {
+			current = k
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = keys
+	r.current = current
+	r.loadedAt = time.Now()
+	return nil
+}
+
+// sealKey encrypts a key stored before Auth.KeyEncryptionSecret was set. Failures are
+// logged and retried on the next reload.
+func (r *Ring) sealKey(ctx context.Context, id uuid.UUID, kid, privatePEM string) {
+	sealedPEM, err := r.sealer.seal(kid, privatePEM)
+	if err == nil {
+		err = r.store.SetPrivateKey(ctx, id, sealedPEM)
+	}
+	if err != nil {
+		log.Printf("WARN: Failed to encrypt signing key %s: %v", kid, err)
+		return
+	}
+	log.Printf("INFO: Encrypted signing key %s", kid)
+}
+
+// refresh reloads the keys when they have not been loaded recently.
+func (r *Ring) refresh(ctx context.Context) {
+	r.mu.RLock()
+	stale := time.Since(r.loadedAt) > reloadInterval
+	r.mu.RUnlock()
+	if stale {
+		if err := r.reload(ctx); err != nil {
+			log.Printf("WARN: %v", err)
+		}
+	}
+}
+
+// Rotate creates a new signing key and retires the others, which keep verifying
+// tokens for the grace period. It returns the new key ID.
+func (r *Ring) Rotate(ctx context.Context) (string, error) {
+	if r.symmetric() {
+		return "", ErrSymmetric
+	}
+
+	privatePEM, publicPEM, err := generateKey(r.algorithm)
+	if err != nil {
+		return "", err
+	}
+	private, err := parsePrivateKey(privatePEM)
+	if err != nil {
+		return "", err
+	}
+	kid, err := thumbprint(private.Public())
+	if err != nil {
+		return "", err
+	}
+
+	storedPEM, err := r.sealer.seal(kid, privatePEM)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+
+	stored := &models.SigningKey{KID: kid, Algorithm: r.algorithm, PrivateKey: storedPEM, PublicKey: publicPEM}
+	if err := r.store.Create(ctx, stored); err != nil {
+		return "", fmt.Errorf("failed to store signing key: %w", err)
+	}
+	if err := r.store.Retire(ctx, stored.ID, time.Now().Add(r.grace)); err != nil {
+		return "", fmt.Errorf("failed to retire previous signing keys: %w", err)
+	}
+	if err := r.reload(ctx); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
+
+// Sign returns a token with the claims that expires after secondsDuration.
+func (r *Ring) Sign(payload jwt.MapClaims, secondsDuration int64) (string, error) {
+	if r.symmetric() {
+		return security.NewJWT(payload, r.secret, secondsDuration)
+	}
+	r.refresh(context.Background())
+
+	r.mu.RLock()
+	current := r.current
+	r.mu.RUnlock()
+	if current == nil {
+		return "", errors.New("no signing key")
+	}
+
+	claims := jwt.MapClaims{
+		"exp": time.Now().Add(time.Duration(secondsDuration) * time.Second).Unix(),
+	}
+	for k, v := range payload {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(current.method(), claims)
+	token.Header["kid"] = current.kid
+	return token.SignedString(current.private)
+}
+
+// VerificationKey returns the key that verifies a token with the kid and alg headers.
+// It suits the key funcs of the jwt libraries.
+func (r *Ring) VerificationKey(kid, algorithm string) (interface{}, error) {
+	if r.symmetric() {
+		if algorithm != AlgorithmHS256 {
+			return nil, fmt.Errorf("unexpected signing algorithm %q", algorithm)
+		}
+		return []byte(r.secret), nil
+	}
+
+	k := r.lookup(kid)
+	if k == nil {
+		// Another instance may have rotated the keys
+		r.mu.Lock()
+		reload := time.Since(r.missLoaded) > missReloadInterval
+		if reload {
+			r.missLoaded = time.Now()
+		}
+		r.mu.Unlock()
+		if reload {
+			if err := r.reload(context.Background()); err != nil {
+				log.Printf("WARN: %v", err)
+			}
+			k = r.lookup(kid)
+		}
+	}
+	if k == nil {
+		return nil, ErrUnknownKey
+	}
+	if k.algorithm != algorithm {
+		return nil, fmt.Errorf("unexpected signing algorithm %q for key %s", algorithm, kid)
+	}
+	return k.public, nil
+}
+
+func (r *Ring) lookup(kid string) *key {
+	if kid == "" {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys[kid]
+}
+
+// Parse verifies a token and returns its claims.
+func (r *Ring) Parse(token string) (jwt.MapClaims, error) {
+	if r.symmetric() {
+		return security.ParseJWT(token, r.secret)
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}))
+	parsed, err := parser.Parse(token, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return r.VerificationKey(kid, t.Method.Alg())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := parsed.Claims.(jwt.MapClaims); ok && parsed.Valid {
+		return claims, nil
+	}
+	return nil, errors.New("unable to parse token")
+}
+
+// JWKS returns the public keys that verify tokens, the current one first.
+func (r *Ring) JWKS() (*JWKS, error) {
+	set := &JWKS{Keys: []JWK{}}
+	if r.symmetric() {
+		return set, nil
+	}
+	r.refresh(context.Background())
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.current != nil {
+		jwk, err := publicJWK(r.current.public, r.current.kid, r.current.algorithm)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	kids := make([]string, 0, len(r.keys))
+	for kid, k := range r.keys {
+		if k != r.current {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Strings(kids)
+	for _, kid := range kids {
+		k := r.keys[kid]
+		jwk, err := publicJWK(k.public, k.kid, k.algorithm)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
@@ -0,0 +1,180 @@
+package keyring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// memoryStore keeps keys in memory for tests.
+type memoryStore struct {
+	mu   sync.Mutex
+	keys []models.SigningKey
+}
+
+func (s *memoryStore) Load(ctx context.Context) ([]models.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var usable []models.SigningKey
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if k := s.keys[i]; k.ExpiresAt == nil || k.ExpiresAt.After(time.Now()) {
+			usable = append(usable, k)
+		}
+	}
+	return usable, nil
+}
+
+func (s *memoryStore) Create(ctx context.Context, key *models.SigningKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key.ID = uuid.New()
+	s.keys = append(s.keys, *key)
+	return nil
+}
+
+func (s *memoryStore) Retire(ctx context.Context, exceptID uuid.UUID, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for i := range s.keys {
+		if s.keys[i].ID != exceptID && s.keys[i].RetiredAt == nil {
+			s.keys[i].RetiredAt, s.keys[i].ExpiresAt = &now, &expiresAt
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) SetPrivateKey(ctx context.Context, id uuid.UUID, privateKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].ID == id {
+			s.keys[i].PrivateKey = privateKey
+		}
+	}
+	return nil
+}
+
+func testConfig(algorithm string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Auth.AccessSecret = "shared-secret"
+	cfg.Auth.AccessExpire = 900
+	cfg.Auth.SigningAlgorithm = algorithm
+	return cfg
+}
+
+func TestRotation(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			ctx := context.Background()
+			store := &memoryStore{}
+			ring, err := New(ctx, store, testConfig(algorithm))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			oldToken, err := ring.Sign(jwt.MapClaims{"id": "user-1"}, 60)
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldKID, err := ring.Rotate(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			newToken, _ := ring.Sign(jwt.MapClaims{"id": "user-1"}, 60)
+
+			// Tokens of the retired key verify during the grace window
+			for _, token := range []string{oldToken, newToken} {
+				claims, err := ring.Parse(token)
+				if err != nil || claims["id"] != "user-1" {
+					t.Fatalf("failed to verify token: %v", err)
+				}
+			}
+			set, _ := ring.JWKS()
+			if len(set.Keys) != 2 || set.Keys[0].Kid != oldKID || set.Keys[0].Alg != algorithm {
+				t.Fatalf("unexpected JWKS %+v", set)
+			}
+
+			// Once the grace window has passed they do not
+			past := time.Now().Add(-time.Second)
+			store.keys[0].ExpiresAt = &past
+			if err := ring.reload(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ring.Parse(oldToken); !errors.Is(err, ErrUnknownKey) {
+				t.Fatalf("expected expired key to be rejected, got %v", err)
+			}
+			if _, err := ring.Parse(newToken); err != nil {
+				t.Fatalf("failed to verify token of the current key: %v", err)
+			}
+		})
+	}
+}
+
+func TestRejectsSharedSecretTokens(t *testing.T) {
+	cfg := testConfig(AlgorithmRS256)
+	ring, err := New(context.Background(), &memoryStore{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, _ := security.NewJWT(jwt.MapClaims{"id": "user-1"}, cfg.Auth.AccessSecret, 60)
+	if _, err := ring.Parse(legacy); err == nil {
+		t.Fatal("expected HS256 token to be rejected by the asymmetric ring")
+	}
+
+	// The HS256 mode keeps the old behaviour and has nothing to publish or rotate
+	legacyRing, _ := New(context.Background(), &memoryStore{}, testConfig(AlgorithmHS256))
+	if _, err := legacyRing.Parse(legacy); err != nil {
+		t.Fatalf("HS256 ring rejected a shared secret token: %v", err)
+	}
+	if _, err := legacyRing.Rotate(context.Background()); !errors.Is(err, ErrSymmetric) {
+		t.Fatalf("expected ErrSymmetric, got %v", err)
+	}
+}
+
+func TestEncryptsKeysAtRest(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	plain, err := New(ctx, store, testConfig(AlgorithmEdDSA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := plain.Sign(jwt.MapClaims{"id": "user-1"}, 60)
+	if sealed(store.keys[0].PrivateKey) {
+		t.Fatal("key stored encrypted without a secret")
+	}
+
+	// Setting a secret encrypts the existing key in place
+	cfg := testConfig(AlgorithmEdDSA)
+	cfg.Auth.KeyEncryptionSecret = "key-encryption-secret"
+	ring, err := New(ctx, store, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.keys) != 1 || !sealed(store.keys[0].PrivateKey) {
+		t.Fatalf("existing key was not encrypted: %+v", store.keys)
+	}
+	if _, err := ring.Parse(token); err != nil {
+		t.Fatalf("failed to verify token of the encrypted key: %v", err)
+	}
+
+	// Without the secret the key cannot be read
+	wrong := testConfig(AlgorithmEdDSA)
+	wrong.Auth.KeyEncryptionSecret = "another-secret"
+	other, err := New(ctx, store, wrong)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Parse(token); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected key encrypted with another secret to be unreadable, got %v", err)
+	}
+}
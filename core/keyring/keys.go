@@ -0,0 +1,144 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Supported signing algorithms.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmHS256 = "HS256" // Legacy: sign with the shared Auth.AccessSecret
+)
+
+const rsaKeyBits = 2048
+
+// key is a parsed signing key.
+type key struct {
+	kid       string
+	algorithm string
+	private   crypto.Signer
+	public    crypto.PublicKey
+	retired   bool
+}
+
+func (k *key) method() jwt.SigningMethod {
+	if k.algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// generateKey creates a key pair for the algorithm and returns it PEM encoded.
+func generateKey(algorithm string) (privatePEM, publicPEM string, err error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", "", fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", "", err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return "", "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})), nil
+}
+
+// parsePrivateKey decodes a PKCS #8 PEM private key.
+func parsePrivateKey(privatePEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+	return signer, nil
+}
+
+// thumbprint derives a key ID from the public key (RFC 7638).
+func thumbprint(public crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(public, "", "")
+	if err != nil {
+		return "", err
+	}
+	var canonical string
+	switch jwk.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	default:
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, jwk.Crv, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func publicJWK(public crypto.PublicKey, kid, algorithm string) (JWK, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: algorithm,
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: algorithm,
+			Kid: kid,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	}
+	return JWK{}, fmt.Errorf("unsupported public key type %T", public)
+}
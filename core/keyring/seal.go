@@ -0,0 +1,79 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// sealedPrefix marks private keys encrypted with Auth.KeyEncryptionSecret.
+const sealedPrefix = "enc:v1:"
+
+// ErrSealed is returned for an encrypted private key when no secret, or another secret,
+// is configured.
+var ErrSealed = errors.New("signing key is encrypted; set Auth.KeyEncryptionSecret to the secret it was stored with")
+
+// sealer encrypts private keys at rest with AES-256-GCM keyed from a secret. The key ID is
+// bound to the ciphertext so encrypted keys cannot be swapped between rows. A nil sealer
+// stores keys as plain PEM.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(secret string) (*sealer, error) {
+	if secret == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal returns the private key PEM as stored.
+func (s *sealer) seal(kid, privatePEM string) (string, error) {
+	if s == nil {
+		return privatePEM, nil
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(privatePEM), []byte(kid))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open returns the private key PEM of a stored key. Keys stored before a secret was
+// configured are returned as they are.
+func (s *sealer) open(kid, stored string) (string, error) {
+	if !sealed(stored) {
+		return stored, nil
+	}
+	if s == nil {
+		return "", ErrSealed
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, sealedPrefix))
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return "", errors.New("malformed encrypted signing key")
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return "", ErrSealed
+	}
+	return string(plain), nil
+}
+
+func sealed(stored string) bool {
+	return strings.HasPrefix(stored, sealedPrefix)
+}
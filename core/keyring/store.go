@@ -0,0 +1,36 @@
+package keyring
+
+import (
+	"context"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DBStore keeps signing keys in the database so all instances share them.
+type DBStore struct {
+	db *gorm.DB
+}
+
+func NewDBStore(db *gorm.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+func (s *DBStore) Load(ctx context.Context) ([]models.SigningKey, error) {
+	return models.FindUsableSigningKeys(s.db.WithContext(ctx))
+}
+
+func (s *DBStore) Create(ctx context.Context, key *models.SigningKey) error {
+	return models.CreateSigningKey(s.db.WithContext(ctx), key)
+}
+
+func (s *DBStore) Retire(ctx context.Context, exceptID uuid.UUID, expiresAt time.Time) error {
+	return models.RetireSigningKeys(s.db.WithContext(ctx), exceptID, expiresAt)
+}
+
+func (s *DBStore) SetPrivateKey(ctx context.Context, id uuid.UUID, privateKey string) error {
+	return models.SetSigningKeyPrivateKey(s.db.WithContext(ctx), id, privateKey)
+}
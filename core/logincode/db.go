@@ -0,0 +1,77 @@
+package logincode
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type dbStore struct {
+	db     *gorm.DB
+	secret string
+}
+
+func (s *dbStore) Issue(ctx context.Context, userID uuid.UUID) (string, error) {
+	db := s.db.WithContext(ctx)
+
+	// 1. Enforce the resend interval
+	existing, err := models.FindActiveLoginCodeByUser(db, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to look up login code: %w", err)
+	}
+	if existing != nil && time.Since(existing.CreatedAt) < ResendInterval {
+		return "", ErrResendTooSoon
+	}
+
+	// 2. Replace any previous code with the new hashed one
+	code := generateCode()
+	record := &models.LoginCode{
+		UserID:    userID,
+		CodeHash:  hashCode(s.secret, userID, code),
+		ExpiresAt: time.Now().Add(CodeTTL),
+	}
+	if err := models.ReplaceLoginCode(db, record); err != nil {
+		return "", fmt.Errorf("failed to store login code: %w", err)
+	}
+	return code, nil
+}
+
+func (s *dbStore) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	// The outcome is tracked outside the transaction so that failed attempts are still committed.
+	var outcome error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		record, err := models.FindActiveLoginCodeByUserForUpdate(tx, userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				outcome = ErrCodeNotFound
+				return nil
+			}
+			return err
+		}
+
+		now := time.Now()
+		record.Attempts++
+		switch {
+		case hmac.Equal([]byte(record.CodeHash), []byte(hashCode(s.secret, userID, code))):
+			record.UsedAt = &now
+			outcome = nil
+		case record.Attempts >= MaxAttempts:
+			record.UsedAt = &now // Burn the code
+			outcome = ErrTooManyAttempts
+		default:
+			outcome = ErrCodeMismatch
+		}
+		return models.UpdateLoginCodeAttempt(tx, record)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify login code: %w", err)
+	}
+	return outcome
+}
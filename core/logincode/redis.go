@@ -0,0 +1,83 @@
+package logincode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// verifyScript checks and consumes a code atomically.
+// Returns 1 on match, 0 on mismatch, -1 when no code exists and -2 when the attempt limit is reached.
+var verifyScript = redis.NewScript(`
+local stored = redis.call('HGET', KEYS[1], 'hash')
+if not stored then
+	return -1
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if stored == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return 1
+end
+if attempts >= tonumber(ARGV[2]) then
+	redis.call('DEL', KEYS[1])
+	return -2
+end
+return 0
+`)
+
+type redisStore struct {
+	client *redis.Client
+	secret string
+}
+
+func codeKey(userID uuid.UUID) string {
+	return "login_code:" + userID.String()
+}
+
+func cooldownKey(userID uuid.UUID) string {
+	return "login_code_cooldown:" + userID.String()
+}
+
+func (s *redisStore) Issue(ctx context.Context, userID uuid.UUID) (string, error) {
+	// 1. Enforce the resend interval
+	ok, err := s.client.SetNX(ctx, cooldownKey(userID), 1, ResendInterval).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to set login code cooldown: %w", err)
+	}
+	if !ok {
+		return "", ErrResendTooSoon
+	}
+
+	// 2. Store the hashed code with a fresh attempt counter
+	code := generateCode()
+	key := codeKey(userID)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "hash", hashCode(s.secret, userID, code), "attempts", 0)
+		pipe.Expire(ctx, key, CodeTTL)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store login code: %w", err)
+	}
+	return code, nil
+}
+
+func (s *redisStore) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	result, err := verifyScript.Run(ctx, s.client, []string{codeKey(userID)}, hashCode(s.secret, userID, code), MaxAttempts).Int()
+	if err != nil {
+		return fmt.Errorf("failed to verify login code: %w", err)
+	}
+	switch result {
+	case 1:
+		return nil
+	case -1:
+		return ErrCodeNotFound
+	case -2:
+		return ErrTooManyAttempts
+	default:
+		return ErrCodeMismatch
+	}
+}
@@ -0,0 +1,64 @@
+package logincode
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/solotoabillion/stab/core/security"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	CodeLength     = 6               // Number of digits in a login code
+	CodeTTL        = 5 * time.Minute // Login codes are valid for 5 minutes
+	MaxAttempts    = 5               // Verification attempts allowed before a code is burned
+	ResendInterval = time.Minute     // Minimum time between two codes for the same user
+)
+
+var (
+	// ErrCodeNotFound is returned when there is no active code (never issued, expired or already used).
+	ErrCodeNotFound = errors.New("login code not found or expired")
+	// ErrCodeMismatch is returned when the submitted code does not match the stored one.
+	ErrCodeMismatch = errors.New("login code does not match")
+	// ErrTooManyAttempts is returned when the attempt limit is reached; the code is burned.
+	ErrTooManyAttempts = errors.New("too many login code attempts")
+	// ErrResendTooSoon is returned when a new code is requested within ResendInterval.
+	ErrResendTooSoon = errors.New("login code requested too recently")
+)
+
+// Store issues and verifies single-use login codes. Codes are only ever stored hashed.
+type Store interface {
+	// Issue generates a new code for the user, replacing any previous one, and returns it in plain text.
+	Issue(ctx context.Context, userID uuid.UUID) (string, error)
+	// Verify consumes the user's code if it matches. Every call counts as an attempt.
+	Verify(ctx context.Context, userID uuid.UUID, code string) error
+}
+
+// NewStore returns a Redis-backed store when a Redis client is available,
+// falling back to the login_codes table otherwise.
+// The secret keys the HMAC used to hash codes at rest.
+func NewStore(redisClient *redis.Client, db *gorm.DB, secret string) Store {
+	if redisClient != nil {
+		return &redisStore{client: redisClient, secret: secret}
+	}
+	return &dbStore{db: db, secret: secret}
+}
+
+// generateCode creates a cryptographically random numeric code.
+func generateCode() string {
+	return security.RandomStringWithAlphabet(CodeLength, "0123456789")
+}
+
+// hashCode returns the hex encoded HMAC-SHA256 of the code, bound to the user.
+func hashCode(secret string, userID uuid.UUID, code string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(userID.String() + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
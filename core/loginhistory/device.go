@@ -0,0 +1,101 @@
+package loginhistory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Device classes.
+const (
+	ClassDesktop = "desktop"
+	ClassMobile  = "mobile"
+	ClassTablet  = "tablet"
+)
+
+// Device is the coarse description of a client derived from its user agent. Versions are
+// left out on purpose so that a browser update does not look like a new device.
+type Device struct {
+	Browser string // Empty when not recognised
+	OS      string // Empty when not recognised
+	Class   string
+}
+
+// browsers is checked in order: most user agents also name the engines they are based on.
+var browsers = []struct {
+	name    string
+	markers []string
+}{
+	{"Edge", []string{"Edg/", "EdgA/", "EdgiOS/", "Edge/"}},
+	{"Opera", []string{"OPR/", "Opera"}},
+	{"Samsung Internet", []string{"SamsungBrowser/"}},
+	{"Firefox", []string{"Firefox/", "FxiOS/"}},
+	{"Chrome", []string{"CriOS/", "Chrome/", "Chromium/"}},
+	{"Safari", []string{"Safari/"}},
+}
+
+// systems is checked in order: iPads also claim to be Macs, Android devices to run Linux.
+var systems = []struct {
+	name    string
+	markers []string
+}{
+	{"Windows", []string{"Windows"}},
+	{"iOS", []string{"iPhone", "iPad", "iPod"}},
+	{"Android", []string{"Android"}},
+	{"ChromeOS", []string{"CrOS"}},
+	{"macOS", []string{"Macintosh", "Mac OS X"}},
+	{"Linux", []string{"Linux"}},
+}
+
+// ParseDevice describes the client sending the user agent.
+func ParseDevice(userAgent string) Device {
+	d := Device{Class: ClassDesktop}
+	for _, b := range browsers {
+		if containsAny(userAgent, b.markers) {
+			d.Browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if containsAny(userAgent, s.markers) {
+			d.OS = s.name
+			break
+		}
+	}
+	switch {
+	case strings.Contains(userAgent, "iPad"), d.OS == "Android" && !strings.Contains(userAgent, "Mobile"):
+		d.Class = ClassTablet
+	case strings.Contains(userAgent, "Mobi"), strings.Contains(userAgent, "iPhone"):
+		d.Class = ClassMobile
+	}
+	return d
+}
+
+// Label names the device for people, e.g. "Firefox on Windows".
+func (d Device) Label() string {
+	switch {
+	case d.Browser == "" && d.OS == "":
+		return "Unknown device"
+	case d.OS == "":
+		return d.Browser
+	case d.Browser == "":
+		return "Unknown browser on " + d.OS
+	}
+	return d.Browser + " on " + d.OS
+}
+
+// Fingerprint identifies the device class for comparisons. It is coarse: two laptops
+// running the same browser and system share a fingerprint.
+func (d Device) Fingerprint() string {
+	sum := sha256.Sum256([]byte(d.Browser + "|" + d.OS + "|" + d.Class))
+	return hex.EncodeToString(sum[:16])
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
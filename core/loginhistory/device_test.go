@@ -0,0 +1,64 @@
+package loginhistory
+
+import "testing"
+
+func TestParseDevice(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      Device
+		label     string
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			Device{"Chrome", "Windows", ClassDesktop}, "Chrome on Windows",
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			Device{"Edge", "Windows", ClassDesktop}, "Edge on Windows",
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4_1) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Safari/605.1.15",
+			Device{"Safari", "macOS", ClassDesktop}, "Safari on macOS",
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1",
+			Device{"Chrome", "iOS", ClassMobile}, "Chrome on iOS",
+		},
+		{
+			"Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			Device{"Safari", "iOS", ClassTablet}, "Safari on iOS",
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36",
+			Device{"Chrome", "Android", ClassMobile}, "Chrome on Android",
+		},
+		{
+			"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			Device{"Firefox", "Linux", ClassDesktop}, "Firefox on Linux",
+		},
+		{"curl/8.5.0", Device{"", "", ClassDesktop}, "Unknown device"},
+		{"", Device{"", "", ClassDesktop}, "Unknown device"},
+	}
+	for _, tt := range tests {
+		got := ParseDevice(tt.userAgent)
+		if got != tt.want {
+			t.Errorf("ParseDevice(%q) = %+v, want %+v", tt.userAgent, got, tt.want)
+		}
+		if label := got.Label(); label != tt.label {
+			t.Errorf("ParseDevice(%q).Label() = %q, want %q", tt.userAgent, label, tt.label)
+		}
+	}
+}
+
+func TestFingerprintIgnoresVersions(t *testing.T) {
+	older := ParseDevice("Mozilla/5.0 (X11; Linux x86_64; rv:124.0) Gecko/20100101 Firefox/124.0")
+	newer := ParseDevice("Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0")
+	if older.Fingerprint() != newer.Fingerprint() {
+		t.Error("a browser update changed the device fingerprint")
+	}
+	phone := ParseDevice("Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36")
+	tablet := ParseDevice("Mozilla/5.0 (Linux; Android 14; Pixel Tablet) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Safari/537.36")
+	if phone.Fingerprint() == tablet.Fingerprint() {
+		t.Error("a phone and a tablet share a device fingerprint")
+	}
+}
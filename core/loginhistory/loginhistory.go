@@ -0,0 +1,77 @@
+// Package loginhistory records login attempts and recognises logins from devices a
+// user has not signed in from before.
+package loginhistory
+
+import (
+	"time"
+
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// HistoryLimit is the number of recent attempts shown to the user.
+const HistoryLimit = 50
+
+// Attempt describes a login attempt to record.
+type Attempt struct {
+	UserID        *uuid.UUID // Nil when the email matches no account
+	Email         string
+	Method        string
+	FailureReason string // Empty for successful logins
+	Client        authsession.Client
+}
+
+// Record stores the attempt. For a successful login it also reports whether the device
+// is new to the user. The first recorded login of a user never counts as a new device,
+// so that accounts do not get an alert on sign-up or on their first login after upgrading.
+func Record(db *gorm.DB, attempt Attempt) (event *models.LoginEvent, newDevice bool, err error) {
+	device := ParseDevice(attempt.Client.UserAgent)
+	event = &models.LoginEvent{
+		UserID:            attempt.UserID,
+		Email:             attempt.Email,
+		Method:            attempt.Method,
+		Success:           attempt.FailureReason == "",
+		FailureReason:     attempt.FailureReason,
+		IPAddress:         attempt.Client.IPAddress,
+		UserAgent:         attempt.Client.UserAgent,
+		DeviceFingerprint: device.Fingerprint(),
+		DeviceLabel:       device.Label(),
+	}
+	if len(event.Email) > 255 {
+		event.Email = event.Email[:255]
+	}
+
+	if event.Success && event.UserID != nil {
+		hasLogins, known, err := models.FindKnownDevice(db, *event.UserID, event.DeviceFingerprint)
+		if err != nil {
+			return nil, false, err
+		}
+		newDevice = hasLogins && !known
+	}
+	if err := models.CreateLoginEvent(db, event); err != nil {
+		return nil, false, err
+	}
+	return event, newDevice, nil
+}
+
+// Describe converts login history for API responses.
+func Describe(events []models.LoginEvent) []types.LoginEvent {
+	history := make([]types.LoginEvent, 0, len(events))
+	for _, e := range events {
+		history = append(history, types.LoginEvent{
+			ID:            e.ID.String(),
+			Method:        e.Method,
+			Success:       e.Success,
+			FailureReason: e.FailureReason,
+			IPAddress:     e.IPAddress,
+			UserAgent:     e.UserAgent,
+			Device:        e.DeviceLabel,
+			CreatedAt:     e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return history
+}
@@ -0,0 +1,144 @@
+package mfa
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	PendingTokenExpiry = 5 * 60 // Seconds an "mfa pending" token stays valid
+	RecoveryCodeCount  = 10     // Recovery codes generated per batch
+
+	pendingTokenPurpose  = "mfa_pending"
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789" // No look-alike characters
+)
+
+var (
+	// ErrInvalidCode is returned when neither a TOTP code nor a recovery code matches.
+	ErrInvalidCode = errors.New("invalid two-factor code")
+	// ErrNotEnrolled is returned when the user has no TOTP secret.
+	ErrNotEnrolled = errors.New("two-factor authentication not enrolled")
+)
+
+// Required reports whether the user must complete a second factor to log in.
+func Required(user *models.User) bool {
+	settings, _ := user.GetSettings()
+	return settings.TwoFactorEnabled && user.TOTPSecret != nil
+}
+
+// pendingSigningKey derives a key distinct from the access secret so that
+// an "mfa pending" token is never accepted as an access token.
+func pendingSigningKey(cfg *config.Config) string {
+	return cfg.Auth.AccessSecret + ":" + pendingTokenPurpose
+}
+
+// NewPendingToken issues a short-lived token proving the first factor, the given login
+// method, succeeded.
+func NewPendingToken(cfg *config.Config, userID uuid.UUID, method string) (string, error) {
+	claims := jwt.MapClaims{
+		"id":      userID.String(),
+		"purpose": pendingTokenPurpose,
+		"method":  method,
+	}
+	return security.NewJWT(claims, pendingSigningKey(cfg), PendingTokenExpiry)
+}
+
+// ParsePendingToken verifies an "mfa pending" token and returns the user ID it was issued for
+// and the login method of the first factor.
+func ParsePendingToken(cfg *config.Config, token string) (userID uuid.UUID, method string, err error) {
+	claims, err := security.ParseJWT(token, pendingSigningKey(cfg))
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	if purpose, _ := claims["purpose"].(string); purpose != pendingTokenPurpose {
+		return uuid.Nil, "", errors.New("token is not an mfa pending token")
+	}
+	idStr, _ := claims["id"].(string)
+	method, _ = claims["method"].(string)
+	userID, err = uuid.Parse(idStr)
+	return userID, method, err
+}
+
+// VerifyTOTP checks a TOTP code for the user and records the time step to prevent replay.
+func VerifyTOTP(db *gorm.DB, user *models.User, code string) error {
+	if user.TOTPSecret == nil {
+		return ErrNotEnrolled
+	}
+	step, ok := security.ValidateTOTP(*user.TOTPSecret, strings.TrimSpace(code), time.Now(), user.TOTPLastStep)
+	if !ok {
+		return ErrInvalidCode
+	}
+	advanced, err := models.AdvanceUserTOTPStep(db, user.ID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+	if !advanced {
+		return ErrInvalidCode // Used concurrently
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+// VerifyRecoveryCode consumes one of the user's recovery codes.
+func VerifyRecoveryCode(db *gorm.DB, user *models.User, code string) error {
+	ok, err := models.ConsumeRecoveryCode(db, user.ID, hashRecoveryCode(code))
+	if err != nil {
+		return fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+	if !ok {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// VerifyCode accepts either a TOTP code or a recovery code.
+func VerifyCode(db *gorm.DB, user *models.User, code string) error {
+	err := VerifyTOTP(db, user, code)
+	if err == nil || !errors.Is(err, ErrInvalidCode) {
+		return err
+	}
+	return VerifyRecoveryCode(db, user, code)
+}
+
+// GenerateRecoveryCodes replaces the user's recovery codes and returns the new ones in plain text.
+// The plain codes are only ever shown once.
+func GenerateRecoveryCodes(db *gorm.DB, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		raw := security.RandomStringWithAlphabet(10, recoveryCodeAlphabet)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err := models.ReplaceRecoveryCodes(db, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode normalizes the code (case, separators) and returns its SHA-256 hex digest.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issuer returns the issuer name shown in authenticator apps.
+func Issuer(cfg *config.Config) string {
+	if cfg.Email.CompanyInfo.Name != "" {
+		return cfg.Email.CompanyInfo.Name
+	}
+	return "Stab"
+}
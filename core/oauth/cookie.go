@@ -0,0 +1,42 @@
+package oauth
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// stateCookieName binds a pending authorization request to the browser that started it,
+// so a callback URL cannot be replayed in someone else's browser (login CSRF).
+const stateCookieName = "oauth_state"
+
+// SetStateCookie stores the state of a new authorization request in the browser.
+// Providers that post the callback (Apple) need SameSite=None, which requires HTTPS.
+func SetStateCookie(c echo.Context, state string) {
+	secure := c.Request().URL.Scheme == "https" || c.Scheme() == "https"
+	sameSite := http.SameSiteLaxMode
+	if secure {
+		sameSite = http.SameSiteNoneMode
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     stateCookieName,
+		Value:    state,
+		Path:     "/api/auth",
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: sameSite,
+		MaxAge:   int(StateTTL.Seconds()),
+	})
+}
+
+// CheckStateCookie reports whether the callback state matches the browser's cookie,
+// and clears the cookie.
+func CheckStateCookie(c echo.Context, state string) bool {
+	cookie, err := c.Cookie(stateCookieName)
+	c.SetCookie(&http.Cookie{Name: stateCookieName, Path: "/api/auth", HttpOnly: true, MaxAge: -1})
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) == 1
+}
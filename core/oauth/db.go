@@ -0,0 +1,59 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type dbStateStore struct {
+	db *gorm.DB
+}
+
+func (s *dbStateStore) Save(ctx context.Context, state string, pending *PendingAuth) error {
+	record := &models.OAuthState{
+		StateHash:    hashState(state),
+		Provider:     pending.Provider,
+		CodeVerifier: pending.CodeVerifier,
+		Nonce:        pending.Nonce,
+		ExpiresAt:    time.Now().Add(StateTTL),
+	}
+	if pending.LinkUserID != uuid.Nil {
+		record.LinkUserID = &pending.LinkUserID
+	}
+	if pending.TeamID != uuid.Nil {
+		record.TeamID = &pending.TeamID
+	}
+	if err := models.CreateOAuthState(s.db.WithContext(ctx), record); err != nil {
+		return fmt.Errorf("failed to save oauth state: %w", err)
+	}
+	return nil
+}
+
+func (s *dbStateStore) Consume(ctx context.Context, state string) (*PendingAuth, error) {
+	record, err := models.ConsumeOAuthState(s.db.WithContext(ctx), hashState(state))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidState
+		}
+		return nil, fmt.Errorf("failed to load oauth state: %w", err)
+	}
+	pending := &PendingAuth{
+		Provider:     record.Provider,
+		CodeVerifier: record.CodeVerifier,
+		Nonce:        record.Nonce,
+	}
+	if record.LinkUserID != nil {
+		pending.LinkUserID = *record.LinkUserID
+	}
+	if record.TeamID != nil {
+		pending.TeamID = *record.TeamID
+	}
+	return pending, nil
+}
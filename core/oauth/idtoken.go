@@ -0,0 +1,232 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksCacheTTL is how long a provider's signing keys are reused before refetching.
+	jwksCacheTTL = time.Hour
+
+	// jwksMinRefresh limits refetches triggered by unknown key IDs.
+	jwksMinRefresh = time.Minute
+)
+
+var (
+	// ErrInvalidIDToken is returned when an ID token fails signature or claim validation.
+	ErrInvalidIDToken = errors.New("invalid id token")
+
+	// ErrNonceMismatch is returned when the ID token was not issued for the pending request.
+	ErrNonceMismatch = errors.New("id token nonce does not match")
+)
+
+// flexBool accepts both JSON booleans and the "true"/"false" strings some providers send.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	*b = flexBool(strings.Trim(string(data), `"`) == "true")
+	return nil
+}
+
+// idTokenClaims holds the ID token and userinfo claims used to build an identity.
+type idTokenClaims struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	GivenName     string   `json:"given_name"`
+	FamilyName    string   `json:"family_name"`
+	Name          string   `json:"name"`
+	Picture       string   `json:"picture"`
+	Nonce         string   `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+func (c *idTokenClaims) identity() *Identity {
+	identity := &Identity{
+		Subject:       c.Subject,
+		Email:         c.Email,
+		EmailVerified: bool(c.EmailVerified),
+		FirstName:     c.GivenName,
+		LastName:      c.FamilyName,
+		AvatarURL:     c.Picture,
+	}
+	if identity.FirstName == "" && c.Name != "" {
+		identity.FirstName, identity.LastName, _ = strings.Cut(c.Name, " ")
+	}
+	return identity
+}
+
+// verifyIDToken checks the signature and claims of an ID token issued for this client and request.
+func verifyIDToken(ctx context.Context, rawIDToken string, issuers []string, jwksURL, clientID, nonce string) (*idTokenClaims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	var claims idTokenClaims
+	_, err := parser.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keys.get(ctx, jwksURL, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if !slices.Contains(issuers, claims.Issuer) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, ErrNonceMismatch
+	}
+	return &claims, nil
+}
+
+// jwk is a JSON Web Key as published in a provider's key set.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey converts the JWK into a key usable by the jwt package.
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.X, "="))
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key length")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// keySet caches the signing keys of each provider by key ID.
+type keySet struct {
+	mu      sync.Mutex
+	keys    map[string]map[string]interface{}
+	fetched map[string]time.Time
+}
+
+var keys = &keySet{
+	keys:    make(map[string]map[string]interface{}),
+	fetched: make(map[string]time.Time),
+}
+
+// get returns the key with the given ID, refetching the key set when it is stale
+// or the key is unknown (providers rotate keys).
+func (s *keySet) get(ctx context.Context, jwksURL, kid string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fetched := s.fetched[jwksURL]
+	if key, ok := s.keys[jwksURL][kid]; ok && time.Since(fetched) < jwksCacheTTL {
+		return key, nil
+	}
+	if time.Since(fetched) >= jwksMinRefresh {
+		if err := s.refresh(ctx, jwksURL); err != nil {
+			return nil, err
+		}
+	}
+
+	set := s.keys[jwksURL]
+	if kid == "" && len(set) == 1 {
+		for _, key := range set {
+			return key, nil
+		}
+	}
+	key, ok := set[kid]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q not found", kid)
+	}
+	return key, nil
+}
+
+// refresh must be called with the mutex held.
+func (s *keySet) refresh(ctx context.Context, jwksURL string) error {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(ctx, httpClient, jwksURL, &doc); err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	set := make(map[string]interface{}, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue // Skip keys we cannot use rather than failing the whole set
+		}
+		set[k.Kid] = key
+	}
+	s.keys[jwksURL] = set
+	s.fetched[jwksURL] = time.Now()
+	return nil
+}
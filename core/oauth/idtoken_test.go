@@ -0,0 +1,98 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestVerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "k1",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	sign := func(claims jwt.MapClaims) string {
+		t.Helper()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "k1"
+		raw, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            "https://issuer.example.com",
+			"aud":            "client",
+			"sub":            "user-1",
+			"email":          "user@example.com",
+			"email_verified": "true",
+			"name":           "Ada Lovelace",
+			"nonce":          "n1",
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	verify := func(raw string) (*idTokenClaims, error) {
+		return verifyIDToken(context.Background(), raw, []string{"https://issuer.example.com"}, server.URL, "client", "n1")
+	}
+
+	got, err := verify(sign(claims()))
+	if err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+	identity := got.identity()
+	if identity.Subject != "user-1" || !identity.EmailVerified || identity.FirstName != "Ada" || identity.LastName != "Lovelace" {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+
+	wrongNonce := claims()
+	wrongNonce["nonce"] = "other"
+	if _, err := verify(sign(wrongNonce)); !errors.Is(err, ErrNonceMismatch) {
+		t.Fatalf("expected nonce mismatch, got %v", err)
+	}
+
+	for field, value := range map[string]interface{}{
+		"aud": "other-client",
+		"iss": "https://evil.example.com",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	} {
+		bad := claims()
+		bad[field] = value
+		if _, err := verify(sign(bad)); !errors.Is(err, ErrInvalidIDToken) {
+			t.Fatalf("expected invalid token for bad %s, got %v", field, err)
+		}
+	}
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, claims())
+	forged.Header["kid"] = "k1"
+	raw, _ := forged.SignedString(otherKey)
+	if _, err := verify(raw); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Package oidctest provides a stand-in OpenID Connect identity provider for tests and
+// local development of single sign-on.
+//
+// The provider serves discovery, JWKS and token endpoints. There is no login page:
+// Authorize plays the part of the user signing in and returns the authorization code
+// that the provider would have sent to the redirect URL.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidctest"
+
+// IdP is a running stand-in identity provider.
+type IdP struct {
+	Server       *httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+// grant is an issued authorization code waiting to be redeemed.
+type grant struct {
+	redirectURI   string
+	codeChallenge string
+	claims        jwt.MapClaims
+}
+
+// NewIdP starts a provider that accepts the given client credentials. Call Close when done.
+func NewIdP(clientID, clientSecret string) (*IdP, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	idp := &IdP{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.Server = httptest.NewServer(mux)
+	return idp, nil
+}
+
+// Issuer returns the issuer URL to configure on the relying party.
+func (idp *IdP) Issuer() string {
+	return idp.Server.URL
+}
+
+// Close shuts the provider down.
+func (idp *IdP) Close() {
+	idp.Server.Close()
+}
+
+// Authorize signs a user in for an authorization URL built by the relying party. claims
+// must include sub and usually email; issuer, audience, nonce and lifetimes are added.
+// It returns the code and state the provider would redirect back with.
+func (idp *IdP) Authorize(authURL string, claims map[string]interface{}) (code, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	switch {
+	case q.Get("client_id") != idp.ClientID:
+		return "", "", errors.New("unknown client_id")
+	case q.Get("response_type") != "code":
+		return "", "", errors.New("unsupported response_type")
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		return "", "", errors.New("missing PKCE challenge")
+	case q.Get("state") == "":
+		return "", "", errors.New("missing state")
+	}
+
+	now := time.Now()
+	signed := jwt.MapClaims{
+		"iss": idp.Issuer(),
+		"aud": idp.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	if nonce := q.Get("nonce"); nonce != "" {
+		signed["nonce"] = nonce
+	}
+	for k, v := range claims {
+		signed[k] = v
+	}
+
+	code = randomString()
+	idp.mu.Lock()
+	idp.codes[code] = grant{
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		claims:        signed,
+	}
+	idp.mu.Unlock()
+	return code, q.Get("state"), nil
+}
+
+func (idp *IdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                idp.Issuer(),
+		"authorization_endpoint":                idp.Issuer() + "/authorize",
+		"token_endpoint":                        idp.Issuer() + "/token",
+		"jwks_uri":                              idp.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (idp *IdP) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": keyID,
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *IdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != idp.ClientID || clientSecret != idp.ClientSecret {
+		tokenError(w, "invalid_client")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	idp.mu.Lock()
+	g, found := idp.codes[code]
+	delete(idp.codes, code)
+	idp.mu.Unlock()
+	if !found || g.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != g.codeChallenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, g.claims)
+	idToken.Header["kid"] = keyID
+	rawIDToken, err := idToken.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     rawIDToken,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("oidctest: failed to read random bytes: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
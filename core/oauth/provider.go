@@ -0,0 +1,175 @@
+// Package oauth signs users in through external OAuth 2.0 and OpenID Connect providers.
+//
+// Providers are configured from the oauth_providers/<name> settings. Every authorization
+// request uses PKCE and a single-use state kept server-side; OpenID Connect providers
+// additionally get a nonce that is checked against the verified ID token.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	// ErrUnknownProvider is returned for provider names without a definition or an issuer.
+	ErrUnknownProvider = errors.New("unknown oauth provider")
+
+	// ErrProviderNotConfigured is returned when a known provider has no client credentials.
+	ErrProviderNotConfigured = errors.New("oauth provider not configured")
+
+	// ErrMissingIDToken is returned when an OpenID Connect provider returns no ID token.
+	ErrMissingIDToken = errors.New("provider returned no id token")
+)
+
+// httpClient is used for provider API calls outside of the oauth2 token exchange.
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Identity is the user profile reported by a provider.
+type Identity struct {
+	Provider      string
+	Subject       string // Stable user ID at the provider
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	AvatarURL     string
+}
+
+// profileFunc fetches the user profile of a plain OAuth 2.0 provider.
+type profileFunc func(ctx context.Context, client *http.Client) (*Identity, error)
+
+// Provider is a configured OAuth 2.0 or OpenID Connect provider.
+type Provider struct {
+	Name   string
+	Config *oauth2.Config
+
+	// Issuer and JWKSURL are set for OpenID Connect providers. The ID token is then
+	// verified, including the nonce, and is the source of the identity.
+	Issuer  string
+	JWKSURL string
+
+	// altIssuers lists other issuer values the provider puts in its ID tokens.
+	altIssuers []string
+
+	// UserInfoURL fills in profile fields the ID token lacks.
+	UserInfoURL string
+
+	authParams []oauth2.AuthCodeOption
+	profile    profileFunc
+}
+
+// OIDC reports whether the provider issues ID tokens.
+func (p *Provider) OIDC() bool {
+	return p.Issuer != ""
+}
+
+// AuthCodeURL returns the provider's authorization URL for a pending request.
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	opts := append([]oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)}, p.authParams...)
+	if p.OIDC() {
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", nonce))
+	}
+	return p.Config.AuthCodeURL(state, opts...)
+}
+
+// Exchange redeems an authorization code and returns the user's identity at the provider.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	token, err := p.Config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	var identity *Identity
+	if p.OIDC() {
+		rawIDToken, _ := token.Extra("id_token").(string)
+		if rawIDToken == "" {
+			return nil, ErrMissingIDToken
+		}
+		claims, err := verifyIDToken(ctx, rawIDToken, append([]string{p.Issuer}, p.altIssuers...), p.JWKSURL, p.Config.ClientID, nonce)
+		if err != nil {
+			return nil, err
+		}
+		identity = claims.identity()
+		if p.UserInfoURL != "" && (identity.Email == "" || identity.FirstName == "") {
+			if info, err := fetchUserInfo(ctx, p.Config.Client(ctx, token), p.UserInfoURL, identity.Subject); err == nil {
+				identity.merge(info)
+			}
+		}
+	} else {
+		if p.profile == nil {
+			return nil, fmt.Errorf("provider %s has no profile endpoint", p.Name)
+		}
+		identity, err = p.profile(ctx, p.Config.Client(ctx, token))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	identity.Provider = p.Name
+	identity.Email = strings.TrimSpace(identity.Email)
+	if identity.Subject == "" {
+		return nil, errors.New("provider returned no user id")
+	}
+	return identity, nil
+}
+
+// merge fills empty fields from another identity of the same subject.
+func (i *Identity) merge(other *Identity) {
+	if i.Email == "" {
+		i.Email, i.EmailVerified = other.Email, other.EmailVerified
+	}
+	if i.FirstName == "" {
+		i.FirstName = other.FirstName
+	}
+	if i.LastName == "" {
+		i.LastName = other.LastName
+	}
+	if i.AvatarURL == "" {
+		i.AvatarURL = other.AvatarURL
+	}
+}
+
+// getJSON fetches a provider API endpoint into v.
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d: %s", url, resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// fetchUserInfo reads an OpenID Connect userinfo endpoint. The subject must match the ID token's.
+func fetchUserInfo(ctx context.Context, client *http.Client, url, subject string) (*Identity, error) {
+	var claims idTokenClaims
+	if err := getJSON(ctx, client, url, &claims); err != nil {
+		return nil, err
+	}
+	if claims.Subject != subject {
+		return nil, errors.New("userinfo subject does not match the id token")
+	}
+	return claims.identity(), nil
+}
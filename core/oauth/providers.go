@@ -0,0 +1,175 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+// definition describes a built-in provider; credentials come from settings.
+type definition struct {
+	endpoint    oauth2.Endpoint
+	scopes      []string
+	issuer      string
+	altIssuers  []string
+	jwksURL     string
+	userInfoURL string
+	authParams  []oauth2.AuthCodeOption
+	profile     profileFunc
+}
+
+// definitions holds the providers that have oauth_providers/<name> settings seeded by default.
+// Other names are treated as generic OpenID Connect providers and need an issuer setting.
+var definitions = map[string]definition{
+	"google": {
+		endpoint:    endpoints.Google,
+		scopes:      []string{"openid", "email", "profile"},
+		issuer:      "https://accounts.google.com",
+		altIssuers:  []string{"accounts.google.com"},
+		jwksURL:     "https://www.googleapis.com/oauth2/v3/certs",
+		userInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+	},
+	// Apple needs a client secret JWT generated from the Sign in with Apple key; it is
+	// valid for up to six months and must be rotated in the settings before it expires.
+	// Apple posts the callback as a form because the name and email scopes are requested.
+	"apple": {
+		endpoint: oauth2.Endpoint{
+			AuthURL:   "https://appleid.apple.com/auth/authorize",
+			TokenURL:  "https://appleid.apple.com/auth/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		scopes:     []string{"name", "email"},
+		issuer:     "https://appleid.apple.com",
+		jwksURL:    "https://appleid.apple.com/auth/keys",
+		authParams: []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("response_mode", "form_post")},
+	},
+	"github": {
+		endpoint: endpoints.GitHub,
+		scopes:   []string{"read:user", "user:email"},
+		profile:  githubProfile,
+	},
+	"meta": {
+		endpoint: oauth2.Endpoint{
+			AuthURL:  "https://www.facebook.com/v19.0/dialog/oauth",
+			TokenURL: "https://graph.facebook.com/v19.0/oauth/access_token",
+		},
+		scopes:  []string{"email", "public_profile"},
+		profile: metaProfile,
+	},
+	"x": {
+		endpoint: oauth2.Endpoint{
+			AuthURL:   "https://twitter.com/i/oauth2/authorize",
+			TokenURL:  "https://api.twitter.com/2/oauth2/token",
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		scopes:  []string{"users.read", "tweet.read"},
+		profile: xProfile,
+	},
+	"instagram": {
+		endpoint: oauth2.Endpoint{
+			AuthURL:   "https://www.instagram.com/oauth/authorize",
+			TokenURL:  "https://api.instagram.com/oauth/access_token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		scopes:  []string{"instagram_business_basic"},
+		profile: instagramProfile,
+	},
+}
+
+// splitName splits a display name into first and last name.
+func splitName(name string) (string, string) {
+	first, last, _ := strings.Cut(strings.TrimSpace(name), " ")
+	return first, last
+}
+
+func githubProfile(ctx context.Context, client *http.Client) (*Identity, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, client, "https://api.github.com/user", &user); err != nil {
+		return nil, err
+	}
+	identity := &Identity{Subject: strconv.FormatInt(user.ID, 10), AvatarURL: user.AvatarURL}
+	identity.FirstName, identity.LastName = splitName(user.Name)
+	if identity.FirstName == "" {
+		identity.FirstName = user.Login
+	}
+
+	// The profile email may be empty or unverified; the emails endpoint says which is primary and verified
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, "https://api.github.com/user/emails", &emails); err == nil {
+		for _, e := range emails {
+			if e.Primary {
+				identity.Email, identity.EmailVerified = e.Email, e.Verified
+				break
+			}
+		}
+	}
+	return identity, nil
+}
+
+func metaProfile(ctx context.Context, client *http.Client) (*Identity, error) {
+	var user struct {
+		ID        string `json:"id"`
+		Email     string `json:"email"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Picture   struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	if err := getJSON(ctx, client, "https://graph.facebook.com/v19.0/me?fields=id,email,first_name,last_name,picture", &user); err != nil {
+		return nil, err
+	}
+	// Meta does not say whether the address was confirmed, so it is not trusted for account linking
+	return &Identity{
+		Subject:   user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		AvatarURL: user.Picture.Data.URL,
+	}, nil
+}
+
+func xProfile(ctx context.Context, client *http.Client) (*Identity, error) {
+	var resp struct {
+		Data struct {
+			ID              string `json:"id"`
+			Name            string `json:"name"`
+			Username        string `json:"username"`
+			ProfileImageURL string `json:"profile_image_url"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, client, "https://api.twitter.com/2/users/me?user.fields=profile_image_url", &resp); err != nil {
+		return nil, err
+	}
+	// X does not share email addresses with standard API access
+	identity := &Identity{Subject: resp.Data.ID, AvatarURL: resp.Data.ProfileImageURL}
+	identity.FirstName, identity.LastName = splitName(resp.Data.Name)
+	return identity, nil
+}
+
+func instagramProfile(ctx context.Context, client *http.Client) (*Identity, error) {
+	var user struct {
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+	}
+	if err := getJSON(ctx, client, "https://graph.instagram.com/me?fields=user_id,username", &user); err != nil {
+		return nil, err
+	}
+	// Instagram does not share email addresses
+	return &Identity{Subject: user.UserID, FirstName: user.Username}, nil
+}
@@ -0,0 +1,44 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStateStore struct {
+	client *redis.Client
+}
+
+func stateKey(state string) string {
+	return "oauth_state:" + hashState(state)
+}
+
+func (s *redisStateStore) Save(ctx context.Context, state string, pending *PendingAuth) error {
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, stateKey(state), data, StateTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save oauth state: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStateStore) Consume(ctx context.Context, state string) (*PendingAuth, error) {
+	data, err := s.client.GetDel(ctx, stateKey(state)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrInvalidState
+		}
+		return nil, fmt.Errorf("failed to load oauth state: %w", err)
+	}
+	var pending PendingAuth
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse oauth state: %w", err)
+	}
+	return &pending, nil
+}
@@ -0,0 +1,180 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/models"
+
+	"golang.org/x/oauth2"
+)
+
+// SettingsPrefix is the settings category prefix of provider configurations.
+// Each oauth_providers/<name> category holds client_id, client_secret and redirect_url,
+// plus optional space separated scopes and, for providers without a built-in definition,
+// the OpenID Connect issuer URL.
+const SettingsPrefix = "oauth_providers/"
+
+// discoveryCacheTTL is how long OpenID Connect discovery documents are reused.
+const discoveryCacheTTL = time.Hour
+
+// Registry resolves provider names to configured providers.
+type Registry struct {
+	cfg      *config.Config
+	settings models.SettingsMap
+}
+
+// NewRegistry returns a registry over the current settings. The Google credentials in
+// the auth config are used when the oauth_providers/google settings are empty.
+func NewRegistry(cfg *config.Config, settings models.SettingsMap) *Registry {
+	return &Registry{cfg: cfg, settings: settings}
+}
+
+// providerSettings returns the settings of a provider with the legacy Google config applied.
+func (r *Registry) providerSettings(name string) map[string]string {
+	s := r.settings[SettingsPrefix+name]
+	if name == "google" && s["client_id"] == "" && r.cfg.Auth.GoogleOAuthClientID != "" {
+		return map[string]string{
+			"client_id":     r.cfg.Auth.GoogleOAuthClientID,
+			"client_secret": r.cfg.Auth.GoogleOAuthClientSecret,
+			"redirect_url":  r.cfg.Auth.GoogleOAuthRedirectURL,
+		}
+	}
+	return s
+}
+
+// Get returns the named provider.
+func (r *Registry) Get(ctx context.Context, name string) (*Provider, error) {
+	s := r.providerSettings(name)
+	def, known := definitions[name]
+	if !known && s["issuer"] == "" {
+		return nil, ErrUnknownProvider
+	}
+	if s["client_id"] == "" || s["redirect_url"] == "" {
+		return nil, ErrProviderNotConfigured
+	}
+
+	scopes := strings.Fields(s["scopes"])
+	if !known {
+		return NewOIDCProvider(ctx, name, s["issuer"], s["client_id"], s["client_secret"], s["redirect_url"], scopes)
+	}
+	if len(scopes) == 0 {
+		scopes = def.scopes
+	}
+	return &Provider{
+		Name: name,
+		Config: &oauth2.Config{
+			ClientID:     s["client_id"],
+			ClientSecret: s["client_secret"],
+			RedirectURL:  s["redirect_url"],
+			Scopes:       scopes,
+			Endpoint:     def.endpoint,
+		},
+		Issuer:      def.issuer,
+		JWKSURL:     def.jwksURL,
+		UserInfoURL: def.userInfoURL,
+		altIssuers:  def.altIssuers,
+		authParams:  def.authParams,
+		profile:     def.profile,
+	}, nil
+}
+
+// Names lists the providers with credentials, sorted by name.
+func (r *Registry) Names() []string {
+	var names []string
+	for category, s := range r.settings {
+		name, ok := strings.CutPrefix(category, SettingsPrefix)
+		if !ok || s["client_id"] == "" || s["redirect_url"] == "" {
+			continue
+		}
+		if _, known := definitions[name]; known || s["issuer"] != "" {
+			names = append(names, name)
+		}
+	}
+	if !slices.Contains(names, "google") && r.cfg.Auth.GoogleOAuthClientID != "" {
+		names = append(names, "google")
+	}
+	sort.Strings(names)
+	return names
+}
+
+// discoveryDocument is the subset of the OpenID Connect discovery document that is used.
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type cachedDiscovery struct {
+	doc       discoveryDocument
+	fetchedAt time.Time
+}
+
+var (
+	discoveryMu    sync.Mutex
+	discoveryCache = make(map[string]cachedDiscovery)
+)
+
+// discover fetches (or reuses) the discovery document of an issuer.
+func discover(ctx context.Context, issuer string) (discoveryDocument, error) {
+	issuer = strings.TrimRight(issuer, "/")
+	discoveryMu.Lock()
+	cached, ok := discoveryCache[issuer]
+	discoveryMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < discoveryCacheTTL {
+		return cached.doc, nil
+	}
+
+	var doc discoveryDocument
+	if err := getJSON(ctx, httpClient, issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return discoveryDocument{}, fmt.Errorf("failed to discover issuer %s: %w", issuer, err)
+	}
+	if strings.TrimRight(doc.Issuer, "/") != issuer {
+		return discoveryDocument{}, fmt.Errorf("discovery document of %s names issuer %s", issuer, doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return discoveryDocument{}, fmt.Errorf("discovery document of %s is incomplete", issuer)
+	}
+
+	discoveryMu.Lock()
+	discoveryCache[issuer] = cachedDiscovery{doc: doc, fetchedAt: time.Now()}
+	discoveryMu.Unlock()
+	return doc, nil
+}
+
+// NewOIDCProvider configures a generic OpenID Connect provider from its issuer's discovery document.
+func NewOIDCProvider(ctx context.Context, name, issuer, clientID, clientSecret, redirectURL string, scopes []string) (*Provider, error) {
+	doc, err := discover(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	} else if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	return &Provider{
+		Name: name,
+		Config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			},
+		},
+		Issuer:      doc.Issuer,
+		JWKSURL:     doc.JWKSURI,
+		UserInfoURL: doc.UserInfoEndpoint,
+	}, nil
+}
@@ -0,0 +1,118 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/solotoabillion/stab/core/security"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	// StateTTL is how long the user has to complete an authorization request.
+	StateTTL = 10 * time.Minute
+
+	// stateLength gives ~256 bits of entropy with the alphanumeric alphabet.
+	stateLength = 43
+)
+
+// ErrInvalidState is returned for unknown, expired, reused or mismatched state parameters.
+var ErrInvalidState = errors.New("invalid or expired oauth state")
+
+// PendingAuth is the server-side half of an authorization request.
+type PendingAuth struct {
+	Provider     string    `json:"provider"`
+	CodeVerifier string    `json:"codeVerifier"`
+	Nonce        string    `json:"nonce"`
+	LinkUserID   uuid.UUID `json:"linkUserId"` // Set when a signed-in user connects another provider
+	TeamID       uuid.UUID `json:"teamId"`     // Set for sign-ins through a team's identity provider
+}
+
+// StateStore keeps pending authorization requests by state. Each state can be consumed once.
+type StateStore interface {
+	Save(ctx context.Context, state string, pending *PendingAuth) error
+	Consume(ctx context.Context, state string) (*PendingAuth, error)
+}
+
+// NewStateStore returns a Redis-backed store when a Redis client is available,
+// falling back to the oauth_states table otherwise.
+func NewStateStore(redisClient *redis.Client, db *gorm.DB) StateStore {
+	if redisClient != nil {
+		return &redisStateStore{client: redisClient}
+	}
+	return &dbStateStore{db: db}
+}
+
+func hashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// Begin starts an authorization request. The provider, PKCE verifier and nonce of pending
+// are filled in. It returns the provider URL to send the user to and the state, which the
+// caller should also bind to the browser.
+func Begin(ctx context.Context, store StateStore, p *Provider, pending PendingAuth) (string, string, error) {
+	state := security.RandomString(stateLength)
+	pending.Provider = p.Name
+	pending.CodeVerifier = oauth2.GenerateVerifier()
+	pending.Nonce = security.RandomString(stateLength)
+	if err := store.Save(ctx, state, &pending); err != nil {
+		return "", "", fmt.Errorf("failed to store oauth state: %w", err)
+	}
+	return p.AuthCodeURL(state, pending.Nonce, pending.CodeVerifier), state, nil
+}
+
+// Complete consumes the state of a callback and redeems its authorization code.
+func Complete(ctx context.Context, store StateStore, p *Provider, state, code string) (*Identity, *PendingAuth, error) {
+	pending, err := consume(ctx, store, state, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending.Provider != p.Name || pending.TeamID != uuid.Nil {
+		return nil, nil, ErrInvalidState
+	}
+	identity, err := p.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return identity, pending, nil
+}
+
+// CompleteTeam is Complete for sign-ins through a team's identity provider, which is
+// only known once the state names the team.
+func CompleteTeam(ctx context.Context, store StateStore, state, code string, teamProvider func(teamID uuid.UUID) (*Provider, error)) (*Identity, *PendingAuth, error) {
+	pending, err := consume(ctx, store, state, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending.TeamID == uuid.Nil {
+		return nil, nil, ErrInvalidState
+	}
+	p, err := teamProvider(pending.TeamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending.Provider != p.Name {
+		return nil, nil, ErrInvalidState
+	}
+	identity, err := p.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return identity, pending, nil
+}
+
+func consume(ctx context.Context, store StateStore, state, code string) (*PendingAuth, error) {
+	if state == "" || code == "" {
+		return nil, ErrInvalidState
+	}
+	return store.Consume(ctx, state)
+}
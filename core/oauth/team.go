@@ -0,0 +1,23 @@
+package oauth
+
+import (
+	"context"
+
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+)
+
+// TeamProviderName is the provider name of a team's identity provider. It keeps
+// identities from different teams' providers apart in the linked identities table.
+func TeamProviderName(teamID uuid.UUID) string {
+	return "sso:" + teamID.String()
+}
+
+// NewTeamProvider configures the OpenID Connect provider a team set up for single sign-on.
+func NewTeamProvider(ctx context.Context, team *models.Team, redirectURL string) (*Provider, error) {
+	if !team.SSOEnabled || team.SSOIssuer == "" || team.SSOClientID == "" {
+		return nil, ErrProviderNotConfigured
+	}
+	return NewOIDCProvider(ctx, TeamProviderName(team.ID), team.SSOIssuer, team.SSOClientID, team.SSOClientSecret, redirectURL, nil)
+}
@@ -0,0 +1,105 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/solotoabillion/stab/core/oauth/oidctest"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+)
+
+// memoryStateStore keeps pending requests in memory for tests.
+type memoryStateStore struct {
+	mu      sync.Mutex
+	pending map[string]PendingAuth
+}
+
+func (s *memoryStateStore) Save(ctx context.Context, state string, pending *PendingAuth) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[state] = *pending
+	return nil
+}
+
+func (s *memoryStateStore) Consume(ctx context.Context, state string) (*PendingAuth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending, ok := s.pending[state]
+	if !ok {
+		return nil, ErrInvalidState
+	}
+	delete(s.pending, state)
+	return &pending, nil
+}
+
+func TestTeamSSOFlow(t *testing.T) {
+	idp, err := oidctest.NewIdP("stab", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+
+	ctx := context.Background()
+	team := &models.Team{
+		ID:              uuid.New(),
+		SSOEnabled:      true,
+		SSOIssuer:       idp.Issuer(),
+		SSOClientID:     "stab",
+		SSOClientSecret: "secret",
+	}
+	const redirectURL = "https://app.example.com/api/auth/sso/callback"
+	teamProvider := func(teamID uuid.UUID) (*Provider, error) {
+		if teamID != team.ID {
+			t.Fatalf("unexpected team %s", teamID)
+		}
+		return NewTeamProvider(ctx, team, redirectURL)
+	}
+
+	provider, err := teamProvider(team.ID)
+	if err != nil {
+		t.Fatalf("failed to configure team provider: %v", err)
+	}
+	store := &memoryStateStore{pending: make(map[string]PendingAuth)}
+	authURL, state, err := Begin(ctx, store, provider, PendingAuth{TeamID: team.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, returnedState, err := idp.Authorize(authURL, map[string]interface{}{
+		"sub":            "employee-1",
+		"email":          "ada@corp.example.com",
+		"email_verified": true,
+		"given_name":     "Ada",
+		"family_name":    "Lovelace",
+	})
+	if err != nil {
+		t.Fatalf("stand-in IdP rejected the authorization request: %v", err)
+	}
+	if returnedState != state {
+		t.Fatalf("state changed in transit")
+	}
+
+	// The regular provider callback must not accept a team sign-in
+	if _, _, err := Complete(ctx, store, provider, state, code); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected team state to be rejected by Complete, got %v", err)
+	}
+
+	authURL, state, _ = Begin(ctx, store, provider, PendingAuth{TeamID: team.ID})
+	code, _, _ = idp.Authorize(authURL, map[string]interface{}{"sub": "employee-1", "email": "ada@corp.example.com"})
+	identity, pending, err := CompleteTeam(ctx, store, state, code, teamProvider)
+	if err != nil {
+		t.Fatalf("failed to complete team sign-in: %v", err)
+	}
+	if pending.TeamID != team.ID || identity.Provider != TeamProviderName(team.ID) || identity.Subject != "employee-1" || identity.Email != "ada@corp.example.com" {
+		t.Fatalf("unexpected result %+v %+v", identity, pending)
+	}
+
+	// States are single use
+	if _, _, err := CompleteTeam(ctx, store, state, code, teamProvider); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected replayed state to be rejected, got %v", err)
+	}
+}
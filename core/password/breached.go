@@ -0,0 +1,64 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prefixLength is the number of SHA-1 hex digits that name a range file.
+const prefixLength = 5
+
+// Corpus screens passwords against breached password hashes stored in k-anonymity range
+// files, the format of the Have I Been Pwned range API: the file named after the first
+// five hex digits of a password's SHA-1 ("21BD1.txt") lists the remaining 35 digits of
+// every breached hash with that prefix, one "SUFFIX:COUNT" per line. Only the matching
+// file is read, and nothing leaves the machine.
+type Corpus struct {
+	dir string
+}
+
+// NewCorpus returns a corpus reading range files from dir. A nil corpus screens nothing.
+func NewCorpus(dir string) (*Corpus, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.New(dir + " is not a directory")
+	}
+	return &Corpus{dir: dir}, nil
+}
+
+// Contains reports whether the password appears in the corpus.
+func (c *Corpus) Contains(password string) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	f, err := os.Open(filepath.Join(c.dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil // No breached hash has this prefix
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
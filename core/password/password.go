@@ -0,0 +1,62 @@
+package password
+
+import (
+	"fmt"
+
+	"github.com/solotoabillion/stab/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Check reports whether password may become the user's new password under the policy.
+// user is nil when registering. It returns a *PolicyError when the password is rejected.
+func Check(db *gorm.DB, policy Policy, corpus *Corpus, user *models.User, password string) error {
+	if err := policy.Validate(password); err != nil {
+		return err
+	}
+
+	breached, err := corpus.Contains(password)
+	if err != nil {
+		return fmt.Errorf("failed to screen password: %w", err)
+	}
+	if breached {
+		return &PolicyError{"This password has appeared in a data breach. Please choose a different one"}
+	}
+
+	if user == nil || policy.History == 0 {
+		return nil
+	}
+	if user.CheckPassword(password) {
+		return &PolicyError{"Please choose a password you have not used recently"}
+	}
+	if policy.History > 1 {
+		hashes, err := models.FindRecentPasswordHashes(db, user.ID, policy.History-1)
+		if err != nil {
+			return fmt.Errorf("failed to load password history: %w", err)
+		}
+		for _, hash := range hashes {
+			if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+				return &PolicyError{"Please choose a password you have not used recently"}
+			}
+		}
+	}
+	return nil
+}
+
+// Update hashes and stores the user's new password, keeps the previous hash for the reuse
+// check and clears any pending reset token. Check the password first.
+func Update(db *gorm.DB, policy Policy, user *models.User, password string) error {
+	previous := user.Password
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if previous != nil && *previous != "" && policy.History > 1 {
+			if err := models.AddPasswordHistory(tx, user.ID, *previous, policy.History-1); err != nil {
+				return err
+			}
+		}
+		return models.UpdateUserPasswordAndClearToken(tx, user.ID, user.Password)
+	})
+}
@@ -0,0 +1,78 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	p := Policy{MinLength: 10, RequireUpper: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		password string
+		ok       bool
+	}{
+		{"Short1!", false},
+		{"longenough1!", false}, // No uppercase letter
+		{"LongEnough!!", false}, // No digit
+		{"LongEnough11", false}, // No symbol
+		{"LongEnough1!", true},
+		{strings.Repeat("Aa1!", 19), false}, // Over bcrypt's limit
+	}
+	for _, tt := range tests {
+		err := p.Validate(tt.password)
+		if (err == nil) != tt.ok {
+			t.Errorf("Validate(%q) = %v, want ok=%v", tt.password, err, tt.ok)
+		}
+		var policyErr *PolicyError
+		if err != nil && !errors.As(err, &policyErr) {
+			t.Errorf("Validate(%q) returned %T, want *PolicyError", tt.password, err)
+		}
+	}
+}
+
+func TestPolicyFromSettings(t *testing.T) {
+	if p := PolicyFromSettings(nil); p != DefaultPolicy {
+		t.Errorf("empty settings = %+v, want the default policy", p)
+	}
+	p := PolicyFromSettings(map[string]string{
+		"password_min_length":     "12",
+		"password_history":        "0",
+		"password_require_digit":  "true",
+		"password_require_symbol": "false",
+	})
+	if p.MinLength != 12 || p.History != 0 || !p.RequireDigit || p.RequireSymbol {
+		t.Errorf("unexpected policy %+v", p)
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte("password123"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	lines := "0018A45C4D1DEF81644B54AB7F969B88D65:1\n" + strings.ToLower(hash[5:]) + ":251682\n"
+	if err := os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	corpus, err := NewCorpus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found, err := corpus.Contains("password123"); err != nil || !found {
+		t.Errorf("breached password not found: %v", err)
+	}
+	if found, err := corpus.Contains("correct horse battery staple"); err != nil || found {
+		t.Errorf("unlisted password reported as breached: %v", err)
+	}
+
+	var none *Corpus
+	if found, err := none.Contains("password123"); err != nil || found {
+		t.Errorf("nil corpus should screen nothing")
+	}
+}
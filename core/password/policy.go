@@ -0,0 +1,94 @@
+// Package password enforces the password policy: length and character class rules,
+// no reuse of recent passwords, and screening against a local corpus of breached passwords.
+package password
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength is the longest password accepted; bcrypt ignores everything after 72 bytes.
+const MaxLength = 72
+
+// Policy lists the rules a new password must meet.
+type Policy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	History       int // Number of recent passwords, including the current one, that cannot be reused
+}
+
+// DefaultPolicy applies when the auth settings do not configure one.
+var DefaultPolicy = Policy{MinLength: 8, History: 5}
+
+// PolicyFromSettings reads the policy from the auth settings category. Missing or
+// invalid values fall back to DefaultPolicy.
+func PolicyFromSettings(settings map[string]string) Policy {
+	p := DefaultPolicy
+	if n, err := strconv.Atoi(settings["password_min_length"]); err == nil && n > 0 {
+		p.MinLength = n
+	}
+	if n, err := strconv.Atoi(settings["password_history"]); err == nil && n >= 0 {
+		p.History = n
+	}
+	p.RequireUpper = settings["password_require_uppercase"] == "true"
+	p.RequireLower = settings["password_require_lowercase"] == "true"
+	p.RequireDigit = settings["password_require_digit"] == "true"
+	p.RequireSymbol = settings["password_require_symbol"] == "true"
+	return p
+}
+
+// PolicyError reports a password that is not accepted. Its message is safe to show to the user.
+type PolicyError struct {
+	Message string
+}
+
+func (e *PolicyError) Error() string {
+	return e.Message
+}
+
+// Validate checks the length and character class rules.
+func (p Policy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return &PolicyError{fmt.Sprintf("Password must be at least %d characters long", p.MinLength)}
+	}
+	if len(password) > MaxLength {
+		return &PolicyError{fmt.Sprintf("Password must be at most %d bytes long", MaxLength)}
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	var missing []string
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return &PolicyError{"Password must contain " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotMember is returned when the user does not belong to the team.
+	ErrNotMember = errors.New("not a member of the team")
+	// ErrForbidden is returned when the user's role lacks the permission.
+	ErrForbidden = errors.New("permission denied")
+	// ErrEscalation is returned when a role would give someone permissions the user does
+	// not hold.
+	ErrEscalation = errors.New("role grants permissions the user does not hold")
+)
+
+// Effective returns the permissions the user holds in the team. The owner holds every
+// permission; members with a custom role hold that role's permissions, other members
+// those of their built-in role.
+func Effective(ctx context.Context, db *gorm.DB, user *models.User, team *models.Team) ([]string, error) {
+	if user == nil {
+		return nil, ErrNotMember
+	}
+	if team.OwnerID == user.ID {
+		return RolePermissions(models.RoleOwner), nil
+	}
+	db = db.WithContext(ctx)
+	membership, err := models.FindMembershipByUserAndTeam(db, user.ID, team.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	return MembershipPermissions(db, team, membership)
+}
+
+// MembershipPermissions returns the permissions held through a membership of the team.
+func MembershipPermissions(db *gorm.DB, team *models.Team, membership *models.Membership) ([]string, error) {
+	if membership.UserID == team.OwnerID {
+		return RolePermissions(models.RoleOwner), nil
+	}
+	if membership.RoleID == nil || membership.Role == models.RoleOwner {
+		return RolePermissions(membership.Role), nil
+	}
+
+	role, err := models.FindTeamRole(db, *membership.RoleID, team.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return RolePermissions(models.RoleMember), nil
+		}
+		return nil, err
+	}
+	return RolePermissionList(role), nil
+}
+
+// Holders returns the IDs of the team's members who hold the permission, the owner first.
+func Holders(ctx context.Context, db *gorm.DB, team *models.Team, permission string) ([]uuid.UUID, error) {
+	db = db.WithContext(ctx)
+	memberships, err := models.FindMembershipsByTeam(db, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	holders := []uuid.UUID{team.OwnerID}
+	for i := range memberships {
+		m := &memberships[i]
+		if m.UserID == team.OwnerID {
+			continue
+		}
+		held, err := MembershipPermissions(db, team, m)
+		if err != nil {
+			return nil, err
+		}
+		if contains(held, permission) {
+			holders = append(holders, m.UserID)
+		}
+	}
+	return holders, nil
+}
+
+// RolePermissionList returns the permissions of a custom role. Permissions of modules
+// that are no longer installed are dropped.
+func RolePermissionList(role *models.TeamRole) []string {
+	var held []string
+	for _, name := range role.PermissionList() {
+		if p, ok := Lookup(name); ok && !p.OwnerOnly {
+			held = append(held, name)
+		}
+	}
+	return held
+}
+
+// Authorize returns nil when the user holds the permission in the team, ErrNotMember when
+// the user is not on the team and ErrForbidden when the user's role lacks the permission.
+// A nil team stands for the user's own account, in which the user holds every permission.
+func Authorize(ctx context.Context, db *gorm.DB, user *models.User, team *models.Team, permission string) error {
+	if team == nil {
+		if user == nil {
+			return ErrNotMember
+		}
+		return nil
+	}
+	held, err := Effective(ctx, db, user, team)
+	if err != nil {
+		return err
+	}
+	if !contains(held, permission) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CanGrant returns nil when the user holds every permission of the built-in role, so they
+// may bring someone into the team with it, and ErrEscalation when they do not. See
+// Effective for the other errors.
+func CanGrant(ctx context.Context, db *gorm.DB, user *models.User, team *models.Team, role models.Role) error {
+	held, err := Effective(ctx, db, user, team)
+	if err != nil {
+		return err
+	}
+	if !Includes(held, RolePermissions(role)) {
+		return ErrEscalation
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Package permissions maps team roles onto permissions. The built-in owner, admin and
+// member roles hold fixed sets of permissions, teams can define custom roles holding any
+// other set, and modules can register permissions of their own.
+package permissions
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/solotoabillion/stab/models"
+)
+
+// Built-in permissions, written as "<area>:<action>".
+const (
+	TeamRead      = "team:read"
+	TeamUpdate    = "team:update"
+	TeamDelete    = "team:delete"
+	TeamTransfer  = "team:transfer"
+	MembersRead   = "members:read"
+	MembersInvite = "members:invite"
+	MembersUpdate = "members:update"
+	MembersRemove = "members:remove"
+	RolesManage   = "roles:manage"
+	TokensManage  = "tokens:manage"
+	SSOManage     = "sso:manage"
+	DomainsManage = "domains:manage"
+	BillingRead   = "billing:read"
+	BillingManage = "billing:manage"
+)
+
+// Permission describes something a team role can be allowed to do.
+type Permission struct {
+	Name        string
+	Description string
+	Roles       []models.Role // Built-in roles holding the permission; the owner holds every permission
+	OwnerOnly   bool          // Cannot be granted to custom roles
+}
+
+var builtin = []Permission{
+	{Name: TeamRead, Description: "View the team", Roles: []models.Role{models.RoleAdmin, models.RoleMember}},
+	{Name: TeamUpdate, Description: "Rename the team", Roles: []models.Role{models.RoleAdmin}},
+	{Name: TeamDelete, Description: "Delete the team", OwnerOnly: true},
+	{Name: TeamTransfer, Description: "Transfer ownership of the team", OwnerOnly: true},
+	{Name: MembersRead, Description: "View members", Roles: []models.Role{models.RoleAdmin, models.RoleMember}},
+	{Name: MembersInvite, Description: "Invite members and manage invitations", Roles: []models.Role{models.RoleAdmin}},
+	{Name: MembersUpdate, Description: "Change the roles of members", Roles: []models.Role{models.RoleAdmin}},
+	{Name: MembersRemove, Description: "Remove members", Roles: []models.Role{models.RoleAdmin}},
+	{Name: RolesManage, Description: "Create and edit custom roles", Roles: []models.Role{models.RoleAdmin}},
+	{Name: TokensManage, Description: "Manage team access tokens", Roles: []models.Role{models.RoleAdmin}},
+	{Name: SSOManage, Description: "Manage single sign-on and provisioning", OwnerOnly: true},
+	{Name: DomainsManage, Description: "Claim email domains and choose who can join through them", Roles: []models.Role{models.RoleAdmin}},
+	{Name: BillingRead, Description: "View subscriptions and invoices"},
+	{Name: BillingManage, Description: "Change subscriptions and payment details"},
+}
+
+var (
+	mu         sync.RWMutex
+	registered = make(map[string]Permission)
+	order      []string
+)
+
+func init() {
+	Register(builtin...)
+}
+
+// Register adds permissions to the catalog. Modules call it, usually through
+// modules.Register, to declare the permissions their routes require. It panics when a
+// name is already registered, so a module cannot redefine which roles hold a built-in
+// permission or another module's.
+func Register(permissions ...Permission) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, p := range permissions {
+		if _, exists := registered[p.Name]; exists {
+			panic(fmt.Sprintf("permissions: %s is already registered", p.Name))
+		}
+		order = append(order, p.Name)
+		registered[p.Name] = p
+	}
+}
+
+// All returns every registered permission in registration order.
+func All() []Permission {
+	mu.RLock()
+	defer mu.RUnlock()
+	all := make([]Permission, 0, len(order))
+	for _, name := range order {
+		all = append(all, registered[name])
+	}
+	return all
+}
+
+// Lookup returns a registered permission.
+func Lookup(name string) (Permission, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := registered[name]
+	return p, ok
+}
+
+// RolePermissions returns the permissions of a built-in role.
+func RolePermissions(role models.Role) []string {
+	var names []string
+	for _, p := range All() {
+		if role == models.RoleOwner || hasRole(p.Roles, role) {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// Validate checks the permissions of a custom role and returns them in catalog order
+// without duplicates. Unknown and owner-only permissions are refused.
+func Validate(names []string) ([]string, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		p, ok := Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown permission %q", name)
+		}
+		if p.OwnerOnly {
+			return nil, fmt.Errorf("the %s permission is reserved for the team owner", name)
+		}
+		wanted[name] = true
+	}
+	valid := make([]string, 0, len(wanted))
+	for _, p := range All() {
+		if wanted[p.Name] {
+			valid = append(valid, p.Name)
+		}
+	}
+	return valid, nil
+}
+
+// Includes reports whether held contains every permission in wanted.
+func Includes(held, wanted []string) bool {
+	for _, w := range wanted {
+		if !contains(held, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasRole(roles []models.Role, role models.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
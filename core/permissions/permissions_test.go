@@ -0,0 +1,66 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/solotoabillion/stab/models"
+)
+
+func TestRolePermissions(t *testing.T) {
+	owner := RolePermissions(models.RoleOwner)
+	if len(owner) != len(All()) {
+		t.Errorf("owner holds %d of %d permissions", len(owner), len(All()))
+	}
+	admin := RolePermissions(models.RoleAdmin)
+	if !Includes(admin, []string{MembersInvite, MembersRemove, TokensManage, DomainsManage}) {
+		t.Errorf("admin permissions %v miss member management", admin)
+	}
+	if Includes(admin, []string{SSOManage}) || Includes(admin, []string{BillingManage}) {
+		t.Errorf("admin permissions %v include owner permissions", admin)
+	}
+	member := RolePermissions(models.RoleMember)
+	if !Includes(member, []string{TeamRead, MembersRead}) || Includes(member, []string{MembersInvite}) {
+		t.Errorf("member permissions = %v", member)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	got, err := Validate([]string{" Members:Remove", TeamRead, TeamRead})
+	if err != nil {
+		t.Fatalf("Validate returned %v", err)
+	}
+	if len(got) != 2 || got[0] != TeamRead || got[1] != MembersRemove {
+		t.Errorf("Validate = %v, want [%s %s]", got, TeamRead, MembersRemove)
+	}
+	if _, err := Validate([]string{"members:fly"}); err == nil {
+		t.Error("Validate accepted an unknown permission")
+	}
+	if _, err := Validate([]string{TeamDelete}); err == nil {
+		t.Error("Validate accepted an owner-only permission")
+	}
+}
+
+func TestRegisterModulePermission(t *testing.T) {
+	Register(Permission{Name: "reports:export", Description: "Export reports", Roles: []models.Role{models.RoleAdmin}})
+	if _, ok := Lookup("reports:export"); !ok {
+		t.Fatal("registered permission not found")
+	}
+	if !Includes(RolePermissions(models.RoleAdmin), []string{"reports:export"}) {
+		t.Error("admin does not hold a module permission granted to admins")
+	}
+	if Includes(RolePermissions(models.RoleMember), []string{"reports:export"}) {
+		t.Error("member holds a module permission granted to admins only")
+	}
+	if !Includes(RolePermissions(models.RoleOwner), []string{"reports:export"}) {
+		t.Error("owner does not hold a module permission")
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a built-in permission again did not panic")
+		}
+	}()
+	Register(Permission{Name: MembersInvite, Roles: []models.Role{models.RoleMember}})
+}
@@ -0,0 +1,433 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed filter expression (RFC 7644 section 3.4.2.2).
+type Filter interface {
+	// Match reports whether a resource in its JSON object form satisfies the filter.
+	Match(resource map[string]interface{}) bool
+}
+
+// ParseFilter parses a filter expression such as
+//
+//	userName eq "ada@example.com" and (active eq true or emails[type eq "work"])
+//
+// String comparisons are case-insensitive.
+func ParseFilter(expr string) (Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, invalidFilter("unexpected " + p.peek().text)
+	}
+	return f, nil
+}
+
+func invalidFilter(detail string) *Error {
+	return NewError(http.StatusBadRequest, ErrTypeInvalidFilter, "Invalid filter: "+detail)
+}
+
+// --- Tokenizer ---
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenOpenBracket, text: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenCloseBracket, text: "]"})
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(expr) && expr[j] != '"'; j++ {
+				if expr[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(expr) {
+				return nil, invalidFilter("unterminated string")
+			}
+			var s string
+			if err := json.Unmarshal([]byte(expr[i:j+1]), &s); err != nil {
+				return nil, invalidFilter("invalid string " + expr[i:j+1])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s})
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(expr) && !strings.ContainsRune(" \t\n()[]\"", rune(expr[j])); j++ {
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: expr[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// --- Parser ---
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{kind: tokenWord, text: "end of filter"}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return !p.done() && t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.next()
+		if p.next().kind != tokenOpen {
+			return nil, invalidFilter("expected ( after not")
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenClose {
+			return nil, invalidFilter("expected )")
+		}
+		return notFilter{f}, nil
+	}
+	if p.peek().kind == tokenOpen {
+		p.next()
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenClose {
+			return nil, invalidFilter("expected )")
+		}
+		return f, nil
+	}
+	return p.parseAttribute()
+}
+
+func (p *parser) parseAttribute() (Filter, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return nil, invalidFilter("expected attribute, got " + t.text)
+	}
+	path := parseAttrPath(t.text)
+
+	// emails[type eq "work"]
+	if p.peek().kind == tokenOpenBracket {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenCloseBracket {
+			return nil, invalidFilter("expected ]")
+		}
+		return valuePathFilter{path: path, filter: inner}, nil
+	}
+
+	op := strings.ToLower(p.next().text)
+	if op == "pr" {
+		return presentFilter{path: path}, nil
+	}
+	switch op {
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, invalidFilter("unknown operator " + op)
+	}
+	if p.done() {
+		return nil, invalidFilter("missing value")
+	}
+	value, err := parseValue(p.next())
+	if err != nil {
+		return nil, err
+	}
+	return compareFilter{path: path, op: op, value: value}, nil
+}
+
+func parseValue(t token) (interface{}, error) {
+	if t.kind == tokenString {
+		return t.text, nil
+	}
+	if t.kind != tokenWord {
+		return nil, invalidFilter("expected value, got " + t.text)
+	}
+	switch strings.ToLower(t.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, invalidFilter("invalid value " + t.text)
+	}
+	return n, nil
+}
+
+// parseAttrPath splits "name.givenName" into its parts, dropping a core schema URN prefix.
+func parseAttrPath(path string) []string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if len(path) > len(schema) && strings.EqualFold(path[:len(schema)+1], schema+":") {
+			path = path[len(schema)+1:]
+			break
+		}
+	}
+	return strings.Split(path, ".")
+}
+
+// --- Evaluation ---
+
+type orFilter struct{ left, right Filter }
+
+func (f orFilter) Match(r map[string]interface{}) bool { return f.left.Match(r) || f.right.Match(r) }
+
+type andFilter struct{ left, right Filter }
+
+func (f andFilter) Match(r map[string]interface{}) bool { return f.left.Match(r) && f.right.Match(r) }
+
+type notFilter struct{ f Filter }
+
+func (f notFilter) Match(r map[string]interface{}) bool { return !f.f.Match(r) }
+
+type presentFilter struct{ path []string }
+
+func (f presentFilter) Match(r map[string]interface{}) bool {
+	for _, v := range resolve(r, f.path) {
+		if s, ok := v.(string); !ok || s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type valuePathFilter struct {
+	path   []string
+	filter Filter
+}
+
+func (f valuePathFilter) Match(r map[string]interface{}) bool {
+	for _, v := range resolveRaw(r, f.path) {
+		if m, ok := v.(map[string]interface{}); ok && f.filter.Match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	path  []string
+	op    string
+	value interface{}
+}
+
+func (f compareFilter) Match(r map[string]interface{}) bool {
+	values := resolve(r, f.path)
+	if f.op == "ne" {
+		for _, v := range values {
+			if compare(v, "eq", f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	if f.value == nil && f.op == "eq" {
+		return len(values) == 0
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the value of a key, matching attribute names case-insensitively.
+func lookup(m map[string]interface{}, name string) (string, interface{}, bool) {
+	if v, ok := m[name]; ok {
+		return name, v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// resolveRaw walks an attribute path, flattening multi-valued attributes.
+func resolveRaw(v interface{}, path []string) []interface{} {
+	if arr, ok := v.([]interface{}); ok {
+		var out []interface{}
+		for _, item := range arr {
+			out = append(out, resolveRaw(item, path)...)
+		}
+		return out
+	}
+	if len(path) == 0 {
+		if v == nil {
+			return nil
+		}
+		return []interface{}{v}
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	_, child, found := lookup(m, path[0])
+	if !found {
+		return nil
+	}
+	return resolveRaw(child, path[1:])
+}
+
+// resolve is resolveRaw for comparisons: complex values compare by their "value" sub-attribute.
+func resolve(r map[string]interface{}, path []string) []interface{} {
+	values := resolveRaw(r, path)
+	for i, v := range values {
+		if m, ok := v.(map[string]interface{}); ok {
+			_, values[i], _ = lookup(m, "value")
+		}
+	}
+	return values
+}
+
+func compare(actual interface{}, op string, expected interface{}) bool {
+	switch want := expected.(type) {
+	case string:
+		got, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		got, want = strings.ToLower(got), strings.ToLower(want)
+		switch op {
+		case "eq":
+			return got == want
+		case "co":
+			return strings.Contains(got, want)
+		case "sw":
+			return strings.HasPrefix(got, want)
+		case "ew":
+			return strings.HasSuffix(got, want)
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case float64:
+		got, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return got == want
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case bool:
+		got, ok := actual.(bool)
+		if !ok {
+			if s, isString := actual.(string); isString {
+				got, ok = strings.EqualFold(s, "true"), strings.EqualFold(s, "true") || strings.EqualFold(s, "false")
+			}
+		}
+		return ok && op == "eq" && got == want
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// patchPath is a parsed PATCH path such as "name.givenName" or `emails[type eq "work"].value`.
+type patchPath struct {
+	attr   string
+	filter Filter // Selects elements of a multi-valued attribute
+	sub    string
+}
+
+func parsePatchPath(path string) (*patchPath, error) {
+	invalid := NewError(http.StatusBadRequest, ErrTypeInvalidPath, "Invalid path: "+path)
+
+	if open := strings.IndexByte(path, '['); open >= 0 {
+		end := strings.LastIndexByte(path, ']')
+		if end < open {
+			return nil, invalid
+		}
+		filter, err := ParseFilter(path[open+1 : end])
+		if err != nil {
+			return nil, err
+		}
+		attrPath := parseAttrPath(path[:open])
+		rest := path[end+1:]
+		if len(attrPath) != 1 || (rest != "" && !strings.HasPrefix(rest, ".")) {
+			return nil, invalid
+		}
+		return &patchPath{attr: attrPath[0], filter: filter, sub: strings.TrimPrefix(rest, ".")}, nil
+	}
+
+	parts := parseAttrPath(path)
+	if len(parts) > 2 || parts[0] == "" {
+		return nil, invalid
+	}
+	pp := &patchPath{attr: parts[0]}
+	if len(parts) == 2 {
+		pp.sub = parts[1]
+	}
+	return pp, nil
+}
+
+// ApplyPatch applies PATCH operations (RFC 7644 section 3.5.2) to a resource in its
+// JSON object form. Attribute names match case-insensitively.
+func ApplyPatch(resource map[string]interface{}, ops []PatchOperation) error {
+	for _, op := range ops {
+		var value interface{}
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return NewError(http.StatusBadRequest, ErrTypeInvalidSyntax, "Invalid operation value")
+			}
+		}
+
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			err = applySet(resource, strings.ToLower(op.Op), op.Path, value)
+		case "remove":
+			err = applyRemove(resource, op.Path, value)
+		default:
+			err = NewError(http.StatusBadRequest, ErrTypeInvalidSyntax, "Unsupported operation "+op.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applySet performs add and replace. They differ for multi-valued attributes:
+// add appends to the existing values while replace swaps them out.
+func applySet(resource map[string]interface{}, op, path string, value interface{}) error {
+	if path == "" {
+		attrs, ok := value.(map[string]interface{})
+		if !ok {
+			return NewError(http.StatusBadRequest, ErrTypeInvalidValue, "Operation without a path needs an object value")
+		}
+		for k, v := range attrs {
+			// Some directories put paths such as "name.givenName" in the keys
+			if err := applySet(resource, op, k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	pp, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+	key, existing, found := lookup(resource, pp.attr)
+	if !found {
+		key = pp.attr
+	}
+
+	if pp.filter != nil {
+		elements, _ := existing.([]interface{})
+		matched := false
+		for i, element := range elements {
+			m, ok := element.(map[string]interface{})
+			if !ok || !pp.filter.Match(m) {
+				continue
+			}
+			matched = true
+			if pp.sub == "" {
+				elements[i] = value
+			} else {
+				subKey, _, subFound := lookup(m, pp.sub)
+				if !subFound {
+					subKey = pp.sub
+				}
+				m[subKey] = value
+			}
+		}
+		if !matched {
+			return NewError(http.StatusBadRequest, ErrTypeNoTarget, "No values match the path "+path)
+		}
+		return nil
+	}
+
+	if pp.sub != "" {
+		m, ok := existing.(map[string]interface{})
+		if !ok {
+			if found && existing != nil {
+				return NewError(http.StatusBadRequest, ErrTypeInvalidPath, "Invalid path: "+path)
+			}
+			m = map[string]interface{}{}
+			resource[key] = m
+		}
+		subKey, _, subFound := lookup(m, pp.sub)
+		if !subFound {
+			subKey = pp.sub
+		}
+		m[subKey] = value
+		return nil
+	}
+
+	if current, isList := existing.([]interface{}); isList && op == "add" {
+		additions, ok := value.([]interface{})
+		if !ok {
+			additions = []interface{}{value}
+		}
+		for _, v := range additions {
+			if !containsValue(current, v) {
+				current = append(current, v)
+			}
+		}
+		resource[key] = current
+		return nil
+	}
+	if current, isMap := existing.(map[string]interface{}); isMap && op == "add" {
+		if attrs, ok := value.(map[string]interface{}); ok {
+			for k, v := range attrs {
+				current[k] = v
+			}
+			return nil
+		}
+	}
+	resource[key] = value
+	return nil
+}
+
+func applyRemove(resource map[string]interface{}, path string, value interface{}) error {
+	if path == "" {
+		return NewError(http.StatusBadRequest, ErrTypeNoTarget, "Remove operations need a path")
+	}
+	pp, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+	key, existing, found := lookup(resource, pp.attr)
+	if !found {
+		return nil
+	}
+
+	switch {
+	case pp.filter != nil:
+		elements, _ := existing.([]interface{})
+		kept := make([]interface{}, 0, len(elements))
+		for _, element := range elements {
+			m, ok := element.(map[string]interface{})
+			if !ok || !pp.filter.Match(m) {
+				kept = append(kept, element)
+				continue
+			}
+			if pp.sub != "" {
+				if subKey, _, subFound := lookup(m, pp.sub); subFound {
+					delete(m, subKey)
+				}
+				kept = append(kept, m)
+			}
+		}
+		resource[key] = kept
+
+	case pp.sub != "":
+		if m, ok := existing.(map[string]interface{}); ok {
+			if subKey, _, subFound := lookup(m, pp.sub); subFound {
+				delete(m, subKey)
+			}
+		}
+
+	default:
+		// Some directories remove list entries with a value instead of a filter:
+		// {"op": "remove", "path": "members", "value": [{"value": "id"}]}
+		if elements, isList := existing.([]interface{}); isList && value != nil {
+			removals, ok := value.([]interface{})
+			if !ok {
+				removals = []interface{}{value}
+			}
+			kept := make([]interface{}, 0, len(elements))
+			for _, element := range elements {
+				if !containsValue(removals, element) {
+					kept = append(kept, element)
+				}
+			}
+			resource[key] = kept
+			return nil
+		}
+		delete(resource, key)
+	}
+	return nil
+}
+
+// containsValue reports whether list holds v. Complex values are compared by their value sub-attribute.
+func containsValue(list []interface{}, v interface{}) bool {
+	vm, vIsMap := v.(map[string]interface{})
+	for _, item := range list {
+		if im, ok := item.(map[string]interface{}); ok && vIsMap {
+			_, a, aFound := lookup(im, "value")
+			_, b, bFound := lookup(vm, "value")
+			if aFound && bFound && reflect.DeepEqual(a, b) {
+				return true
+			}
+		}
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,337 @@
+// Package scim implements the protocol side of SCIM 2.0 (RFC 7643 and RFC 7644):
+// resource and message types, filter expressions and PATCH operations.
+//
+// Resources are filtered and patched in their JSON form, so the same code serves
+// Users and Groups; the caller maps the result back onto its models.
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Schema and message URNs.
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// ContentType is the media type of SCIM requests and responses.
+const ContentType = "application/scim+json"
+
+// MaxResults caps the page size of list responses.
+const MaxResults = 1000
+
+// Error types from RFC 7644 section 3.12.
+const (
+	ErrTypeInvalidFilter = "invalidFilter"
+	ErrTypeUniqueness    = "uniqueness"
+	ErrTypeMutability    = "mutability"
+	ErrTypeInvalidSyntax = "invalidSyntax"
+	ErrTypeInvalidPath   = "invalidPath"
+	ErrTypeNoTarget      = "noTarget"
+	ErrTypeInvalidValue  = "invalidValue"
+)
+
+// Error is a SCIM error response. It implements error so logic can return it directly.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// NewError returns a SCIM error with the HTTP status and optional scimType.
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.ScimType != "" {
+		return fmt.Sprintf("scim %s (%s): %s", e.Status, e.ScimType, e.Detail)
+	}
+	return fmt.Sprintf("scim %s: %s", e.Status, e.Detail)
+}
+
+// StatusCode returns the HTTP status of the error.
+func (e *Error) StatusCode() int {
+	status, err := strconv.Atoi(e.Status)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+// Bool is a boolean that also accepts the strings "true" and "false" in any case,
+// which some directories send for the active attribute.
+type Bool bool
+
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := strconv.ParseBool(strings.ToLower(s))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		*b = Bool(v)
+		return nil
+	}
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = Bool(v)
+	return nil
+}
+
+// Meta holds the resource metadata.
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+// Name is the components of a user's name.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValue is an entry of a multi-valued attribute such as emails, groups or members.
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// User is the SCIM User resource.
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *Name        `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Active      *Bool        `json:"active,omitempty"` // Absent on create means active
+	Groups      []MultiValue `json:"groups,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// PrimaryEmail returns userName, or the primary (else first) email when userName is empty.
+func (u *User) PrimaryEmail() string {
+	if u.UserName != "" {
+		return u.UserName
+	}
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// IsActive reports the active attribute, which defaults to true.
+func (u *User) IsActive() bool {
+	return u.Active == nil || bool(*u.Active)
+}
+
+// Group is the SCIM Group resource.
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// ListResponse is the result of a query.
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// PatchRequest is the body of a PATCH request.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single add, remove or replace operation.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ToMap converts a resource to its JSON object form for filtering and patching.
+func ToMap(resource interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FromMap converts the JSON object form back into a resource.
+func FromMap(m map[string]interface{}, resource interface{}) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, resource); err != nil {
+		return NewError(http.StatusBadRequest, ErrTypeInvalidValue, err.Error())
+	}
+	return nil
+}
+
+// Page applies SCIM pagination (1-based startIndex, count) to a result set.
+func Page(resources []interface{}, startIndex, count int) *ListResponse {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	total := len(resources)
+	from := startIndex - 1
+	if from > total {
+		from = total
+	}
+	to := total
+	if count >= 0 && from+count < total {
+		to = from + count
+	}
+	page := resources[from:to]
+	if page == nil {
+		page = []interface{}{}
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+// ListQuery holds the query parameters of a list request.
+type ListQuery struct {
+	Filter             Filter // nil matches everything
+	StartIndex         int
+	Count              int // At most MaxResults
+	ExcludedAttributes []string
+}
+
+// ParseListQuery reads filter, startIndex, count and excludedAttributes from a request's query.
+func ParseListQuery(query url.Values) (*ListQuery, error) {
+	q := &ListQuery{StartIndex: 1, Count: MaxResults}
+	if expr := strings.TrimSpace(query.Get("filter")); expr != "" {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		q.Filter = f
+	}
+	if v := query.Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, NewError(http.StatusBadRequest, ErrTypeInvalidValue, "Invalid startIndex")
+		}
+		q.StartIndex = n
+	}
+	if v := query.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, NewError(http.StatusBadRequest, ErrTypeInvalidValue, "Invalid count")
+		}
+		q.Count = min(n, MaxResults)
+	}
+	for _, attr := range strings.Split(query.Get("excludedAttributes"), ",") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			q.ExcludedAttributes = append(q.ExcludedAttributes, attr)
+		}
+	}
+	return q, nil
+}
+
+// Excludes reports whether the client asked to leave out the attribute.
+func (q *ListQuery) Excludes(attr string) bool {
+	for _, excluded := range q.ExcludedAttributes {
+		if path := parseAttrPath(excluded); len(path) == 1 && strings.EqualFold(path[0], attr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply filters the resources in their JSON object form and returns the requested page.
+func (q *ListQuery) Apply(resources []map[string]interface{}) *ListResponse {
+	matched := make([]interface{}, 0, len(resources))
+	for _, r := range resources {
+		if q.Filter == nil || q.Filter.Match(r) {
+			matched = append(matched, r)
+		}
+	}
+	return Page(matched, q.StartIndex, q.Count)
+}
+
+// ServiceProviderConfig describes the features this service provider supports.
+type ServiceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	DocumentationURI      string                 `json:"documentationUri,omitempty"`
+	Patch                 Supported              `json:"patch"`
+	Bulk                  BulkSupport            `json:"bulk"`
+	Filter                FilterSupport          `json:"filter"`
+	ChangePassword        Supported              `json:"changePassword"`
+	Sort                  Supported              `json:"sort"`
+	ETag                  Supported              `json:"etag"`
+	AuthenticationSchemes []AuthenticationScheme `json:"authenticationSchemes"`
+	Meta                  *Meta                  `json:"meta,omitempty"`
+}
+
+type Supported struct {
+	Supported bool `json:"supported"`
+}
+
+type BulkSupport struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type FilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type AuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary,omitempty"`
+}
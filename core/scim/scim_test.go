@@ -0,0 +1,152 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func testUser(t *testing.T) map[string]interface{} {
+	t.Helper()
+	active := Bool(true)
+	m, err := ToMap(&User{
+		Schemas:    []string{SchemaUser},
+		ID:         "u1",
+		ExternalID: "00u1",
+		UserName:   "Ada@Example.com",
+		Name:       &Name{GivenName: "Ada", FamilyName: "Lovelace"},
+		Emails:     []MultiValue{{Value: "ada@example.com", Type: "work", Primary: true}},
+		Active:     &active,
+		Meta:       &Meta{ResourceType: "User", Created: "2024-01-02T00:00:00Z"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestFilter(t *testing.T) {
+	user := testUser(t)
+	cases := map[string]bool{
+		`userName eq "ada@example.com"`:                                     true,
+		`userName eq "grace@example.com"`:                                   false,
+		`urn:ietf:params:scim:schemas:core:2.0:User:userName sw "ada"`:      true,
+		`name.familyName co "love"`:                                         true,
+		`emails[type eq "work" and value ew "@example.com"]`:                true,
+		`emails[type eq "home"]`:                                            false,
+		`emails eq "ada@example.com"`:                                       true,
+		`active eq true and not (externalId eq "other")`:                    true,
+		`externalId pr`:                                                     true,
+		`displayName pr`:                                                    false,
+		`meta.created gt "2023-12-31T00:00:00Z"`:                            true,
+		`userName ne "ada@example.com" or (active eq false)`:                false,
+		`(userName eq "x" or userName eq "ada@example.com") and id eq "u1"`: true,
+	}
+	for expr, want := range cases {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", expr, err)
+			continue
+		}
+		if got := f.Match(user); got != want {
+			t.Errorf("%q matched %v, want %v", expr, got, want)
+		}
+	}
+
+	for _, expr := range []string{`userName eq`, `userName xx "a"`, `(userName eq "a"`, `userName eq "a`, `emails[type eq "work"`} {
+		var scimErr *Error
+		if _, err := ParseFilter(expr); !errors.As(err, &scimErr) || scimErr.ScimType != ErrTypeInvalidFilter {
+			t.Errorf("ParseFilter(%q) = %v, want invalidFilter", expr, err)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	user := testUser(t)
+	var req PatchRequest
+	err := json.Unmarshal([]byte(`{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "Replace", "path": "active", "value": "False"},
+			{"op": "replace", "value": {"name.givenName": "Augusta", "externalId": "00u2"}},
+			{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "augusta@example.com"},
+			{"op": "add", "path": "emails", "value": [{"value": "ada@home.example", "type": "home"}]},
+			{"op": "remove", "path": "name.familyName"}
+		]
+	}`), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyPatch(user, req.Operations); err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+
+	var got User
+	if err := FromMap(user, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.IsActive() || got.ExternalID != "00u2" || got.Name.GivenName != "Augusta" || got.Name.FamilyName != "" {
+		t.Fatalf("unexpected user after patch: %+v %+v", got, got.Name)
+	}
+	if len(got.Emails) != 2 || got.Emails[0].Value != "augusta@example.com" || got.Emails[1].Type != "home" {
+		t.Fatalf("unexpected emails after patch: %+v", got.Emails)
+	}
+
+	group, _ := ToMap(&Group{Schemas: []string{SchemaGroup}, ID: "admin", DisplayName: "admin", Members: []MultiValue{{Value: "u1"}, {Value: "u2"}}})
+	ops := []PatchOperation{
+		{Op: "add", Path: "members", Value: json.RawMessage(`[{"value": "u2"}, {"value": "u3"}]`)},
+		{Op: "remove", Path: `members[value eq "u1"]`},
+		{Op: "remove", Path: "members", Value: json.RawMessage(`[{"value": "u3"}]`)},
+	}
+	if err := ApplyPatch(group, ops); err != nil {
+		t.Fatalf("ApplyPatch on group: %v", err)
+	}
+	var g Group
+	if err := FromMap(group, &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Members) != 1 || g.Members[0].Value != "u2" {
+		t.Fatalf("unexpected members after patch: %+v", g.Members)
+	}
+
+	var scimErr *Error
+	err = ApplyPatch(group, []PatchOperation{{Op: "replace", Path: `members[value eq "nobody"].display`, Value: json.RawMessage(`"x"`)}})
+	if !errors.As(err, &scimErr) || scimErr.ScimType != ErrTypeNoTarget {
+		t.Fatalf("expected noTarget, got %v", err)
+	}
+}
+
+func TestPage(t *testing.T) {
+	resources := []interface{}{1, 2, 3, 4, 5}
+	page := Page(resources, 2, 2)
+	if page.TotalResults != 5 || page.ItemsPerPage != 2 || page.Resources[0] != 2 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	if page := Page(resources, 10, 5); page.ItemsPerPage != 0 || page.Resources == nil {
+		t.Fatalf("expected empty page past the end, got %+v", page)
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	q, err := ParseListQuery(url.Values{
+		"filter":             {`userName sw "a"`},
+		"startIndex":         {"2"},
+		"count":              {"100000"},
+		"excludedAttributes": {"members, meta"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.StartIndex != 2 || q.Count != MaxResults || !q.Excludes("members") || q.Excludes("groups") {
+		t.Fatalf("unexpected query %+v", q)
+	}
+	resp := q.Apply([]map[string]interface{}{{"userName": "ada"}, {"userName": "alan"}, {"userName": "grace"}})
+	if resp.TotalResults != 2 || resp.ItemsPerPage != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	if _, err := ParseListQuery(url.Values{"count": {"-1"}}); err == nil {
+		t.Fatal("expected negative count to be rejected")
+	}
+}
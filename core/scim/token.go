@@ -0,0 +1,29 @@
+package scim
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/solotoabillion/stab/core/security"
+)
+
+const (
+	// TokenPrefix starts every SCIM bearer token so leaked tokens are easy to recognise.
+	TokenPrefix = "scim_"
+
+	tokenLength = 40
+	// displayLength is how much of a token is kept in plain text to tell tokens apart.
+	displayLength = len(TokenPrefix) + 6
+)
+
+// NewToken returns a new bearer token, the prefix to display for it and the hash to store.
+func NewToken() (token, display, hash string) {
+	token = TokenPrefix + security.RandomString(tokenLength)
+	return token, token[:displayLength], HashToken(token)
+}
+
+// HashToken returns the stored form of a bearer token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,241 @@
+// Package seats holds teams to the member limit of their owner's plan and keeps per-seat
+// subscriptions billed for the seats in use.
+package seats
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	stripe "github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/subscriptionitem"
+	"gorm.io/gorm"
+)
+
+// LimitError is returned when a team has no seat left on its owner's plan.
+type LimitError struct {
+	Plan     string
+	MaxSeats int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("The %s plan allows up to %d team members. Upgrade the team owner's plan to add more.", e.Plan, e.MaxSeats)
+}
+
+// Usage is how many of a team's seats are taken. Pending invitations hold a seat until
+// they are answered or expire.
+type Usage struct {
+	Plan     string
+	MaxSeats int // Zero means unlimited
+	Members  int64
+	Pending  int64
+}
+
+// CanInvite reports whether one more person can be invited or let in.
+func (u Usage) CanInvite() bool {
+	return u.MaxSeats <= 0 || u.Members+u.Pending < int64(u.MaxSeats)
+}
+
+// CanAccept reports whether a pending invitation can be accepted. Its seat is already
+// counted, so only current members are compared with the limit; this keeps invitations
+// sent before a downgrade from growing the team past the new limit.
+func (u Usage) CanAccept() bool {
+	return u.MaxSeats <= 0 || u.Members < int64(u.MaxSeats)
+}
+
+func (u Usage) limitError() error {
+	return &LimitError{Plan: u.Plan, MaxSeats: u.MaxSeats}
+}
+
+// Load counts the team's seats against its plan: the plan the team subscribes to in its
+// own name, or else its owner's. Plans that no longer exist do not limit the team.
+func Load(db *gorm.DB, team *models.Team) (Usage, error) {
+	planID, err := PlanID(db, team)
+	if err != nil {
+		return Usage{}, err
+	}
+	usage := Usage{Plan: planID}
+	plan, err := models.FindPlanByID(db, planID)
+	switch {
+	case err == nil:
+		usage.Plan = plan.Name
+		usage.MaxSeats = plan.Limits.MaxSeats
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return Usage{}, fmt.Errorf("failed to load plan %s: %w", planID, err)
+	}
+	if usage.MaxSeats <= 0 {
+		return usage, nil
+	}
+	if usage.Members, err = models.CountMembershipsByTeam(db, team.ID); err != nil {
+		return Usage{}, fmt.Errorf("failed to count members of team %s: %w", team.ID, err)
+	}
+	if usage.Pending, err = models.CountPendingInvitationsByTeam(db, team.ID); err != nil {
+		return Usage{}, fmt.Errorf("failed to count invitations of team %s: %w", team.ID, err)
+	}
+	return usage, nil
+}
+
+// PlanID returns the plan the team is on: the plan of its own active subscription, or else
+// its owner's.
+func PlanID(db *gorm.DB, team *models.Team) (string, error) {
+	sub, err := billingSubscription(db, team)
+	if err != nil {
+		return "", err
+	}
+	if sub != nil {
+		return sub.PlanID, nil
+	}
+	owner, err := models.FindUserByID(db, team.OwnerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load owner of team %s: %w", team.ID, err)
+	}
+	return owner.Plan, nil
+}
+
+// billingSubscription returns the active subscription that pays for the team's seats: the
+// team's own, or else its owner's personal one. It returns nil when there is neither.
+func billingSubscription(db *gorm.DB, team *models.Team) (*models.Subscription, error) {
+	sub, err := models.FindLatestActiveSubscriptionBySubject(db, models.BillingSubjectTeam, team.ID)
+	if err == nil {
+		return sub, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load subscription of team %s: %w", team.ID, err)
+	}
+	sub, err = models.FindLatestActiveSubscriptionByUserID(db, team.OwnerID)
+	if err == nil {
+		return sub, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load subscription of user %s: %w", team.OwnerID, err)
+	}
+	return nil, nil
+}
+
+// Count returns the seats a subject pays for on a per-seat plan: the members of a team,
+// or the members of the teams a user owns that are not billed in their own name. It is
+// never less than one, the subscriber's own seat.
+func Count(db *gorm.DB, subjectType models.BillingSubjectType, subjectID uuid.UUID) (int64, error) {
+	count, err := models.CountMembershipsInTeamsOwnedBy(db, subjectID)
+	if subjectType == models.BillingSubjectTeam {
+		count, err = models.CountMembershipsByTeam(db, subjectID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count seats of %s %s: %w", subjectType, subjectID, err)
+	}
+	if count < 1 {
+		count = 1
+	}
+	return count, nil
+}
+
+// CheckInvite returns a *LimitError when the team cannot take another person.
+func CheckInvite(db *gorm.DB, team *models.Team) error {
+	usage, err := Load(db, team)
+	if err != nil {
+		return err
+	}
+	if !usage.CanInvite() {
+		return usage.limitError()
+	}
+	return nil
+}
+
+// Join adds the user to the team with the role, taking a seat, unless they already belong
+// to it. The team row stays locked while the seats are counted and the membership is
+// stored, so concurrent joins cannot go over the limit. It returns a *LimitError when the
+// team is full.
+func Join(db *gorm.DB, team *models.Team, userID uuid.UUID, role models.Role) (membership *models.Membership, created bool, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := models.LockTeam(tx, team.ID); err != nil {
+			return fmt.Errorf("failed to lock team %s: %w", team.ID, err)
+		}
+		existing, err := models.FindMembershipByUserAndTeam(tx, userID, team.ID)
+		if err == nil {
+			membership = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to load membership of user %s in team %s: %w", userID, team.ID, err)
+		}
+		if err := CheckInvite(tx, team); err != nil {
+			return err
+		}
+		membership, created, err = models.EnsureMembership(tx, userID, team.ID, role)
+		if err != nil {
+			return fmt.Errorf("failed to add user %s to team %s: %w", userID, team.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return membership, created, nil
+}
+
+// CheckAccept returns a *LimitError when the team is full and a pending invitation to it
+// cannot be accepted.
+func CheckAccept(db *gorm.DB, team *models.Team) error {
+	usage, err := Load(db, team)
+	if err != nil {
+		return err
+	}
+	if !usage.CanAccept() {
+		return usage.limitError()
+	}
+	return nil
+}
+
+// Sync sets the quantity of the per-seat subscription paying for the team to the seats
+// in use. Teams without an active per-seat subscription are left alone.
+func Sync(db *gorm.DB, stripeKey string, team *models.Team) error {
+	sub, err := billingSubscription(db, team)
+	if err != nil || sub == nil {
+		return err
+	}
+	plan, err := models.FindPlanByID(db, sub.PlanID)
+	if err != nil {
+		return fmt.Errorf("failed to load plan %s: %w", sub.PlanID, err)
+	}
+	if !plan.PerSeat {
+		return nil
+	}
+	if stripeKey == "" {
+		return errors.New("stripe is not configured")
+	}
+	seats, err := Count(db, sub.SubjectType, *sub.SubjectID)
+	if err != nil {
+		return err
+	}
+
+	stripe.Key = stripeKey
+	params := &stripe.SubscriptionItemListParams{Subscription: stripe.String(sub.StripeSubscriptionID)}
+	iter := subscriptionitem.List(params)
+	for iter.Next() {
+		item := iter.SubscriptionItem()
+		if item.Price == nil || !isPlanPrice(plan, item.Price.ID) {
+			continue
+		}
+		if item.Quantity == seats {
+			return nil
+		}
+		_, err := subscriptionitem.Update(item.ID, &stripe.SubscriptionItemParams{
+			Quantity:          stripe.Int64(seats),
+			ProrationBehavior: stripe.String("create_prorations"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update seats of subscription %s: %w", sub.StripeSubscriptionID, err)
+		}
+		return nil
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to list items of subscription %s: %w", sub.StripeSubscriptionID, err)
+	}
+	return fmt.Errorf("subscription %s has no item for plan %s", sub.StripeSubscriptionID, plan.ID)
+}
+
+func isPlanPrice(plan *models.Plan, priceID string) bool {
+	return priceID == plan.StripePriceID || (plan.StripePriceIDYearly != nil && priceID == *plan.StripePriceIDYearly)
+}
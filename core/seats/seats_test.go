@@ -0,0 +1,33 @@
+package seats
+
+import "testing"
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		name                 string
+		usage                Usage
+		canInvite, canAccept bool
+	}{
+		{"unlimited", Usage{MaxSeats: 0, Members: 40, Pending: 10}, true, true},
+		{"room left", Usage{MaxSeats: 5, Members: 3, Pending: 1}, true, true},
+		{"seats held by invitations", Usage{MaxSeats: 5, Members: 3, Pending: 2}, false, true},
+		{"full", Usage{MaxSeats: 5, Members: 5}, false, false},
+		{"over the limit after a downgrade", Usage{MaxSeats: 1, Members: 4, Pending: 2}, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.usage.CanInvite(); got != tt.canInvite {
+			t.Errorf("%s: CanInvite() = %v, want %v", tt.name, got, tt.canInvite)
+		}
+		if got := tt.usage.CanAccept(); got != tt.canAccept {
+			t.Errorf("%s: CanAccept() = %v, want %v", tt.name, got, tt.canAccept)
+		}
+	}
+}
+
+func TestLimitError(t *testing.T) {
+	err := Usage{Plan: "Indie", MaxSeats: 5}.limitError()
+	want := "The Indie plan allows up to 5 team members. Upgrade the team owner's plan to add more."
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,62 @@
+package security
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B vectors (SHA1), truncated to 6 digits.
+func TestTOTPCode_RFC6238(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for ts, want := range cases {
+		got, err := TOTPCode(secret, time.Unix(ts, 0))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("TOTPCode at %d = %s, want %s", ts, got, want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatalf("unexpected error generating secret: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	code, _ := TOTPCode(secret, now)
+
+	step, ok := ValidateTOTP(secret, code, now, 0)
+	if !ok {
+		t.Fatal("expected current code to validate")
+	}
+	if _, ok := ValidateTOTP(secret, code, now, step); ok {
+		t.Error("expected replayed code to be rejected")
+	}
+	if _, ok := ValidateTOTP(secret, code, now.Add(30*time.Second), 0); !ok {
+		t.Error("expected code from previous step to be accepted within skew")
+	}
+	if _, ok := ValidateTOTP(secret, code, now.Add(5*time.Minute), 0); ok {
+		t.Error("expected stale code to be rejected")
+	}
+}
+
+func TestTOTPAuthURI(t *testing.T) {
+	uri := TOTPAuthURI("Acme", "jane@example.com", "ABCDEF")
+	if !strings.HasPrefix(uri, "otpauth://totp/Acme:jane@example.com?") {
+		t.Errorf("unexpected URI prefix: %s", uri)
+	}
+	if !strings.Contains(uri, "secret=ABCDEF") || !strings.Contains(uri, "issuer=Acme") {
+		t.Errorf("URI missing secret or issuer: %s", uri)
+	}
+}
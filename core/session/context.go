@@ -3,6 +3,7 @@ package session
 import (
 	"github.com/solotoabillion/stab/models"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
@@ -10,13 +11,31 @@ const (
 	ContextAccountKey      string = "accountCtx"
 	ContextUserKey         string = "userCtx"
 	ContextSubscriptionKey string = "subscriptionCtx"
+	ContextSessionIDKey    string = "sessionIDCtx"
+	ContextSCIMTeamKey     string = "scimTeamCtx"
+	ContextAPITokenKey     string = "apiTokenCtx"
+	ContextImpersonatorKey string = "impersonatorCtx"
 )
 
-func AccountFromContext(c echo.Context) *models.Team {
+// Account is the team the signed-in user is acting for, with their membership of it.
+type Account struct {
+	*models.Team
+	Membership *models.Membership
+}
+
+// Role returns the user's built-in role in the team. Members with a custom role also
+// carry Membership.RoleID; use permissions.Authorize to check what they may do.
+func (a *Account) Role() models.Role {
+	return a.Membership.Role
+}
+
+// AccountFromContext returns the team selected with the X-Team-ID header, a team access
+// token or the account cookie, or nil when the user is acting for their personal account.
+func AccountFromContext(c echo.Context) *Account {
 	if c.Get(ContextAccountKey) == nil {
 		return nil
 	}
-	return c.Get(ContextAccountKey).(*models.Team)
+	return c.Get(ContextAccountKey).(*Account)
 }
 
 func UserFromContext(c echo.Context) *models.User {
@@ -26,6 +45,41 @@ func UserFromContext(c echo.Context) *models.User {
 	return c.Get(ContextUserKey).(*models.User)
 }
 
+// SessionIDFromContext returns the ID of the signed-in session, or uuid.Nil when the
+// request was authenticated without one (e.g. an API key or a legacy token).
+func SessionIDFromContext(c echo.Context) uuid.UUID {
+	if c.Get(ContextSessionIDKey) == nil {
+		return uuid.Nil
+	}
+	return c.Get(ContextSessionIDKey).(uuid.UUID)
+}
+
+// SCIMTeamFromContext returns the team whose SCIM token authenticated the request.
+func SCIMTeamFromContext(c echo.Context) *models.Team {
+	if c.Get(ContextSCIMTeamKey) == nil {
+		return nil
+	}
+	return c.Get(ContextSCIMTeamKey).(*models.Team)
+}
+
+// APITokenFromContext returns the access token that authenticated the request, or nil
+// when the user signed in interactively.
+func APITokenFromContext(c echo.Context) *models.APIToken {
+	if c.Get(ContextAPITokenKey) == nil {
+		return nil
+	}
+	return c.Get(ContextAPITokenKey).(*models.APIToken)
+}
+
+// ImpersonatorFromContext returns the admin acting as the user, or nil when the user
+// is acting for themselves.
+func ImpersonatorFromContext(c echo.Context) *models.User {
+	if c.Get(ContextImpersonatorKey) == nil {
+		return nil
+	}
+	return c.Get(ContextImpersonatorKey).(*models.User)
+}
+
 type SubscriptionCtx struct {
 	// Subscription *models.Subscription
 	// Product      *models.Product
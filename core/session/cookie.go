@@ -39,3 +39,15 @@ func ClearCookies(c echo.Context, cookieNames ...string) {
 		})
 	}
 }
+
+// DefaultAccountCookieName is used when Auth.AccountCookieName is not configured.
+const DefaultAccountCookieName = "account"
+
+// AccountCookieName returns the name of the cookie holding the account token, which
+// selects the team the user is acting for.
+func AccountCookieName(cfg *config.Config) string {
+	if cfg.Auth.AccountCookieName != "" {
+		return cfg.Auth.AccountCookieName
+	}
+	return DefaultAccountCookieName
+}
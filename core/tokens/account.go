@@ -1,10 +1,9 @@
 package tokens
 
 import (
-	"time"
-
 	"github.com/solotoabillion/stab/config"
-	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/core/keyring"
 	"github.com/solotoabillion/stab/core/session"
 	"github.com/solotoabillion/stab/models"
 
@@ -12,27 +11,24 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func SetAccountToken(c echo.Context, cfg *config.Config, membership *models.Membership) error {
-	token, err := newAccountToken(cfg, membership)
+func SetAccountToken(c echo.Context, cfg *config.Config, keys *keyring.Ring, membership *models.Membership) error {
+	token, err := newAccountToken(cfg, keys, membership)
 	if err != nil {
 		return err
 	}
 
-	session.SetCookie(cfg, c, token, cfg.Auth.AccountCookieName)
+	session.SetCookie(cfg, c, token, session.AccountCookieName(cfg))
 	return nil
 }
 
 // newAccountToken generates and returns a new auth record authentication token.
-func newAccountToken(cfg *config.Config, membership *models.Membership) (string, error) {
-	duration := time.Duration(cfg.Auth.AccessExpire) * time.Second
-	expirationTime := time.Now().Add(duration)
-
-	return security.NewJWT(
+func newAccountToken(cfg *config.Config, keys *keyring.Ring, membership *models.Membership) (string, error) {
+	return keys.Sign(
 		jwt.MapClaims{
+			"typ":    authsession.TypeAccount,
 			"id":     membership.TeamID,
 			"userID": membership.UserID,
 		},
-		(cfg.Auth.AccessSecret),
-		expirationTime.Unix(),
+		cfg.Auth.AccessExpire,
 	)
 }
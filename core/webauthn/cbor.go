@@ -0,0 +1,145 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// decodeCBOR decodes a single CBOR data item and returns it together with the remaining bytes.
+//
+// Only the subset of CBOR used by WebAuthn is supported: integers, byte and text strings,
+// arrays, maps, tags (ignored) and the simple values false, true and null.
+// Maps decode to map[any]any with int64 or string keys. Indefinite lengths are rejected.
+func decodeCBOR(data []byte) (any, []byte, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (any, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, errors.New("cbor: nesting too deep")
+	}
+	if len(data) == 0 {
+		return nil, nil, errCBORTruncated
+	}
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22:
+			return nil, data, nil
+		default:
+			return nil, nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+		}
+	}
+
+	arg, data, err := readCBORArgument(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0: // unsigned integer
+		if arg > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return int64(arg), data, nil
+	case 1: // negative integer
+		if arg > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(arg), data, nil
+	case 2, 3: // byte string, text string
+		if uint64(len(data)) < arg {
+			return nil, nil, errCBORTruncated
+		}
+		raw := data[:arg]
+		if major == 3 {
+			return string(raw), data[arg:], nil
+		}
+		b := make([]byte, len(raw))
+		copy(b, raw)
+		return b, data[arg:], nil
+	case 4: // array
+		if uint64(len(data)) < arg { // every item needs at least one byte
+			return nil, nil, errCBORTruncated
+		}
+		items := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item any
+			item, data, err = decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+	case 5: // map
+		if uint64(len(data)) < arg*2 {
+			return nil, nil, errCBORTruncated
+		}
+		m := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			var key, value any
+			key, data, err = decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+			}
+			value, data, err = decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, data, nil
+	case 6: // tag, the tagged value is returned as is
+		return decodeCBORItem(data, depth+1)
+	}
+	return nil, nil, fmt.Errorf("cbor: unsupported major type %d", major)
+}
+
+// readCBORArgument reads the argument encoded by the additional information bits.
+func readCBORArgument(info byte, data []byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, nil, errCBORTruncated
+		}
+		return binary.BigEndian.Uint64(data), data[8:], nil
+	default:
+		return 0, nil, errors.New("cbor: indefinite length items are not supported")
+	}
+}
@@ -0,0 +1,118 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers supported for passkeys.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// COSE key parameters (RFC 9053).
+const (
+	coseKeyType   int64 = 1
+	coseAlgorithm int64 = 3
+	coseCurve     int64 = -1 // EC2/OKP: crv, RSA: n
+	coseX         int64 = -2 // EC2/OKP: x, RSA: e
+	coseY         int64 = -3 // EC2: y
+
+	coseKeyTypeOKP int64 = 1
+	coseKeyTypeEC2 int64 = 2
+	coseKeyTypeRSA int64 = 3
+
+	coseCurveP256    int64 = 1
+	coseCurveEd25519 int64 = 6
+)
+
+// ErrInvalidSignature is returned when an assertion signature does not verify.
+var ErrInvalidSignature = errors.New("webauthn: invalid signature")
+
+// parsePublicKey decodes a CBOR encoded COSE_Key into a Go public key.
+func parsePublicKey(coseKey []byte) (crypto.PublicKey, int64, error) {
+	decoded, rest, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, 0, fmt.Errorf("webauthn: invalid public key: %w", err)
+	}
+	if len(rest) != 0 {
+		return nil, 0, errors.New("webauthn: trailing data after public key")
+	}
+	m, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, 0, errors.New("webauthn: public key is not a map")
+	}
+	kty, _ := m[coseKeyType].(int64)
+	alg, _ := m[coseAlgorithm].(int64)
+
+	switch {
+	case kty == coseKeyTypeEC2 && alg == AlgES256:
+		crv, _ := m[coseCurve].(int64)
+		x, _ := m[coseX].([]byte)
+		y, _ := m[coseY].([]byte)
+		if crv != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("webauthn: invalid P-256 key")
+		}
+		// Let crypto/ecdh reject points that are not on the curve
+		uncompressed := append(append([]byte{0x04}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(uncompressed); err != nil {
+			return nil, 0, fmt.Errorf("webauthn: invalid P-256 point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, alg, nil
+	case kty == coseKeyTypeOKP && alg == AlgEdDSA:
+		crv, _ := m[coseCurve].(int64)
+		x, _ := m[coseX].([]byte)
+		if crv != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("webauthn: invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), alg, nil
+	case kty == coseKeyTypeRSA && alg == AlgRS256:
+		n, _ := m[coseCurve].([]byte)
+		e, _ := m[coseX].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, errors.New("webauthn: invalid RSA key")
+		}
+		exponent := 0
+		for _, b := range e {
+			exponent = exponent<<8 | int(b)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, alg, nil
+	}
+	return nil, 0, fmt.Errorf("webauthn: unsupported key type %d / algorithm %d", kty, alg)
+}
+
+// verifySignature checks sig over data with the given COSE public key.
+func verifySignature(coseKey, data, sig []byte) error {
+	pub, _, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(data)
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], sig) {
+			return ErrInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, sig) {
+			return ErrInvalidSignature
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+			return ErrInvalidSignature
+		}
+	default:
+		return errors.New("webauthn: unsupported public key")
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package webauthn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Ceremony purposes.
+const (
+	PurposeRegister = "register"
+	PurposeLogin    = "login"
+)
+
+// ErrSessionNotFound is returned when a ceremony is unknown, expired, already used or of another purpose.
+var ErrSessionNotFound = errors.New("webauthn: session not found or expired")
+
+// Session is the server-side state of a registration or login ceremony.
+type Session struct {
+	UserID    uuid.UUID `json:"userId"` // uuid.Nil for discoverable login
+	Purpose   string    `json:"purpose"`
+	Challenge string    `json:"challenge"`
+}
+
+// SessionStore keeps ceremony challenges server-side so each one can be used exactly once.
+type SessionStore interface {
+	Save(ctx context.Context, s Session) (string, error)
+	Take(ctx context.Context, id, purpose string) (*Session, error)
+}
+
+// NewSessionStore returns a Redis-backed store when a Redis client is available,
+// falling back to the web_authn_sessions table otherwise.
+func NewSessionStore(redisClient *redis.Client, db *gorm.DB) SessionStore {
+	if redisClient != nil {
+		return &redisSessionStore{client: redisClient}
+	}
+	return &dbSessionStore{db: db}
+}
+
+type redisSessionStore struct {
+	client *redis.Client
+}
+
+func sessionKey(id string) string {
+	return "webauthn_session:" + id
+}
+
+func (s *redisSessionStore) Save(ctx context.Context, sess Session) (string, error) {
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return "", err
+	}
+	id := uuid.New().String()
+	if err := s.client.Set(ctx, sessionKey(id), data, ChallengeTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to store webauthn session: %w", err)
+	}
+	return id, nil
+}
+
+func (s *redisSessionStore) Take(ctx context.Context, id, purpose string) (*Session, error) {
+	data, err := s.client.GetDel(ctx, sessionKey(id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to load webauthn session: %w", err)
+	}
+	var sess Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("failed to decode webauthn session: %w", err)
+	}
+	if sess.Purpose != purpose {
+		return nil, ErrSessionNotFound
+	}
+	return &sess, nil
+}
+
+type dbSessionStore struct {
+	db *gorm.DB
+}
+
+func (s *dbSessionStore) Save(ctx context.Context, sess Session) (string, error) {
+	record := &models.WebAuthnSession{
+		Purpose:   sess.Purpose,
+		Challenge: sess.Challenge,
+		ExpiresAt: time.Now().Add(ChallengeTTL),
+	}
+	if sess.UserID != uuid.Nil {
+		record.UserID = &sess.UserID
+	}
+	if err := models.CreateWebAuthnSession(s.db.WithContext(ctx), record); err != nil {
+		return "", fmt.Errorf("failed to store webauthn session: %w", err)
+	}
+	return record.ID.String(), nil
+}
+
+func (s *dbSessionStore) Take(ctx context.Context, id, purpose string) (*Session, error) {
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	record, err := models.TakeWebAuthnSession(s.db.WithContext(ctx), sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to load webauthn session: %w", err)
+	}
+	if record.Purpose != purpose {
+		return nil, ErrSessionNotFound
+	}
+	sess := &Session{Purpose: record.Purpose, Challenge: record.Challenge}
+	if record.UserID != nil {
+		sess.UserID = *record.UserID
+	}
+	return sess, nil
+}
@@ -0,0 +1,333 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+)
+
+const (
+	// ChallengeTTL bounds how long a registration or login ceremony may take.
+	ChallengeTTL = 5 * time.Minute
+
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// Authenticator data flags (WebAuthn §6.1).
+const (
+	flagUserPresent    byte = 0x01
+	flagUserVerified   byte = 0x04
+	flagBackupEligible byte = 0x08
+	flagBackupState    byte = 0x10
+	flagAttestedData   byte = 0x40
+	flagExtensionData  byte = 0x80
+)
+
+var (
+	// ErrSignCountRegression is returned when the authenticator's signature counter did not increase,
+	// which indicates a cloned authenticator.
+	ErrSignCountRegression = errors.New("webauthn: signature counter did not increase")
+
+	// Encoding is the base64url encoding (no padding) used for all binary values exchanged with the browser.
+	Encoding = base64.RawURLEncoding
+)
+
+// Config identifies the relying party.
+type Config struct {
+	RPID    string   // Effective domain, e.g. "example.com"
+	RPName  string   // Human readable name shown by the authenticator
+	Origins []string // Allowed origins, e.g. "https://app.example.com"
+}
+
+// ConfigFromApp derives the relying party configuration from the application config.
+// RPID and origins default to the frontend URL.
+func ConfigFromApp(cfg *config.Config) Config {
+	frontendURL := cfg.FrontendURL
+	if frontendURL == "" {
+		frontendURL = "http://localhost:5173" // Default for local dev
+	}
+	rp := Config{
+		RPID:    cfg.Auth.WebAuthnRPID,
+		RPName:  cfg.Email.CompanyInfo.Name,
+		Origins: cfg.Auth.WebAuthnOrigins,
+	}
+	if rp.RPID == "" {
+		if u, err := url.Parse(frontendURL); err == nil {
+			rp.RPID = u.Hostname()
+		}
+	}
+	if rp.RPName == "" {
+		rp.RPName = rp.RPID
+	}
+	if len(rp.Origins) == 0 {
+		rp.Origins = []string{frontendURL}
+	}
+	return rp
+}
+
+// DecodeString decodes a base64url value sent by the browser, tolerating padding.
+func DecodeString(s string) ([]byte, error) {
+	return Encoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// NewChallenge returns a random base64url encoded challenge.
+func NewChallenge() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return Encoding.EncodeToString(b), nil
+}
+
+// CredentialDescriptor references an existing credential in creation and request options.
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// NewCredentialDescriptor builds a descriptor for a stored credential ID.
+func NewCredentialDescriptor(id []byte, transports []string) CredentialDescriptor {
+	return CredentialDescriptor{Type: "public-key", ID: Encoding.EncodeToString(id), Transports: transports}
+}
+
+// CreationOptions mirrors PublicKeyCredentialCreationOptions for navigator.credentials.create().
+type CreationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams []struct {
+		Type string `json:"type"`
+		Alg  int64  `json:"alg"`
+	} `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection struct {
+		ResidentKey      string `json:"residentKey"`
+		UserVerification string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+// NewCreationOptions builds registration options for a user.
+// The user handle must be stable and must not contain personal information (the user ID is used).
+func (rp Config) NewCreationOptions(challenge string, userHandle []byte, name, displayName string, exclude []CredentialDescriptor) CreationOptions {
+	var opts CreationOptions
+	opts.Challenge = challenge
+	opts.RP.ID = rp.RPID
+	opts.RP.Name = rp.RPName
+	opts.User.ID = Encoding.EncodeToString(userHandle)
+	opts.User.Name = name
+	opts.User.DisplayName = displayName
+	for _, alg := range []int64{AlgES256, AlgEdDSA, AlgRS256} {
+		opts.PubKeyCredParams = append(opts.PubKeyCredParams, struct {
+			Type string `json:"type"`
+			Alg  int64  `json:"alg"`
+		}{Type: "public-key", Alg: alg})
+	}
+	opts.Timeout = ChallengeTTL.Milliseconds()
+	opts.ExcludeCredentials = exclude
+	opts.AuthenticatorSelection.ResidentKey = "preferred"
+	opts.AuthenticatorSelection.UserVerification = "preferred"
+	opts.Attestation = "none"
+	return opts
+}
+
+// RequestOptions mirrors PublicKeyCredentialRequestOptions for navigator.credentials.get().
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// NewRequestOptions builds login options. An empty allow list lets the browser offer discoverable credentials.
+func (rp Config) NewRequestOptions(challenge string, allow []CredentialDescriptor) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          ChallengeTTL.Milliseconds(),
+		RPID:             rp.RPID,
+		AllowCredentials: allow,
+		UserVerification: "preferred",
+	}
+}
+
+// AuthenticatorData is the parsed authenticator data structure.
+type AuthenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte // CBOR encoded COSE_Key, only present during registration
+}
+
+// UserVerified reports whether the authenticator verified the user (PIN, biometrics).
+func (ad *AuthenticatorData) UserVerified() bool { return ad.Flags&flagUserVerified != 0 }
+
+// BackupEligible reports whether the credential can be synced (a multi-device passkey).
+func (ad *AuthenticatorData) BackupEligible() bool { return ad.Flags&flagBackupEligible != 0 }
+
+// BackedUp reports whether the credential is currently backed up.
+func (ad *AuthenticatorData) BackedUp() bool { return ad.Flags&flagBackupState != 0 }
+
+func parseAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("webauthn: authenticator data too short")
+	}
+	ad := &AuthenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+	if ad.Flags&flagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, errors.New("webauthn: attested credential data too short")
+		}
+		ad.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return nil, errors.New("webauthn: credential ID truncated")
+		}
+		ad.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("webauthn: invalid credential public key: %w", err)
+		}
+		ad.PublicKey = rest[:len(rest)-len(after)]
+		rest = after
+	}
+	if ad.Flags&flagExtensionData != 0 {
+		var err error
+		if _, rest, err = decodeCBOR(rest); err != nil {
+			return nil, fmt.Errorf("webauthn: invalid extension data: %w", err)
+		}
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("webauthn: trailing data after authenticator data")
+	}
+	return ad, nil
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (rp Config) verifyClientData(raw []byte, ceremony, challenge string) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("webauthn: invalid client data: %w", err)
+	}
+	if cd.Type != ceremony {
+		return fmt.Errorf("webauthn: unexpected ceremony type %q", cd.Type)
+	}
+	if subtle.ConstantTimeCompare([]byte(cd.Challenge), []byte(challenge)) != 1 {
+		return errors.New("webauthn: challenge mismatch")
+	}
+	if !slices.Contains(rp.Origins, cd.Origin) {
+		return fmt.Errorf("webauthn: origin %q not allowed", cd.Origin)
+	}
+	return nil
+}
+
+func (rp Config) verifyAuthenticatorData(ad *AuthenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.RPID))
+	if !bytes.Equal(ad.RPIDHash, rpIDHash[:]) {
+		return errors.New("webauthn: RP ID hash mismatch")
+	}
+	if ad.Flags&flagUserPresent == 0 {
+		return errors.New("webauthn: user not present")
+	}
+	return nil
+}
+
+// VerifyRegistration validates the response of navigator.credentials.create() and returns the new credential.
+//
+// Attestation statements are not verified: options request "none" conveyance, so the
+// credential is trusted on first use like any other passkey.
+func (rp Config) VerifyRegistration(challenge string, clientDataJSON, attestationObject []byte) (*AuthenticatorData, error) {
+	if err := rp.verifyClientData(clientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: invalid attestation object: %w", err)
+	}
+	obj, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, errors.New("webauthn: attestation object is not a map")
+	}
+	authData, ok := obj["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("webauthn: attestation object has no authData")
+	}
+
+	ad, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(ad); err != nil {
+		return nil, err
+	}
+	if ad.Flags&flagAttestedData == 0 || len(ad.CredentialID) == 0 {
+		return nil, errors.New("webauthn: no attested credential data")
+	}
+	if _, _, err := parsePublicKey(ad.PublicKey); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// VerifyAssertion validates the response of navigator.credentials.get() against a stored credential.
+// The returned authenticator data carries the new signature counter to persist.
+func (rp Config) VerifyAssertion(challenge string, publicKey []byte, storedSignCount uint32, clientDataJSON, authenticatorData, signature []byte) (*AuthenticatorData, error) {
+	if err := rp.verifyClientData(clientDataJSON, ceremonyGet, challenge); err != nil {
+		return nil, err
+	}
+	ad, err := parseAuthenticatorData(authenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(ad); err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authenticatorData...), clientDataHash[:]...)
+	if err := verifySignature(publicKey, signed, signature); err != nil {
+		return nil, err
+	}
+
+	// Authenticators that do not implement counters always report zero
+	if (ad.SignCount != 0 || storedSignCount != 0) && ad.SignCount <= storedSignCount {
+		return nil, ErrSignCountRegression
+	}
+	return ad, nil
+}
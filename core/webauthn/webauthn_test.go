@@ -0,0 +1,216 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// encodeCBOR is a minimal CBOR encoder for building authenticator responses in tests.
+func encodeCBOR(v any) []byte {
+	header := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 1<<8:
+			return []byte{major<<5 | 24, byte(n)}
+		case n < 1<<16:
+			b := []byte{major<<5 | 25, 0, 0}
+			binary.BigEndian.PutUint16(b[1:], uint16(n))
+			return b
+		default:
+			b := []byte{major<<5 | 26, 0, 0, 0, 0}
+			binary.BigEndian.PutUint32(b[1:], uint32(n))
+			return b
+		}
+	}
+	switch val := v.(type) {
+	case int:
+		if val < 0 {
+			return header(1, uint64(-1-val))
+		}
+		return header(0, uint64(val))
+	case []byte:
+		return append(header(2, uint64(len(val))), val...)
+	case string:
+		return append(header(3, uint64(len(val))), val...)
+	case map[any]any:
+		out := header(5, uint64(len(val)))
+		for k, item := range val {
+			out = append(out, encodeCBOR(k)...)
+			out = append(out, encodeCBOR(item)...)
+		}
+		return out
+	}
+	panic("unsupported type")
+}
+
+// softAuthenticator emulates a platform authenticator holding a single credential.
+type softAuthenticator struct {
+	rpID         string
+	origin       string
+	credentialID []byte
+	signer       crypto.Signer
+	coseKey      []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T, rpID, origin string, alg int64) *softAuthenticator {
+	t.Helper()
+	a := &softAuthenticator{rpID: rpID, origin: origin, credentialID: make([]byte, 16)}
+	rand.Read(a.credentialID)
+	switch alg {
+	case AlgES256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.signer = key
+		x, y := make([]byte, 32), make([]byte, 32)
+		key.X.FillBytes(x)
+		key.Y.FillBytes(y)
+		a.coseKey = encodeCBOR(map[any]any{1: 2, 3: -7, -1: 1, -2: x, -3: y})
+	case AlgEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.signer = priv
+		a.coseKey = encodeCBOR(map[any]any{1: 1, 3: -8, -1: 6, -2: []byte(pub)})
+	}
+	return a
+}
+
+func (a *softAuthenticator) clientData(ceremony, challenge string) []byte {
+	data, _ := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": a.origin})
+	return data
+}
+
+func (a *softAuthenticator) authData(flags byte, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	out := append([]byte{}, rpIDHash[:]...)
+	out = append(out, flags)
+	out = binary.BigEndian.AppendUint32(out, a.signCount)
+	if attested {
+		out = append(out, make([]byte, 16)...) // AAGUID
+		out = binary.BigEndian.AppendUint16(out, uint16(len(a.credentialID)))
+		out = append(out, a.credentialID...)
+		out = append(out, a.coseKey...)
+	}
+	return out
+}
+
+func (a *softAuthenticator) create(challenge string) (clientDataJSON, attestationObject []byte) {
+	clientDataJSON = a.clientData(ceremonyCreate, challenge)
+	attestationObject = encodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": a.authData(flagUserPresent|flagUserVerified|flagAttestedData, true),
+	})
+	return
+}
+
+func (a *softAuthenticator) get(t *testing.T, challenge string) (clientDataJSON, authenticatorData, signature []byte) {
+	t.Helper()
+	a.signCount++
+	clientDataJSON = a.clientData(ceremonyGet, challenge)
+	authenticatorData = a.authData(flagUserPresent|flagUserVerified, false)
+	hash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authenticatorData...), hash[:]...)
+	var err error
+	if _, ok := a.signer.(ed25519.PrivateKey); ok {
+		signature, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(signed)
+		signature, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	rp := Config{RPID: "example.com", RPName: "Example", Origins: []string{"https://app.example.com"}}
+
+	for name, alg := range map[string]int64{"ES256": AlgES256, "EdDSA": AlgEdDSA} {
+		t.Run(name, func(t *testing.T) {
+			auth := newSoftAuthenticator(t, rp.RPID, "https://app.example.com", alg)
+
+			challenge, _ := NewChallenge()
+			clientDataJSON, attestationObject := auth.create(challenge)
+			cred, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+			if err != nil {
+				t.Fatalf("registration failed: %v", err)
+			}
+			if string(cred.CredentialID) != string(auth.credentialID) {
+				t.Fatal("credential ID mismatch")
+			}
+
+			challenge, _ = NewChallenge()
+			clientDataJSON, authData, sig := auth.get(t, challenge)
+			ad, err := rp.VerifyAssertion(challenge, cred.PublicKey, cred.SignCount, clientDataJSON, authData, sig)
+			if err != nil {
+				t.Fatalf("assertion failed: %v", err)
+			}
+			if ad.SignCount != 1 || !ad.UserVerified() {
+				t.Errorf("unexpected authenticator data: count=%d uv=%v", ad.SignCount, ad.UserVerified())
+			}
+
+			// Replaying with a stale counter must be rejected
+			if _, err := rp.VerifyAssertion(challenge, cred.PublicKey, ad.SignCount, clientDataJSON, authData, sig); !errors.Is(err, ErrSignCountRegression) {
+				t.Errorf("expected sign count regression, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	rp := Config{RPID: "example.com", Origins: []string{"https://app.example.com"}}
+	auth := newSoftAuthenticator(t, rp.RPID, "https://app.example.com", AlgES256)
+	challenge, _ := NewChallenge()
+	clientDataJSON, attestationObject := auth.create(challenge)
+	cred, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+
+	if _, err := rp.VerifyRegistration("other-challenge", clientDataJSON, attestationObject); err == nil {
+		t.Error("expected challenge mismatch to fail")
+	}
+
+	evil := newSoftAuthenticator(t, rp.RPID, "https://evil.example.net", AlgES256)
+	evilClientData, evilAttestation := evil.create(challenge)
+	if _, err := rp.VerifyRegistration(challenge, evilClientData, evilAttestation); err == nil {
+		t.Error("expected foreign origin to fail")
+	}
+
+	wrongRP := newSoftAuthenticator(t, "evil.example.net", "https://app.example.com", AlgES256)
+	wrongClientData, wrongAttestation := wrongRP.create(challenge)
+	if _, err := rp.VerifyRegistration(challenge, wrongClientData, wrongAttestation); err == nil {
+		t.Error("expected RP ID mismatch to fail")
+	}
+
+	clientDataJSON, authData, sig := auth.get(t, challenge)
+	sig[len(sig)-1] ^= 0xff
+	if _, err := rp.VerifyAssertion(challenge, cred.PublicKey, 0, clientDataJSON, authData, sig); err == nil {
+		t.Error("expected tampered signature to fail")
+	}
+}
+
+func TestDecodeCBORRejectsTruncatedInput(t *testing.T) {
+	data := encodeCBOR(map[any]any{"authData": []byte{1, 2, 3, 4}})
+	if _, _, err := decodeCBOR(data[:len(data)-1]); err == nil {
+		t.Error("expected truncated input to fail")
+	}
+	if _, _, err := decodeCBOR([]byte{0x9f}); err == nil { // indefinite length array
+		t.Error("expected indefinite length to fail")
+	}
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	logicHandler "github.com/solotoabillion/stab/internal/logic/auth"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/templwind/soul/webserver/httpx"
+)
+
+// GetDownloadDataExportHandler serves the export archive as a file rather than JSON.
+func GetDownloadDataExportHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req types.DownloadDataExportRequest
+		if err := httpx.Parse(c, &req, path); err != nil {
+			c.Logger().Error(err)
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error": "Internal Server Error",
+				"msg":   err.Error(),
+			})
+		}
+		l := logicHandler.NewDownloadDataExportLogic(c.Request().Context(), svcCtx)
+		export, err := l.GetDownloadDataExport(c, &req)
+		if err != nil {
+			c.Logger().Error(err)
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error": "Internal Server Error",
+				"msg":   err.Error(),
+			})
+		}
+		name := fmt.Sprintf("data-export-%s.zip", export.CreatedAt.Format("2006-01-02"))
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+		c.Response().Header().Set("Cache-Control", "no-store")
+		return c.Blob(http.StatusOK, "application/zip", export.Archive)
+	}
+}
@@ -10,10 +10,10 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func GetGoogleCallbackHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+func GetOAuthProvidersHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		l := logicHandler.NewGoogleCallbackLogic(c.Request().Context(), svcCtx)
-		resp, err := l.GetGoogleCallback(c)
+		l := logicHandler.NewOAuthProvidersLogic(c.Request().Context(), svcCtx)
+		resp, err := l.GetOAuthProviders(c)
 		if err != nil {
 			c.Logger().Error(err)
 			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
@@ -10,10 +10,10 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func PostRegenerateApiKeyHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+func PostEnrollTwoFactorHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		l := logicHandler.NewRegenerateApiKeyLogic(c.Request().Context(), svcCtx)
-		resp, err := l.PostRegenerateApiKey(c)
+		l := logicHandler.NewEnrollTwoFactorLogic(c.Request().Context(), svcCtx)
+		resp, err := l.PostEnrollTwoFactor(c)
 		if err != nil {
 			c.Logger().Error(err)
 			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
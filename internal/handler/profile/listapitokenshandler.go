@@ -10,10 +10,10 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func PostRevokeApiKeyHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+func GetListAPITokensHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		l := logicHandler.NewRevokeApiKeyLogic(c.Request().Context(), svcCtx)
-		resp, err := l.PostRevokeApiKey(c)
+		l := logicHandler.NewListAPITokensLogic(c.Request().Context(), svcCtx)
+		resp, err := l.GetListAPITokens(c)
 		if err != nil {
 			c.Logger().Error(err)
 			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
@@ -10,10 +10,10 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func DeleteApiKeyHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+func GetListPasskeysHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		l := logicHandler.NewDeleteApiKeyLogic(c.Request().Context(), svcCtx)
-		resp, err := l.DeleteApiKey(c)
+		l := logicHandler.NewListPasskeysLogic(c.Request().Context(), svcCtx)
+		resp, err := l.GetListPasskeys(c)
 		if err != nil {
 			c.Logger().Error(err)
 			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
@@ -3,6 +3,8 @@ package handler
 
 import (
 	// Use correct paths relative to module root ('stab')
+	"github.com/solotoabillion/stab/core/apitoken"
+	"github.com/solotoabillion/stab/core/permissions"
 	admin "github.com/solotoabillion/stab/internal/handler/admin" // Keep internal handlers
 	adminsettings "github.com/solotoabillion/stab/internal/handler/admin/settings"
 	api "github.com/solotoabillion/stab/internal/handler/api"
@@ -15,30 +17,17 @@ import (
 	knowledgebaseadmin "github.com/solotoabillion/stab/internal/handler/knowledgebase/admin"
 	notifications "github.com/solotoabillion/stab/internal/handler/notifications"
 	profile "github.com/solotoabillion/stab/internal/handler/profile"
+	scim "github.com/solotoabillion/stab/internal/handler/scim"
 	teams "github.com/solotoabillion/stab/internal/handler/teams"
+	wellknown "github.com/solotoabillion/stab/internal/handler/wellknown"
 	// Removed imports for non-existent agentic_cs module
 	// support "github.com/solotoabillion/stab/internal/modules/agentic_cs/handler/support"
 	// supportadmin "github.com/solotoabillion/stab/internal/modules/agentic_cs/handler/support/admin"
 	"github.com/solotoabillion/stab/svc" // Use correct svc path
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 )
 
-type jwtCustomClaims struct {
-	Name  string `json:"name"`
-	Admin bool   `json:"admin"`
-	jwt.RegisteredClaims
-}
-
-func errorHandler(c echo.Context, err error) error {
-	return c.JSON(401, map[string]string{
-		"error":   "Unauthorized",
-		"message": "Please log in to access this resource.",
-	})
-}
-
 func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	////////////////////////////////////////////////////////////
 	// /api/knowledgebase routes
@@ -46,12 +35,8 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	knowledgebaseGroup := server.Group(
 		"/api/knowledgebase",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceKnowledgebase),
 		}...,
 	)
 	// knowledgebaseGroup.Use(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
@@ -68,13 +53,9 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	knowledgebaseAdminGroup := server.Group(
 		"/api/admin/knowledgebase",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
-			// svcCtx.AdminRequired, // Middleware applied by consumer
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceAdmin),
+			svcCtx.AdminRequiredMiddleware,
 		}...,
 	)
 	// knowledgebaseAdminGroup.Use(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
@@ -99,6 +80,15 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	healthGroup.GET("/readyz", health.GetReadinessCheckHandler(svcCtx, "/readyz"))
 	healthGroup.GET("/livez", health.GetLivenessCheckHandler(svcCtx, "/livez"))
 
+	////////////////////////////////////////////////////////////
+	// /.well-known routes
+	////////////////////////////////////////////////////////////
+	wellKnownGroup := server.Group(
+		"/.well-known",
+	)
+
+	wellKnownGroup.GET("/jwks.json", wellknown.GetJWKSHandler(svcCtx, "/jwks.json"))
+
 	////////////////////////////////////////////////////////////
 	// /api routes
 	////////////////////////////////////////////////////////////
@@ -129,10 +119,26 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	authGroup.POST("/login", auth.PostLoginUserHandler(svcCtx, "/login"))
 	authGroup.POST("/forgot-password", auth.PostForgotPasswordHandler(svcCtx, "/forgot-password"))
 	authGroup.POST("/reset-password", auth.PostResetPasswordHandler(svcCtx, "/reset-password"))
+	authGroup.POST("/verify-email", auth.PostVerifyEmailHandler(svcCtx, "/verify-email"))
+	authGroup.POST("/verify-email/resend", auth.PostResendVerificationHandler(svcCtx, "/verify-email/resend"))
+	authGroup.POST("/email-change/approve", auth.PostApproveEmailChangeHandler(svcCtx, "/email-change/approve"))
+	authGroup.POST("/email-change/confirm", auth.PostConfirmEmailChangeHandler(svcCtx, "/email-change/confirm"))
+	authGroup.POST("/email-change/revert", auth.PostRevertEmailChangeHandler(svcCtx, "/email-change/revert"))
 	authGroup.POST("/login/code", auth.PostRequestLoginCodeHandler(svcCtx, "/login/code"))
 	authGroup.POST("/login/verify", auth.PostVerifyLoginCodeHandler(svcCtx, "/login/verify"))
-	authGroup.GET("/login/google", auth.GetGoogleLoginHandler(svcCtx, "/login/google"))
-	authGroup.GET("/callback/google", auth.GetGoogleCallbackHandler(svcCtx, "/callback/google"))
+	authGroup.POST("/login/mfa", auth.PostVerifyMfaHandler(svcCtx, "/login/mfa"))
+	authGroup.POST("/login/passkey/options", auth.PostPasskeyLoginOptionsHandler(svcCtx, "/login/passkey/options"))
+	authGroup.POST("/login/passkey", auth.PostPasskeyLoginHandler(svcCtx, "/login/passkey"))
+	authGroup.POST("/refresh", auth.PostRefreshTokenHandler(svcCtx, "/refresh"))
+	authGroup.POST("/logout", auth.PostLogoutHandler(svcCtx, "/logout"))
+	authGroup.GET("/providers", auth.GetOAuthProvidersHandler(svcCtx, "/providers"))
+	authGroup.GET("/login/:provider", auth.GetOAuthLoginHandler(svcCtx, "/login/:provider"))
+	authGroup.GET("/callback/:provider", auth.GetOAuthCallbackHandler(svcCtx, "/callback/:provider"))
+	authGroup.POST("/callback/:provider", auth.GetOAuthCallbackHandler(svcCtx, "/callback/:provider"))
+	authGroup.POST("/sso", auth.PostSSOLoginHandler(svcCtx, "/sso"))
+	authGroup.GET("/sso/callback", auth.GetSSOCallbackHandler(svcCtx, "/sso/callback"))
+	authGroup.POST("/sso/callback", auth.GetSSOCallbackHandler(svcCtx, "/sso/callback"))
+	authGroup.GET("/data-export/:exportId", auth.GetDownloadDataExportHandler(svcCtx, "/data-export/:exportId"))
 	// authGroup.Any("/*", fallbackHandler)
 
 	////////////////////////////////////////////////////////////
@@ -157,15 +163,11 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	profileGroup := server.Group(
 		"/api/profile",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
-			svcCtx.UserGuardMiddleware,
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceProfile),
 			svcCtx.NoCacheMiddleware,
 			svcCtx.AuthGuardMiddleware,
+			svcCtx.AccountMiddleware,
 		}...,
 	)
 	// profileGroup.Use(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
@@ -173,13 +175,33 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	// }))
 
 	profileGroup.GET("", profile.GetProfileHandler(svcCtx, ""))
-	profileGroup.POST("/regenerate-apikey", profile.PostRegenerateApiKeyHandler(svcCtx, "/regenerate-apikey"))
-	profileGroup.POST("/revoke-apikey", profile.PostRevokeApiKeyHandler(svcCtx, "/revoke-apikey"))
-	profileGroup.DELETE("/apikey", profile.DeleteApiKeyHandler(svcCtx, "/apikey"))
-	profileGroup.POST("/change-email", profile.PostChangeEmailHandler(svcCtx, "/change-email"))
+	profileGroup.GET("/tokens", profile.GetListAPITokensHandler(svcCtx, "/tokens"))
+	profileGroup.POST("/tokens", profile.PostCreateAPITokenHandler(svcCtx, "/tokens"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.DELETE("/tokens/:tokenId", profile.DeleteRevokeAPITokenHandler(svcCtx, "/tokens/:tokenId"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.POST("/change-email", profile.PostChangeEmailHandler(svcCtx, "/change-email"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.POST("/password", profile.PostChangePasswordHandler(svcCtx, "/password"), svcCtx.NoImpersonationMiddleware)
 	profileGroup.GET("/preferences/email", profile.GetEmailPreferencesHandler(svcCtx, "/preferences/email"))
 	profileGroup.PUT("/preferences/email", profile.PutUpdateEmailPreferencesHandler(svcCtx, "/preferences/email"))
 	profileGroup.GET("/settings/security", profile.GetSecuritySettingsHandler(svcCtx, "/settings/security"))
+	profileGroup.POST("/2fa/enroll", profile.PostEnrollTwoFactorHandler(svcCtx, "/2fa/enroll"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.POST("/2fa/confirm", profile.PostConfirmTwoFactorHandler(svcCtx, "/2fa/confirm"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.POST("/2fa/disable", profile.PostDisableTwoFactorHandler(svcCtx, "/2fa/disable"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.POST("/2fa/recovery-codes", profile.PostRegenerateRecoveryCodesHandler(svcCtx, "/2fa/recovery-codes"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.GET("/passkeys", profile.GetListPasskeysHandler(svcCtx, "/passkeys"))
+	profileGroup.POST("/passkeys/options", profile.PostPasskeyRegisterOptionsHandler(svcCtx, "/passkeys/options"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.POST("/passkeys", profile.PostRegisterPasskeyHandler(svcCtx, "/passkeys"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.PATCH("/passkeys/:passkeyId", profile.PatchRenamePasskeyHandler(svcCtx, "/passkeys/:passkeyId"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.DELETE("/passkeys/:passkeyId", profile.DeletePasskeyHandler(svcCtx, "/passkeys/:passkeyId"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.GET("/sessions", profile.GetListSessionsHandler(svcCtx, "/sessions"))
+	profileGroup.POST("/sessions/revoke-all", profile.PostRevokeAllSessionsHandler(svcCtx, "/sessions/revoke-all"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.DELETE("/sessions/:sessionId", profile.DeleteRevokeSessionHandler(svcCtx, "/sessions/:sessionId"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.GET("/identities", profile.GetListIdentitiesHandler(svcCtx, "/identities"))
+	profileGroup.POST("/identities/:provider", profile.PostLinkIdentityHandler(svcCtx, "/identities/:provider"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.DELETE("/identities/:identityId", profile.DeleteIdentityHandler(svcCtx, "/identities/:identityId"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.GET("/export", profile.GetDataExportHandler(svcCtx, "/export"))
+	profileGroup.POST("/export", profile.PostRequestDataExportHandler(svcCtx, "/export"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.POST("/delete", profile.PostDeleteAccountHandler(svcCtx, "/delete"), svcCtx.NoImpersonationMiddleware)
+	profileGroup.DELETE("/delete", profile.DeleteCancelDeleteAccountHandler(svcCtx, "/delete"), svcCtx.NoImpersonationMiddleware)
 	// profileGroup.Any("/*", fallbackHandler)
 
 	////////////////////////////////////////////////////////////
@@ -188,45 +210,49 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	billingGroup := server.Group(
 		"/api/billing",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
-			svcCtx.UserGuardMiddleware,
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceBilling),
 			svcCtx.NoCacheMiddleware,
 			svcCtx.AuthGuardMiddleware,
+			svcCtx.VerifiedEmailMiddleware,
+			svcCtx.ImpersonationReadOnlyMiddleware,
+			svcCtx.AccountMiddleware,
 		}...,
 	)
 	// billingGroup.Use(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
 	// 	RedirectCode: http.StatusMovedPermanently,
 	// }))
 
-	billingGroup.POST("/checkout-session", billing.PostCreateCheckoutSessionHandler(svcCtx, "/checkout-session"))
-	billingGroup.POST("/portal-session", billing.PostCreatePortalSessionHandler(svcCtx, "/portal-session"))
-	billingGroup.GET("/subscription", billing.GetSubscriptionHandler(svcCtx, "/subscription"))
-	billingGroup.GET("/invoices", billing.GetInvoicesHandler(svcCtx, "/invoices"))
-	billingGroup.POST("/add-ons/:addonId", billing.PostAddAddonHandler(svcCtx, "/add-ons/:addonId"))
-	billingGroup.DELETE("/add-ons/:addonId", billing.DeleteRemoveAddonHandler(svcCtx, "/add-ons/:addonId"))
-	billingGroup.POST("/webhook", billing.PostStripeWebhookHandler(svcCtx, "/webhook"))
+	billingGroup.POST("/checkout-session", billing.PostCreateCheckoutSessionHandler(svcCtx, "/checkout-session"), svcCtx.RequirePermission(permissions.BillingManage))
+	billingGroup.POST("/portal-session", billing.PostCreatePortalSessionHandler(svcCtx, "/portal-session"), svcCtx.RequirePermission(permissions.BillingManage))
+	billingGroup.GET("/subscription", billing.GetSubscriptionHandler(svcCtx, "/subscription"), svcCtx.RequirePermission(permissions.BillingRead))
+	billingGroup.GET("/invoices", billing.GetInvoicesHandler(svcCtx, "/invoices"), svcCtx.RequirePermission(permissions.BillingRead))
+	billingGroup.POST("/add-ons/:addonId", billing.PostAddAddonHandler(svcCtx, "/add-ons/:addonId"), svcCtx.RequirePermission(permissions.BillingManage))
+	billingGroup.DELETE("/add-ons/:addonId", billing.DeleteRemoveAddonHandler(svcCtx, "/add-ons/:addonId"), svcCtx.RequirePermission(permissions.BillingManage))
 	// billingGroup.Any("/*", fallbackHandler)
 
+	////////////////////////////////////////////////////////////
+	// /api/billing/webhook route
+	////////////////////////////////////////////////////////////
+	// Stripe calls without credentials; the handler verifies the Stripe-Signature header
+	billingWebhookGroup := server.Group(
+		"/api/billing",
+	)
+
+	billingWebhookGroup.POST("/webhook", billing.PostStripeWebhookHandler(svcCtx, "/webhook"))
+
 	////////////////////////////////////////////////////////////
 	// /api/teams routes
 	////////////////////////////////////////////////////////////
 	teamsGroup := server.Group(
 		"/api/teams",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
-			svcCtx.UserGuardMiddleware,
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceTeams),
 			svcCtx.NoCacheMiddleware,
 			svcCtx.AuthGuardMiddleware,
+			svcCtx.VerifiedEmailMiddleware,
+			svcCtx.AccountMiddleware,
 		}...,
 	)
 	// teamsGroup.Use(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
@@ -235,33 +261,83 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 
 	teamsGroup.POST("", teams.PostCreateTeamHandler(svcCtx, ""))
 	teamsGroup.GET("", teams.GetListTeamsHandler(svcCtx, ""))
-	teamsGroup.GET("/:teamId", teams.GetTeamDetailsHandler(svcCtx, "/:teamId"))
-	teamsGroup.GET("/:teamId/members", teams.GetListMembersHandler(svcCtx, "/:teamId/members"))
-	teamsGroup.DELETE("/:teamId/members/:memberId", teams.DeleteRemoveMemberHandler(svcCtx, "/:teamId/members/:memberId"))
-	teamsGroup.PATCH("/:teamId/members/:memberId/role", teams.PatchUpdateMemberRoleHandler(svcCtx, "/:teamId/members/:memberId/role"))
-	teamsGroup.POST("/:teamId/invitations", teams.PostInviteMemberHandler(svcCtx, "/:teamId/invitations"))
-	teamsGroup.GET("/:teamId/invitations", teams.GetListInvitationsHandler(svcCtx, "/:teamId/invitations"))
-	teamsGroup.DELETE("/:teamId/invitations/:invitationId", teams.DeleteCancelInvitationHandler(svcCtx, "/:teamId/invitations/:invitationId"))
+	teamsGroup.GET("/joinable", teams.GetListJoinableTeamsHandler(svcCtx, "/joinable"))
+	teamsGroup.GET("/:teamId", teams.GetTeamDetailsHandler(svcCtx, "/:teamId"), svcCtx.RequirePermission(permissions.TeamRead))
+	teamsGroup.PATCH("/:teamId", teams.PatchRenameTeamHandler(svcCtx, "/:teamId"), svcCtx.RequirePermission(permissions.TeamUpdate))
+	teamsGroup.DELETE("/:teamId", teams.DeleteTeamHandler(svcCtx, "/:teamId"), svcCtx.NoImpersonationMiddleware, svcCtx.RequirePermission(permissions.TeamDelete))
+	teamsGroup.POST("/:teamId/transfer", teams.PostTransferTeamOwnershipHandler(svcCtx, "/:teamId/transfer"), svcCtx.NoImpersonationMiddleware, svcCtx.RequirePermission(permissions.TeamTransfer))
+	teamsGroup.POST("/:teamId/switch", teams.PostSwitchTeamHandler(svcCtx, "/:teamId/switch"))
+	teamsGroup.DELETE("/switch", teams.DeleteSwitchPersonalAccountHandler(svcCtx, "/switch"))
+	teamsGroup.POST("/:teamId/leave", teams.PostLeaveTeamHandler(svcCtx, "/:teamId/leave"))
+	teamsGroup.POST("/:teamId/join", teams.PostJoinTeamHandler(svcCtx, "/:teamId/join"))
+	teamsGroup.GET("/:teamId/members", teams.GetListMembersHandler(svcCtx, "/:teamId/members"), svcCtx.RequirePermission(permissions.MembersRead))
+	teamsGroup.DELETE("/:teamId/members/:memberId", teams.DeleteRemoveMemberHandler(svcCtx, "/:teamId/members/:memberId"), svcCtx.RequirePermission(permissions.MembersRemove))
+	teamsGroup.PATCH("/:teamId/members/:memberId/role", teams.PatchUpdateMemberRoleHandler(svcCtx, "/:teamId/members/:memberId/role"), svcCtx.RequirePermission(permissions.MembersUpdate))
+	teamsGroup.POST("/:teamId/invitations", teams.PostInviteMemberHandler(svcCtx, "/:teamId/invitations"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.GET("/:teamId/invitations", teams.GetListInvitationsHandler(svcCtx, "/:teamId/invitations"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.POST("/:teamId/invitations/:invitationId/resend", teams.PostResendInvitationHandler(svcCtx, "/:teamId/invitations/:invitationId/resend"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.DELETE("/:teamId/invitations/:invitationId", teams.DeleteCancelInvitationHandler(svcCtx, "/:teamId/invitations/:invitationId"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.POST("/:teamId/invitation-imports", teams.PostImportInvitationsHandler(svcCtx, "/:teamId/invitation-imports"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.GET("/:teamId/invitation-imports/:importId", teams.GetInvitationImportHandler(svcCtx, "/:teamId/invitation-imports/:importId"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.GET("/:teamId/join-requests", teams.GetListJoinRequestsHandler(svcCtx, "/:teamId/join-requests"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.POST("/:teamId/join-requests/:requestId/approve", teams.PostApproveJoinRequestHandler(svcCtx, "/:teamId/join-requests/:requestId/approve"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.POST("/:teamId/join-requests/:requestId/reject", teams.PostRejectJoinRequestHandler(svcCtx, "/:teamId/join-requests/:requestId/reject"), svcCtx.RequirePermission(permissions.MembersInvite))
+	teamsGroup.GET("/:teamId/roles", teams.GetListTeamRolesHandler(svcCtx, "/:teamId/roles"), svcCtx.RequirePermission(permissions.MembersRead))
+	teamsGroup.POST("/:teamId/roles", teams.PostCreateTeamRoleHandler(svcCtx, "/:teamId/roles"), svcCtx.RequirePermission(permissions.RolesManage))
+	teamsGroup.PUT("/:teamId/roles/:roleId", teams.PutUpdateTeamRoleHandler(svcCtx, "/:teamId/roles/:roleId"), svcCtx.RequirePermission(permissions.RolesManage))
+	teamsGroup.DELETE("/:teamId/roles/:roleId", teams.DeleteTeamRoleHandler(svcCtx, "/:teamId/roles/:roleId"), svcCtx.RequirePermission(permissions.RolesManage))
+	teamsGroup.GET("/:teamId/sso", teams.GetTeamSSOHandler(svcCtx, "/:teamId/sso"), svcCtx.RequirePermission(permissions.SSOManage))
+	teamsGroup.PUT("/:teamId/sso", teams.PutUpdateTeamSSOHandler(svcCtx, "/:teamId/sso"), svcCtx.NoImpersonationMiddleware, svcCtx.RequirePermission(permissions.SSOManage))
+	teamsGroup.GET("/:teamId/sso/domains", teams.GetListTeamDomainsHandler(svcCtx, "/:teamId/sso/domains"), svcCtx.RequirePermission(permissions.DomainsManage))
+	teamsGroup.POST("/:teamId/sso/domains", teams.PostAddTeamDomainHandler(svcCtx, "/:teamId/sso/domains"), svcCtx.RequirePermission(permissions.DomainsManage))
+	teamsGroup.POST("/:teamId/sso/domains/:domainId/verify", teams.PostVerifyTeamDomainHandler(svcCtx, "/:teamId/sso/domains/:domainId/verify"), svcCtx.RequirePermission(permissions.DomainsManage))
+	teamsGroup.DELETE("/:teamId/sso/domains/:domainId", teams.DeleteTeamDomainHandler(svcCtx, "/:teamId/sso/domains/:domainId"), svcCtx.RequirePermission(permissions.DomainsManage))
+	teamsGroup.PUT("/:teamId/sso/domains/:domainId/join-policy", teams.PutUpdateDomainJoinPolicyHandler(svcCtx, "/:teamId/sso/domains/:domainId/join-policy"), svcCtx.RequirePermission(permissions.DomainsManage))
+	teamsGroup.GET("/:teamId/scim/tokens", teams.GetListSCIMTokensHandler(svcCtx, "/:teamId/scim/tokens"), svcCtx.RequirePermission(permissions.SSOManage))
+	teamsGroup.POST("/:teamId/scim/tokens", teams.PostCreateSCIMTokenHandler(svcCtx, "/:teamId/scim/tokens"), svcCtx.NoImpersonationMiddleware, svcCtx.RequirePermission(permissions.SSOManage))
+	teamsGroup.DELETE("/:teamId/scim/tokens/:tokenId", teams.DeleteRevokeSCIMTokenHandler(svcCtx, "/:teamId/scim/tokens/:tokenId"), svcCtx.NoImpersonationMiddleware, svcCtx.RequirePermission(permissions.SSOManage))
+	teamsGroup.GET("/:teamId/tokens", teams.GetListTeamAPITokensHandler(svcCtx, "/:teamId/tokens"), svcCtx.RequirePermission(permissions.TokensManage))
+	teamsGroup.POST("/:teamId/tokens", teams.PostCreateTeamAPITokenHandler(svcCtx, "/:teamId/tokens"), svcCtx.NoImpersonationMiddleware, svcCtx.RequirePermission(permissions.TokensManage))
+	teamsGroup.DELETE("/:teamId/tokens/:tokenId", teams.DeleteRevokeTeamAPITokenHandler(svcCtx, "/:teamId/tokens/:tokenId"), svcCtx.NoImpersonationMiddleware, svcCtx.RequirePermission(permissions.TokensManage))
 	teamsGroup.GET("/invitations/:token", teams.GetInvitationDetailsHandler(svcCtx, "/invitations/:token"))
 	teamsGroup.POST("/invitations/:token/accept", teams.PostAcceptInvitationHandler(svcCtx, "/invitations/:token/accept"))
 	teamsGroup.POST("/invitations/:token/decline", teams.PostDeclineInvitationHandler(svcCtx, "/invitations/:token/decline"))
 	// teamsGroup.Any("/*", fallbackHandler)
 
+	////////////////////////////////////////////////////////////
+	// /api/scim/v2 routes
+	////////////////////////////////////////////////////////////
+	scimGroup := server.Group(
+		"/api/scim/v2",
+		[]echo.MiddlewareFunc{
+			svcCtx.NoCacheMiddleware,
+			svcCtx.SCIMAuthMiddleware,
+		}...,
+	)
+
+	scimGroup.GET("/ServiceProviderConfig", scim.GetServiceProviderConfigHandler(svcCtx, "/ServiceProviderConfig"))
+	scimGroup.GET("/Users", scim.GetListUsersHandler(svcCtx, "/Users"))
+	scimGroup.POST("/Users", scim.PostCreateUserHandler(svcCtx, "/Users"))
+	scimGroup.GET("/Users/:id", scim.GetUserHandler(svcCtx, "/Users/:id"))
+	scimGroup.PUT("/Users/:id", scim.PutReplaceUserHandler(svcCtx, "/Users/:id"))
+	scimGroup.PATCH("/Users/:id", scim.PatchUserHandler(svcCtx, "/Users/:id"))
+	scimGroup.DELETE("/Users/:id", scim.DeleteUserHandler(svcCtx, "/Users/:id"))
+	scimGroup.GET("/Groups", scim.GetListGroupsHandler(svcCtx, "/Groups"))
+	scimGroup.GET("/Groups/:id", scim.GetGroupHandler(svcCtx, "/Groups/:id"))
+	scimGroup.PUT("/Groups/:id", scim.PutReplaceGroupHandler(svcCtx, "/Groups/:id"))
+	scimGroup.PATCH("/Groups/:id", scim.PatchGroupHandler(svcCtx, "/Groups/:id"))
+
 	////////////////////////////////////////////////////////////
 	// /api/developer routes
 	////////////////////////////////////////////////////////////
 	developerGroup := server.Group(
 		"/api/developer",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
-			svcCtx.UserGuardMiddleware,
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceDeveloper),
 			svcCtx.NoCacheMiddleware,
 			svcCtx.AuthGuardMiddleware,
+			svcCtx.AccountMiddleware,
 		}...,
 	)
 	// developerGroup.Use(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
@@ -278,15 +354,11 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	notificationsGroup := server.Group(
 		"/api/notifications",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
-			svcCtx.UserGuardMiddleware,
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceNotifications),
 			svcCtx.NoCacheMiddleware,
 			svcCtx.AuthGuardMiddleware,
+			svcCtx.AccountMiddleware,
 		}...,
 	)
 	// notificationsGroup.Use(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
@@ -306,13 +378,8 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	adminGroup := server.Group(
 		"/api/admin",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
-			svcCtx.UserGuardMiddleware,
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceAdmin),
 			svcCtx.NoCacheMiddleware,
 			svcCtx.AuthGuardMiddleware,
 			svcCtx.AdminRequiredMiddleware,
@@ -326,6 +393,11 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	adminGroup.GET("/users/:userId", admin.GetUserDetailsAdminHandler(svcCtx, "/users/:userId"))
 	adminGroup.GET("/users/:userId/communications", admin.GetListUserCommunicationsHandler(svcCtx, "/users/:userId/communications"))
 	adminGroup.POST("/users/:userId/communications", admin.PostSendCommunicationHandler(svcCtx, "/users/:userId/communications"))
+	adminGroup.POST("/users/:userId/unlock", admin.PostUnlockUserHandler(svcCtx, "/users/:userId/unlock"))
+	adminGroup.POST("/users/:userId/impersonate", admin.PostImpersonateUserHandler(svcCtx, "/users/:userId/impersonate"))
+	adminGroup.GET("/users/:userId/impersonation-log", admin.GetListImpersonationLogHandler(svcCtx, "/users/:userId/impersonation-log"))
+	adminGroup.GET("/signing-keys", admin.GetListSigningKeysHandler(svcCtx, "/signing-keys"))
+	adminGroup.POST("/signing-keys/rotate", admin.PostRotateSigningKeysHandler(svcCtx, "/signing-keys/rotate"))
 	adminGroup.GET("/dashboard/metrics", admin.GetDashboardMetricsHandler(svcCtx, "/dashboard/metrics"))
 	// adminGroup.Any("/*", fallbackHandler)
 
@@ -335,13 +407,8 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	adminSettingsGroup := server.Group(
 		"/api/admin/settings",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:    []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:   "header:Authorization:Bearer,cookie:auth",
-				ErrorHandler: errorHandler,
-			}),
-			svcCtx.UserGuardMiddleware,
+			svcCtx.AuthMiddleware,
+			svcCtx.RequireScope(apitoken.ResourceAdmin),
 			svcCtx.NoCacheMiddleware,
 			svcCtx.AuthGuardMiddleware,
 			svcCtx.AdminRequiredMiddleware,
@@ -365,16 +432,7 @@ func RegisterHandlers(server *echo.Echo, svcCtx *svc.ServiceContext) {
 	////////////////////////////////////////////////////////////
 	server.Group("",
 		[]echo.MiddlewareFunc{
-			echojwt.WithConfig(echojwt.Config{
-				NewClaimsFunc:          func(c echo.Context) jwt.Claims { return new(jwtCustomClaims) },
-				SigningKey:             []byte(svcCtx.Config.Auth.AccessSecret),
-				TokenLookup:            "header:Authorization:Bearer,cookie:auth",
-				ContinueOnIgnoredError: true,
-				ErrorHandler: func(c echo.Context, err error) error {
-					// Simply continue to next middleware without error
-					return nil
-				},
-			}),
+			svcCtx.OptionalAuthMiddleware,
 			// svcCtx.CustomStatic, // Static file serving handled by consumer
 			// customStatic("../frontend/dist"),
 		}...,
@@ -0,0 +1,27 @@
+package scim
+
+import (
+	"net/http"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	logicHandler "github.com/solotoabillion/stab/internal/logic/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+)
+
+func PostCreateUserHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req scimcore.User
+		if err := readBody(c, &req); err != nil {
+			return writeError(c, err)
+		}
+		l := logicHandler.NewCreateUserLogic(c.Request().Context(), svcCtx)
+		resp, err := l.PostCreateUser(c, &req)
+		if err != nil {
+			return writeError(c, err)
+		}
+		c.Response().Header().Set(echo.HeaderLocation, resp.Meta.Location)
+		return writeResource(c, http.StatusCreated, resp)
+	}
+}
@@ -0,0 +1,20 @@
+package scim
+
+import (
+	"net/http"
+
+	logicHandler "github.com/solotoabillion/stab/internal/logic/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+)
+
+func DeleteUserHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		l := logicHandler.NewDeleteUserLogic(c.Request().Context(), svcCtx)
+		if err := l.DeleteUser(c, c.Param("id")); err != nil {
+			return writeError(c, err)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,21 @@
+package scim
+
+import (
+	"net/http"
+
+	logicHandler "github.com/solotoabillion/stab/internal/logic/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetUserHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		l := logicHandler.NewGetUserLogic(c.Request().Context(), svcCtx)
+		resp, err := l.GetUser(c, c.Param("id"))
+		if err != nil {
+			return writeError(c, err)
+		}
+		return writeResource(c, http.StatusOK, resp)
+	}
+}
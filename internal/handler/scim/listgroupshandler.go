@@ -0,0 +1,26 @@
+package scim
+
+import (
+	"net/http"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	logicHandler "github.com/solotoabillion/stab/internal/logic/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+)
+
+func GetListGroupsHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		query, err := scimcore.ParseListQuery(c.QueryParams())
+		if err != nil {
+			return writeError(c, err)
+		}
+		l := logicHandler.NewListGroupsLogic(c.Request().Context(), svcCtx)
+		resp, err := l.GetListGroups(c, query)
+		if err != nil {
+			return writeError(c, err)
+		}
+		return writeResource(c, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,26 @@
+package scim
+
+import (
+	"net/http"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	logicHandler "github.com/solotoabillion/stab/internal/logic/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+)
+
+func PatchUserHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req scimcore.PatchRequest
+		if err := readBody(c, &req); err != nil {
+			return writeError(c, err)
+		}
+		l := logicHandler.NewPatchUserLogic(c.Request().Context(), svcCtx)
+		resp, err := l.PatchUser(c, c.Param("id"), &req)
+		if err != nil {
+			return writeError(c, err)
+		}
+		return writeResource(c, http.StatusOK, resp)
+	}
+}
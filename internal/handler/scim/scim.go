@@ -0,0 +1,44 @@
+// Package scim serves the SCIM 2.0 endpoints. Unlike the generated handlers these
+// answer with SCIM status codes, error bodies and the application/scim+json type.
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+
+	"github.com/labstack/echo/v4"
+)
+
+// writeResource sends a SCIM response body.
+func writeResource(c echo.Context, status int, v interface{}) error {
+	c.Response().Header().Set(echo.HeaderContentType, scimcore.ContentType)
+	return c.JSON(status, v)
+}
+
+// writeError sends err as a SCIM error. Errors other than *scim.Error are logged and
+// reported as internal errors.
+func writeError(c echo.Context, err error) error {
+	var scimErr *scimcore.Error
+	if !errors.As(err, &scimErr) {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			scimErr = scimcore.NewError(httpErr.Code, "", fmt.Sprint(httpErr.Message))
+		} else {
+			c.Logger().Error(err)
+			scimErr = scimcore.NewError(http.StatusInternalServerError, "", "Internal server error")
+		}
+	}
+	return writeResource(c, scimErr.StatusCode(), scimErr)
+}
+
+// readBody decodes a JSON request body.
+func readBody(c echo.Context, v interface{}) error {
+	if err := json.NewDecoder(c.Request().Body).Decode(v); err != nil {
+		return scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeInvalidSyntax, "Invalid request body: "+err.Error())
+	}
+	return nil
+}
@@ -1,19 +1,19 @@
 // Code generated by soul. DO NOT EDIT.
-package auth
+package wellknown
 
 import (
 	"net/http"
 
-	logicHandler "github.com/solotoabillion/stab/internal/logic/auth"
+	logicHandler "github.com/solotoabillion/stab/internal/logic/wellknown"
 	"github.com/solotoabillion/stab/svc"
 
 	"github.com/labstack/echo/v4"
 )
 
-func GetGoogleLoginHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
+func GetJWKSHandler(svcCtx *svc.ServiceContext, path string) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		l := logicHandler.NewGoogleLoginLogic(c.Request().Context(), svcCtx)
-		resp, err := l.GetGoogleLogin(c)
+		l := logicHandler.NewGetJWKSLogic(c.Request().Context(), svcCtx)
+		resp, err := l.GetJWKS(c)
 		if err != nil {
 			c.Logger().Error(err)
 			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
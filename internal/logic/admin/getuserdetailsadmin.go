@@ -70,9 +70,9 @@ func (l *GetUserDetailsAdminLogic) GetUserDetailsAdmin(c echo.Context, req *type
 		Email:            user.Email,
 		ProfileData:      profileData, // Assign unmarshalled data
 		Role:             string(user.Role),
-		ApiKey:           user.ApiKey,
 		DefaultSubdomain: user.DefaultSubdomain,
 		AccountStatus:    string(user.AccountStatus),
+		EmailVerified:    user.EmailVerified(),
 		CreatedAt:        user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:        user.UpdatedAt.Format(time.RFC3339),
 	}
@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultImpersonationMinutes = 30
+	maxImpersonationMinutes     = 60
+)
+
+type ImpersonateUserLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewImpersonateUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImpersonateUserLogic {
+	return &ImpersonateUserLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostImpersonateUser issues a short-lived token with which the admin sees the app as the
+// user does. Sensitive actions are blocked and every request is written to the audit log.
+func (l *ImpersonateUserLogic) PostImpersonateUser(c echo.Context, req *types.ImpersonateUserRequest) (resp *types.ImpersonateUserResponse, err error) {
+	admin := session.UserFromContext(c)
+	if admin == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if session.APITokenFromContext(c) != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Impersonation can only be started from a signed-in session")
+	}
+
+	// 1. Validate the request
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID format")
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || len(reason) > 500 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "A reason of at most 500 characters is required")
+	}
+	minutes := req.Minutes
+	if minutes == 0 {
+		minutes = defaultImpersonationMinutes
+	}
+	if minutes < 1 || minutes > maxImpersonationMinutes {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Impersonation can last between 1 and 60 minutes")
+	}
+
+	// 2. Fetch the user
+	user, err := models.FindUserByID(l.svcCtx.DB, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		l.Errorf("Admin: Failed to retrieve user %s: %v", userID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
+	}
+	if user.ID == admin.ID || user.Role == models.SystemRoleAdmin {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Administrators cannot be impersonated")
+	}
+	if user.AccountStatus != models.AccountStatusActive {
+		return nil, echo.NewHTTPError(http.StatusConflict, "Suspended users cannot be impersonated")
+	}
+
+	// 3. Start the session and record it
+	tokens, err := authsession.Impersonate(l.svcCtx.DB, l.svcCtx.KeyRing, admin, user, time.Duration(minutes)*time.Minute, authsession.ClientFromContext(c))
+	if err != nil {
+		l.Errorf("Admin: Failed to start impersonation of %s by %s: %v", user.ID, admin.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start impersonation")
+	}
+	entry := &models.ImpersonationLog{
+		SessionID: tokens.SessionID,
+		AdminID:   admin.ID,
+		UserID:    user.ID,
+		Action:    models.ImpersonationActionStart,
+		Reason:    reason,
+		IPAddress: c.RealIP(),
+	}
+	if err := models.CreateImpersonationLog(l.svcCtx.DB, entry); err != nil {
+		// Without an audit trail the impersonation must not go ahead
+		_ = models.RevokeUserSession(l.svcCtx.DB, tokens.SessionID, user.ID, models.SessionRevokedImpersonation)
+		l.Errorf("Admin: Failed to record impersonation of %s by %s: %v", user.ID, admin.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start impersonation")
+	}
+
+	l.Infof("Admin: %s started impersonating %s for %d minutes: %s", admin.ID, user.ID, minutes, reason)
+	return &types.ImpersonateUserResponse{
+		Success:   true,
+		Message:   "Impersonation started",
+		Token:     tokens.AccessToken,
+		ExpiresIn: tokens.ExpiresIn,
+		ExpiresAt: time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second).Format(time.RFC3339),
+		User: types.User{
+			ID:               user.ID.String(),
+			Email:            user.Email,
+			Role:             string(user.Role),
+			DefaultSubdomain: user.DefaultSubdomain,
+			AccountStatus:    string(user.AccountStatus),
+			EmailVerified:    user.EmailVerified(),
+			CreatedAt:        user.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:        user.UpdatedAt.Format(time.RFC3339),
+		},
+	}, nil
+}
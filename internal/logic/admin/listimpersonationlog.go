@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// impersonationLogLimit caps the entries returned for a user.
+const impersonationLogLimit = 500
+
+type ListImpersonationLogLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListImpersonationLogLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListImpersonationLogLogic {
+	return &ListImpersonationLogLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListImpersonationLog returns the audit trail of admins impersonating the user, newest first.
+func (l *ListImpersonationLogLogic) GetListImpersonationLog(c echo.Context, req *types.AdminUserRequest) (resp *types.ImpersonationLogResponse, err error) {
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID format")
+	}
+
+	entries, err := models.FindImpersonationLogsByUser(l.svcCtx.DB, userID, impersonationLogLimit)
+	if err != nil {
+		l.Errorf("Admin: Failed to load impersonation log of user %s: %v", userID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load impersonation log")
+	}
+
+	resp = &types.ImpersonationLogResponse{Entries: make([]types.ImpersonationLogEntry, 0, len(entries))}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, types.ImpersonationLogEntry{
+			ID:         e.ID.String(),
+			SessionID:  e.SessionID.String(),
+			AdminID:    e.AdminID.String(),
+			AdminEmail: e.Admin.Email,
+			Action:     e.Action,
+			Reason:     e.Reason,
+			Method:     e.Method,
+			Path:       e.Path,
+			Status:     e.Status,
+			IPAddress:  e.IPAddress,
+			CreatedAt:  e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListSigningKeysLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListSigningKeysLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListSigningKeysLogic {
+	return &ListSigningKeysLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListSigningKeys lists the access token signing keys that still verify tokens.
+func (l *ListSigningKeysLogic) GetListSigningKeys(c echo.Context) (resp *types.ListSigningKeysResponse, err error) {
+	resp = &types.ListSigningKeysResponse{
+		Algorithm: l.svcCtx.KeyRing.Algorithm(),
+		Keys:      []types.SigningKey{},
+	}
+	if resp.Algorithm == keyring.AlgorithmHS256 {
+		return resp, nil
+	}
+
+	keys, err := models.FindUsableSigningKeys(l.svcCtx.DB)
+	if err != nil {
+		l.Logger.Errorf("Admin: Failed to load signing keys: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load signing keys")
+	}
+	for _, k := range keys {
+		key := types.SigningKey{
+			KID:       k.KID,
+			Algorithm: k.Algorithm,
+			Current:   k.RetiredAt == nil,
+			CreatedAt: k.CreatedAt.Format(time.RFC3339),
+		}
+		if k.RetiredAt != nil {
+			key.RetiredAt = k.RetiredAt.Format(time.RFC3339)
+		}
+		if k.ExpiresAt != nil {
+			key.ExpiresAt = k.ExpiresAt.Format(time.RFC3339)
+		}
+		resp.Keys = append(resp.Keys, key)
+	}
+	return resp, nil
+}
@@ -58,9 +58,9 @@ func (l *ListUsersAdminLogic) GetListUsersAdmin(c echo.Context) (resp *[]types.U
 			Email:            u.Email,
 			ProfileData:      profileData, // Assign unmarshalled data
 			Role:             string(u.Role),
-			ApiKey:           u.ApiKey,
 			DefaultSubdomain: u.DefaultSubdomain,
 			AccountStatus:    string(u.AccountStatus),
+			EmailVerified:    u.EmailVerified(),
 			CreatedAt:        u.CreatedAt.Format(time.RFC3339),
 			UpdatedAt:        u.UpdatedAt.Format(time.RFC3339),
 		}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RotateSigningKeysLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRotateSigningKeysLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RotateSigningKeysLogic {
+	return &RotateSigningKeysLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostRotateSigningKeys starts signing access tokens with a new key. Tokens signed with
+// the previous keys stay valid until the grace period ends, so nobody is signed out.
+func (l *RotateSigningKeysLogic) PostRotateSigningKeys(c echo.Context) (resp *types.RotateSigningKeysResponse, err error) {
+	kid, err := l.svcCtx.KeyRing.Rotate(l.ctx)
+	if err != nil {
+		if errors.Is(err, keyring.ErrSymmetric) {
+			return nil, echo.NewHTTPError(http.StatusConflict, "Tokens are signed with the shared secret; configure RS256 or EdDSA signing to rotate keys")
+		}
+		l.Logger.Errorf("Admin: Failed to rotate signing keys: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to rotate signing keys")
+	}
+
+	l.Logger.Infof("Admin: Signing keys rotated, new key %s", kid)
+	return &types.RotateSigningKeysResponse{Success: true, Message: "Signing keys rotated", KID: kid}, nil
+}
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type UnlockUserLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUnlockUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UnlockUserLogic {
+	return &UnlockUserLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostUnlockUser clears the failed sign-in counters and any temporary lockout of a user.
+func (l *UnlockUserLogic) PostUnlockUser(c echo.Context, req *types.AdminUserRequest) (resp *types.Response, err error) {
+	l.Logger.Infof("Admin request: UnlockUser for UserID: %s", req.UserID)
+
+	// 1. Parse UserID from request
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID format")
+	}
+
+	// 2. Fetch the user
+	user, err := models.FindUserByID(l.svcCtx.DB, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		l.Logger.Errorf("Admin: Failed to retrieve user %s: %v", userID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve user")
+	}
+
+	// 3. Clear the counters
+	if err := l.svcCtx.AuthLimiter.Unlock(l.ctx, user.Email); err != nil {
+		l.Logger.Errorf("Admin: Failed to unlock user %s: %v", userID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to unlock user")
+	}
+
+	l.Logger.Infof("Admin: User %s unlocked", userID)
+	return &types.Response{Success: true, Message: "User unlocked"}, nil
+}
@@ -44,6 +44,8 @@ func (l *PlansLogic) GetPlans(c echo.Context) (resp *[]types.Plan, err error) {
 				return 0
 			}(),
 			Features: models.UnmarshalJSONFeatures(plan.Features),
+			MaxSeats: plan.Limits.MaxSeats,
+			PerSeat:  plan.PerSeat,
 		})
 	}
 	return resp, nil
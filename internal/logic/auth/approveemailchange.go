@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/core/emailchange"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ApproveEmailChangeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewApproveEmailChangeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApproveEmailChangeLogic {
+	return &ApproveEmailChangeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostApproveEmailChange accepts a change requested without a password, using the link
+// sent to the current address, and sends the confirmation link to the new address.
+func (l *ApproveEmailChangeLogic) PostApproveEmailChange(c echo.Context, req *types.EmailChangeTokenRequest) (resp *types.Response, err error) {
+	// 1. Refuse clients that keep submitting bad tokens
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeEmailToken, ""); err != nil {
+		l.Infof("Email change approval from %s throttled", c.RealIP())
+		return nil, err
+	}
+
+	// 2. Approve the change
+	change, confirmToken, err := emailchange.Approve(l.svcCtx.DB, req.Token)
+	if err != nil {
+		if errors.Is(err, emailchange.ErrInvalidToken) {
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeEmailToken, "", nil)
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired link")
+		}
+		l.Errorf("Failed to approve email change: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not approve email change")
+	}
+
+	// 3. Ask the new address to confirm
+	confirmBody := fmt.Sprintf("Please confirm that you want to use this address for your account by opening the link below:\n\n%s\n\nThe link expires in %d hours. If you didn't request this, you can ignore this email.",
+		emailchange.Link(l.svcCtx.FrontendBaseURL(), emailchange.ActionConfirm, confirmToken), int(emailchange.ConfirmTTL.Hours()))
+	if err := l.svcCtx.SendAccountEmail(l.ctx, change.NewEmail, "Confirm Your New Email Address", confirmBody); err != nil {
+		l.Errorf("Failed to send email change confirmation for user %s: %v", change.UserID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not send confirmation email")
+	}
+
+	l.Infof("Email change to %s approved for user %s", change.NewEmail, change.UserID)
+	return &types.Response{
+		Success: true,
+		Message: "Change approved. Check your new email address for a link to confirm it.",
+	}, nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/core/emailchange"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ConfirmEmailChangeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewConfirmEmailChangeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ConfirmEmailChangeLogic {
+	return &ConfirmEmailChangeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostConfirmEmailChange applies a change using the link sent to the new address.
+func (l *ConfirmEmailChangeLogic) PostConfirmEmailChange(c echo.Context, req *types.EmailChangeTokenRequest) (resp *types.Response, err error) {
+	// 1. Refuse clients that keep submitting bad tokens
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeEmailToken, ""); err != nil {
+		l.Infof("Email change confirmation from %s throttled", c.RealIP())
+		return nil, err
+	}
+
+	// 2. Apply the change; this signs out every session of the user
+	change, err := emailchange.Confirm(l.svcCtx.DB, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, emailchange.ErrInvalidToken):
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeEmailToken, "", nil)
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired link")
+		case errors.Is(err, emailchange.ErrEmailTaken):
+			return nil, echo.NewHTTPError(http.StatusConflict, "This email address is already in use")
+		}
+		l.Errorf("Failed to confirm email change: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not change email address")
+	}
+
+	// 3. Keep billing receipts going to the new address
+	syncStripeCustomerEmail(l.Logger, l.svcCtx, change.UserID)
+
+	l.Infof("Email changed from %s to %s for user %s", change.OldEmail, change.NewEmail, change.UserID)
+	return &types.Response{
+		Success: true,
+		Message: "Email address updated. Please sign in again.",
+	}, nil
+}
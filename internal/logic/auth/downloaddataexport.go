@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/dataexport"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DownloadDataExportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDownloadDataExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadDataExportLogic {
+	return &DownloadDataExportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetDownloadDataExport returns the export a signed download link points to. The link
+// itself authorizes the download so it works straight from the email.
+func (l *DownloadDataExportLogic) GetDownloadDataExport(c echo.Context, req *types.DownloadDataExportRequest) (*models.DataExport, error) {
+	exportID, err := uuid.Parse(req.ExportID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Data export not found")
+	}
+	if err := dataexport.VerifyLink(l.svcCtx.Config.Auth.AccessSecret, exportID, req.Expires, req.Signature); err != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "This download link is invalid or has expired")
+	}
+
+	export, err := models.FindReadyDataExport(l.svcCtx.DB, exportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Data export not found")
+		}
+		l.Errorf("Failed to load data export %s: %v", exportID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load data export")
+	}
+
+	l.Infof("Data export %s of user %s downloaded from %s", export.ID, export.UserID, c.RealIP())
+	return export, nil
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/customer"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// syncStripeCustomerEmail copies the user's current email address to their Stripe customer
+// so receipts follow the account. Failures are logged; the address change itself stands.
+func syncStripeCustomerEmail(logger logx.Logger, svcCtx *svc.ServiceContext, userID uuid.UUID) {
+	user, err := models.FindUserByID(svcCtx.DB, userID)
+	if err != nil {
+		logger.Errorf("Failed to load user %s for Stripe email sync: %v", userID, err)
+		return
+	}
+	if user.StripeCustomerID == nil || *user.StripeCustomerID == "" {
+		return
+	}
+
+	stripe.Key = svcCtx.Config.Stripe.SecretKey
+	if stripe.Key == "" {
+		logger.Error("STRIPE_SECRET_KEY missing from service config, Stripe customer email not updated")
+		return
+	}
+	if _, err := customer.Update(*user.StripeCustomerID, &stripe.CustomerParams{Email: stripe.String(user.Email)}); err != nil {
+		logger.Errorf("Failed to update Stripe customer %s email for user %s: %v", *user.StripeCustomerID, user.ID, err)
+		return
+	}
+	logger.Infof("Updated Stripe customer %s email for user %s", *user.StripeCustomerID, user.ID)
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/solotoabillion/stab/core/emailverify"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+)
+
+// sendVerificationEmail issues a verification link for the user's current address and emails it.
+func sendVerificationEmail(ctx context.Context, svcCtx *svc.ServiceContext, user *models.User) error {
+	token, err := emailverify.Issue(svcCtx.DB, user)
+	if err != nil {
+		return err
+	}
+	link := emailverify.Link(svcCtx.FrontendBaseURL(), token)
+
+	emailSubject := "Verify Your Email Address"
+	emailBody := fmt.Sprintf("Please confirm that this is your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours. If you didn't create an account, you can ignore this email.",
+		link, int(emailverify.TokenTTL.Hours()))
+
+	if err := svcCtx.SendAccountEmail(ctx, user.Email, emailSubject, emailBody); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
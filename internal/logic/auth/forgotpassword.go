@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/solotoabillion/stab/core/bruteforce"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
@@ -33,8 +34,14 @@ func NewForgotPasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Fo
 }
 
 func (l *ForgotPasswordLogic) PostForgotPassword(c echo.Context, req *types.LoginCodeRequest) (resp *types.Response, err error) { // Assuming input type matches RequestLoginCodeRequest (just email)
-	// 1. Find user by email
-	// 1. Find user by email using model function
+	// 1. Throttle reset emails per address and client; every request counts
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeEmailRequest, req.Email); err != nil {
+		l.Infof("Forgot password request for %s throttled", req.Email)
+		return nil, err
+	}
+	recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeEmailRequest, req.Email, nil)
+
+	// 2. Find user by email using model function
 	userPtr, err := models.FindUserByEmail(l.svcCtx.DB, req.Email)
 	if err != nil {
 		// IMPORTANT: Do NOT reveal if the user exists or not for security reasons.
@@ -53,7 +60,7 @@ func (l *ForgotPasswordLogic) PostForgotPassword(c echo.Context, req *types.Logi
 	}
 	user := *userPtr // Dereference if found
 
-	// 2. Generate secure random token
+	// 3. Generate secure random token
 	tokenBytes := make([]byte, 32) // 32 bytes = 256 bits
 	if _, err = crand.Read(tokenBytes); err != nil {
 		l.Errorf("Failed to generate password reset token for %s: %v", req.Email, err)
@@ -62,15 +69,15 @@ func (l *ForgotPasswordLogic) PostForgotPassword(c echo.Context, req *types.Logi
 	resetToken := hex.EncodeToString(tokenBytes)
 	expiresAt := time.Now().Add(1 * time.Hour) // Token valid for 1 hour
 
-	// 3. Update user record with token and expiry
-	// 3. Update user record using model function
+	// 4. Update user record with token and expiry
+	// 4. Update user record using model function
 	err = models.UpdateUserPasswordResetToken(l.svcCtx.DB, user.ID, resetToken, expiresAt)
 	if err != nil {
 		l.Errorf("Failed to save password reset token for user %s: %v", req.Email, err)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process request (db save)")
 	}
 
-	// 4. Send email (TODO: Implement email sending via svcCtx or dedicated service)
+	// 5. Send email (TODO: Implement email sending via svcCtx or dedicated service)
 	frontendURL := l.svcCtx.Config.Email.BaseURL // Assuming BaseURL in config is the frontend URL
 	if frontendURL == "" {
 		frontendURL = "http://localhost:5173"                                                                // Fallback for local dev - adjust if needed
@@ -94,7 +101,7 @@ func (l *ForgotPasswordLogic) PostForgotPassword(c echo.Context, req *types.Logi
 
 	l.Infof("Password reset initiated for %s.", req.Email)
 
-	// 5. Return generic success message
+	// 6. Return generic success message
 	// Optionally return token in dev environment if needed for testing, but avoid in prod.
 	// For now, always return generic message.
 	resp = &types.Response{
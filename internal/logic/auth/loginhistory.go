@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/core/loginhistory"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// recordLoginFailure adds a failed attempt to the login history. userID is nil when the
+// email matches no account. Storage errors are logged and do not change the response.
+func recordLoginFailure(c echo.Context, svcCtx *svc.ServiceContext, method, email string, userID *uuid.UUID, reason string) {
+	attempt := loginhistory.Attempt{
+		UserID:        userID,
+		Email:         email,
+		Method:        method,
+		FailureReason: reason,
+		Client:        authsession.ClientFromContext(c),
+	}
+	if _, _, err := loginhistory.Record(svcCtx.DB, attempt); err != nil {
+		logx.WithContext(c.Request().Context()).Errorf("Failed to record %s login failure for %s: %v", method, email, err)
+	}
+}
+
+// recordLogin adds a successful login to the history. A login from a device the user
+// has not signed in from before creates a security notification, and an email for users
+// who opted in to security alerts.
+func recordLogin(c echo.Context, svcCtx *svc.ServiceContext, user *models.User, method string) {
+	ctx := c.Request().Context()
+	logger := logx.WithContext(ctx)
+	event, newDevice, err := loginhistory.Record(svcCtx.DB, loginhistory.Attempt{
+		UserID: &user.ID,
+		Email:  user.Email,
+		Method: method,
+		Client: authsession.ClientFromContext(c),
+	})
+	if err != nil {
+		logger.Errorf("Failed to record %s login for user %s: %v", method, user.ID, err)
+		return
+	}
+	if !newDevice {
+		return
+	}
+
+	logger.Infof("User %s signed in from a new device (%s, %s)", user.ID, event.DeviceLabel, event.IPAddress)
+	body := fmt.Sprintf("Your account was just signed in to from a new device: %s (IP address %s) at %s.\n\n"+
+		"If this was you, there is nothing to do. If it wasn't, change your password right away, "+
+		"sign out the device from your account's sessions and enable two-factor authentication.",
+		event.DeviceLabel, event.IPAddress, event.CreatedAt.UTC().Format(time.RFC1123))
+	notification := &models.Notification{
+		UserID: user.ID,
+		Type:   "security",
+		Title:  "New sign-in to your account",
+		Body:   body,
+	}
+	if err := models.CreateNotification(svcCtx.DB, notification); err != nil {
+		logger.Errorf("Failed to create new device notification for user %s: %v", user.ID, err)
+	}
+
+	if settings, _ := user.GetSettings(); !settings.SecurityAlertsEnabled {
+		return
+	}
+	if err := svcCtx.SendAccountEmail(ctx, user.Email, "New Sign-in to Your Account", body); err != nil {
+		logger.Errorf("Failed to send new device alert to user %s: %v", user.ID, err)
+	}
+}
@@ -6,13 +6,13 @@ import (
 	"errors"
 	"net/http"
 
-	"github.com/solotoabillion/stab/core/security" // Added session import for cookie setting
-	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/core/mfa"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/datatypes"
@@ -58,12 +58,76 @@ func extractProfileData(profileData datatypes.JSON) (firstName, lastName string)
 	return
 }
 
+// completeLogin finishes a successful first-factor login with the given method. Users with two-factor
+// authentication enabled get a short-lived "mfa pending" token instead of the auth cookie.
+func completeLogin(c echo.Context, svcCtx *svc.ServiceContext, user *models.User, method string) (*types.LoginResponse, error) {
+	if mfa.Required(user) {
+		mfaToken, err := mfa.NewPendingToken(svcCtx.Config, user.ID, method)
+		if err != nil {
+			return nil, err
+		}
+		return &types.LoginResponse{
+			Success:     true,
+			Message:     "Two-factor authentication required",
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		}, nil
+	}
+	return issueLogin(c, svcCtx, user, method)
+}
+
+// issueLogin starts a server-side session, sets the auth cookies and builds the login response.
+// The login is added to the user's login history under the method of its first factor.
+func issueLogin(c echo.Context, svcCtx *svc.ServiceContext, user *models.User, method string) (*types.LoginResponse, error) {
+	tokens, err := authsession.Start(svcCtx.DB, svcCtx.Config, svcCtx.KeyRing, user, authsession.ClientFromContext(c))
+	if err != nil {
+		return nil, err
+	}
+
+	authsession.SetCookies(svcCtx.Config, c, tokens)
+	recordLogin(c, svcCtx, user, method)
+
+	firstName, lastName := extractProfileData(user.ProfileData)
+	return &types.LoginResponse{
+		Success:      true,
+		Message:      "Login successful",
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User: types.User{
+			ID:    user.ID.String(),
+			Email: user.Email,
+			ProfileData: types.UserProfileData{
+				FirstName: firstName,
+				LastName:  lastName,
+			},
+			Role:             string(user.Role),
+			DefaultSubdomain: user.DefaultSubdomain,
+			EmailVerified:    user.EmailVerified(),
+		},
+	}, nil
+}
+
 func (l *LoginUserLogic) PostLoginUser(c echo.Context, req *types.LoginRequest) (resp *types.LoginResponse, err error) { // Changed return type
-	// 1. Find user by email using model function
+	// 1. Refuse throttled accounts and clients before touching the password
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeLogin, req.Email); err != nil {
+		l.Infof("Login attempt for email %s throttled", req.Email)
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodPassword, req.Email, nil, models.LoginFailureThrottled)
+		return nil, err
+	}
+
+	// 2. Domains that enforce single sign-on cannot use passwords
+	if err := checkPasswordAllowed(l.ctx, l.svcCtx, req.Email); err != nil {
+		return nil, err
+	}
+
+	// 3. Find user by email using model function
 	userPtr, err := models.FindUserByEmail(l.svcCtx.DB, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			l.Infof("Login attempt failed for email %s: user not found", req.Email)
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeLogin, req.Email, nil)
+			recordLoginFailure(c, l.svcCtx, models.LoginMethodPassword, req.Email, nil, models.LoginFailureUnknownAccount)
 			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid credentials")
 		}
 		l.Errorf("Database error during login for %s: %v", req.Email, err)
@@ -71,48 +135,31 @@ func (l *LoginUserLogic) PostLoginUser(c echo.Context, req *types.LoginRequest)
 	}
 	user := *userPtr // Dereference if found
 
-	// 2. Check password
+	// 4. Check password
 	if !user.CheckPassword(req.Password) {
 		l.Infof("Login attempt failed for email %s: invalid password", req.Email) // Use Infof instead of Warnf
+		recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeLogin, req.Email, &user)
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodPassword, req.Email, &user.ID, models.LoginFailureInvalidCredentials)
 		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid credentials")
 	}
-
-	// 3. Generate JWT
-	claims := jwt.MapClaims{
-		"id":    user.ID.String(), // Use string representation of UUID
-		"email": user.Email,
-		"role":  user.Role,
-		// Add other claims as needed, e.g., name
-		// "name": user.Name,
+	resetAttempts(c, l.svcCtx, bruteforce.ScopeLogin, req.Email)
+	if user.AccountStatus == models.AccountStatusSuspended {
+		l.Infof("Login rejected for suspended user %s", user.ID)
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodPassword, req.Email, &user.ID, models.LoginFailureSuspended)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Account suspended")
 	}
-	tokenString, err := security.NewJWT(claims, l.svcCtx.Config.Auth.AccessSecret, l.svcCtx.Config.Auth.AccessExpire)
+
+	// 5. Issue the session, or an "mfa pending" token if a second factor is required
+	resp, err = completeLogin(c, l.svcCtx, &user, models.LoginMethodPassword)
 	if err != nil {
 		l.Errorf("Error generating JWT for user %s: %v", user.Email, err)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login (token generation)")
 	}
 
-	l.Infof("User logged in successfully: %s", user.Email)
-
-	// 4. Set auth cookie
-	session.SetCookie(l.svcCtx.Config, c, tokenString, l.svcCtx.Config.Auth.UserCookieName)
-
-	// 5. Return standard success response
-	firstName, lastName := extractProfileData(user.ProfileData)
-	resp = &types.LoginResponse{
-		Success: true,
-		Message: "Login successful",
-		Token:   tokenString,
-		User: types.User{
-			ID:    user.ID.String(),
-			Email: user.Email,
-			ProfileData: types.UserProfileData{
-				FirstName: firstName,
-				LastName:  lastName,
-			},
-			Role:             string(user.Role),
-			ApiKey:           user.ApiKey,
-			DefaultSubdomain: user.DefaultSubdomain,
-		},
+	if resp.MfaRequired {
+		l.Infof("Password accepted for %s, awaiting second factor", user.Email)
+	} else {
+		l.Infof("User logged in successfully: %s", user.Email)
 	}
 
 	return resp, nil // Return standard response and nil error
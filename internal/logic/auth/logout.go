@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type LogoutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewLogoutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LogoutLogic {
+	return &LogoutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostLogout revokes the current session and clears the auth cookies.
+func (l *LogoutLogic) PostLogout(c echo.Context, req *types.LogoutRequest) (resp *types.Response, err error) {
+	if req.RefreshToken != "" {
+		err = authsession.Revoke(l.svcCtx.DB, req.RefreshToken, models.SessionRevokedLogout)
+	} else {
+		err = authsession.RevokeRequest(l.svcCtx.DB, l.svcCtx.Config, l.svcCtx.KeyRing, c, models.SessionRevokedLogout)
+	}
+	if err != nil {
+		l.Errorf("Failed to revoke session on logout: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not log out")
+	}
+
+	authsession.ClearCookies(l.svcCtx.Config, c)
+	return &types.Response{Success: true, Message: "Logged out"}, nil
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/solotoabillion/stab/core/oauth"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type OAuthCallbackLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewOAuthCallbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *OAuthCallbackLogic {
+	return &OAuthCallbackLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetOAuthCallback completes a provider sign-in, or connects the provider to the signed-in
+// user who started the request from their profile.
+func (l *OAuthCallbackLogic) GetOAuthCallback(c echo.Context, req *types.OAuthCallbackRequest) (resp *types.OAuthResponse, err error) {
+	// 1. The user may have declined at the provider
+	if req.Error != "" {
+		l.Infof("OAuth sign-in with %s cancelled: %s", req.Provider, req.Error)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Sign-in was cancelled")
+	}
+
+	// 2. Resolve the provider
+	provider, err := oauth.NewRegistry(l.svcCtx.Config, l.svcCtx.Settings).Get(l.ctx, req.Provider)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) || errors.Is(err, oauth.ErrProviderNotConfigured) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Unknown sign-in provider")
+		}
+		l.Errorf("Failed to load OAuth provider %s: %v", req.Provider, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Sign-in provider unavailable")
+	}
+
+	// 3. Check the state against the browser and the server-side record, then redeem the code
+	if !oauth.CheckStateCookie(c, req.State) {
+		l.Infof("OAuth callback for %s with a state not bound to this browser", req.Provider)
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid state token")
+	}
+	store := oauth.NewStateStore(l.svcCtx.RedisClient, l.svcCtx.DB)
+	identity, pending, err := oauth.Complete(l.ctx, store, provider, req.State, req.Code)
+	if err != nil {
+		if errors.Is(err, oauth.ErrInvalidState) {
+			l.Infof("OAuth callback for %s with an unknown or expired state", req.Provider)
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid state token")
+		}
+		l.Errorf("Failed to complete OAuth sign-in with %s: %v", req.Provider, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to sign in with provider")
+	}
+
+	frontendURL := l.svcCtx.FrontendBaseURL()
+
+	// 4. Connecting another provider from the profile page
+	if pending.LinkUserID != uuid.Nil {
+		if err := l.linkIdentity(pending.LinkUserID, identity); err != nil {
+			return nil, err
+		}
+		l.Infof("Connected %s account to user %s", provider.Name, pending.LinkUserID)
+		return &types.OAuthResponse{
+			Success:     true,
+			Message:     fmt.Sprintf("Connected your %s account", provider.Name),
+			RedirectURL: fmt.Sprintf("%s/app/settings?connected=%s", frontendURL, url.QueryEscape(provider.Name)),
+		}, nil
+	}
+
+	// 5. Find or create the user
+	user, err := resolveOAuthUser(l.ctx, l.svcCtx, identity)
+	if err != nil {
+		return nil, err
+	}
+	if user.AccountStatus == models.AccountStatusSuspended {
+		l.Infof("OAuth sign-in rejected for suspended user %s", user.ID)
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodOAuth, user.Email, &user.ID, models.LoginFailureSuspended)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Account suspended")
+	}
+
+	// 6. Issue the session, or an "mfa pending" token if a second factor is required
+	login, err := completeLogin(c, l.svcCtx, user, models.LoginMethodOAuth)
+	if err != nil {
+		l.Errorf("Failed to start session for %s user %s: %v", provider.Name, user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to generate session token")
+	}
+	if login.MfaRequired {
+		l.Infof("%s sign-in accepted for %s, awaiting second factor. Redirecting.", provider.Name, user.Email)
+		return &types.OAuthResponse{
+			Success:     true,
+			Message:     "Two-factor authentication required",
+			RedirectURL: fmt.Sprintf("%s/app#mfa_token=%s&user=%s", frontendURL, login.MfaToken, url.QueryEscape(user.Email)),
+		}, nil
+	}
+
+	// 7. Redirect back to frontend with token
+	l.Infof("%s sign-in successful for %s. Redirecting.", provider.Name, user.Email)
+	return &types.OAuthResponse{
+		Success:     true,
+		Message:     fmt.Sprintf("Successfully authenticated with %s", provider.Name),
+		RedirectURL: fmt.Sprintf("%s/app#token=%s&user=%s", frontendURL, login.Token, url.QueryEscape(user.Email)),
+	}, nil
+}
+
+// linkIdentity connects a provider account to the signed-in user who requested it.
+func (l *OAuthCallbackLogic) linkIdentity(userID uuid.UUID, identity *oauth.Identity) error {
+	existing, err := models.FindUserIdentity(l.svcCtx.DB, identity.Provider, identity.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			l.Infof("%s identity already linked to another user, refused for user %s", identity.Provider, userID)
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("This %s account is connected to another user", identity.Provider))
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Database error finding %s identity: %v", identity.Provider, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Database error")
+	}
+	if err := models.CreateUserIdentity(l.svcCtx.DB, &models.UserIdentity{UserID: userID, Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email}); err != nil {
+		l.Errorf("Failed to link %s identity to user %s: %v", identity.Provider, userID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to connect account")
+	}
+	return nil
+}
+
+// generateRandomSubdomain creates a memorable subdomain
+func generateRandomSubdomain() string {
+	// Simple implementation - in production, should use the full word lists and uniqueness check
+	return fmt.Sprintf("user-%s", uuid.New().String()[:8])
+}
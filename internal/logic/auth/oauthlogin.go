@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/oauth"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type OAuthLoginLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewOAuthLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *OAuthLoginLogic {
+	return &OAuthLoginLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetOAuthLogin starts a sign-in with the named provider and returns its authorization URL.
+func (l *OAuthLoginLogic) GetOAuthLogin(c echo.Context, req *types.OAuthProviderRequest) (resp *types.OAuthResponse, err error) {
+	// 1. Resolve the provider from settings
+	provider, err := oauth.NewRegistry(l.svcCtx.Config, l.svcCtx.Settings).Get(l.ctx, req.Provider)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) || errors.Is(err, oauth.ErrProviderNotConfigured) {
+			l.Infof("OAuth login requested for unavailable provider %s: %v", req.Provider, err)
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Unknown sign-in provider")
+		}
+		l.Errorf("Failed to load OAuth provider %s: %v", req.Provider, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Sign-in provider unavailable")
+	}
+
+	// 2. Store the state, PKCE verifier and nonce server-side and bind the state to this browser
+	store := oauth.NewStateStore(l.svcCtx.RedisClient, l.svcCtx.DB)
+	authURL, state, err := oauth.Begin(l.ctx, store, provider, oauth.PendingAuth{})
+	if err != nil {
+		l.Errorf("Failed to start OAuth login with %s: %v", provider.Name, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not start sign-in")
+	}
+	oauth.SetStateCookie(c, state)
+
+	// 3. Return the URL in the response
+	return &types.OAuthResponse{
+		Success:     true,
+		Message:     "Successfully generated authorization URL",
+		RedirectURL: authURL,
+	}, nil
+}
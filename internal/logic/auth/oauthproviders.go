@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/solotoabillion/stab/core/oauth"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type OAuthProvidersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewOAuthProvidersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *OAuthProvidersLogic {
+	return &OAuthProvidersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetOAuthProviders lists the configured sign-in providers for the login page.
+func (l *OAuthProvidersLogic) GetOAuthProviders(c echo.Context) (resp *types.OAuthProvidersResponse, err error) {
+	providers := oauth.NewRegistry(l.svcCtx.Config, l.svcCtx.Settings).Names()
+	if providers == nil {
+		providers = []string{}
+	}
+	return &types.OAuthProvidersResponse{Providers: providers}, nil
+}
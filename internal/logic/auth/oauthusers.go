@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/core/oauth"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// resolveOAuthUser finds the user linked to the provider account. Otherwise an account with the
+// same address is linked when the provider verified the address, or a new user is created.
+func resolveOAuthUser(ctx context.Context, svcCtx *svc.ServiceContext, identity *oauth.Identity) (*models.User, error) {
+	l := logx.WithContext(ctx)
+	db := svcCtx.DB
+
+	linked, err := models.FindUserIdentity(db, identity.Provider, identity.Subject)
+	if err == nil {
+		user, err := models.FindUserByID(db, linked.UserID)
+		if err != nil {
+			l.Errorf("Failed to load user %s linked to %s identity: %v", linked.UserID, identity.Provider, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Database error during login")
+		}
+		if err := models.TouchUserIdentity(db, linked.ID, identity.Email); err != nil {
+			l.Errorf("Failed to update %s identity of user %s: %v", identity.Provider, user.ID, err)
+		}
+		markOAuthEmailVerified(ctx, svcCtx, user, identity)
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Database error finding %s identity: %v", identity.Provider, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Database error during login")
+	}
+
+	if identity.Email == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("%s did not share an email address. Sign in another way and connect %s from your profile.", identity.Provider, identity.Provider))
+	}
+
+	now := time.Now()
+	user, err := models.FindUserByEmail(db, identity.Email)
+	switch {
+	case err == nil:
+		// Linking on an unverified address would let anyone claim the account
+		if !identity.EmailVerified {
+			l.Infof("Refused to link unverified %s identity to existing user %s", identity.Provider, user.ID)
+			return nil, echo.NewHTTPError(http.StatusConflict,
+				fmt.Sprintf("An account with this email already exists. Sign in and connect %s from your profile.", identity.Provider))
+		}
+		if err := models.CreateUserIdentity(db, &models.UserIdentity{UserID: user.ID, Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email, LastLoginAt: &now}); err != nil {
+			l.Errorf("Failed to link %s identity to user %s: %v", identity.Provider, user.ID, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Database error during login")
+		}
+		l.Infof("Linked %s identity to existing user %s by verified email", identity.Provider, user.ID)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = createOAuthUser(ctx, svcCtx, identity)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		l.Errorf("Database error finding user %s from %s sign-in: %v", identity.Email, identity.Provider, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Database error during login")
+	}
+
+	markOAuthEmailVerified(ctx, svcCtx, user, identity)
+	return user, nil
+}
+
+// createOAuthUser registers a new user from a provider identity.
+func createOAuthUser(ctx context.Context, svcCtx *svc.ServiceContext, identity *oauth.Identity) (*models.User, error) {
+	l := logx.WithContext(ctx)
+	l.Infof("%s user %s not found, creating new user.", identity.Provider, identity.Email)
+
+	profileJSON, err := json.Marshal(types.UserProfileData{
+		FirstName:      identity.FirstName,
+		LastName:       identity.LastName,
+		AvatarURL:      identity.AvatarURL,
+		AuthProvider:   identity.Provider,
+		AuthProviderID: identity.Subject,
+	})
+	if err != nil {
+		l.Errorf("Error marshalling profile data for %s: %v", identity.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process registration")
+	}
+
+	now := time.Now()
+	user := &models.User{
+		Email:            identity.Email,
+		Role:             models.SystemRoleUser,
+		DefaultSubdomain: generateRandomSubdomain(),
+		ProfileData:      profileJSON,
+	}
+	if identity.EmailVerified {
+		user.EmailVerifiedAt = &now
+	}
+
+	err = svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := models.CreateUser(tx, user); err != nil {
+			return err
+		}
+		return models.CreateUserIdentity(tx, &models.UserIdentity{UserID: user.ID, Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email, LastLoginAt: &now})
+	})
+	if err != nil {
+		l.Errorf("Failed to create new user from %s sign-in (%s): %v", identity.Provider, identity.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create user account")
+	}
+
+	// Ask unverified users to confirm the address the provider reported
+	if !identity.EmailVerified {
+		if err := sendVerificationEmail(ctx, svcCtx, user); err != nil {
+			l.Errorf("Failed to send verification email to user %s: %v", user.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// markOAuthEmailVerified records that the provider vouches for the user's current address.
+func markOAuthEmailVerified(ctx context.Context, svcCtx *svc.ServiceContext, user *models.User, identity *oauth.Identity) {
+	if user.EmailVerified() || !identity.EmailVerified || !strings.EqualFold(user.Email, identity.Email) {
+		return
+	}
+	if err := models.MarkUserEmailVerified(svcCtx.DB, user.ID); err != nil {
+		logx.WithContext(ctx).Errorf("Failed to mark email verified for user %s: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/mfa"
+	"github.com/solotoabillion/stab/core/webauthn"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type PasskeyLoginLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyLoginLogic {
+	return &PasskeyLoginLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostPasskeyLogin completes a passkey login ceremony.
+//
+// A passkey verified with PIN or biometrics counts as multi-factor and signs the user in directly.
+// Without user verification the regular second-factor gate applies. When an "mfa pending" token is
+// supplied the passkey is used as the second factor for that login instead (step-up).
+func (l *PasskeyLoginLogic) PostPasskeyLogin(c echo.Context, req *types.PasskeyLoginRequest) (resp *types.LoginResponse, err error) {
+	invalid := echo.NewHTTPError(http.StatusUnauthorized, "Passkey could not be verified")
+
+	// 1. Consume the ceremony
+	sess, err := webauthn.NewSessionStore(l.svcCtx.RedisClient, l.svcCtx.DB).Take(l.ctx, req.SessionID, webauthn.PurposeLogin)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSessionNotFound) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Passkey login expired. Please try again.")
+		}
+		l.Errorf("Failed to load passkey session: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login")
+	}
+
+	// 2. Decode the authenticator response
+	credentialID, err := webauthn.DecodeString(req.Credential.ID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid passkey response")
+	}
+	clientDataJSON, err := webauthn.DecodeString(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid passkey response")
+	}
+	authenticatorData, err := webauthn.DecodeString(req.Credential.Response.AuthenticatorData)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid passkey response")
+	}
+	signature, err := webauthn.DecodeString(req.Credential.Response.Signature)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid passkey response")
+	}
+
+	// 3. Look up the credential and make sure it belongs to the expected user
+	cred, err := models.FindWebAuthnCredentialByCredentialID(l.svcCtx.DB, credentialID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			l.Infof("Passkey login failed: unknown credential")
+			return nil, invalid
+		}
+		l.Errorf("Failed to load passkey: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login")
+	}
+	if sess.UserID != uuid.Nil && sess.UserID != cred.UserID {
+		l.Infof("Passkey login failed: credential %s not offered for this ceremony", cred.ID)
+		return nil, invalid
+	}
+	if req.Credential.Response.UserHandle != "" {
+		userHandle, err := webauthn.DecodeString(req.Credential.Response.UserHandle)
+		if err != nil || !bytes.Equal(userHandle, cred.UserID[:]) {
+			l.Infof("Passkey login failed: user handle mismatch for credential %s", cred.ID)
+			return nil, invalid
+		}
+	}
+
+	// 4. Verify the assertion and record the new signature counter
+	rp := webauthn.ConfigFromApp(l.svcCtx.Config)
+	ad, err := rp.VerifyAssertion(sess.Challenge, cred.PublicKey, uint32(cred.SignCount), clientDataJSON, authenticatorData, signature)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCountRegression) {
+			l.Errorf("Passkey %s of user %s reported a stale signature counter, possible cloned authenticator", cred.ID, cred.UserID)
+		} else {
+			l.Infof("Passkey login failed for credential %s: %v", cred.ID, err)
+		}
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodPasskey, "", &cred.UserID, models.LoginFailureInvalidCredentials)
+		return nil, invalid
+	}
+	if err := models.UpdateWebAuthnCredentialUsage(l.svcCtx.DB, cred.ID, int64(ad.SignCount), ad.BackedUp()); err != nil {
+		l.Errorf("Failed to update passkey %s usage: %v", cred.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login")
+	}
+
+	// 5. Load the user
+	user, err := models.FindUserByID(l.svcCtx.DB, cred.UserID)
+	if err != nil {
+		l.Errorf("Failed to load user %s for passkey %s: %v", cred.UserID, cred.ID, err)
+		return nil, invalid
+	}
+	if user.AccountStatus == models.AccountStatusSuspended {
+		l.Infof("Passkey login rejected for suspended user %s", user.ID)
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodPasskey, user.Email, &user.ID, models.LoginFailureSuspended)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Account suspended")
+	}
+
+	// 6. Issue the session, gating on the second factor where needed
+	switch {
+	case req.MfaToken != "":
+		pendingUserID, method, err := mfa.ParsePendingToken(l.svcCtx.Config, req.MfaToken)
+		if err != nil || pendingUserID != user.ID {
+			l.Infof("Passkey step-up failed for user %s: pending token invalid or for another user", user.ID)
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Your sign-in session has expired. Please log in again.")
+		}
+		resp, err = issueLogin(c, l.svcCtx, user, method)
+	case ad.UserVerified():
+		resp, err = issueLogin(c, l.svcCtx, user, models.LoginMethodPasskey)
+	default:
+		resp, err = completeLogin(c, l.svcCtx, user, models.LoginMethodPasskey)
+	}
+	if err != nil {
+		l.Errorf("Error generating JWT for user %s after passkey login: %v", user.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login (token generation)")
+	}
+
+	l.Infof("User logged in with passkey %s: %s", cred.ID, user.Email)
+	return resp, nil
+}
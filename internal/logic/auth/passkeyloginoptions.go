@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/webauthn"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PasskeyLoginOptionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyLoginOptionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyLoginOptionsLogic {
+	return &PasskeyLoginOptionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostPasskeyLoginOptions starts a passkey login ceremony.
+// With an email the user's credentials are listed; without one the browser offers discoverable passkeys.
+// Unknown emails get the same response as a discoverable login so accounts cannot be enumerated.
+func (l *PasskeyLoginOptionsLogic) PostPasskeyLoginOptions(c echo.Context, req *types.PasskeyLoginOptionsRequest) (resp *types.PasskeyOptionsResponse, err error) {
+	// 1. Resolve the allow list
+	userID := uuid.Nil
+	var allow []webauthn.CredentialDescriptor
+	if email := strings.TrimSpace(req.Email); email != "" {
+		if user, err := models.FindUserByEmail(l.svcCtx.DB, email); err == nil {
+			creds, err := models.FindWebAuthnCredentialsByUser(l.svcCtx.DB, user.ID)
+			if err != nil {
+				l.Errorf("Failed to load passkeys for user %s: %v", user.ID, err)
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start passkey login")
+			}
+			if len(creds) > 0 {
+				userID = user.ID
+				for _, cred := range creds {
+					allow = append(allow, webauthn.NewCredentialDescriptor(cred.CredentialID, cred.TransportList()))
+				}
+			}
+		}
+	}
+
+	// 2. Store the challenge server-side
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		l.Errorf("Failed to generate passkey challenge: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start passkey login")
+	}
+	sessionID, err := webauthn.NewSessionStore(l.svcCtx.RedisClient, l.svcCtx.DB).Save(l.ctx, webauthn.Session{
+		UserID:    userID,
+		Purpose:   webauthn.PurposeLogin,
+		Challenge: challenge,
+	})
+	if err != nil {
+		l.Errorf("Failed to store passkey login session: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start passkey login")
+	}
+
+	rp := webauthn.ConfigFromApp(l.svcCtx.Config)
+	return &types.PasskeyOptionsResponse{
+		SessionID: sessionID,
+		PublicKey: rp.NewRequestOptions(challenge, allow),
+	}, nil
+}
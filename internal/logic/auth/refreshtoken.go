@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RefreshTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRefreshTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RefreshTokenLogic {
+	return &RefreshTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostRefreshToken exchanges a refresh token for a new access and refresh token pair.
+// The presented refresh token is rotated; replaying an old one revokes the session.
+func (l *RefreshTokenLogic) PostRefreshToken(c echo.Context, req *types.RefreshTokenRequest) (resp *types.TokenResponse, err error) {
+	// 1. Take the refresh token from the body, falling back to the cookie
+	refreshToken := req.RefreshToken
+	if refreshToken == "" {
+		refreshToken = authsession.RefreshTokenFromCookie(l.svcCtx.Config, c)
+	}
+
+	// 2. Rotate
+	tokens, user, err := authsession.Refresh(l.svcCtx.DB, l.svcCtx.Config, l.svcCtx.KeyRing, refreshToken, authsession.ClientFromContext(c))
+	if err != nil {
+		if errors.Is(err, authsession.ErrRefreshTokenReused) {
+			l.Errorf("Refresh token reuse detected from %s, session revoked", c.RealIP())
+			authsession.ClearCookies(l.svcCtx.Config, c)
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Your session has expired. Please log in again.")
+		}
+		if errors.Is(err, authsession.ErrInvalidRefreshToken) {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Your session has expired. Please log in again.")
+		}
+		l.Errorf("Failed to refresh session: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not refresh session")
+	}
+
+	// 3. Suspended users lose their sessions
+	if user.AccountStatus == models.AccountStatusSuspended {
+		if err := models.RevokeUserSession(l.svcCtx.DB, tokens.SessionID, user.ID, models.SessionRevokedByUser); err != nil {
+			l.Errorf("Failed to revoke session of suspended user %s: %v", user.ID, err)
+		}
+		authsession.ClearCookies(l.svcCtx.Config, c)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Account suspended")
+	}
+
+	authsession.SetCookies(l.svcCtx.Config, c, tokens)
+	return &types.TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/solotoabillion/stab/core/password"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
@@ -37,8 +38,13 @@ func NewRegisterUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Regi
 }
 
 func (l *RegisterUserLogic) PostRegisterUser(c echo.Context, req *types.RegisterRequest) (resp *types.Response, err error) { // Changed response type to types.Response as per API def
-	// 1. Check if user already exists
-	// 1. Check if user already exists using model function
+	// 1. Domains that enforce single sign-on get their accounts from the identity provider
+	if err := checkPasswordAllowed(l.ctx, l.svcCtx, req.Email); err != nil {
+		return nil, err
+	}
+
+	// 2. Check if user already exists
+	// 2. Check if user already exists using model function
 	_, err = models.FindUserByEmail(l.svcCtx.DB, req.Email)
 	if err == nil {
 		// User found (no error means user exists)
@@ -52,7 +58,7 @@ func (l *RegisterUserLogic) PostRegisterUser(c echo.Context, req *types.Register
 	}
 	// User does not exist (ErrRecordNotFound), proceed.
 
-	// 2. Prepare ProfileData
+	// 3. Prepare ProfileData
 	profileData := types.UserProfileData{
 		FirstName:    req.FirstName,
 		LastName:     req.LastName,
@@ -64,7 +70,15 @@ func (l *RegisterUserLogic) PostRegisterUser(c echo.Context, req *types.Register
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process registration (profile data)")
 	}
 
-	// 3. Create new user model and hash password
+	// 4. Create new user model and hash password
+	if err := password.Check(l.svcCtx.DB, l.svcCtx.PasswordPolicy(), l.svcCtx.BreachedPasswords, nil, req.Password); err != nil {
+		var policyErr *password.PolicyError
+		if errors.As(err, &policyErr) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, policyErr.Message)
+		}
+		l.Errorf("Error checking password for %s: %v", req.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process registration (password check)")
+	}
 	user := models.User{
 		Email:       req.Email,
 		Role:        models.SystemRoleUser, // Set default role
@@ -75,9 +89,7 @@ func (l *RegisterUserLogic) PostRegisterUser(c echo.Context, req *types.Register
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process registration (password hash)")
 	}
 
-	// 4. Generate API Key and Default Subdomain (using hooks in model now)
-	// ApiKey and DefaultSubdomain are handled by BeforeCreate hook if needed
-	// user.ApiKey = apiKey // Handled by hook
+	// 5. Generate Default Subdomain
 	// user.DefaultSubdomain = generateRandomSubdomain() // Handled by hook? Or generate here? Let's generate here for now.
 	// TODO: Add uniqueness check loop for subdomain if needed
 	// --- Subdomain Generation (Moved inside function) ---
@@ -125,9 +137,9 @@ func (l *RegisterUserLogic) PostRegisterUser(c echo.Context, req *types.Register
 	user.DefaultSubdomain = generateRandomSubdomain()
 	// --- End Subdomain Generation ---
 
-	// 5. Save user to database
+	// 6. Save user to database
 	// The BeforeCreate hook in models/user.go will generate UUID and API key
-	// 5. Save user to database using model function
+	// 6. Save user to database using model function
 	if err := models.CreateUser(l.svcCtx.DB, &user); err != nil {
 		// TODO: Handle potential unique constraint violation on DefaultSubdomain if generation collides
 		l.Errorf("Error creating user %s: %v", req.Email, err)
@@ -137,10 +149,15 @@ func (l *RegisterUserLogic) PostRegisterUser(c echo.Context, req *types.Register
 
 	l.Infof("User registered successfully: %s, Subdomain: %s", user.Email, user.DefaultSubdomain)
 
-	// 6. Return success response (API defines types.Response, not types.User)
+	// 7. Send the email verification link; the user can ask for a new one if this fails
+	if err := sendVerificationEmail(l.ctx, l.svcCtx, &user); err != nil {
+		l.Errorf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+
+	// 8. Return success response (API defines types.Response, not types.User)
 	resp = &types.Response{
 		Success: true,
-		Message: "User registered successfully",
+		Message: "User registered successfully. Please check your email to verify your address.",
 	}
 
 	return resp, nil // Return success response and nil error
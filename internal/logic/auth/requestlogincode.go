@@ -2,19 +2,20 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand"
-	"time"
+	"net/http"
 
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/core/email"
+	"github.com/solotoabillion/stab/core/logincode"
+	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
 
 	"github.com/labstack/echo/v4"
 	"github.com/zeromicro/go-zero/core/logx"
-)
-
-const (
-	loginCodeExpiry = 5 * time.Minute // Login codes are valid for 5 minutes
+	"gorm.io/gorm"
 )
 
 type RequestLoginCodeLogic struct {
@@ -24,7 +25,6 @@ type RequestLoginCodeLogic struct {
 }
 
 func NewRequestLoginCodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RequestLoginCodeLogic {
-	// No need to seed the global rand generator in Go 1.20+
 	return &RequestLoginCodeLogic{
 		Logger: logx.WithContext(ctx),
 		ctx:    ctx,
@@ -32,40 +32,64 @@ func NewRequestLoginCodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
-// generateLoginCode creates a random 6-digit numeric code
-func generateLoginCode() string {
-	// Use the default global rand source
-	code := rand.Intn(1000000)       // Generate a number between 0 and 999999
-	return fmt.Sprintf("%06d", code) // Pad with leading zeros if needed
-}
-
 func (l *RequestLoginCodeLogic) PostRequestLoginCode(c echo.Context, req *types.LoginCodeRequest) (resp *types.Response, err error) {
 	l.Logger.Infof("RequestLoginCode attempt for email: %s", req.Email)
 
-	// 1. Generate secure code
-	loginCode := generateLoginCode()
+	// Always return the same response so the endpoint cannot be used to enumerate accounts
+	resp = &types.Response{
+		Success: true,
+		Message: "If an account with that email exists, a login code has been sent.",
+	}
+
+	// 1. Throttle code emails per address and client; every request counts
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeEmailRequest, req.Email); err != nil {
+		l.Infof("RequestLoginCode for %s throttled", req.Email)
+		return nil, err
+	}
+	recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeEmailRequest, req.Email, nil)
 
-	// 2. Store code (Skipped - No Redis caching in this migration phase)
-	//    The verification step will need adjustment later.
-	l.Logger.Infof("Login code generated for %s: %s (Not stored persistently in this phase)", req.Email, loginCode)
+	// 2. Find the user; unknown or suspended accounts silently get no code
+	user, err := models.FindUserByEmail(l.svcCtx.DB, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			l.Infof("RequestLoginCode for unknown email %s, no code issued", req.Email)
+			return resp, nil
+		}
+		l.Errorf("Database error during RequestLoginCode for %s: %v", req.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login code request")
+	}
+	if user.AccountStatus == models.AccountStatusSuspended {
+		l.Infof("RequestLoginCode for suspended user %s, no code issued", user.ID)
+		return resp, nil
+	}
 
-	// 3. Send code via email (Placeholder/Log for now)
-	emailSubject := "Your Login Code"
-	emailBody := fmt.Sprintf("Your login code is: %s\n\nIt will expire in %d minutes.", loginCode, int(loginCodeExpiry.Minutes()))
+	// 3. Generate and store the hashed code
+	store := logincode.NewStore(l.svcCtx.RedisClient, l.svcCtx.DB, l.svcCtx.Config.Auth.AccessSecret)
+	loginCode, err := store.Issue(l.ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, logincode.ErrResendTooSoon) {
+			l.Infof("RequestLoginCode for user %s throttled: previous code sent too recently", user.ID)
+			return resp, nil
+		}
+		l.Errorf("Failed to issue login code for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login code request")
+	}
 
-	// TODO: Implement actual email sending using a communication service/library
-	// Example: err = l.svcCtx.EmailService.Send(req.Email, emailSubject, emailBody)
-	// if err != nil { l.Logger.Errorf(...) /* Handle error, maybe still return success? */ }
-	l.Logger.Infof("[Action Required] Send email to %s - Subject: %s - Body: %s", req.Email, emailSubject, emailBody)
-	// Use Errorf for logging warnings/errors if Warnf is not available
-	l.Logger.Errorf("Login code for %s: %s (Logging for testing/dev, not stored)", req.Email, loginCode)
+	// 4. Send code via email
+	emailSubject := "Your Login Code"
+	emailBody := fmt.Sprintf("Your login code is: %s\n\nIt will expire in %d minutes. If you did not request this code, you can ignore this email.",
+		loginCode, int(logincode.CodeTTL.Minutes()))
 
-	// 4. Return generic success response
-	resp = &types.Response{
-		Success: true,
-		Message: "If an account with that email exists, a login code has been sent.",
+	if err := l.svcCtx.SendEmail(l.ctx, user.Email, emailSubject, emailBody); err != nil {
+		if errors.Is(err, email.ErrNoEmailSender) && l.svcCtx.Config.Environment != "production" {
+			// Keep local development usable without an email provider
+			l.Infof("No email sender configured. Login code for %s: %s", user.Email, loginCode)
+			return resp, nil
+		}
+		l.Errorf("Failed to send login code email to user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not send login code")
 	}
 
-	l.Logger.Infof("Login code generated and email sending simulated for %s.", req.Email)
+	l.Infof("Login code sent to user %s", user.ID)
 	return resp, nil
 }
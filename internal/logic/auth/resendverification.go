@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type ResendVerificationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResendVerificationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResendVerificationLogic {
+	return &ResendVerificationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ResendVerificationLogic) PostResendVerification(c echo.Context, req *types.ResendVerificationRequest) (resp *types.Response, err error) {
+	// Same response whether or not the address is registered or already verified
+	resp = &types.Response{
+		Success: true,
+		Message: "If an unverified account with that email exists, a verification link has been sent.",
+	}
+
+	// 1. Throttle verification emails per address and client; every request counts
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeEmailRequest, req.Email); err != nil {
+		l.Infof("Verification email request for %s throttled", req.Email)
+		return nil, err
+	}
+	recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeEmailRequest, req.Email, nil)
+
+	// 2. Find the user; unknown and already verified accounts silently get nothing
+	user, err := models.FindUserByEmail(l.svcCtx.DB, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			l.Infof("Verification email requested for unknown email %s", req.Email)
+			return resp, nil
+		}
+		l.Errorf("Database error during verification email request for %s: %v", req.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process verification request")
+	}
+	if user.EmailVerified() {
+		l.Infof("Verification email requested for already verified user %s", user.ID)
+		return resp, nil
+	}
+
+	// 3. Issue a new link, invalidating the previous one
+	if err := sendVerificationEmail(l.ctx, l.svcCtx, user); err != nil {
+		l.Errorf("Failed to send verification email to user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not send verification email")
+	}
+
+	l.Infof("Verification email sent to user %s", user.ID)
+	return resp, nil
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/solotoabillion/stab/core/password"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/gorm"
@@ -43,21 +45,32 @@ func (l *ResetPasswordLogic) PostResetPassword(c echo.Context, req *types.ResetP
 	}
 	user := *userPtr // Dereference if found
 
-	// 2. Token is valid, update the password
-	if err := user.SetPassword(req.Password); err != nil {
-		l.Errorf("Error hashing new password during reset for user %s: %v", user.Email, err)
+	// 2. Token is valid, check the new password against the policy
+	policy := l.svcCtx.PasswordPolicy()
+	if err := password.Check(l.svcCtx.DB, policy, l.svcCtx.BreachedPasswords, &user, req.Password); err != nil {
+		var policyErr *password.PolicyError
+		if errors.As(err, &policyErr) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, policyErr.Message)
+		}
+		l.Errorf("Error checking new password during reset for user %s: %v", user.Email, err)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not update password")
 	}
 
-	// 3. Clear the reset token fields
-	// 3. Update password and clear token fields using model function
-	if err := models.UpdateUserPasswordAndClearToken(l.svcCtx.DB, user.ID, user.Password); err != nil {
+	// 3. Update password and clear token fields
+	if err := password.Update(l.svcCtx.DB, policy, &user, req.Password); err != nil {
 		l.Errorf("Failed to update user password and clear token for user %s: %v", user.Email, err)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not finalize password reset")
 	}
 
 	l.Infof("Password successfully reset for user %s", user.Email)
 
+	// 4. Sign out every device; whoever knew the old password must not stay logged in
+	if revoked, err := models.RevokeUserSessions(l.svcCtx.DB, user.ID, uuid.Nil, models.SessionRevokedPasswordReset); err != nil {
+		l.Errorf("Failed to revoke sessions after password reset for user %s: %v", user.Email, err)
+	} else if revoked > 0 {
+		l.Infof("Revoked %d sessions after password reset for user %s", revoked, user.Email)
+	}
+
 	// 5. Return success response
 	resp = &types.Response{
 		Success: true,
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/core/emailchange"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RevertEmailChangeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRevertEmailChangeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevertEmailChangeLogic {
+	return &RevertEmailChangeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostRevertEmailChange cancels a pending change, or restores the old address of an applied one,
+// using the link sent to the old address.
+func (l *RevertEmailChangeLogic) PostRevertEmailChange(c echo.Context, req *types.EmailChangeTokenRequest) (resp *types.Response, err error) {
+	// 1. Refuse clients that keep submitting bad tokens
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeEmailRevert, ""); err != nil {
+		l.Infof("Email change revert from %s throttled", c.RealIP())
+		return nil, err
+	}
+
+	// 2. Cancel or undo the change
+	change, err := emailchange.Revert(l.svcCtx.DB, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, emailchange.ErrInvalidToken):
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeEmailRevert, "", nil)
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired link")
+		case errors.Is(err, emailchange.ErrEmailTaken):
+			return nil, echo.NewHTTPError(http.StatusConflict, "Your previous email address is now used by another account")
+		}
+		l.Errorf("Failed to revert email change: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not revert email change")
+	}
+
+	if change.ConfirmedAt == nil {
+		l.Infof("Pending email change to %s cancelled for user %s", change.NewEmail, change.UserID)
+		return &types.Response{
+			Success: true,
+			Message: "The email change has been cancelled",
+		}, nil
+	}
+
+	// 3. The change was applied; restore billing receipts to the old address
+	syncStripeCustomerEmail(l.Logger, l.svcCtx, change.UserID)
+
+	l.Infof("Email change to %s reverted to %s for user %s", change.NewEmail, change.OldEmail, change.UserID)
+	return &types.Response{
+		Success: true,
+		Message: "Your email address has been restored and all devices were signed out. We recommend resetting your password.",
+	}, nil
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// findSSOTeam returns the team whose identity provider signs in users of the email's domain.
+// Teams whose plan no longer includes single sign-on are ignored.
+func findSSOTeam(svcCtx *svc.ServiceContext, email string) (*models.Team, error) {
+	team, err := models.FindSSOTeamByEmail(svcCtx.DB, email)
+	if err != nil {
+		return nil, err
+	}
+	planID, err := seats.PlanID(svcCtx.DB, team)
+	if err != nil {
+		return nil, err
+	}
+	if !svcCtx.SSOAvailableForPlan(planID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return team, nil
+}
+
+// checkPasswordAllowed refuses passwords for email domains whose team enforces single sign-on.
+func checkPasswordAllowed(ctx context.Context, svcCtx *svc.ServiceContext, email string) error {
+	team, err := findSSOTeam(svcCtx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		logx.WithContext(ctx).Errorf("Failed to look up single sign-on for %s: %v", email, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Database error during login")
+	}
+	if team.SSOEnforced {
+		return echo.NewHTTPError(http.StatusForbidden, "Your organization requires single sign-on. Continue with SSO to sign in.")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/oauth"
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type SSOCallbackLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSSOCallbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SSOCallbackLogic {
+	return &SSOCallbackLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetSSOCallback completes a sign-in through a team's identity provider. Users are
+// created and added to the team on their first sign-in.
+func (l *SSOCallbackLogic) GetSSOCallback(c echo.Context, req *types.SSOCallbackRequest) (resp *types.OAuthResponse, err error) {
+	// 1. The user may have declined at the identity provider
+	if req.Error != "" {
+		l.Infof("Single sign-on cancelled: %s", req.Error)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Sign-in was cancelled")
+	}
+
+	// 2. Check the state against the browser and the server-side record, then redeem the code
+	if !oauth.CheckStateCookie(c, req.State) {
+		l.Infof("Single sign-on callback with a state not bound to this browser")
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid state token")
+	}
+	var team *models.Team
+	store := oauth.NewStateStore(l.svcCtx.RedisClient, l.svcCtx.DB)
+	identity, _, err := oauth.CompleteTeam(l.ctx, store, req.State, req.Code, func(teamID uuid.UUID) (*oauth.Provider, error) {
+		found, err := models.FindTeamByID(l.svcCtx.DB, teamID)
+		if err != nil {
+			return nil, err
+		}
+		team = found
+		return oauth.NewTeamProvider(l.ctx, team, l.svcCtx.SSOCallbackURL())
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, oauth.ErrInvalidState):
+			l.Infof("Single sign-on callback with an unknown or expired state")
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid state token")
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, oauth.ErrProviderNotConfigured):
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Single sign-on is no longer enabled for this organization")
+		}
+		l.Errorf("Failed to complete single sign-on: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to sign in with your organization")
+	}
+
+	// 3. The identity provider only speaks for the domains its team verified
+	at := strings.LastIndex(identity.Email, "@")
+	if at < 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Your identity provider did not share an email address")
+	}
+	domain, err := models.FindVerifiedTeamDomain(l.svcCtx.DB, identity.Email[at+1:])
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to look up domain of %s: %v", identity.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Database error during login")
+	}
+	if err != nil || domain.TeamID != team.ID {
+		l.Infof("Team %s identity provider asserted %s outside its verified domains", team.ID, identity.Email)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Your email domain is not managed by this organization")
+	}
+	identity.EmailVerified = true
+
+	// 4. Find or create the user
+	user, err := resolveOAuthUser(l.ctx, l.svcCtx, identity)
+	if err != nil {
+		return nil, err
+	}
+	if user.AccountStatus == models.AccountStatusSuspended {
+		l.Infof("Single sign-on rejected for suspended user %s", user.ID)
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodSSO, user.Email, &user.ID, models.LoginFailureSuspended)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Account suspended")
+	}
+
+	// 5. Add the user to the team just in time, if the team's plan has a seat left
+	if _, created, err := seats.Join(l.svcCtx.DB, team, user.ID, team.SSODefaultRole); err != nil {
+		var limitErr *seats.LimitError
+		if errors.As(err, &limitErr) {
+			l.Infof("Single sign-on of user %s rejected: team %s has no seat left", user.ID, team.ID)
+			return nil, echo.NewHTTPError(http.StatusPaymentRequired, limitErr.Error())
+		}
+		l.Errorf("Failed to add user %s to team %s: %v", user.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to join your organization's team")
+	} else if created {
+		l.svcCtx.SyncSeats(team)
+		l.Infof("User %s joined team %s as %s through single sign-on", user.ID, team.ID, team.SSODefaultRole)
+	}
+
+	// 6. Issue the session, or an "mfa pending" token if a second factor is required
+	frontendURL := l.svcCtx.FrontendBaseURL()
+	login, err := completeLogin(c, l.svcCtx, user, models.LoginMethodSSO)
+	if err != nil {
+		l.Errorf("Failed to start session for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to generate session token")
+	}
+	if login.MfaRequired {
+		return &types.OAuthResponse{
+			Success:     true,
+			Message:     "Two-factor authentication required",
+			RedirectURL: fmt.Sprintf("%s/app#mfa_token=%s&user=%s", frontendURL, login.MfaToken, url.QueryEscape(user.Email)),
+		}, nil
+	}
+
+	// 7. Redirect back to frontend with token
+	l.Infof("Single sign-on successful for %s (team %s)", user.Email, team.ID)
+	return &types.OAuthResponse{
+		Success:     true,
+		Message:     "Successfully authenticated with your organization",
+		RedirectURL: fmt.Sprintf("%s/app#token=%s&user=%s", frontendURL, login.Token, url.QueryEscape(user.Email)),
+	}, nil
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/oauth"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type SSOLoginLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSSOLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SSOLoginLogic {
+	return &SSOLoginLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostSSOLogin looks up the team that manages the email's domain and returns the
+// authorization URL of the team's identity provider.
+func (l *SSOLoginLogic) PostSSOLogin(c echo.Context, req *types.SSOLoginRequest) (resp *types.OAuthResponse, err error) {
+	// 1. Route by email domain
+	team, err := findSSOTeam(l.svcCtx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Single sign-on is not set up for this email domain")
+		}
+		l.Errorf("Failed to look up single sign-on for %s: %v", req.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Database error during login")
+	}
+
+	// 2. Load the team's identity provider
+	provider, err := oauth.NewTeamProvider(l.ctx, team, l.svcCtx.SSOCallbackURL())
+	if err != nil {
+		l.Errorf("Failed to load identity provider of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusBadGateway, "Your organization's identity provider is unavailable")
+	}
+
+	// 3. Store the state, PKCE verifier and nonce server-side and bind the state to this browser
+	store := oauth.NewStateStore(l.svcCtx.RedisClient, l.svcCtx.DB)
+	authURL, state, err := oauth.Begin(l.ctx, store, provider, oauth.PendingAuth{TeamID: team.ID})
+	if err != nil {
+		l.Errorf("Failed to start single sign-on for team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not start sign-in")
+	}
+	oauth.SetStateCookie(c, state)
+
+	return &types.OAuthResponse{
+		Success:     true,
+		Message:     "Successfully generated authorization URL",
+		RedirectURL: authURL,
+	}, nil
+}
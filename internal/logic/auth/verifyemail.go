@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/core/emailverify"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type VerifyEmailLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewVerifyEmailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *VerifyEmailLogic {
+	return &VerifyEmailLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *VerifyEmailLogic) PostVerifyEmail(c echo.Context, req *types.VerifyEmailRequest) (resp *types.Response, err error) {
+	// 1. Refuse clients that keep submitting bad tokens
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeEmailToken, ""); err != nil {
+		l.Infof("Email verification from %s throttled", c.RealIP())
+		return nil, err
+	}
+
+	// 2. Redeem the token
+	user, err := emailverify.Verify(l.svcCtx.DB, req.Token)
+	if err != nil {
+		if errors.Is(err, emailverify.ErrInvalidToken) {
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeEmailToken, "", nil)
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid or expired verification link")
+		}
+		l.Errorf("Failed to verify email token: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not verify email address")
+	}
+
+	l.Infof("Email address verified for user %s", user.ID)
+	return &types.Response{
+		Success: true,
+		Message: "Email address verified",
+	}, nil
+}
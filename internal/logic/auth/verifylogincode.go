@@ -5,13 +5,12 @@ import (
 	"errors"
 	"net/http"
 
-	"github.com/solotoabillion/stab/core/security"
-	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/core/logincode"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/gorm"
@@ -31,20 +30,23 @@ func NewVerifyLoginCodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *V
 	}
 }
 
-func (l *VerifyLoginCodeLogic) PostVerifyLoginCode(c echo.Context, req *types.VerifyCodeRequest) (resp *types.Response, err error) {
+func (l *VerifyLoginCodeLogic) PostVerifyLoginCode(c echo.Context, req *types.VerifyCodeRequest) (resp *types.LoginResponse, err error) {
 	l.Logger.Infof("VerifyLoginCode attempt for email: %s", req.Email)
 
-	// TODO: Implement actual code verification.
-	// Currently bypassed because RequestLoginCode migration skipped storing the code (e.g., in Redis).
-	// This handler currently acts as a direct login mechanism based on email only if the user exists.
-	l.Logger.Errorf("Login code verification is currently BYPASSED for email: %s", req.Email) // Changed Warnf to Errorf
+	// 1. Refuse throttled accounts and clients
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeLoginCode, req.Email); err != nil {
+		l.Infof("VerifyLoginCode for %s throttled", req.Email)
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodCode, req.Email, nil, models.LoginFailureThrottled)
+		return nil, err
+	}
 
-	// 1. Find the user by email (since code verification is skipped)
-	// 1. Find the user by email using model function (since code verification is skipped)
+	// 2. Find the user by email using model function
 	userPtr, err := models.FindUserByEmail(l.svcCtx.DB, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			l.Infof("VerifyLoginCode failed for email %s: user not found", req.Email)
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeLoginCode, req.Email, nil)
+			recordLoginFailure(c, l.svcCtx, models.LoginMethodCode, req.Email, nil, models.LoginFailureUnknownAccount)
 			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid email or code.")
 		}
 		l.Errorf("Database error during VerifyLoginCode user lookup for %s: %v", req.Email, err)
@@ -52,27 +54,51 @@ func (l *VerifyLoginCodeLogic) PostVerifyLoginCode(c echo.Context, req *types.Ve
 	}
 	user := *userPtr // Dereference if found
 
-	// 2. User found, generate JWT (similar to LoginUser)
-	claims := jwt.MapClaims{
-		"id":    user.ID.String(),
-		"email": user.Email,
-		"role":  user.Role,
+	// 3. Verify and consume the code
+	store := logincode.NewStore(l.svcCtx.RedisClient, l.svcCtx.DB, l.svcCtx.Config.Auth.AccessSecret)
+	if err := store.Verify(l.ctx, user.ID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, logincode.ErrTooManyAttempts):
+			l.Infof("VerifyLoginCode failed for user %s: too many attempts, code burned", user.ID)
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeLoginCode, req.Email, &user)
+			recordLoginFailure(c, l.svcCtx, models.LoginMethodCode, req.Email, &user.ID, models.LoginFailureInvalidCredentials)
+			return nil, echo.NewHTTPError(http.StatusTooManyRequests, "Too many attempts. Please request a new code.")
+		case errors.Is(err, logincode.ErrCodeMismatch), errors.Is(err, logincode.ErrCodeNotFound):
+			l.Infof("VerifyLoginCode failed for user %s: %v", user.ID, err)
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeLoginCode, req.Email, &user)
+			recordLoginFailure(c, l.svcCtx, models.LoginMethodCode, req.Email, &user.ID, models.LoginFailureInvalidCredentials)
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid email or code.")
+		default:
+			l.Errorf("Error verifying login code for user %s: %v", user.ID, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not verify login code")
+		}
+	}
+	resetAttempts(c, l.svcCtx, bruteforce.ScopeLoginCode, req.Email)
+
+	if user.AccountStatus == models.AccountStatusSuspended {
+		l.Infof("VerifyLoginCode rejected for suspended user %s", user.ID)
+		recordLoginFailure(c, l.svcCtx, models.LoginMethodCode, req.Email, &user.ID, models.LoginFailureSuspended)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Account suspended")
 	}
-	tokenString, err := security.NewJWT(claims, l.svcCtx.Config.Auth.AccessSecret, l.svcCtx.Config.Auth.AccessExpire)
+
+	// 4. Receiving the code proves the user owns the address
+	if !user.EmailVerified() {
+		if err := models.MarkUserEmailVerified(l.svcCtx.DB, user.ID); err != nil {
+			l.Errorf("Failed to mark email verified for user %s: %v", user.ID, err)
+		}
+	}
+
+	// 5. Code accepted; issue the session or an "mfa pending" token (same as LoginUser)
+	resp, err = completeLogin(c, l.svcCtx, &user, models.LoginMethodCode)
 	if err != nil {
 		l.Errorf("Error generating JWT for user %s during VerifyLoginCode: %v", user.Email, err)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login (token generation)")
 	}
 
-	l.Infof("Passwordless login successful (verification bypassed) for: %s", user.Email)
-
-	// 3. Set auth cookie (similar to LoginUser)
-	session.SetCookie(l.svcCtx.Config, c, tokenString, l.svcCtx.Config.Auth.UserCookieName)
-
-	// 4. Return standard success response
-	resp = &types.Response{
-		Success: true,
-		Message: "Login successful", // Keep message consistent with password login
+	if resp.MfaRequired {
+		l.Infof("Login code accepted for %s, awaiting second factor", user.Email)
+	} else {
+		l.Infof("Passwordless login successful for: %s", user.Email)
 	}
 
 	return resp, nil
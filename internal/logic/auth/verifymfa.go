@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/bruteforce"
+	"github.com/solotoabillion/stab/core/mfa"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type VerifyMfaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewVerifyMfaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *VerifyMfaLogic {
+	return &VerifyMfaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostVerifyMfa completes a login that is waiting on the second factor.
+// It accepts either a TOTP code or a one-time recovery code together with the "mfa pending" token.
+func (l *VerifyMfaLogic) PostVerifyMfa(c echo.Context, req *types.MfaLoginRequest) (resp *types.LoginResponse, err error) {
+	// 1. Validate the pending token
+	userID, method, err := mfa.ParsePendingToken(l.svcCtx.Config, req.MfaToken)
+	if err != nil {
+		l.Infof("VerifyMfa failed: invalid or expired MFA token: %v", err)
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Your sign-in session has expired. Please log in again.")
+	}
+
+	if req.Code == "" && req.RecoveryCode == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "A code or recovery code is required")
+	}
+
+	// 2. Load the user
+	user, err := models.FindUserByID(l.svcCtx.DB, userID)
+	if err != nil {
+		l.Errorf("VerifyMfa failed to load user %s: %v", userID, err)
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Your sign-in session has expired. Please log in again.")
+	}
+	if user.AccountStatus == models.AccountStatusSuspended {
+		l.Infof("VerifyMfa rejected for suspended user %s", user.ID)
+		recordLoginFailure(c, l.svcCtx, method, user.Email, &user.ID, models.LoginFailureSuspended)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Account suspended")
+	}
+
+	// 3. Verify the second factor; failures have their own counter, which a correct
+	// password does not reset
+	if err := checkAttempts(c, l.svcCtx, bruteforce.ScopeMFA, user.Email); err != nil {
+		l.Infof("VerifyMfa for user %s throttled", user.ID)
+		recordLoginFailure(c, l.svcCtx, method, user.Email, &user.ID, models.LoginFailureThrottled)
+		return nil, err
+	}
+	if req.Code != "" {
+		err = mfa.VerifyTOTP(l.svcCtx.DB, user, req.Code)
+	} else {
+		err = mfa.VerifyRecoveryCode(l.svcCtx.DB, user, req.RecoveryCode)
+	}
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrNotEnrolled) {
+			l.Infof("VerifyMfa failed for user %s: %v", user.ID, err)
+			recordFailedAttempt(c, l.svcCtx, bruteforce.ScopeMFA, user.Email, user)
+			recordLoginFailure(c, l.svcCtx, method, user.Email, &user.ID, models.LoginFailureInvalidSecondFactor)
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid two-factor code")
+		}
+		l.Errorf("Error verifying second factor for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not verify two-factor code")
+	}
+	resetAttempts(c, l.svcCtx, bruteforce.ScopeMFA, user.Email)
+	if req.Code == "" {
+		l.Infof("User %s logged in with a recovery code", user.ID)
+	}
+
+	// 4. Second factor accepted, issue the session
+	resp, err = issueLogin(c, l.svcCtx, user, method)
+	if err != nil {
+		l.Errorf("Error generating JWT for user %s after MFA: %v", user.Email, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not process login (token generation)")
+	}
+
+	l.Infof("User logged in successfully with second factor: %s", user.Email)
+	return resp, nil
+}
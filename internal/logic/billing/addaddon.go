@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/solotoabillion/stab/middleware"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
@@ -39,16 +38,10 @@ func NewAddAddonLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AddAddon
 // but adding requires ItemType and ResourceID in the body. Assume 'req' contains these fields
 // (e.g., from a hypothetical 'types.AddAddonRequestBody' defined in the .api file).
 func (l *AddAddonLogic) PostAddAddon(c echo.Context, req *types.AddAddonRequestBody) (resp *types.AddonResponse, err error) { // Assuming AddAddonRequestBody exists
-	// 1. Get authenticated User from context
-	userCtx := c.Get(middleware.ContextUserKey)
-	if userCtx == nil {
-		l.Errorf("User not found in context for adding addon")
-		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
-	}
-	authedUser, ok := userCtx.(*models.User)
-	if !ok || authedUser == nil {
-		l.Errorf("User in context is not of type *models.User or is nil")
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Error retrieving user data")
+	// 1. Get the authenticated user and the account being billed
+	authedUser, subject, err := billingSubject(c)
+	if err != nil {
+		return nil, err
 	}
 	userID := authedUser.ID
 
@@ -89,13 +82,13 @@ func (l *AddAddonLogic) PostAddAddon(c echo.Context, req *types.AddAddonRequestB
 	// --- Start DB Transaction ---
 	// --- Refactored Logic (Stripe call outside transaction) ---
 
-	// 5. Fetch User's Active Subscription using model function
-	currentSubscriptionPtr, dbErr := models.FindLatestActiveSubscriptionByUserID(l.svcCtx.DB, userID)
+	// 5. Fetch the billed account's active subscription using model function
+	currentSubscriptionPtr, dbErr := models.FindLatestActiveSubscriptionBySubject(l.svcCtx.DB, subject.Type, subject.ID)
 	if dbErr != nil {
 		if errors.Is(dbErr, gorm.ErrRecordNotFound) {
 			return nil, echo.NewHTTPError(http.StatusNotFound, "No active subscription found to add item to")
 		}
-		l.Errorf("Error fetching active subscription for user %s: %v", userID, dbErr)
+		l.Errorf("Error fetching active subscription of %s for user %s: %v", subject.Reference(), userID, dbErr)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve subscription details")
 	}
 	currentSubscription := *currentSubscriptionPtr
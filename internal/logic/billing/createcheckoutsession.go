@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/solotoabillion/stab/middleware"
+	"github.com/solotoabillion/stab/core/seats"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
@@ -35,16 +35,10 @@ func NewCreateCheckoutSessionLogic(ctx context.Context, svcCtx *svc.ServiceConte
 
 // PostCreateCheckoutSession creates a Stripe Checkout session for subscribing to a plan.
 func (l *CreateCheckoutSessionLogic) PostCreateCheckoutSession(c echo.Context, req *types.CheckoutSessionRequest) (resp *types.CheckoutSessionResponse, err error) {
-	// 1. Get authenticated User from context
-	userCtx := c.Get(middleware.ContextUserKey)
-	if userCtx == nil {
-		l.Errorf("User not found in context for creating checkout session")
-		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
-	}
-	authedUser, ok := userCtx.(*models.User)
-	if !ok || authedUser == nil {
-		l.Errorf("User in context is not of type *models.User or is nil")
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Error retrieving user data")
+	// 1. Get the authenticated user and the account being billed
+	authedUser, subject, err := billingSubject(c)
+	if err != nil {
+		return nil, err
 	}
 	userID := authedUser.ID
 
@@ -56,7 +50,7 @@ func (l *CreateCheckoutSessionLogic) PostCreateCheckoutSession(c echo.Context, r
 	// Removed parsing req.PlanID as UUID as it's used directly with FindPlanByID.
 
 	// 3. Fetch User Details (needed for Stripe Customer ID/Email)
-	// User details are already available in authedUser from context.
+	// User details are already available in authedUser from context, the billed account in subject.
 
 	// 4. Fetch Plan Details from DB
 	// 4. Fetch Plan Details using model function
@@ -112,20 +106,28 @@ func (l *CreateCheckoutSessionLogic) PostCreateCheckoutSession(c echo.Context, r
 	successURL := frontendURL + "/app/billing?session_id={CHECKOUT_SESSION_ID}&status=success"
 	cancelURL := frontendURL + "/app/billing?status=cancel"
 
-	// 8. Create Stripe Checkout Session Params
+	// 8. Create Stripe Checkout Session Params; per-seat plans are billed for the seats in use
+	quantity := int64(1)
+	if plan.PerSeat {
+		if quantity, err = seats.Count(l.svcCtx.DB, subject.Type, subject.ID); err != nil {
+			l.Errorf("Failed to count seats of %s: %v", subject.Reference(), err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to initiate billing session")
+		}
+	}
 	params := &stripe.CheckoutSessionParams{
 		Mode:              stripe.String(string(stripe.CheckoutSessionModeSubscription)),
-		LineItems:         []*stripe.CheckoutSessionLineItemParams{{Price: stripe.String(stripePriceID), Quantity: stripe.Int64(1)}},
+		LineItems:         []*stripe.CheckoutSessionLineItemParams{{Price: stripe.String(stripePriceID), Quantity: stripe.Int64(quantity)}},
 		SuccessURL:        stripe.String(successURL),
 		CancelURL:         stripe.String(cancelURL),
-		ClientReferenceID: stripe.String(userID.String()), // Pass user UUID string
+		ClientReferenceID: stripe.String(subject.Reference()), // The user or team being billed
 		// Allow promotion codes if needed
 		// AllowPromotionCodes: stripe.Bool(true),
 		// Pre-fill email and potentially customer ID
 		CustomerEmail: stripe.String(authedUser.Email),
 	}
-	if authedUser.StripeCustomerID != nil && *authedUser.StripeCustomerID != "" {
-		params.Customer = authedUser.StripeCustomerID
+	params.AddMetadata("user_id", userID.String()) // Who subscribed, for team subscriptions
+	if subject.HasStripeCustomer() {
+		params.Customer = subject.StripeCustomerID
 		// If customer exists, don't send email again unless needed
 		params.CustomerEmail = nil
 	}
@@ -133,7 +135,7 @@ func (l *CreateCheckoutSessionLogic) PostCreateCheckoutSession(c echo.Context, r
 	// 9. Create Stripe Session
 	s, stripeErr := checkoutsession.New(params)
 	if stripeErr != nil {
-		l.Errorf("Failed to create Stripe checkout session for user %s, plan %s (%s): %v", userID.String(), plan.Name, stripePriceID, stripeErr)
+		l.Errorf("Failed to create Stripe checkout session for %s by user %s, plan %s (%s): %v", subject.Reference(), userID.String(), plan.Name, stripePriceID, stripeErr)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to initiate billing session: %s", stripeErr.Error()))
 	}
 
@@ -145,6 +147,6 @@ func (l *CreateCheckoutSessionLogic) PostCreateCheckoutSession(c echo.Context, r
 		URL:       s.URL,
 	}
 
-	l.Infof("Created Stripe checkout session %s for user %s, plan %s (%s)", s.ID, userID.String(), plan.Name, stripePriceID)
+	l.Infof("Created Stripe checkout session %s for %s by user %s, plan %s (%s)", s.ID, subject.Reference(), userID.String(), plan.Name, stripePriceID)
 	return resp, nil
 }
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/solotoabillion/stab/middleware"
-	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
 
@@ -33,27 +31,20 @@ func NewCreatePortalSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext
 
 // PostCreatePortalSession creates a Stripe Billing Portal session for the user.
 func (l *CreatePortalSessionLogic) PostCreatePortalSession(c echo.Context) (resp *types.PortalSessionResponse, err error) {
-	// 1. Get authenticated User from context
-	userCtx := c.Get(middleware.ContextUserKey)
-	if userCtx == nil {
-		l.Errorf("User not found in context for creating portal session")
-		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
-	}
-	authedUser, ok := userCtx.(*models.User)
-	if !ok || authedUser == nil {
-		l.Errorf("User in context is not of type *models.User or is nil")
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Error retrieving user data")
+	// 1. Get the authenticated user and the account being billed
+	authedUser, subject, err := billingSubject(c)
+	if err != nil {
+		return nil, err
 	}
 	userID := authedUser.ID
 
-	// 2. Check if user has a Stripe Customer ID
-	// User details (including StripeCustomerID) are already available in authedUser
-	if authedUser.StripeCustomerID == nil || *authedUser.StripeCustomerID == "" {
-		l.Infof("User %s attempted to access billing portal without a Stripe Customer ID", userID.String())
+	// 2. Check if the billed account has a Stripe Customer ID
+	if !subject.HasStripeCustomer() {
+		l.Infof("User %s attempted to access billing portal of %s without a Stripe Customer ID", userID.String(), subject.Reference())
 		// Use a more appropriate status code like Bad Request or Forbidden? Using Bad Request.
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "No active billing account found. Please subscribe to a plan first.")
 	}
-	stripeCustomerID := *authedUser.StripeCustomerID
+	stripeCustomerID := *subject.StripeCustomerID
 
 	// 3. Initialize Stripe client
 	stripe.Key = l.svcCtx.Config.Stripe.SecretKey
@@ -7,8 +7,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/solotoabillion/stab/middleware"
-	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
 
@@ -33,22 +31,16 @@ func NewInvoicesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Invoices
 }
 
 func (l *InvoicesLogic) GetInvoices(c echo.Context) (resp *types.InvoiceResponse, err error) {
-	// 1. Get authenticated User from context
-	userCtx := c.Get(middleware.ContextUserKey)
-	if userCtx == nil {
-		l.Errorf("User not found in context for getting invoices")
-		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
-	}
-	authedUser, ok := userCtx.(*models.User)
-	if !ok || authedUser == nil {
-		l.Errorf("User in context is not of type *models.User or is nil")
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Error retrieving user data")
+	// 1. Get the authenticated user and the account being billed
+	authedUser, subject, err := billingSubject(c)
+	if err != nil {
+		return nil, err
 	}
 	userID := authedUser.ID
 
-	// 2. Check if user has a Stripe Customer ID
-	if authedUser.StripeCustomerID == nil || *authedUser.StripeCustomerID == "" {
-		l.Infof("User %s attempted to fetch invoices without a Stripe Customer ID", userID.String())
+	// 2. Check if the billed account has a Stripe Customer ID
+	if !subject.HasStripeCustomer() {
+		l.Infof("User %s attempted to fetch invoices of %s without a Stripe Customer ID", userID.String(), subject.Reference())
 		// Return success with empty list as per old handler logic
 		return &types.InvoiceResponse{
 			Success:  true,
@@ -56,7 +48,7 @@ func (l *InvoicesLogic) GetInvoices(c echo.Context) (resp *types.InvoiceResponse
 			Invoices: []types.InvoiceItem{},
 		}, nil
 	}
-	stripeCustomerID := *authedUser.StripeCustomerID
+	stripeCustomerID := *subject.StripeCustomerID
 
 	// 3. Initialize Stripe client
 	stripe.Key = l.svcCtx.Config.Stripe.SecretKey
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/solotoabillion/stab/middleware"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
@@ -35,16 +34,10 @@ func NewRemoveAddonLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Remov
 }
 
 func (l *RemoveAddonLogic) DeleteRemoveAddon(c echo.Context, req *types.AddonRequest) (resp *types.AddonResponse, err error) {
-	// 1. Get authenticated User from context
-	userCtx := c.Get(middleware.ContextUserKey)
-	if userCtx == nil {
-		l.Errorf("User not found in context for removing addon")
-		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
-	}
-	authedUser, ok := userCtx.(*models.User)
-	if !ok || authedUser == nil {
-		l.Errorf("User in context is not of type *models.User or is nil")
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Error retrieving user data")
+	// 1. Get the authenticated user and the account being billed
+	authedUser, subject, err := billingSubject(c)
+	if err != nil {
+		return nil, err
 	}
 	userID := authedUser.ID
 
@@ -63,12 +56,12 @@ func (l *RemoveAddonLogic) DeleteRemoveAddon(c echo.Context, req *types.AddonReq
 	}
 
 	// 4. Find the Local SubscriptionItem Record
-	currentSubscriptionPtr, dbErr := models.FindLatestActiveSubscriptionByUserID(l.svcCtx.DB, userID)
+	currentSubscriptionPtr, dbErr := models.FindLatestActiveSubscriptionBySubject(l.svcCtx.DB, subject.Type, subject.ID)
 	if dbErr != nil {
 		if errors.Is(dbErr, gorm.ErrRecordNotFound) {
 			return nil, echo.NewHTTPError(http.StatusNotFound, "No active subscription found")
 		}
-		l.Errorf("Error fetching active subscription for user %s: %v", userID, dbErr)
+		l.Errorf("Error fetching active subscription of %s for user %s: %v", subject.Reference(), userID, dbErr)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve subscription details")
 	}
 	currentSubscription := *currentSubscriptionPtr
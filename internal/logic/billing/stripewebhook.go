@@ -144,9 +144,7 @@ func (l *StripeWebhookLogic) PostStripeWebhook(c echo.Context) (*types.Response,
 // --- Event Specific Handlers ---
 
 func (l *StripeWebhookLogic) handleCheckoutSessionCompleted(session stripe.CheckoutSession) error {
-	l.Infof("Processing checkout.session.completed: UserRef=%s, CustomerID=%s, SubscriptionID=%s", session.ClientReferenceID, session.Customer.ID, session.Subscription.ID)
-
-	userIDStr := session.ClientReferenceID
+	subjectRef := session.ClientReferenceID
 	stripeCustomerID := ""
 	if session.Customer != nil {
 		stripeCustomerID = session.Customer.ID
@@ -155,15 +153,26 @@ func (l *StripeWebhookLogic) handleCheckoutSessionCompleted(session stripe.Check
 	if session.Subscription != nil {
 		stripeSubscriptionID = session.Subscription.ID
 	}
+	l.Infof("Processing checkout.session.completed: SubjectRef=%s, CustomerID=%s, SubscriptionID=%s", subjectRef, stripeCustomerID, stripeSubscriptionID)
 
-	if userIDStr == "" || stripeCustomerID == "" || stripeSubscriptionID == "" {
-		l.Errorf("Missing critical IDs in checkout.session.completed event: UserRef=%s, CustomerID=%s, SubscriptionID=%s", userIDStr, stripeCustomerID, stripeSubscriptionID)
+	if subjectRef == "" || stripeCustomerID == "" || stripeSubscriptionID == "" {
+		l.Errorf("Missing critical IDs in checkout.session.completed event: SubjectRef=%s, CustomerID=%s, SubscriptionID=%s", subjectRef, stripeCustomerID, stripeSubscriptionID)
 		return echo.NewHTTPError(http.StatusBadRequest, "Webhook payload missing required IDs")
 	}
 
+	// The reference names the user or team billed; the metadata names who subscribed
+	subjectType, subjectID, err := models.ParseBillingReference(subjectRef)
+	if err != nil {
+		l.Errorf("Invalid billing subject in ClientReferenceID: %s, Error: %v", subjectRef, err)
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid billing subject in webhook")
+	}
+	userIDStr := session.Metadata["user_id"]
+	if userIDStr == "" && subjectType == models.BillingSubjectUser {
+		userIDStr = subjectID.String() // Checkouts started before teams could subscribe
+	}
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		l.Errorf("Invalid UserID UUID format in ClientReferenceID: %s, Error: %v", userIDStr, err)
+		l.Errorf("Invalid user_id metadata in checkout session %s: %q, Error: %v", session.ID, userIDStr, err)
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user identifier in webhook")
 	}
 
@@ -197,21 +206,21 @@ func (l *StripeWebhookLogic) handleCheckoutSessionCompleted(session stripe.Check
 
 	// Start DB transaction
 	txErr := l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
-		// Verify user exists and update Stripe Customer ID
-		var userExists models.User
-		// Use tx for transaction operations
-		if err := tx.Select("id").First(&userExists, "id = ?", userID).Error; err != nil {
-			l.Errorf("User %s not found during webhook processing: %v", userID, err)
-			return echo.NewHTTPError(http.StatusBadRequest, "User specified in webhook not found")
-		}
-		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("stripe_customer_id", stripeCustomerID).Error; err != nil {
-			l.Errorf("Failed to update user %s with Stripe Customer ID %s: %v", userID, stripeCustomerID, err)
-			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user billing info")
+		// Verify the billed user or team exists and update its Stripe Customer ID
+		if err := models.SetBillingCustomerID(tx, subjectType, subjectID, stripeCustomerID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				l.Errorf("%s not found during webhook processing", subjectRef)
+				return echo.NewHTTPError(http.StatusBadRequest, "Billing subject specified in webhook not found")
+			}
+			l.Errorf("Failed to update %s with Stripe Customer ID %s: %v", subjectRef, stripeCustomerID, err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update billing info")
 		}
 
 		// Create or update the subscription record
 		subscriptionRecord := models.Subscription{
 			UserID:               userID,
+			SubjectType:          subjectType,
+			SubjectID:            &subjectID,
 			PlanID:               plan.ID,
 			StripeSubscriptionID: stripeSubscriptionID,
 			Status:               string(sub.Status),
@@ -230,11 +239,11 @@ func (l *StripeWebhookLogic) handleCheckoutSessionCompleted(session stripe.Check
 	}) // End Transaction
 
 	if txErr != nil {
-		l.Errorf("Transaction failed for checkout.session.completed User %s, Subscription %s: %v", userID, stripeSubscriptionID, txErr)
+		l.Errorf("Transaction failed for checkout.session.completed %s, Subscription %s: %v", subjectRef, stripeSubscriptionID, txErr)
 		return txErr // Return the error bubbled up
 	}
 
-	l.Infof("Successfully processed checkout.session.completed for User %s, Subscription %s", userID, stripeSubscriptionID)
+	l.Infof("Successfully processed checkout.session.completed for %s by User %s, Subscription %s", subjectRef, userID, stripeSubscriptionID)
 	return nil
 }
 
@@ -0,0 +1,24 @@
+package billing
+
+import (
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// billingSubject returns the signed-in user and the account the request bills: the team
+// selected in the account token, or else the user's personal account. The route's
+// RequirePermission middleware has already checked the user may act on it.
+func billingSubject(c echo.Context) (*models.User, models.BillingSubject, error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, models.BillingSubject{}, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if account := session.AccountFromContext(c); account != nil {
+		return user, models.TeamBillingSubject(account.Team), nil
+	}
+	return user, models.UserBillingSubject(user), nil
+}
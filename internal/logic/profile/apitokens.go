@@ -0,0 +1,40 @@
+package profile
+
+import (
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/apitoken"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// personalScopes returns the scopes a user can grant their own tokens.
+// Only administrators can grant admin access.
+func personalScopes(user *models.User) []string {
+	if user.Role == models.SystemRoleAdmin {
+		return apitoken.ScopesFor()
+	}
+	return apitoken.ScopesFor(
+		apitoken.ResourceProfile,
+		apitoken.ResourceBilling,
+		apitoken.ResourceTeams,
+		apitoken.ResourceNotifications,
+		apitoken.ResourceDeveloper,
+		apitoken.ResourceKnowledgebase,
+	)
+}
+
+// requireInteractiveUser returns the signed-in user, refusing requests made with an
+// access token so a token cannot mint tokens with more access than itself.
+func requireInteractiveUser(c echo.Context) (*models.User, error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if session.APITokenFromContext(c) != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Access tokens can only be managed from a signed-in session")
+	}
+	return user, nil
+}
@@ -0,0 +1,51 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/accountdeletion"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CancelDeleteAccountLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCancelDeleteAccountLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CancelDeleteAccountLogic {
+	return &CancelDeleteAccountLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteCancelDeleteAccount withdraws a scheduled account deletion.
+func (l *CancelDeleteAccountLogic) DeleteCancelDeleteAccount(c echo.Context) (resp *types.Response, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	cancelled, err := accountdeletion.Cancel(l.svcCtx.DB, user)
+	if err != nil {
+		l.Errorf("Failed to cancel deletion of user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to cancel account deletion")
+	}
+	if !cancelled {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Your account is not scheduled for deletion")
+	}
+
+	l.Infof("User %s cancelled the deletion of their account", user.ID)
+	return &types.Response{
+		Success: true,
+		Message: "Account deletion cancelled. Subscriptions stay set to end with their period; resume them from billing.",
+	}, nil
+}
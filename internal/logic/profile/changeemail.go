@@ -3,8 +3,11 @@ package profile
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/solotoabillion/stab/core/emailchange"
 	"github.com/solotoabillion/stab/middleware" // Added for ContextUserKey
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
@@ -12,7 +15,6 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/zeromicro/go-zero/core/logx"
-	"gorm.io/gorm" // Added for gorm.ErrRecordNotFound
 )
 
 type ChangeEmailLogic struct {
@@ -29,6 +31,9 @@ func NewChangeEmailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Chang
 	}
 }
 
+// PostChangeEmail starts an email change. Nothing changes until the new address confirms it.
+// Users with a password prove their identity with it; users who only sign in through an
+// OAuth provider approve the change from their current address instead.
 func (l *ChangeEmailLogic) PostChangeEmail(c echo.Context, req *types.ChangeEmailRequest) (resp *types.Response, err error) { // Added req parameter
 	// 1. Get user from context
 	userCtx := c.Get(middleware.ContextUserKey)
@@ -42,50 +47,67 @@ func (l *ChangeEmailLogic) PostChangeEmail(c echo.Context, req *types.ChangeEmai
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Error retrieving user data")
 	}
 
-	// Note: Validation of req happens automatically via go-zero/echo handler generation if tags are correct in types.go
-
-	// 2. Verify current password
-	if !user.CheckPassword(req.Password) {
+	// 2. Verify current password, if the account has one
+	hasPassword := user.Password != nil && *user.Password != ""
+	if hasPassword && !user.CheckPassword(req.Password) {
 		l.Infof("Incorrect password provided for email change attempt by user %s", user.Email)
 		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Incorrect password provided")
 	}
 
 	// 3. Check if new email is the same as the old one
-	if user.Email == req.NewEmail {
+	if strings.EqualFold(user.Email, req.NewEmail) {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "New email address cannot be the same as the current one")
 	}
 
-	// 4. Check if new email is already in use by another user
-	var existingUser models.User
-	err = l.svcCtx.DB.Where("email = ?", req.NewEmail).First(&existingUser).Error
-	if err == nil {
-		// Email found, means it's already in use
-		l.Infof("Email change conflict for user %s: new email %s already exists.", user.Email, req.NewEmail)
-		return nil, echo.NewHTTPError(http.StatusConflict, "This email address is already in use")
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		// Database error during lookup
-		l.Errorf("DB error checking new email %s for user %s: %v", req.NewEmail, user.Email, err)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify new email address")
+	// 4. Record the pending change
+	_, tokens, err := emailchange.Start(l.svcCtx.DB, user, req.NewEmail, hasPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, emailchange.ErrEmailTaken):
+			l.Infof("Email change conflict for user %s: new email %s already exists.", user.Email, req.NewEmail)
+			return nil, echo.NewHTTPError(http.StatusConflict, "This email address is already in use")
+		case errors.Is(err, emailchange.ErrRequestTooSoon):
+			return nil, echo.NewHTTPError(http.StatusTooManyRequests, "Please wait a minute before requesting another email change")
+		}
+		l.Errorf("Failed to start email change for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not start email change")
 	}
-	// If ErrRecordNotFound, the email is available, proceed.
 
-	// 5. Update user's email
-	// TODO: Consider sending a verification email to the new address before finalizing the change
-	updateResult := l.svcCtx.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("email", req.NewEmail)
-	if updateResult.Error != nil {
-		l.Errorf("Error updating email for user %s to %s: %v", user.Email, req.NewEmail, updateResult.Error)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to update email address")
-	}
-	if updateResult.RowsAffected == 0 {
-		l.Infof("No rows affected when updating email for user %s (ID: %s)", user.Email, user.ID) // Changed from Warnf
-		return nil, echo.NewHTTPError(http.StatusNotFound, "User not found during update")
+	// 5. Send the confirmation link to the new address and the alert to the old one,
+	// or, without a password, the approval request to the old address
+	baseURL := l.svcCtx.FrontendBaseURL()
+	revertLink := emailchange.Link(baseURL, emailchange.ActionRevert, tokens.Revert)
+	if hasPassword {
+		confirmBody := fmt.Sprintf("Please confirm that you want to use this address for your account by opening the link below:\n\n%s\n\nThe link expires in %d hours. If you didn't request this, you can ignore this email.",
+			emailchange.Link(baseURL, emailchange.ActionConfirm, tokens.Confirm), int(emailchange.ConfirmTTL.Hours()))
+		if err := l.svcCtx.SendAccountEmail(l.ctx, req.NewEmail, "Confirm Your New Email Address", confirmBody); err != nil {
+			l.Errorf("Failed to send email change confirmation for user %s: %v", user.ID, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not send confirmation email")
+		}
+
+		alertBody := fmt.Sprintf("A request was made to change the email address of your account to %s. The change takes effect once the new address confirms it.\n\nIf this wasn't you, cancel or undo the change within %d days:\n\n%s\n\nWe also recommend changing your password.",
+			req.NewEmail, int(emailchange.RevertTTL.Hours()/24), revertLink)
+		if err := l.svcCtx.SendAccountEmail(l.ctx, user.Email, "Your Email Address Is Being Changed", alertBody); err != nil {
+			l.Errorf("Failed to send email change alert to user %s: %v", user.ID, err)
+		}
+	} else {
+		approveBody := fmt.Sprintf("A request was made to change the email address of your account to %s. To continue, approve the change:\n\n%s\n\nWe will then send a confirmation link to the new address.\n\nIf this wasn't you, cancel the request:\n\n%s",
+			req.NewEmail, emailchange.Link(baseURL, emailchange.ActionApprove, tokens.Approve), revertLink)
+		if err := l.svcCtx.SendAccountEmail(l.ctx, user.Email, "Approve Your Email Address Change", approveBody); err != nil {
+			l.Errorf("Failed to send email change approval to user %s: %v", user.ID, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not send approval email")
+		}
 	}
 
 	// 6. Return success
-	l.Infof("Successfully changed email for user %s to %s", user.ID, req.NewEmail)
+	l.Infof("Email change to %s requested for user %s", req.NewEmail, user.ID)
+	message := "Check your new email address for a link to confirm the change"
+	if !hasPassword {
+		message = "Check your current email address for a link to approve the change"
+	}
 	resp = &types.Response{
 		Success: true,
-		Message: "Email address updated successfully",
+		Message: message,
 	}
 	return resp, nil
 }
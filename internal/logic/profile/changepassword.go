@@ -0,0 +1,85 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/password"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ChangePasswordLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewChangePasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ChangePasswordLogic {
+	return &ChangePasswordLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostChangePassword sets a new password after checking the current one, and signs out
+// every other device. Users who only sign in through a provider can add a password here.
+func (l *ChangePasswordLogic) PostChangePassword(c echo.Context, req *types.ChangePasswordRequest) (resp *types.Response, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if session.APITokenFromContext(c) != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Passwords can only be changed from a signed-in session")
+	}
+
+	// 2. Verify the current password, if the account has one
+	if user.Password != nil && *user.Password != "" && !user.CheckPassword(req.CurrentPassword) {
+		l.Infof("Incorrect current password provided for password change by user %s", user.ID)
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Incorrect password provided")
+	}
+
+	// 3. Check the new password against the policy
+	policy := l.svcCtx.PasswordPolicy()
+	if err := password.Check(l.svcCtx.DB, policy, l.svcCtx.BreachedPasswords, user, req.NewPassword); err != nil {
+		var policyErr *password.PolicyError
+		if errors.As(err, &policyErr) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, policyErr.Message)
+		}
+		l.Errorf("Error checking new password for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not update password")
+	}
+
+	// 4. Store it
+	if err := password.Update(l.svcCtx.DB, policy, user, req.NewPassword); err != nil {
+		l.Errorf("Failed to update password for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not update password")
+	}
+
+	// 5. Sign out every other device
+	if revoked, err := models.RevokeUserSessions(l.svcCtx.DB, user.ID, session.SessionIDFromContext(c), models.SessionRevokedPasswordChange); err != nil {
+		l.Errorf("Failed to revoke sessions after password change for user %s: %v", user.ID, err)
+	} else if revoked > 0 {
+		l.Infof("Revoked %d sessions after password change for user %s", revoked, user.ID)
+	}
+
+	// 6. Let the user know in case it wasn't them
+	body := "The password of your account was just changed and your other devices were signed out.\n\nIf you didn't do this, reset your password right away and review your account's sessions."
+	if err := l.svcCtx.SendAccountEmail(l.ctx, user.Email, "Your Password Was Changed", body); err != nil {
+		l.Errorf("Failed to send password change alert to user %s: %v", user.ID, err)
+	}
+
+	l.Infof("Password changed for user %s", user.ID)
+	return &types.Response{
+		Success: true,
+		Message: "Password changed",
+	}, nil
+}
@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/mfa"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ConfirmTwoFactorLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewConfirmTwoFactorLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ConfirmTwoFactorLogic {
+	return &ConfirmTwoFactorLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostConfirmTwoFactor verifies the first code from the authenticator app, enables
+// two-factor authentication and returns a fresh set of recovery codes.
+func (l *ConfirmTwoFactorLogic) PostConfirmTwoFactor(c echo.Context, req *types.TwoFactorCodeRequest) (resp *types.RecoveryCodesResponse, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if mfa.Required(user) {
+		return nil, echo.NewHTTPError(http.StatusConflict, "Two-factor authentication is already enabled")
+	}
+
+	// 2. Verify the code against the pending secret
+	if err := mfa.VerifyTOTP(l.svcCtx.DB, user, req.Code); err != nil {
+		if errors.Is(err, mfa.ErrNotEnrolled) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Start two-factor enrollment first")
+		}
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid two-factor code")
+		}
+		l.Errorf("Error verifying TOTP code for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to confirm two-factor authentication")
+	}
+
+	// 3. Generate recovery codes, then enable 2FA
+	codes, err := mfa.GenerateRecoveryCodes(l.svcCtx.DB, user.ID)
+	if err != nil {
+		l.Errorf("Failed to generate recovery codes for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to confirm two-factor authentication")
+	}
+
+	settings, _ := user.GetSettings()
+	settings.TwoFactorEnabled = true
+	if err := user.UpdateSettings(l.svcCtx.DB, settings); err != nil {
+		l.Errorf("Failed to enable two-factor authentication for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to confirm two-factor authentication")
+	}
+
+	l.Infof("Two-factor authentication enabled for user %s", user.ID)
+	return &types.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
@@ -0,0 +1,64 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/solotoabillion/stab/core/apitoken"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateAPITokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateAPITokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateAPITokenLogic {
+	return &CreateAPITokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostCreateAPIToken issues a personal access token. The token is returned once and
+// cannot be retrieved later.
+func (l *CreateAPITokenLogic) PostCreateAPIToken(c echo.Context, req *types.CreateAPITokenRequest) (resp *types.CreateAPITokenResponse, err error) {
+	// 1. Tokens are managed from a signed-in session only
+	user, err := requireInteractiveUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate and store the token
+	allowed := personalScopes(user)
+	token, secret, err := apitoken.Issue(l.svcCtx.DB, user.ID, nil, apitoken.Spec{
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: req.ExpiresInDays,
+		IPAllowlist:   req.IPAllowlist,
+	}, func(scope string) bool { return slices.Contains(allowed, scope) })
+	if err != nil {
+		var invalid *apitoken.ValidationError
+		if errors.As(err, &invalid) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, invalid.Message)
+		}
+		l.Errorf("Failed to create access token for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create access token")
+	}
+
+	l.Infof("Access token %s created for user %s", token.ID, user.ID)
+	return &types.CreateAPITokenResponse{
+		Success: true,
+		Message: "Access token created. Copy it now; it will not be shown again.",
+		Token:   apitoken.Describe(*token),
+		Secret:  secret,
+	}, nil
+}
@@ -0,0 +1,32 @@
+package profile
+
+import (
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+)
+
+// describeDataExport converts an export for API responses, with its download link while it is ready.
+func describeDataExport(svcCtx *svc.ServiceContext, e *models.DataExport) *types.DataExport {
+	export := &types.DataExport{
+		ID:        e.ID.String(),
+		Status:    string(e.Status),
+		Size:      e.Size,
+		Error:     e.Error,
+		CreatedAt: e.CreatedAt.Format(time.RFC3339),
+	}
+	if e.CompletedAt != nil {
+		export.CompletedAt = e.CompletedAt.Format(time.RFC3339)
+	}
+	if e.Status == models.DataExportReady && e.ExpiresAt != nil {
+		if time.Now().After(*e.ExpiresAt) {
+			export.Status = "expired"
+		} else {
+			export.ExpiresAt = e.ExpiresAt.Format(time.RFC3339)
+			export.DownloadURL = svcCtx.DataExportLink(e)
+		}
+	}
+	return export
+}
@@ -0,0 +1,106 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/core/accountdeletion"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteAccountLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteAccountLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteAccountLogic {
+	return &DeleteAccountLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostDeleteAccount schedules the user's account for deletion. The account keeps working
+// during the grace period and the deletion can be cancelled until it ends. Teams the user
+// owns with other members must be handed to one of them first.
+func (l *DeleteAccountLogic) PostDeleteAccount(c echo.Context, req *types.DeleteAccountRequest) (resp *types.DeleteAccountResponse, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if session.APITokenFromContext(c) != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Accounts can only be deleted from a signed-in session")
+	}
+
+	// 2. Confirm the user means it
+	if !strings.EqualFold(strings.TrimSpace(req.Confirm), user.Email) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Type your email address to confirm the deletion")
+	}
+	if user.Password != nil && *user.Password != "" && !user.CheckPassword(req.Password) {
+		l.Infof("Incorrect password provided for account deletion by user %s", user.ID)
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Incorrect password provided")
+	}
+
+	transfers := make(map[uuid.UUID]uuid.UUID, len(req.Transfers))
+	for _, t := range req.Transfers {
+		teamID, err := uuid.Parse(t.TeamID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid team ID format")
+		}
+		newOwnerID, err := uuid.Parse(t.NewOwnerID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid new owner ID format")
+		}
+		transfers[teamID] = newOwnerID
+	}
+
+	// 3. Schedule the deletion
+	deleteAt, err := accountdeletion.Schedule(l.svcCtx.DB, l.svcCtx.Config, user, transfers)
+	if err != nil {
+		var owned *accountdeletion.OwnedTeamsError
+		var transfer *accountdeletion.TransferError
+		switch {
+		case errors.As(err, &owned):
+			names := make([]string, 0, len(owned.Teams))
+			for _, team := range owned.Teams {
+				names = append(names, team.Name)
+			}
+			return nil, echo.NewHTTPError(http.StatusConflict, "Choose a new owner for these teams first: "+strings.Join(names, ", "))
+		case errors.As(err, &transfer):
+			return nil, echo.NewHTTPError(http.StatusBadRequest, transfer.Message)
+		case errors.Is(err, accountdeletion.ErrAdministrator):
+			return nil, echo.NewHTTPError(http.StatusForbidden, "Administrators cannot delete their account")
+		case errors.Is(err, accountdeletion.ErrAlreadyScheduled):
+			return nil, echo.NewHTTPError(http.StatusConflict, "Your account is already scheduled for deletion")
+		}
+		l.Errorf("Failed to schedule deletion of user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete account")
+	}
+
+	// 4. Tell the user how to change their mind
+	body := fmt.Sprintf("Your account is scheduled for deletion on %s. Until then you can sign in and cancel the deletion from your account settings.\n\nAfter that date your account and its data are erased and cannot be recovered. Your subscriptions will not renew.\n\nIf you didn't request this, sign in, cancel the deletion and change your password.",
+		deleteAt.Format("January 2, 2006"))
+	if err := l.svcCtx.SendAccountEmail(l.ctx, user.Email, "Your Account Will Be Deleted", body); err != nil {
+		l.Errorf("Failed to send account deletion email to user %s: %v", user.ID, err)
+	}
+
+	l.Infof("User %s scheduled their account for deletion on %s", user.ID, deleteAt.Format(time.RFC3339))
+	return &types.DeleteAccountResponse{
+		Success:             true,
+		Message:             "Your account is scheduled for deletion",
+		DeletionScheduledAt: deleteAt.Format(time.RFC3339),
+	}, nil
+}
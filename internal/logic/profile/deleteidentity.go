@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DeleteIdentityLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteIdentityLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteIdentityLogic {
+	return &DeleteIdentityLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteIdentity disconnects a sign-in provider from the user's account. Email login
+// codes keep working, so the user cannot lock themselves out by removing the last one.
+func (l *DeleteIdentityLogic) DeleteIdentity(c echo.Context, req *types.LinkedIdentityRequest) (resp *types.Response, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	identityID, err := uuid.Parse(req.IdentityID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid identity ID")
+	}
+
+	if err := models.DeleteUserIdentity(l.svcCtx.DB, identityID, user.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Connected account not found")
+		}
+		l.Errorf("Failed to delete identity %s: %v", identityID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to disconnect account")
+	}
+
+	l.Infof("Identity %s disconnected from user %s", identityID, user.ID)
+	return &types.Response{Success: true, Message: "Account disconnected"}, nil
+}
@@ -0,0 +1,55 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DeletePasskeyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeletePasskeyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeletePasskeyLogic {
+	return &DeletePasskeyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeletePasskey removes one of the user's passkeys.
+func (l *DeletePasskeyLogic) DeletePasskey(c echo.Context, req *types.PasskeyRequest) (resp *types.Response, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	passkeyID, err := uuid.Parse(req.PasskeyID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid passkey ID")
+	}
+
+	if err := models.DeleteWebAuthnCredential(l.svcCtx.DB, passkeyID, user.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Passkey not found")
+		}
+		l.Errorf("Failed to delete passkey %s: %v", passkeyID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete passkey")
+	}
+
+	l.Infof("Passkey %s deleted for user %s", passkeyID, user.ID)
+	return &types.Response{Success: true, Message: "Passkey deleted"}, nil
+}
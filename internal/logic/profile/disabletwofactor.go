@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/mfa"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DisableTwoFactorLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDisableTwoFactorLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DisableTwoFactorLogic {
+	return &DisableTwoFactorLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostDisableTwoFactor turns off two-factor authentication after checking a
+// current TOTP code or recovery code, and removes the secret and recovery codes.
+func (l *DisableTwoFactorLogic) PostDisableTwoFactor(c echo.Context, req *types.TwoFactorCodeRequest) (resp *types.Response, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if !mfa.Required(user) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Two-factor authentication is not enabled")
+	}
+
+	// 2. Require proof of the second factor
+	if err := mfa.VerifyCode(l.svcCtx.DB, user, req.Code); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid two-factor code")
+		}
+		l.Errorf("Error verifying second factor for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to disable two-factor authentication")
+	}
+
+	// 3. Disable and clean up in one transaction
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		settings, _ := user.GetSettings()
+		settings.TwoFactorEnabled = false
+		if err := user.UpdateSettings(tx, settings); err != nil {
+			return err
+		}
+		if err := models.UpdateUserTOTPSecret(tx, user.ID, nil); err != nil {
+			return err
+		}
+		return models.DeleteRecoveryCodesByUser(tx, user.ID)
+	})
+	if err != nil {
+		l.Errorf("Failed to disable two-factor authentication for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to disable two-factor authentication")
+	}
+
+	l.Infof("Two-factor authentication disabled for user %s", user.ID)
+	return &types.Response{
+		Success: true,
+		Message: "Two-factor authentication disabled",
+	}, nil
+}
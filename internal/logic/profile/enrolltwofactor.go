@@ -0,0 +1,62 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/mfa"
+	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type EnrollTwoFactorLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewEnrollTwoFactorLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EnrollTwoFactorLogic {
+	return &EnrollTwoFactorLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostEnrollTwoFactor generates a new TOTP secret for the user.
+// Two-factor authentication stays disabled until the secret is confirmed with a valid code.
+func (l *EnrollTwoFactorLogic) PostEnrollTwoFactor(c echo.Context) (resp *types.TwoFactorEnrollResponse, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	// 2. Refuse to overwrite an active secret; the user must disable 2FA first
+	if mfa.Required(user) {
+		return nil, echo.NewHTTPError(http.StatusConflict, "Two-factor authentication is already enabled")
+	}
+
+	// 3. Generate and store the pending secret
+	secret, err := security.NewTOTPSecret()
+	if err != nil {
+		l.Errorf("Failed to generate TOTP secret for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start two-factor enrollment")
+	}
+	if err := models.UpdateUserTOTPSecret(l.svcCtx.DB, user.ID, &secret); err != nil {
+		l.Errorf("Failed to store TOTP secret for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start two-factor enrollment")
+	}
+
+	l.Infof("Two-factor enrollment started for user %s", user.ID)
+	return &types.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: security.TOTPAuthURI(mfa.Issuer(l.svcCtx.Config), user.Email, secret),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type GetDataExportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetDataExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetDataExportLogic {
+	return &GetDataExportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetDataExport returns the user's most recent data export and, once it is ready, its download link.
+func (l *GetDataExportLogic) GetDataExport(c echo.Context) (resp *types.DataExportResponse, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if session.APITokenFromContext(c) != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Data exports are only available from a signed-in session")
+	}
+
+	resp = &types.DataExportResponse{Success: true, Message: "No data export requested"}
+	export, err := models.FindLatestDataExportByUser(l.svcCtx.DB, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resp, nil
+		}
+		l.Errorf("Failed to load data export of user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load data export")
+	}
+	resp.Message = "Data export retrieved"
+	resp.Export = describeDataExport(l.svcCtx, export)
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/oauth"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type LinkIdentityLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewLinkIdentityLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LinkIdentityLogic {
+	return &LinkIdentityLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostLinkIdentity starts connecting a sign-in provider to the user's account. The provider
+// redirects to the usual callback, which links the identity instead of signing in.
+func (l *LinkIdentityLogic) PostLinkIdentity(c echo.Context, req *types.OAuthProviderRequest) (resp *types.OAuthResponse, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	provider, err := oauth.NewRegistry(l.svcCtx.Config, l.svcCtx.Settings).Get(l.ctx, req.Provider)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) || errors.Is(err, oauth.ErrProviderNotConfigured) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Unknown sign-in provider")
+		}
+		l.Errorf("Failed to load OAuth provider %s: %v", req.Provider, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Sign-in provider unavailable")
+	}
+
+	store := oauth.NewStateStore(l.svcCtx.RedisClient, l.svcCtx.DB)
+	authURL, state, err := oauth.Begin(l.ctx, store, provider, oauth.PendingAuth{LinkUserID: user.ID})
+	if err != nil {
+		l.Errorf("Failed to start linking %s for user %s: %v", provider.Name, user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Could not connect account")
+	}
+	oauth.SetStateCookie(c, state)
+
+	return &types.OAuthResponse{
+		Success:     true,
+		Message:     "Successfully generated authorization URL",
+		RedirectURL: authURL,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/apitoken"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListAPITokensLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListAPITokensLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListAPITokensLogic {
+	return &ListAPITokensLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListAPITokens returns the user's personal access tokens and the scopes they can grant.
+func (l *ListAPITokensLogic) GetListAPITokens(c echo.Context) (resp *types.ListAPITokensResponse, err error) {
+	user, err := requireInteractiveUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := models.FindActiveAPITokensByUser(l.svcCtx.DB, user.ID)
+	if err != nil {
+		l.Errorf("Failed to load access tokens for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load access tokens")
+	}
+
+	resp = &types.ListAPITokensResponse{
+		Tokens:          make([]types.APIToken, 0, len(tokens)),
+		AvailableScopes: personalScopes(user),
+	}
+	for _, t := range tokens {
+		resp.Tokens = append(resp.Tokens, apitoken.Describe(t))
+	}
+	return resp, nil
+}
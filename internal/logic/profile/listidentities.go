@@ -0,0 +1,58 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListIdentitiesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListIdentitiesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListIdentitiesLogic {
+	return &ListIdentitiesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListIdentities returns the sign-in providers connected to the user's account.
+func (l *ListIdentitiesLogic) GetListIdentities(c echo.Context) (resp *types.LinkedIdentitiesResponse, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	identities, err := models.FindUserIdentitiesByUser(l.svcCtx.DB, user.ID)
+	if err != nil {
+		l.Errorf("Failed to load linked identities for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load connected accounts")
+	}
+
+	resp = &types.LinkedIdentitiesResponse{Identities: make([]types.LinkedIdentity, 0, len(identities))}
+	for _, identity := range identities {
+		item := types.LinkedIdentity{
+			ID:        identity.ID.String(),
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt.Format(time.RFC3339),
+		}
+		if identity.LastLoginAt != nil {
+			item.LastLoginAt = identity.LastLoginAt.Format(time.RFC3339)
+		}
+		resp.Identities = append(resp.Identities, item)
+	}
+	return resp, nil
+}
@@ -0,0 +1,63 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListPasskeysLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListPasskeysLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListPasskeysLogic {
+	return &ListPasskeysLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListPasskeys returns the passkeys registered by the current user.
+func (l *ListPasskeysLogic) GetListPasskeys(c echo.Context) (resp *types.PasskeysResponse, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	creds, err := models.FindWebAuthnCredentialsByUser(l.svcCtx.DB, user.ID)
+	if err != nil {
+		l.Errorf("Failed to load passkeys for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load passkeys")
+	}
+
+	resp = &types.PasskeysResponse{Passkeys: make([]types.Passkey, 0, len(creds))}
+	for i := range creds {
+		resp.Passkeys = append(resp.Passkeys, toPasskey(&creds[i]))
+	}
+	return resp, nil
+}
+
+func toPasskey(cred *models.WebAuthnCredential) types.Passkey {
+	passkey := types.Passkey{
+		ID:             cred.ID.String(),
+		Name:           cred.Name,
+		BackupEligible: cred.BackupEligible,
+		BackedUp:       cred.BackedUp,
+		CreatedAt:      cred.CreatedAt.Format(time.RFC3339),
+	}
+	if cred.LastUsedAt != nil {
+		passkey.LastUsedAt = cred.LastUsedAt.Format(time.RFC3339)
+	}
+	return passkey
+}
@@ -0,0 +1,57 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListSessionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListSessionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListSessionsLogic {
+	return &ListSessionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListSessions returns the devices the user is currently signed in on.
+func (l *ListSessionsLogic) GetListSessions(c echo.Context) (resp *types.UserSessionsResponse, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	sessions, err := models.FindActiveUserSessionsByUser(l.svcCtx.DB, user.ID)
+	if err != nil {
+		l.Errorf("Failed to load sessions for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load sessions")
+	}
+
+	currentID := session.SessionIDFromContext(c)
+	resp = &types.UserSessionsResponse{Sessions: make([]types.UserSession, 0, len(sessions))}
+	for _, s := range sessions {
+		resp.Sessions = append(resp.Sessions, types.UserSession{
+			ID:         s.ID.String(),
+			IPAddress:  s.IPAddress,
+			UserAgent:  s.UserAgent,
+			LastSeenAt: s.LastSeenAt.Format(time.RFC3339),
+			CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+			Current:    s.ID == currentID,
+		})
+	}
+	return resp, nil
+}
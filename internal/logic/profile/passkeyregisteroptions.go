@@ -0,0 +1,73 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/core/webauthn"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PasskeyRegisterOptionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPasskeyRegisterOptionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PasskeyRegisterOptionsLogic {
+	return &PasskeyRegisterOptionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostPasskeyRegisterOptions starts a passkey registration ceremony.
+// The returned options are passed to navigator.credentials.create() and the session ID echoed back on completion.
+func (l *PasskeyRegisterOptionsLogic) PostPasskeyRegisterOptions(c echo.Context) (resp *types.PasskeyOptionsResponse, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	// 2. Exclude credentials the user already registered so the same authenticator is not added twice
+	existing, err := models.FindWebAuthnCredentialsByUser(l.svcCtx.DB, user.ID)
+	if err != nil {
+		l.Errorf("Failed to load passkeys for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start passkey registration")
+	}
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(existing))
+	for _, cred := range existing {
+		exclude = append(exclude, webauthn.NewCredentialDescriptor(cred.CredentialID, cred.TransportList()))
+	}
+
+	// 3. Store the challenge server-side
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		l.Errorf("Failed to generate passkey challenge: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start passkey registration")
+	}
+	sessionID, err := webauthn.NewSessionStore(l.svcCtx.RedisClient, l.svcCtx.DB).Save(l.ctx, webauthn.Session{
+		UserID:    user.ID,
+		Purpose:   webauthn.PurposeRegister,
+		Challenge: challenge,
+	})
+	if err != nil {
+		l.Errorf("Failed to store passkey session for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to start passkey registration")
+	}
+
+	// 4. Build the creation options; the user handle is the opaque user ID
+	rp := webauthn.ConfigFromApp(l.svcCtx.Config)
+	return &types.PasskeyOptionsResponse{
+		SessionID: sessionID,
+		PublicKey: rp.NewCreationOptions(challenge, user.ID[:], user.Email, user.Email, exclude),
+	}, nil
+}
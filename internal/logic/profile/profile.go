@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
 
@@ -29,7 +30,7 @@ func NewProfileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ProfileLo
 }
 
 func (l *ProfileLogic) GetProfile(c echo.Context) (resp *types.User, err error) {
-	// 1. Get user from context (set by UserGuardMiddleware)
+	// 1. Get user from context (set by AuthMiddleware)
 	user := session.UserFromContext(c)
 	if user == nil {
 		l.Errorf("User not found in context")
@@ -52,12 +53,23 @@ func (l *ProfileLogic) GetProfile(c echo.Context) (resp *types.User, err error)
 		Email:            user.Email,
 		ProfileData:      profileData, // Assign the unmarshalled struct
 		Role:             string(user.Role),
-		ApiKey:           user.ApiKey,
 		DefaultSubdomain: user.DefaultSubdomain,
 		AccountStatus:    string(user.AccountStatus),
+		EmailVerified:    user.EmailVerified(),
 		CreatedAt:        user.CreatedAt.Format(time.RFC3339), // Format time
 		UpdatedAt:        user.UpdatedAt.Format(time.RFC3339), // Format time
 	}
+	if user.DeletionScheduledAt != nil {
+		resp.DeletionScheduledAt = user.DeletionScheduledAt.Format(time.RFC3339)
+	}
+
+	// 4. Flag impersonation so the frontend can show a banner
+	if admin := session.ImpersonatorFromContext(c); admin != nil {
+		resp.Impersonation = &types.Impersonation{AdminID: admin.ID.String(), AdminEmail: admin.Email}
+		if sess, err := models.FindUserSessionByID(l.svcCtx.DB, session.SessionIDFromContext(c)); err == nil {
+			resp.Impersonation.ExpiresAt = sess.ExpiresAt.Format(time.RFC3339)
+		}
+	}
 
 	l.Infof("Successfully retrieved profile for user %s", user.Email)
 	return resp, nil
@@ -0,0 +1,61 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/mfa"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RegenerateRecoveryCodesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRegenerateRecoveryCodesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RegenerateRecoveryCodesLogic {
+	return &RegenerateRecoveryCodesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostRegenerateRecoveryCodes invalidates all existing recovery codes and returns a new set.
+// A current TOTP code is required so a hijacked session cannot mint new codes.
+func (l *RegenerateRecoveryCodesLogic) PostRegenerateRecoveryCodes(c echo.Context, req *types.TwoFactorCodeRequest) (resp *types.RecoveryCodesResponse, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if !mfa.Required(user) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Two-factor authentication is not enabled")
+	}
+
+	// 2. Verify the TOTP code
+	if err := mfa.VerifyTOTP(l.svcCtx.DB, user, req.Code); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid two-factor code")
+		}
+		l.Errorf("Error verifying TOTP code for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to regenerate recovery codes")
+	}
+
+	// 3. Replace the codes
+	codes, err := mfa.GenerateRecoveryCodes(l.svcCtx.DB, user.ID)
+	if err != nil {
+		l.Errorf("Failed to regenerate recovery codes for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to regenerate recovery codes")
+	}
+
+	l.Infof("Recovery codes regenerated for user %s", user.ID)
+	return &types.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
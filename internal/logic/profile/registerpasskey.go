@@ -0,0 +1,103 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/core/webauthn"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RegisterPasskeyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRegisterPasskeyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RegisterPasskeyLogic {
+	return &RegisterPasskeyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostRegisterPasskey completes a passkey registration ceremony and stores the new credential.
+func (l *RegisterPasskeyLogic) PostRegisterPasskey(c echo.Context, req *types.RegisterPasskeyRequest) (resp *types.Passkey, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	// 2. Consume the ceremony; it must have been started by this user
+	sess, err := webauthn.NewSessionStore(l.svcCtx.RedisClient, l.svcCtx.DB).Take(l.ctx, req.SessionID, webauthn.PurposeRegister)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSessionNotFound) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Passkey registration expired. Please try again.")
+		}
+		l.Errorf("Failed to load passkey session: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to register passkey")
+	}
+	if sess.UserID != user.ID {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Passkey registration expired. Please try again.")
+	}
+
+	// 3. Verify the authenticator response
+	clientDataJSON, err := webauthn.DecodeString(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid passkey response")
+	}
+	attestationObject, err := webauthn.DecodeString(req.Credential.Response.AttestationObject)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid passkey response")
+	}
+	rp := webauthn.ConfigFromApp(l.svcCtx.Config)
+	ad, err := rp.VerifyRegistration(sess.Challenge, clientDataJSON, attestationObject)
+	if err != nil {
+		l.Infof("Passkey registration rejected for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Passkey could not be verified")
+	}
+
+	// 4. Refuse credentials that are already registered (to this or another account)
+	if _, err := models.FindWebAuthnCredentialByCredentialID(l.svcCtx.DB, ad.CredentialID); err == nil {
+		return nil, echo.NewHTTPError(http.StatusConflict, "This passkey is already registered")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to check passkey uniqueness: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to register passkey")
+	}
+
+	// 5. Store the credential
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	cred := &models.WebAuthnCredential{
+		UserID:         user.ID,
+		Name:           name,
+		CredentialID:   ad.CredentialID,
+		PublicKey:      ad.PublicKey,
+		SignCount:      int64(ad.SignCount),
+		AAGUID:         ad.AAGUID,
+		Transports:     strings.Join(req.Credential.Response.Transports, ","),
+		BackupEligible: ad.BackupEligible(),
+		BackedUp:       ad.BackedUp(),
+	}
+	if err := models.CreateWebAuthnCredential(l.svcCtx.DB, cred); err != nil {
+		l.Errorf("Failed to store passkey for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to register passkey")
+	}
+
+	l.Infof("Passkey %s registered for user %s", cred.ID, user.ID)
+	passkey := toPasskey(cred)
+	return &passkey, nil
+}
@@ -0,0 +1,65 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RenamePasskeyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRenamePasskeyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RenamePasskeyLogic {
+	return &RenamePasskeyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PatchRenamePasskey changes the display name of one of the user's passkeys.
+func (l *RenamePasskeyLogic) PatchRenamePasskey(c echo.Context, req *types.RenamePasskeyRequest) (resp *types.Passkey, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	passkeyID, err := uuid.Parse(req.PasskeyID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid passkey ID")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Name is required")
+	}
+
+	if err := models.UpdateWebAuthnCredentialName(l.svcCtx.DB, passkeyID, user.ID, name); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Passkey not found")
+		}
+		l.Errorf("Failed to rename passkey %s: %v", passkeyID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to rename passkey")
+	}
+
+	cred, err := models.FindWebAuthnCredentialByIDAndUser(l.svcCtx.DB, passkeyID, user.ID)
+	if err != nil {
+		l.Errorf("Failed to reload passkey %s: %v", passkeyID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to rename passkey")
+	}
+	passkey := toPasskey(cred)
+	return &passkey, nil
+}
@@ -0,0 +1,74 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/core/dataexport"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RequestDataExportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRequestDataExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RequestDataExportLogic {
+	return &RequestDataExportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostRequestDataExport queues a copy of the user's data. A background job builds the
+// archive and emails the user a signed download link.
+func (l *RequestDataExportLogic) PostRequestDataExport(c echo.Context) (resp *types.DataExportResponse, err error) {
+	// 1. Get user from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	if session.APITokenFromContext(c) != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Data exports are only available from a signed-in session")
+	}
+
+	// 2. One export at a time, and at most one a day
+	latest, err := models.FindLatestDataExportByUser(l.svcCtx.DB, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to load data export of user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to request data export")
+	}
+	if latest != nil {
+		switch {
+		case latest.Status == models.DataExportPending || latest.Status == models.DataExportProcessing:
+			return nil, echo.NewHTTPError(http.StatusConflict, "Your data export is already being prepared")
+		case latest.Status == models.DataExportReady && time.Since(latest.CreatedAt) < dataexport.MinInterval:
+			return nil, echo.NewHTTPError(http.StatusTooManyRequests, "You can request one data export per day")
+		}
+	}
+
+	// 3. Queue the export
+	export := &models.DataExport{UserID: user.ID, Status: models.DataExportPending}
+	if err := models.CreateDataExport(l.svcCtx.DB, export); err != nil {
+		l.Errorf("Failed to queue data export for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to request data export")
+	}
+
+	l.Infof("Data export %s requested by user %s", export.ID, user.ID)
+	return &types.DataExportResponse{
+		Success: true,
+		Message: "We are preparing your data and will email you a download link when it is ready",
+		Export:  describeDataExport(l.svcCtx, export),
+	}, nil
+}
@@ -0,0 +1,45 @@
+package profile
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RevokeAllSessionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRevokeAllSessionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeAllSessionsLogic {
+	return &RevokeAllSessionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostRevokeAllSessions signs the user out of every device except the current one.
+func (l *RevokeAllSessionsLogic) PostRevokeAllSessions(c echo.Context) (resp *types.Response, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	revoked, err := models.RevokeUserSessions(l.svcCtx.DB, user.ID, session.SessionIDFromContext(c), models.SessionRevokedByUser)
+	if err != nil {
+		l.Errorf("Failed to revoke sessions for user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to revoke sessions")
+	}
+
+	l.Infof("User %s revoked %d other sessions", user.ID, revoked)
+	return &types.Response{Success: true, Message: "Signed out of all other devices"}, nil
+}
@@ -0,0 +1,53 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RevokeAPITokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRevokeAPITokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeAPITokenLogic {
+	return &RevokeAPITokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteRevokeAPIToken revokes one of the user's personal access tokens.
+func (l *RevokeAPITokenLogic) DeleteRevokeAPIToken(c echo.Context, req *types.APITokenRequest) (resp *types.Response, err error) {
+	user, err := requireInteractiveUser(c)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := uuid.Parse(req.TokenID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid token ID")
+	}
+
+	if err := models.RevokeUserAPIToken(l.svcCtx.DB, tokenID, user.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Token not found")
+		}
+		l.Errorf("Failed to revoke access token %s of user %s: %v", tokenID, user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to revoke access token")
+	}
+
+	l.Infof("Access token %s of user %s revoked", tokenID, user.ID)
+	return &types.Response{Success: true, Message: "Access token revoked"}, nil
+}
@@ -0,0 +1,55 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RevokeSessionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRevokeSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeSessionLogic {
+	return &RevokeSessionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteRevokeSession signs the user out of one device.
+func (l *RevokeSessionLogic) DeleteRevokeSession(c echo.Context, req *types.UserSessionRequest) (resp *types.Response, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+
+	sessionID, err := uuid.Parse(req.SessionID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid session ID")
+	}
+
+	if err := models.RevokeUserSession(l.svcCtx.DB, sessionID, user.ID, models.SessionRevokedByUser); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Session not found")
+		}
+		l.Errorf("Failed to revoke session %s: %v", sessionID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to revoke session")
+	}
+
+	l.Infof("Session %s revoked by user %s", sessionID, user.ID)
+	return &types.Response{Success: true, Message: "Session revoked"}, nil
+}
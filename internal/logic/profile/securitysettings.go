@@ -2,8 +2,11 @@ package profile
 
 import (
 	"context"
+	"time"
 
+	"github.com/solotoabillion/stab/core/loginhistory"
 	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
 	"github.com/solotoabillion/stab/types"
 
@@ -28,8 +31,21 @@ func NewSecuritySettingsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 func (l *SecuritySettingsLogic) GetSecuritySettings(c echo.Context) (resp *types.SecuritySettings, err error) {
 	user := session.UserFromContext(c)
 	settings, _ := user.GetSettings()
-	return &types.SecuritySettings{
-		TwoFactorEnabled:   settings.TwoFactorEnabled,
-		LastPasswordChange: "", // Add logic if you track this
-	}, nil
+	remaining, err := models.CountUnusedRecoveryCodes(l.svcCtx.DB, user.ID)
+	if err != nil {
+		l.Errorf("Failed to count recovery codes for user %s: %v", user.ID, err)
+	}
+	resp = &types.SecuritySettings{
+		TwoFactorEnabled:       settings.TwoFactorEnabled,
+		RecoveryCodesRemaining: int(remaining),
+	}
+	if user.PasswordChangedAt != nil {
+		resp.LastPasswordChange = user.PasswordChangedAt.Format(time.RFC3339)
+	}
+	events, err := models.FindLoginEventsByUser(l.svcCtx.DB, user.ID, loginhistory.HistoryLimit)
+	if err != nil {
+		l.Errorf("Failed to load login history for user %s: %v", user.ID, err)
+	}
+	resp.LoginHistory = loginhistory.Describe(events)
+	return resp, nil
 }
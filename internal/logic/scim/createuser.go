@@ -0,0 +1,38 @@
+package scim
+
+import (
+	"context"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateUserLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateUserLogic {
+	return &CreateUserLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostCreateUser provisions a person to the team.
+func (l *CreateUserLogic) PostCreateUser(c echo.Context, req *scimcore.User) (*scimcore.User, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	m, err := createMember(l.ctx, l.svcCtx, team, req)
+	if err != nil {
+		return nil, err
+	}
+	return toUser(l.svcCtx, m), nil
+}
@@ -0,0 +1,49 @@
+package scim
+
+import (
+	"context"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteUserLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteUserLogic {
+	return &DeleteUserLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteUser deprovisions a member and forgets the membership, so the resource no
+// longer exists. The account is kept, suspended if it has no team left.
+func (l *DeleteUserLogic) DeleteUser(c echo.Context, id string) error {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return err
+	}
+	m, err := findMember(l.svcCtx.DB, team, id)
+	if err != nil {
+		return err
+	}
+
+	if !m.DeletedAt.Valid {
+		if err := deprovision(l.ctx, l.svcCtx, team, &m.User); err != nil {
+			return err
+		}
+	}
+	if err := models.PurgeMembershipByUserAndTeam(l.svcCtx.DB, m.UserID, team.ID); err != nil {
+		l.Errorf("Failed to purge membership of user %s in team %s: %v", m.UserID, team.ID, err)
+		return errInternal
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package scim
+
+import (
+	"context"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetGroupLogic {
+	return &GetGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetGroup returns a role group with its members.
+func (l *GetGroupLogic) GetGroup(c echo.Context, id string) (*scimcore.Group, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	role, err := groupRole(id)
+	if err != nil {
+		return nil, err
+	}
+	return loadGroup(l.svcCtx, team, role)
+}
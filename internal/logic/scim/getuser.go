@@ -0,0 +1,38 @@
+package scim
+
+import (
+	"context"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetUserLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUserLogic {
+	return &GetUserLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetUser returns one of the team's members as a User resource.
+func (l *GetUserLogic) GetUser(c echo.Context, id string) (*scimcore.User, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	m, err := findMember(l.svcCtx.DB, team, id)
+	if err != nil {
+		return nil, err
+	}
+	return toUser(l.svcCtx, m), nil
+}
@@ -0,0 +1,93 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// loadGroup returns the group of the role with its current members.
+func loadGroup(svcCtx *svc.ServiceContext, team *models.Team, role models.Role) (*scimcore.Group, error) {
+	memberships, err := models.FindMembershipsByTeamWithUsers(svcCtx.DB, team.ID, false)
+	if err != nil {
+		logx.Errorf("Failed to load members of team %s: %v", team.ID, err)
+		return nil, errInternal
+	}
+	return toGroup(svcCtx, role, memberships), nil
+}
+
+// setGroupMembers gives the role to exactly the desired members. Members are added
+// before others are removed, so moving someone between groups never drops them.
+// Leaving the admin group demotes to member; leaving the member group deprovisions.
+func setGroupMembers(ctx context.Context, svcCtx *svc.ServiceContext, team *models.Team, current *scimcore.Group, role models.Role, desired []scimcore.MultiValue) error {
+	l := logx.WithContext(ctx)
+	db := svcCtx.DB
+
+	have := make(map[string]bool, len(current.Members))
+	for _, member := range current.Members {
+		have[member.Value] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, member := range desired {
+		want[member.Value] = true
+	}
+
+	// 1. Additions
+	for _, member := range desired {
+		if have[member.Value] {
+			continue
+		}
+		have[member.Value] = true
+		m, err := findMember(db, team, member.Value)
+		if err != nil {
+			if _, ok := err.(*scimcore.Error); ok {
+				return scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeInvalidValue, "Unknown member "+member.Value)
+			}
+			l.Errorf("Failed to load member %s of team %s: %v", member.Value, team.ID, err)
+			return errInternal
+		}
+		if m.UserID == team.OwnerID {
+			return scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeMutability, "The team owner's role cannot be changed")
+		}
+		if !isActive(m) {
+			if _, err := provision(ctx, svcCtx, team, m.UserID, role); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := models.UpdateMembershipRole(db, m.ID, role); err != nil {
+			l.Errorf("Failed to change role of user %s in team %s: %v", m.UserID, team.ID, err)
+			return errInternal
+		}
+		l.Infof("User %s of team %s given role %s by directory", m.UserID, team.ID, role)
+	}
+
+	// 2. Removals
+	for _, member := range current.Members {
+		if want[member.Value] {
+			continue
+		}
+		m, err := findMember(db, team, member.Value)
+		if err != nil {
+			l.Errorf("Failed to load member %s of team %s: %v", member.Value, team.ID, err)
+			return errInternal
+		}
+		if role == models.RoleAdmin {
+			if err := models.UpdateMembershipRole(db, m.ID, models.RoleMember); err != nil {
+				l.Errorf("Failed to demote user %s in team %s: %v", m.UserID, team.ID, err)
+				return errInternal
+			}
+			l.Infof("User %s of team %s demoted to member by directory", m.UserID, team.ID)
+			continue
+		}
+		if err := deprovision(ctx, svcCtx, team, &m.User); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package scim
+
+import (
+	"context"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListGroupsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListGroupsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListGroupsLogic {
+	return &ListGroupsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListGroups lists the role groups with their members.
+func (l *ListGroupsLogic) GetListGroups(c echo.Context, query *scimcore.ListQuery) (*scimcore.ListResponse, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := models.FindMembershipsByTeamWithUsers(l.svcCtx.DB, team.ID, false)
+	if err != nil {
+		l.Errorf("Failed to load members of team %s: %v", team.ID, err)
+		return nil, errInternal
+	}
+
+	resources := make([]map[string]interface{}, 0, len(groupRoles))
+	for _, role := range groupRoles {
+		resource, err := scimcore.ToMap(toGroup(l.svcCtx, role, memberships))
+		if err != nil {
+			return nil, err
+		}
+		if query.Excludes("members") {
+			delete(resource, "members")
+		}
+		resources = append(resources, resource)
+	}
+	return query.Apply(resources), nil
+}
@@ -0,0 +1,53 @@
+package scim
+
+import (
+	"context"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListUsersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListUsersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListUsersLogic {
+	return &ListUsersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListUsers lists the team's members, including deprovisioned ones, as User resources.
+func (l *ListUsersLogic) GetListUsers(c echo.Context, query *scimcore.ListQuery) (*scimcore.ListResponse, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := models.FindMembershipsByTeamWithUsers(l.svcCtx.DB, team.ID, true)
+	if err != nil {
+		l.Errorf("Failed to load members of team %s: %v", team.ID, err)
+		return nil, errInternal
+	}
+
+	resources := make([]map[string]interface{}, 0, len(memberships))
+	for i := range memberships {
+		resource, err := scimcore.ToMap(toUser(l.svcCtx, &memberships[i]))
+		if err != nil {
+			return nil, err
+		}
+		if query.Excludes("groups") {
+			delete(resource, "groups")
+		}
+		resources = append(resources, resource)
+	}
+	return query.Apply(resources), nil
+}
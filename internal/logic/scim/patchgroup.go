@@ -0,0 +1,64 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PatchGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPatchGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PatchGroupLogic {
+	return &PatchGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PatchGroup applies PATCH operations to a role group, typically adding or removing members.
+func (l *PatchGroupLogic) PatchGroup(c echo.Context, id string, req *scimcore.PatchRequest) (*scimcore.Group, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	role, err := groupRole(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Patch the current resource
+	current, err := loadGroup(l.svcCtx, team, role)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := scimcore.ToMap(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := scimcore.ApplyPatch(resource, req.Operations); err != nil {
+		return nil, err
+	}
+	var desired scimcore.Group
+	if err := scimcore.FromMap(resource, &desired); err != nil {
+		return nil, err
+	}
+	if desired.DisplayName != string(role) {
+		return nil, scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeMutability, "Groups are team roles and cannot be renamed")
+	}
+
+	// 2. Apply the membership changes
+	if err := setGroupMembers(l.ctx, l.svcCtx, team, current, role, desired.Members); err != nil {
+		return nil, err
+	}
+	return loadGroup(l.svcCtx, team, role)
+}
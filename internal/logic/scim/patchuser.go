@@ -0,0 +1,59 @@
+package scim
+
+import (
+	"context"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PatchUserLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPatchUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PatchUserLogic {
+	return &PatchUserLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PatchUser applies PATCH operations to a member's User resource.
+func (l *PatchUserLogic) PatchUser(c echo.Context, id string, req *scimcore.PatchRequest) (*scimcore.User, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	m, err := findMember(l.svcCtx.DB, team, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Patch the current resource
+	resource, err := scimcore.ToMap(toUser(l.svcCtx, m))
+	if err != nil {
+		return nil, err
+	}
+	if err := scimcore.ApplyPatch(resource, req.Operations); err != nil {
+		return nil, err
+	}
+	var desired scimcore.User
+	if err := scimcore.FromMap(resource, &desired); err != nil {
+		return nil, err
+	}
+
+	// 2. Apply the result like a replace
+	if err := updateMember(l.ctx, l.svcCtx, team, m, &desired); err != nil {
+		return nil, err
+	}
+	if m, err = reload(l.svcCtx, team, m.UserID); err != nil {
+		return nil, err
+	}
+	return toUser(l.svcCtx, m), nil
+}
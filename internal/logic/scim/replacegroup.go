@@ -0,0 +1,50 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ReplaceGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReplaceGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReplaceGroupLogic {
+	return &ReplaceGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PutReplaceGroup sets the members of a role group. The group itself cannot be renamed.
+func (l *ReplaceGroupLogic) PutReplaceGroup(c echo.Context, id string, req *scimcore.Group) (*scimcore.Group, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	role, err := groupRole(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.DisplayName != "" && req.DisplayName != string(role) {
+		return nil, scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeMutability, "Groups are team roles and cannot be renamed")
+	}
+
+	current, err := loadGroup(l.svcCtx, team, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := setGroupMembers(l.ctx, l.svcCtx, team, current, role, req.Members); err != nil {
+		return nil, err
+	}
+	return loadGroup(l.svcCtx, team, role)
+}
@@ -0,0 +1,45 @@
+package scim
+
+import (
+	"context"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ReplaceUserLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReplaceUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReplaceUserLogic {
+	return &ReplaceUserLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PutReplaceUser replaces a member's User resource. Setting active to false
+// deprovisions the member.
+func (l *ReplaceUserLogic) PutReplaceUser(c echo.Context, id string, req *scimcore.User) (*scimcore.User, error) {
+	team, err := teamFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	m, err := findMember(l.svcCtx.DB, team, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateMember(l.ctx, l.svcCtx, team, m, req); err != nil {
+		return nil, err
+	}
+	if m, err = reload(l.svcCtx, team, m.UserID); err != nil {
+		return nil, err
+	}
+	return toUser(l.svcCtx, m), nil
+}
@@ -0,0 +1,266 @@
+// Package scim maps SCIM 2.0 Users and Groups onto a team's members.
+//
+// A User resource is a person's membership of the team: its id is the user's ID and
+// active reports whether the membership is current. Groups are the team roles "admin"
+// and "member"; the owner is in neither and cannot be changed through SCIM.
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// groupRoles are the roles exposed as groups, in listing order.
+var groupRoles = []models.Role{models.RoleAdmin, models.RoleMember}
+
+var errInternal = scimcore.NewError(http.StatusInternalServerError, "", "Internal server error")
+
+func notFound(resourceType, id string) *scimcore.Error {
+	return scimcore.NewError(http.StatusNotFound, "", resourceType+" "+id+" not found")
+}
+
+// teamFromContext returns the team authenticated by the SCIM middleware.
+func teamFromContext(c echo.Context) (*models.Team, error) {
+	team := session.SCIMTeamFromContext(c)
+	if team == nil {
+		return nil, scimcore.NewError(http.StatusUnauthorized, "", "Invalid or missing SCIM bearer token")
+	}
+	return team, nil
+}
+
+// governsEmail reports whether the team has verified the domain of the address. Only
+// then may the directory change the account itself rather than just its membership.
+func governsEmail(db *gorm.DB, team *models.Team, email string) (bool, error) {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false, nil
+	}
+	td, err := models.FindVerifiedTeamDomain(db, email[at+1:])
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return td.TeamID == team.ID, nil
+}
+
+// findMember loads a membership of the team, including a deprovisioned one, by user ID.
+func findMember(db *gorm.DB, team *models.Team, id string) (*models.Membership, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, notFound("User", id)
+	}
+	m, err := models.FindMembershipByUserAndTeamUnscoped(db, userID, team.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, notFound("User", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func isActive(m *models.Membership) bool {
+	return !m.DeletedAt.Valid && m.User.AccountStatus != models.AccountStatusSuspended
+}
+
+func profileOf(user *models.User) types.UserProfileData {
+	var profile types.UserProfileData
+	if len(user.ProfileData) > 0 {
+		_ = json.Unmarshal(user.ProfileData, &profile)
+	}
+	return profile
+}
+
+// nameProfile returns the profile with the name taken from the resource.
+func nameProfile(profile types.UserProfileData, name *scimcore.Name) types.UserProfileData {
+	profile.FirstName = name.GivenName
+	profile.LastName = name.FamilyName
+	if profile.FirstName == "" && profile.LastName == "" && name.Formatted != "" {
+		profile.FirstName, profile.LastName, _ = strings.Cut(name.Formatted, " ")
+	}
+	return profile
+}
+
+func toUser(svcCtx *svc.ServiceContext, m *models.Membership) *scimcore.User {
+	profile := profileOf(&m.User)
+	active := scimcore.Bool(isActive(m))
+	user := &scimcore.User{
+		Schemas:  []string{scimcore.SchemaUser},
+		ID:       m.UserID.String(),
+		UserName: m.User.Email,
+		Emails:   []scimcore.MultiValue{{Value: m.User.Email, Type: "work", Primary: true}},
+		Active:   &active,
+		Meta: &scimcore.Meta{
+			ResourceType: "User",
+			Created:      m.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: lastModified(m).UTC().Format(time.RFC3339),
+			Location:     svcCtx.SCIMBaseURL() + "/Users/" + m.UserID.String(),
+		},
+	}
+	if m.SCIMExternalID != nil {
+		user.ExternalID = *m.SCIMExternalID
+	}
+	if profile.FirstName != "" || profile.LastName != "" {
+		user.Name = &scimcore.Name{
+			GivenName:  profile.FirstName,
+			FamilyName: profile.LastName,
+			Formatted:  strings.TrimSpace(profile.FirstName + " " + profile.LastName),
+		}
+		user.DisplayName = user.Name.Formatted
+	}
+	if !m.DeletedAt.Valid && m.Role != models.RoleOwner {
+		user.Groups = []scimcore.MultiValue{{
+			Value:   string(m.Role),
+			Display: string(m.Role),
+			Ref:     svcCtx.SCIMBaseURL() + "/Groups/" + string(m.Role),
+		}}
+	}
+	return user
+}
+
+func lastModified(m *models.Membership) time.Time {
+	modified := m.UpdatedAt
+	if m.User.UpdatedAt.After(modified) {
+		modified = m.User.UpdatedAt
+	}
+	return modified
+}
+
+func toGroup(svcCtx *svc.ServiceContext, role models.Role, members []models.Membership) *scimcore.Group {
+	group := &scimcore.Group{
+		Schemas:     []string{scimcore.SchemaGroup},
+		ID:          string(role),
+		DisplayName: string(role),
+		Members:     []scimcore.MultiValue{},
+		Meta: &scimcore.Meta{
+			ResourceType: "Group",
+			Location:     svcCtx.SCIMBaseURL() + "/Groups/" + string(role),
+		},
+	}
+	for _, m := range members {
+		if m.Role != role || m.DeletedAt.Valid {
+			continue
+		}
+		group.Members = append(group.Members, scimcore.MultiValue{
+			Value:   m.UserID.String(),
+			Display: m.User.Email,
+			Ref:     svcCtx.SCIMBaseURL() + "/Users/" + m.UserID.String(),
+		})
+	}
+	return group
+}
+
+// groupRole maps a group ID onto its role.
+func groupRole(id string) (models.Role, error) {
+	for _, role := range groupRoles {
+		if id == string(role) {
+			return role, nil
+		}
+	}
+	return "", notFound("Group", id)
+}
+
+// deprovision removes the user from the team. An account left without any team is
+// suspended when the team has verified its email domain, i.e. the account belongs
+// to the organisation rather than to the person.
+func deprovision(ctx context.Context, svcCtx *svc.ServiceContext, team *models.Team, user *models.User) error {
+	l := logx.WithContext(ctx)
+	db := svcCtx.DB
+
+	if user.ID == team.OwnerID {
+		return scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeMutability, "The team owner cannot be deprovisioned")
+	}
+	if err := models.DeleteMembershipByUserAndTeam(db, user.ID, team.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to remove user %s from team %s: %v", user.ID, team.ID, err)
+		return errInternal
+	}
+	svcCtx.SyncSeats(team)
+	l.Infof("User %s deprovisioned from team %s", user.ID, team.ID)
+
+	remaining, err := models.CountMembershipsByUser(db, user.ID)
+	if err != nil {
+		l.Errorf("Failed to count memberships of user %s: %v", user.ID, err)
+		return errInternal
+	}
+	if remaining > 0 {
+		return nil
+	}
+	governed, err := governsEmail(db, team, user.Email)
+	if err != nil {
+		l.Errorf("Failed to look up domain of user %s: %v", user.ID, err)
+		return errInternal
+	}
+	if !governed {
+		return nil
+	}
+	suspended, err := models.SuspendUser(db, user.ID, models.SuspensionReasonDeprovisioned)
+	if err != nil {
+		l.Errorf("Failed to suspend deprovisioned user %s: %v", user.ID, err)
+		return errInternal
+	}
+	if suspended {
+		if _, err := models.RevokeUserSessions(db, user.ID, uuid.Nil, models.SessionRevokedSuspended); err != nil {
+			l.Errorf("Failed to revoke sessions of suspended user %s: %v", user.ID, err)
+		}
+		l.Infof("Suspended user %s after deprovisioning from their last team", user.ID)
+	}
+	return nil
+}
+
+// provision makes the user an active member of the team with the role, restoring a
+// deprovisioned membership and lifting a suspension caused by deprovisioning.
+func provision(ctx context.Context, svcCtx *svc.ServiceContext, team *models.Team, userID uuid.UUID, role models.Role) (*models.Membership, error) {
+	l := logx.WithContext(ctx)
+	db := svcCtx.DB
+
+	// New members take a seat on the team's plan
+	m, created, err := seats.Join(db, team, userID, role)
+	if err != nil {
+		var limitErr *seats.LimitError
+		if errors.As(err, &limitErr) {
+			l.Infof("Team %s reached the seat limit of its plan (%d)", team.ID, limitErr.MaxSeats)
+			return nil, scimcore.NewError(http.StatusForbidden, "", limitErr.Error())
+		}
+		l.Errorf("Failed to add user %s to team %s: %v", userID, team.ID, err)
+		return nil, errInternal
+	}
+	if created {
+		svcCtx.SyncSeats(team)
+		l.Infof("User %s provisioned to team %s as %s", userID, team.ID, role)
+	}
+	if reactivated, err := models.ReactivateUser(db, userID, models.SuspensionReasonDeprovisioned); err != nil {
+		l.Errorf("Failed to reactivate user %s: %v", userID, err)
+		return nil, errInternal
+	} else if reactivated {
+		l.Infof("Reactivated user %s provisioned to team %s", userID, team.ID)
+	}
+	return m, nil
+}
+
+// reload returns the current state of a member after a change.
+func reload(svcCtx *svc.ServiceContext, team *models.Team, userID uuid.UUID) (*models.Membership, error) {
+	m, err := models.FindMembershipByUserAndTeamUnscoped(svcCtx.DB, userID, team.ID)
+	if err != nil {
+		logx.Errorf("Failed to reload user %s of team %s: %v", userID, team.ID, err)
+		return nil, errInternal
+	}
+	return m, nil
+}
@@ -0,0 +1,48 @@
+package scim
+
+import (
+	"context"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetServiceProviderConfigLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetServiceProviderConfigLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetServiceProviderConfigLogic {
+	return &GetServiceProviderConfigLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetServiceProviderConfig describes the supported SCIM features to the directory.
+func (l *GetServiceProviderConfigLogic) GetServiceProviderConfig(c echo.Context) (*scimcore.ServiceProviderConfig, error) {
+	return &scimcore.ServiceProviderConfig{
+		Schemas:        []string{scimcore.SchemaServiceProviderConfig},
+		Patch:          scimcore.Supported{Supported: true},
+		Bulk:           scimcore.BulkSupport{},
+		Filter:         scimcore.FilterSupport{Supported: true, MaxResults: scimcore.MaxResults},
+		ChangePassword: scimcore.Supported{},
+		Sort:           scimcore.Supported{},
+		ETag:           scimcore.Supported{},
+		AuthenticationSchemes: []scimcore.AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer token",
+			Description: "A provisioning token created by the team owner",
+			Primary:     true,
+		}},
+		Meta: &scimcore.Meta{
+			ResourceType: "ServiceProviderConfig",
+			Location:     l.svcCtx.SCIMBaseURL() + "/ServiceProviderConfig",
+		},
+	}, nil
+}
@@ -0,0 +1,197 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	scimcore "github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// createMember provisions the person described by the resource: an existing account
+// with the address joins the team, otherwise a new account is created. The address
+// must be in a domain the team has verified.
+func createMember(ctx context.Context, svcCtx *svc.ServiceContext, team *models.Team, desired *scimcore.User) (*models.Membership, error) {
+	l := logx.WithContext(ctx)
+	db := svcCtx.DB
+
+	// 1. The team vouches for the address through its verified domain
+	email := strings.ToLower(strings.TrimSpace(desired.PrimaryEmail()))
+	if !strings.Contains(email, "@") {
+		return nil, scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeInvalidValue, "userName must be an email address")
+	}
+	governed, err := governsEmail(db, team, email)
+	if err != nil {
+		l.Errorf("Failed to look up domain of %s: %v", email, err)
+		return nil, errInternal
+	}
+	if !governed {
+		return nil, scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeInvalidValue, "userName must be in one of the team's verified domains")
+	}
+
+	// 2. Find or create the account
+	user, err := models.FindUserByEmail(db, email)
+	switch {
+	case err == nil:
+		if existing, err := models.FindMembershipByUserAndTeam(db, user.ID, team.ID); err == nil && existing != nil {
+			return nil, scimcore.NewError(http.StatusConflict, scimcore.ErrTypeUniqueness, "User "+email+" is already a member of the team")
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		now := time.Now()
+		user = &models.User{
+			Email:            email,
+			Role:             models.SystemRoleUser,
+			DefaultSubdomain: "user-" + uuid.New().String()[:8],
+			EmailVerifiedAt:  &now, // The team verified the domain
+		}
+		if desired.Name != nil {
+			profile, _ := json.Marshal(nameProfile(profileOf(user), desired.Name))
+			user.ProfileData = profile
+		}
+		if err := models.CreateUser(db, user); err != nil {
+			l.Errorf("Failed to create provisioned user %s: %v", email, err)
+			return nil, errInternal
+		}
+		l.Infof("Created user %s (%s) provisioned by team %s", user.ID, email, team.ID)
+	default:
+		l.Errorf("Failed to look up user %s: %v", email, err)
+		return nil, errInternal
+	}
+
+	// 3. Join the team with its default role, then apply the rest of the resource
+	role := team.SSODefaultRole
+	if role != models.RoleAdmin {
+		role = models.RoleMember
+	}
+	if _, err := provision(ctx, svcCtx, team, user.ID, role); err != nil {
+		return nil, err
+	}
+	m, err := reload(svcCtx, team, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateMember(ctx, svcCtx, team, m, desired); err != nil {
+		return nil, err
+	}
+	return reload(svcCtx, team, user.ID)
+}
+
+// updateMember applies the desired state of a User resource to a member. The directory
+// may change the account itself (userName and name) only when the team has verified
+// the domain of its address; otherwise name changes are ignored.
+func updateMember(ctx context.Context, svcCtx *svc.ServiceContext, team *models.Team, m *models.Membership, desired *scimcore.User) error {
+	l := logx.WithContext(ctx)
+	db := svcCtx.DB
+	user := &m.User
+
+	governed, err := governsEmail(db, team, user.Email)
+	if err != nil {
+		l.Errorf("Failed to look up domain of user %s: %v", user.ID, err)
+		return errInternal
+	}
+
+	// 1. userName is the account's email address
+	email := strings.ToLower(strings.TrimSpace(desired.UserName))
+	if email != "" && !strings.EqualFold(email, user.Email) {
+		if err := changeEmail(ctx, svcCtx, team, user, email, governed); err != nil {
+			return err
+		}
+	}
+
+	// 2. Name
+	if desired.Name != nil && governed {
+		current := profileOf(user)
+		updated := nameProfile(current, desired.Name)
+		if updated != current {
+			profile, err := json.Marshal(updated)
+			if err != nil {
+				return errInternal
+			}
+			if err := models.UpdateUserProfileData(db, user.ID, profile); err != nil {
+				l.Errorf("Failed to update name of user %s: %v", user.ID, err)
+				return errInternal
+			}
+		}
+	}
+
+	// 3. externalId belongs to the membership, since each directory has its own
+	var externalID *string
+	if desired.ExternalID != "" {
+		externalID = &desired.ExternalID
+	}
+	if !equalStringPtr(externalID, m.SCIMExternalID) {
+		if err := models.UpdateMembershipSCIMExternalID(db, m.ID, externalID); err != nil {
+			l.Errorf("Failed to update externalId of user %s in team %s: %v", user.ID, team.ID, err)
+			return errInternal
+		}
+	}
+
+	// 4. active provisions and deprovisions the membership
+	switch wantActive := desired.IsActive(); {
+	case wantActive && !isActive(m):
+		role := m.Role
+		if role == "" || role == models.RoleOwner && user.ID != team.OwnerID {
+			role = models.RoleMember
+		}
+		if _, err := provision(ctx, svcCtx, team, user.ID, role); err != nil {
+			return err
+		}
+	case !wantActive && !m.DeletedAt.Valid:
+		if err := deprovision(ctx, svcCtx, team, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeEmail moves the account to a new address. Both the old and the new domain
+// must be verified by the team so a directory cannot take over outside accounts.
+func changeEmail(ctx context.Context, svcCtx *svc.ServiceContext, team *models.Team, user *models.User, email string, governed bool) error {
+	l := logx.WithContext(ctx)
+	db := svcCtx.DB
+
+	if !strings.Contains(email, "@") {
+		return scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeInvalidValue, "userName must be an email address")
+	}
+	if !governed {
+		return scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeMutability, "userName of accounts outside the team's verified domains cannot be changed")
+	}
+	newGoverned, err := governsEmail(db, team, email)
+	if err != nil {
+		l.Errorf("Failed to look up domain of %s: %v", email, err)
+		return errInternal
+	}
+	if !newGoverned {
+		return scimcore.NewError(http.StatusBadRequest, scimcore.ErrTypeInvalidValue, "userName must be in one of the team's verified domains")
+	}
+	if _, err := models.FindUserByEmail(db, email); err == nil {
+		return scimcore.NewError(http.StatusConflict, scimcore.ErrTypeUniqueness, "Another account uses "+email)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to look up user %s: %v", email, err)
+		return errInternal
+	}
+
+	now := time.Now()
+	if err := models.UpdateUserEmail(db, user.ID, email, &now); err != nil {
+		l.Errorf("Failed to change email of user %s: %v", user.ID, err)
+		return errInternal
+	}
+	l.Infof("Email of user %s changed by team %s directory", user.ID, team.ID)
+	return nil
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
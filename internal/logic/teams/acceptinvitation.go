@@ -52,7 +52,20 @@ func (l *AcceptInvitationLogic) PostAcceptInvitation(c echo.Context, req *types.
 	acceptingUserID := acceptingUser.ID
 	acceptingUserEmail := acceptingUser.Email
 
-	// 3. Accept invitation using model function, getting back membership and final invitation state
+	// 3. A full team cannot take the invitee, even with a pending invitation. Unknown or
+	// answered invitations are left to AcceptInvitation to report.
+	pending, err := models.FindInvitationByTokenWithTeam(l.svcCtx.DB, token)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to load invitation for token %s: %v", token, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to accept invitation")
+	}
+	if err == nil && pending.Status == models.StatusPending {
+		if err := requireSeat(l.svcCtx, l.Logger, &pending.Team, true); err != nil {
+			return nil, err
+		}
+	}
+
+	// 4. Accept invitation using model function, getting back membership and final invitation state
 	_, finalInvitation, err := models.AcceptInvitation(l.svcCtx.DB, acceptingUserID, token)
 	// The finalInvitation object is returned even if there's an error, allowing us to check its state.
 
@@ -93,17 +106,20 @@ func (l *AcceptInvitationLogic) PostAcceptInvitation(c echo.Context, req *types.
 	}
 	// If err is nil, finalInvitation is guaranteed to be non-nil and accepted.
 
-	// 4. Check if finalInvitation is valid (should always be non-nil if we reach here)
+	// 5. Check if finalInvitation is valid (should always be non-nil if we reach here)
 	if finalInvitation == nil {
 		// This case should ideally be unreachable due to error handling above.
 		l.Errorf("Internal logic error: finalInvitation is nil after successful acceptance or handled error for token %s", token)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Internal server error processing invitation")
 	}
 
-	// 5. Log success (using details from the returned finalInvitation)
+	// 6. Log success (using details from the returned finalInvitation)
+	if pending != nil {
+		l.svcCtx.SyncSeats(&pending.Team)
+	}
 	l.Infof("User %s (%s) successfully accepted invitation %s to join team %s as %s (Final Status: %s)", acceptingUserID.String(), acceptingUserEmail, finalInvitation.ID.String(), finalInvitation.TeamID.String(), finalInvitation.Role, finalInvitation.Status)
 
-	// 6. Map the final invitation details for the response
+	// 7. Map the final invitation details for the response
 
 	// (Removed redundant logging from previous step)
 	respInvitation := types.Invitation{
@@ -0,0 +1,80 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/domainverify"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type AddTeamDomainLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAddTeamDomainLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AddTeamDomainLogic {
+	return &AddTeamDomainLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostAddTeamDomain claims an email domain for the team's single sign-on and domain joins.
+// The response holds the TXT record to publish before the domain can be verified.
+func (l *AddTeamDomainLogic) PostAddTeamDomain(c echo.Context, req *types.AddTeamDomainRequest) (resp *types.TeamDomainResponse, err error) {
+	_, team, err := requireTeamDomainManager(c, l.svcCtx, l.Logger, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := domainverify.Normalize(req.Domain)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid domain name")
+	}
+
+	// Another team's verified claim cannot be taken over
+	if _, err := models.FindVerifiedTeamDomain(l.svcCtx.DB, name); err == nil {
+		return nil, echo.NewHTTPError(http.StatusConflict, "This domain is already verified by another team")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to look up domain %s: %v", name, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to add domain")
+	}
+
+	claimed, err := models.FindTeamDomainsByTeam(l.svcCtx.DB, team.ID)
+	if err != nil {
+		l.Errorf("Failed to load domains of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to add domain")
+	}
+	for _, d := range claimed {
+		if d.Domain == name {
+			return nil, echo.NewHTTPError(http.StatusConflict, "This domain is already added to the team")
+		}
+	}
+
+	domain := &models.TeamDomain{
+		TeamID:            team.ID,
+		Domain:            name,
+		VerificationToken: domainverify.NewToken(),
+	}
+	if err := models.CreateTeamDomain(l.svcCtx.DB, domain); err != nil {
+		l.Errorf("Failed to add domain %s to team %s: %v", name, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to add domain")
+	}
+
+	l.Infof("Domain %s claimed by team %s", name, team.ID)
+	return &types.TeamDomainResponse{
+		Success: true,
+		Message: "Domain added. Publish the TXT record, then verify the domain.",
+		Domain:  toTeamDomain(*domain),
+	}, nil
+}
@@ -0,0 +1,23 @@
+package teams
+
+import (
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// requireTeamTokenManager returns the signed-in user and the team when the user holds
+// the tokens:manage permission in it. Requests made with an access token are refused so a
+// token cannot mint tokens with more access than itself.
+func requireTeamTokenManager(c echo.Context, svcCtx *svc.ServiceContext, logger logx.Logger, teamIDStr string) (*models.User, *models.Team, error) {
+	if session.APITokenFromContext(c) != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusForbidden, "Access tokens can only be managed from a signed-in session")
+	}
+	return requireTeamPermission(c, svcCtx, logger, teamIDStr, permissions.TokensManage)
+}
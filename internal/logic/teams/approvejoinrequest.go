@@ -0,0 +1,30 @@
+package teams
+
+import (
+	"context"
+
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ApproveJoinRequestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewApproveJoinRequestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApproveJoinRequestLogic {
+	return &ApproveJoinRequestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostApproveJoinRequest admits the requester to the team as a member.
+func (l *ApproveJoinRequestLogic) PostApproveJoinRequest(c echo.Context, req *types.JoinRequestRequest) (resp *types.JoinRequestResponse, err error) {
+	return decideJoinRequest(c, l.svcCtx, l.Logger, req.TeamID, req.RequestID, true)
+}
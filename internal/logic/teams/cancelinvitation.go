@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/solotoabillion/stab/core/permissions"
 	"github.com/solotoabillion/stab/middleware"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
@@ -58,26 +59,12 @@ func (l *CancelInvitationLogic) DeleteCancelInvitation(c echo.Context, req *type
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid invitation ID format")
 	}
 
-	// 3. Verify requesting user is a member and get their role using model function
-	requestingMembership, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, userID, teamUUID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			l.Infof("User %s attempted action on team %s but is not a member", userID.String(), teamUUID.String())
-			return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to access this team")
-		}
-		l.Errorf("DB error verifying membership for user %s in team %s: %v", userID.String(), teamUUID.String(), err)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
-	}
-	requestingUserRole := requestingMembership.Role // Role is available from the returned membership
-
-	// 4. Check permissions (Owner or Admin)
-	isAdminOrOwner := requestingUserRole == models.RoleOwner || requestingUserRole == models.RoleAdmin
-	if !isAdminOrOwner {
-		l.Infof("User %s (Role: %s) attempted to cancel invitation %s for team %s without permission", userID.String(), requestingUserRole, invitationUUID.String(), teamUUID.String())
-		return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to cancel invitations for this team")
+	// 3. Verify requesting user holds the members:invite permission
+	if _, _, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersInvite); err != nil {
+		return nil, err
 	}
 
-	// 5. Find the Invitation record using model function
+	// 4. Find the Invitation record using model function
 	invitation, err := models.FindInvitationByIDAndTeam(l.svcCtx.DB, invitationUUID, teamUUID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -88,13 +75,13 @@ func (l *CancelInvitationLogic) DeleteCancelInvitation(c echo.Context, req *type
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find invitation")
 	}
 
-	// 6. Check if the invitation is still pending
+	// 5. Check if the invitation is still pending
 	if invitation.Status != models.StatusPending {
 		// Consider what status code is best - Bad Request or Conflict?
 		return nil, echo.NewHTTPError(http.StatusConflict, "This invitation is no longer pending and cannot be cancelled")
 	}
 
-	// 7. Delete the Invitation record using model function
+	// 6. Delete the Invitation record using model function
 	err = models.DeleteInvitation(l.svcCtx.DB, invitation.ID) // Use the ID from the found invitation
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -107,7 +94,7 @@ func (l *CancelInvitationLogic) DeleteCancelInvitation(c echo.Context, req *type
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to cancel invitation")
 	}
 
-	// 8. Return success
+	// 7. Return success
 	l.Infof("User %s successfully cancelled invitation %s (Email: %s) for team %s", userID.String(), invitation.ID.String(), invitation.Email, teamUUID.String()) // Use invitation.ID
 	// Response type expects InvitationResponse, return success message
 	resp = &types.InvitationResponse{
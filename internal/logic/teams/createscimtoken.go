@@ -0,0 +1,78 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateSCIMTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateSCIMTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateSCIMTokenLogic {
+	return &CreateSCIMTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostCreateSCIMToken issues a bearer token the team's directory uses to provision
+// members through SCIM. The token is returned once and cannot be retrieved later.
+func (l *CreateSCIMTokenLogic) PostCreateSCIMToken(c echo.Context, req *types.CreateSCIMTokenRequest) (resp *types.CreateSCIMTokenResponse, err error) {
+	// 1. Only the owner manages provisioning
+	owner, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.SSOManage)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Provisioning comes with single sign-on on the same plans
+	planID, err := seats.PlanID(l.svcCtx.DB, team)
+	if err != nil {
+		l.Errorf("Failed to load plan of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create provisioning token")
+	}
+	if !l.svcCtx.SSOAvailableForPlan(planID) {
+		return nil, echo.NewHTTPError(http.StatusPaymentRequired, "Provisioning is not included in the team's plan")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Token name must be between 1 and 100 characters")
+	}
+
+	// 3. Store only the hash of the token
+	secret, prefix, hash := scim.NewToken()
+	token := &models.SCIMToken{
+		TeamID:    team.ID,
+		Name:      name,
+		TokenHash: hash,
+		Prefix:    prefix,
+	}
+	if err := models.CreateSCIMToken(l.svcCtx.DB, token); err != nil {
+		l.Errorf("Failed to create SCIM token for team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create provisioning token")
+	}
+
+	l.Infof("SCIM token %s created for team %s by %s", token.ID, team.ID, owner.ID)
+	return &types.CreateSCIMTokenResponse{
+		Success: true,
+		Message: "Provisioning token created. Copy it now; it will not be shown again.",
+		BaseURL: l.svcCtx.SCIMBaseURL(),
+		Token:   toSCIMToken(*token),
+		Secret:  secret,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/apitoken"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateTeamAPITokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateTeamAPITokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateTeamAPITokenLogic {
+	return &CreateTeamAPITokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostCreateTeamAPIToken issues an access token that acts on the team's routes only, on
+// behalf of the member who created it. It stops working if that member leaves the team.
+func (l *CreateTeamAPITokenLogic) PostCreateTeamAPIToken(c echo.Context, req *types.CreateTeamAPITokenRequest) (resp *types.CreateAPITokenResponse, err error) {
+	// 1. Owners and admins manage team tokens
+	user, team, err := requireTeamTokenManager(c, l.svcCtx, l.Logger, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Team tokens can only be granted team scopes
+	token, secret, err := apitoken.Issue(l.svcCtx.DB, user.ID, &team.ID, apitoken.Spec{
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: req.ExpiresInDays,
+		IPAllowlist:   req.IPAllowlist,
+	}, func(scope string) bool { return strings.HasPrefix(scope, apitoken.ResourceTeams+":") })
+	if err != nil {
+		var invalid *apitoken.ValidationError
+		if errors.As(err, &invalid) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, invalid.Message)
+		}
+		l.Errorf("Failed to create access token for team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create access token")
+	}
+
+	l.Infof("Access token %s created for team %s by %s", token.ID, team.ID, user.ID)
+	return &types.CreateAPITokenResponse{
+		Success: true,
+		Message: "Access token created. Copy it now; it will not be shown again.",
+		Token:   apitoken.Describe(*token),
+		Secret:  secret,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateTeamRoleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateTeamRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateTeamRoleLogic {
+	return &CreateTeamRoleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostCreateTeamRole defines a custom role for the team.
+func (l *CreateTeamRoleLogic) PostCreateTeamRole(c echo.Context, req *types.CreateTeamRoleRequest) (resp *types.TeamRoleResponse, err error) {
+	// 1. Check the caller can manage roles
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.RolesManage)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate the role
+	role := &models.TeamRole{}
+	if err := teamRoleInput(c, l.svcCtx, l.Logger, user, team, role, req.Name, req.Description, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	// 3. Save
+	if err := models.CreateTeamRole(l.svcCtx.DB, role); err != nil {
+		l.Errorf("Failed to create role in team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create role")
+	}
+
+	l.Infof("User %s created role %s in team %s", user.ID, role.ID, team.ID)
+	return &types.TeamRoleResponse{
+		Success: true,
+		Message: "Role created successfully",
+		Role:    toTeamRole(*role, 0),
+	}, nil
+}
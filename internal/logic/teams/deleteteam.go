@@ -0,0 +1,71 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DeleteTeamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteTeamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteTeamLogic {
+	return &DeleteTeamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteTeam soft-deletes the team with its memberships and pending invitations.
+// Only the owner can delete a team.
+func (l *DeleteTeamLogic) DeleteTeam(c echo.Context, req *types.TeamIDRequest) (resp *types.Response, err error) {
+	// 1. Only the owner holds the team:delete permission
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.TeamDelete)
+	if err != nil {
+		return nil, err
+	}
+	if session.APITokenFromContext(c) != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Teams can only be deleted from a signed-in session")
+	}
+
+	// 2. A team paying for its own subscription has to cancel it first
+	subscriptions, err := models.FindOpenSubscriptionsBySubject(l.svcCtx.DB, models.BillingSubjectTeam, team.ID)
+	if err != nil {
+		l.Errorf("Failed to load subscriptions of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete team")
+	}
+	for _, sub := range subscriptions {
+		if !sub.CancelAtPeriodEnd {
+			return nil, echo.NewHTTPError(http.StatusConflict, "Cancel the team's subscription before deleting the team")
+		}
+	}
+
+	// 3. Delete the team, its memberships and pending invitations together
+	if err := models.DeleteTeam(l.svcCtx.DB, team.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Team not found")
+		}
+		l.Errorf("Failed to delete team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete team")
+	}
+
+	l.Infof("User %s deleted team %s", user.ID, team.ID)
+	return &types.Response{
+		Success: true,
+		Message: "Team deleted successfully",
+	}, nil
+}
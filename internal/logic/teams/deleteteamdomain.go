@@ -0,0 +1,54 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DeleteTeamDomainLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteTeamDomainLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteTeamDomainLogic {
+	return &DeleteTeamDomainLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteTeamDomain releases the team's claim on a domain. Users of the domain keep their
+// accounts and memberships but no longer sign in through the team's identity provider.
+func (l *DeleteTeamDomainLogic) DeleteTeamDomain(c echo.Context, req *types.TeamDomainRequest) (resp *types.Response, err error) {
+	_, team, err := requireTeamDomainManager(c, l.svcCtx, l.Logger, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	domainID, err := uuid.Parse(req.DomainID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid domain ID")
+	}
+
+	if err := models.DeleteTeamDomain(l.svcCtx.DB, domainID, team.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Domain not found")
+		}
+		l.Errorf("Failed to delete domain %s of team %s: %v", domainID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove domain")
+	}
+
+	l.Infof("Domain %s removed from team %s", domainID, team.ID)
+	return &types.Response{Success: true, Message: "Domain removed"}, nil
+}
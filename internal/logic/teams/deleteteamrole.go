@@ -0,0 +1,60 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DeleteTeamRoleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteTeamRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteTeamRoleLogic {
+	return &DeleteTeamRoleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteTeamRole deletes a custom role. Members holding it fall back to the member role.
+func (l *DeleteTeamRoleLogic) DeleteTeamRole(c echo.Context, req *types.TeamRoleRequest) (resp *types.Response, err error) {
+	// 1. Check the caller can manage roles
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.RolesManage)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Load the role
+	role, err := findManagedTeamRole(c, l.svcCtx, l.Logger, user, team, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Delete it and move its members back to the member role
+	if err := models.DeleteTeamRole(l.svcCtx.DB, role.ID, team.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Role not found in this team")
+		}
+		l.Errorf("Failed to delete role %s in team %s: %v", role.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete role")
+	}
+
+	l.Infof("User %s deleted role %s in team %s", user.ID, role.ID, team.ID)
+	return &types.Response{
+		Success: true,
+		Message: "Role deleted successfully",
+	}, nil
+}
@@ -0,0 +1,34 @@
+package teams
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// requireTeamDomainManager returns the signed-in user and the team when the user can change
+// the team's domains. While single sign-on is enabled the domains decide who signs in
+// through the team's identity provider, so changing them also takes sso:manage.
+func requireTeamDomainManager(c echo.Context, svcCtx *svc.ServiceContext, logger logx.Logger, teamIDStr string) (*models.User, *models.Team, error) {
+	user, team, err := requireTeamPermission(c, svcCtx, logger, teamIDStr, permissions.DomainsManage)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !team.SSOEnabled {
+		return user, team, nil
+	}
+	if err := svcCtx.Authorize(c.Request().Context(), user, team, permissions.SSOManage); err != nil {
+		if errors.Is(err, permissions.ErrForbidden) {
+			return nil, nil, echo.NewHTTPError(http.StatusForbidden, "While single sign-on is enabled, only the team owner can change its domains")
+		}
+		logger.Errorf("Failed to authorize user %s in team %s: %v", user.ID, team.ID, err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+	}
+	return user, team, nil
+}
@@ -0,0 +1,61 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type GetInvitationImportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetInvitationImportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetInvitationImportLogic {
+	return &GetInvitationImportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetInvitationImport returns the progress of an invitation import and, once it
+// completed, the outcome of every row.
+func (l *GetInvitationImportLogic) GetInvitationImport(c echo.Context, req *types.InvitationImportRequest) (resp *types.InvitationImportResponse, err error) {
+	// 1. Verify the caller holds the members:invite permission
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersInvite)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Load the import
+	importID, err := uuid.Parse(req.ImportID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid import ID format")
+	}
+	imp, err := models.FindInvitationImportByIDAndTeam(l.svcCtx.DB, importID, team.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Import not found")
+		}
+		l.Errorf("Failed to load invitation import %s of team %s: %v", importID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load import")
+	}
+
+	return &types.InvitationImportResponse{
+		Success: true,
+		Message: "Import retrieved",
+		Import:  toInvitationImport(imp),
+	}, nil
+}
@@ -0,0 +1,42 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTeamSSOLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetTeamSSOLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTeamSSOLogic {
+	return &GetTeamSSOLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetTeamSSO returns the team's single sign-on configuration and claimed domains.
+func (l *GetTeamSSOLogic) GetTeamSSO(c echo.Context, req *types.TeamRequest) (resp *types.TeamSSOResponse, err error) {
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.SSOManage)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = teamSSOResponse(l.svcCtx, team)
+	if err != nil {
+		l.Errorf("Failed to load domains of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load single sign-on settings")
+	}
+	return resp, nil
+}
@@ -0,0 +1,111 @@
+package teams
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/invitations"
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ImportInvitationsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewImportInvitationsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportInvitationsLogic {
+	return &ImportInvitationsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostImportInvitations queues a CSV file of people to invite to the team, uploaded as the
+// "file" field of a form or as a text/csv body. A background job invites them with the
+// same checks as a single invitation; poll the import or wait for the notification to
+// see the outcome of every row.
+func (l *ImportInvitationsLogic) PostImportInvitations(c echo.Context, req *types.TeamIDRequest) (resp *types.InvitationImportResponse, err error) {
+	// 1. Verify the uploader holds the members:invite permission
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersInvite)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Read the uploaded file
+	name, data, err := readImportFile(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Reject files that cannot be read before queueing them; rows are checked by the job
+	if _, err := invitations.ParseCSV(bytes.NewReader(data)); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// 4. Queue the import
+	imp := &models.InvitationImport{
+		TeamID:    team.ID,
+		InviterID: user.ID,
+		Status:    models.InvitationImportPending,
+		FileName:  name,
+		File:      data,
+	}
+	if err := models.CreateInvitationImport(l.svcCtx.DB, imp); err != nil {
+		l.Errorf("Failed to queue invitation import for team %s by user %s: %v", team.ID, user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to import invitations")
+	}
+
+	l.Infof("Invitation import %s queued for team %s by user %s", imp.ID, team.ID, user.ID)
+	return &types.InvitationImportResponse{
+		Success: true,
+		Message: "We are inviting the people in your file and will notify you when we are done",
+		Import:  toInvitationImport(imp),
+	}, nil
+}
+
+// readImportFile returns the name and content of the uploaded CSV file.
+func readImportFile(c echo.Context) (string, []byte, error) {
+	var (
+		name   = "invitations.csv"
+		reader io.Reader
+	)
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case echo.MIMEMultipartForm:
+		header, err := c.FormFile("file")
+		if err != nil {
+			return "", nil, echo.NewHTTPError(http.StatusBadRequest, "Upload the CSV file in the \"file\" field")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return "", nil, echo.NewHTTPError(http.StatusBadRequest, "The uploaded file could not be read")
+		}
+		defer file.Close()
+		name, reader = header.Filename, file
+	case "text/csv", echo.MIMETextPlain:
+		reader = c.Request().Body
+	default:
+		return "", nil, echo.NewHTTPError(http.StatusUnsupportedMediaType, "Upload a CSV file as multipart/form-data or text/csv")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, invitations.MaxImportSize+1))
+	if err != nil {
+		return "", nil, echo.NewHTTPError(http.StatusBadRequest, "The uploaded file could not be read")
+	}
+	if len(data) > invitations.MaxImportSize {
+		return "", nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("The file is larger than %d KB", invitations.MaxImportSize>>10))
+	}
+	return name, data, nil
+}
@@ -0,0 +1,39 @@
+package teams
+
+import (
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/types"
+)
+
+// toInvitationImport converts an import for API responses, with the outcome of every row
+// once it completed.
+func toInvitationImport(i *models.InvitationImport) types.InvitationImport {
+	imp := types.InvitationImport{
+		ID:        i.ID.String(),
+		TeamID:    i.TeamID.String(),
+		Status:    string(i.Status),
+		FileName:  i.FileName,
+		Total:     i.Total,
+		Invited:   i.Invited,
+		Skipped:   i.Skipped,
+		Failed:    i.Failed,
+		Error:     i.Error,
+		Rows:      []types.InvitationImportRow{},
+		CreatedAt: i.CreatedAt.Format(time.RFC3339),
+	}
+	if i.CompletedAt != nil {
+		imp.CompletedAt = i.CompletedAt.Format(time.RFC3339)
+	}
+	for _, row := range i.Rows() {
+		imp.Rows = append(imp.Rows, types.InvitationImportRow{
+			Line:    row.Line,
+			Email:   row.Email,
+			Role:    row.Role,
+			Status:  string(row.Status),
+			Message: row.Message,
+		})
+	}
+	return imp
+}
@@ -0,0 +1,28 @@
+package teams
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/invitations"
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// invitationCheckError converts why invitations.Check refused an email into the HTTP
+// error of the invite endpoints.
+func invitationCheckError(logger logx.Logger, team *models.Team, email string, err error) error {
+	var limitErr *seats.LimitError
+	switch {
+	case errors.Is(err, invitations.ErrAlreadyMember), errors.Is(err, invitations.ErrAlreadyInvited):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.As(err, &limitErr):
+		logger.Infof("Team %s reached the seat limit of its plan (%d)", team.ID, limitErr.MaxSeats)
+		return echo.NewHTTPError(http.StatusPaymentRequired, limitErr.Error())
+	}
+	logger.Errorf("Failed to check invitation of %s to team %s: %v", email, team.ID, err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check invitation")
+}
@@ -2,11 +2,12 @@ package teams
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"time" // Added for time formatting
 
+	"github.com/solotoabillion/stab/core/invitations"
+	"github.com/solotoabillion/stab/core/permissions"
 	"github.com/solotoabillion/stab/middleware"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
@@ -15,7 +16,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/zeromicro/go-zero/core/logx"
-	"gorm.io/gorm"
 )
 
 type InviteMemberLogic struct {
@@ -66,72 +66,30 @@ func (l *InviteMemberLogic) PostInviteMember(c echo.Context, req *types.InviteMe
 	if invitedEmail == "" {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invited email is required")
 	}
-	if invitedRoleStr != string(models.RoleAdmin) && invitedRoleStr != string(models.RoleMember) {
+	if !invitations.ValidRole(invitedRoleStr) {
 		err := fmt.Errorf("invalid role specified: %s. Must be 'admin' or 'member'", invitedRoleStr)
 		l.Error(err.Error())
 		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 	invitedRole := models.Role(invitedRoleStr)
 
-	// 4. Verify inviter is a member and get their role
-	// 4. Verify inviter is a member using model function
-	inviterMembershipPtr, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, inviterID, teamUUID)
+	// 4. Verify the inviter holds the members:invite permission and every permission of
+	// the offered role
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersInvite)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			l.Infof("User %s attempted action on team %s but is not a member", inviterID.String(), teamUUID.String())
-			return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to access this team")
-		}
-		l.Errorf("DB error verifying membership for user %s in team %s: %v", inviterID.String(), teamUUID.String(), err)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+		return nil, err
 	}
-	inviterMembership := *inviterMembershipPtr // Dereference
-	inviterRole := inviterMembership.Role
-
-	// 5. Check inviter permissions (Owner or Admin)
-	isAdminOrOwner := inviterRole == models.RoleOwner || inviterRole == models.RoleAdmin
-	if !isAdminOrOwner {
-		l.Infof("User %s (Role: %s) attempted to invite member to team %s without permission", inviterID.String(), inviterRole, teamUUID.String())
-		return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to invite members to this team")
-	}
-
-	// 6. Check if the invited email belongs to an existing user and if they are already a member
-	// 6. Check if the invited email belongs to an existing user using model function
-	invitedUserPtr, userCheckErr := models.FindUserByEmail(l.svcCtx.DB, invitedEmail)
-	if userCheckErr == nil { // User exists
-		invitedUser := *invitedUserPtr // Dereference
-		// Check if they are already a member of this team using model function
-		_, existingMemErr := models.FindMembershipByUserAndTeam(l.svcCtx.DB, invitedUser.ID, teamUUID)
-		if existingMemErr == nil {
-			// User is already a member
-			return nil, echo.NewHTTPError(http.StatusConflict, "This user is already a member of the team")
-		} else if !errors.Is(existingMemErr, gorm.ErrRecordNotFound) {
-			// Database error checking membership
-			l.Errorf("DB error checking existing membership for user %s in team %s: %v", invitedUser.ID.String(), teamUUID.String(), existingMemErr)
-			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to check existing membership")
-		}
-		// If ErrRecordNotFound, proceed (user exists but is not a member)
-	} else if !errors.Is(userCheckErr, gorm.ErrRecordNotFound) {
-		// Database error finding user by email
-		l.Errorf("DB error checking invited user email %s: %v", invitedEmail, userCheckErr)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to check invited user")
+	if err := requireGrant(c, l.svcCtx, l.Logger, authedUser, team, invitedRole); err != nil {
+		return nil, err
 	}
-	// If ErrRecordNotFound, proceed (user does not exist yet)
 
-	// 7. Check for existing *pending* invitation for this email/team
-	// 7. Check for existing *pending* invitation using model function
-	_, existingInviteErr := models.FindPendingInvitationByTeamAndEmail(l.svcCtx.DB, teamUUID, invitedEmail)
-	if existingInviteErr == nil {
-		// Pending invitation already exists
-		// TODO: Optionally resend the invitation email here or just return conflict
-		return nil, echo.NewHTTPError(http.StatusConflict, "An invitation for this email address is already pending")
-	} else if !errors.Is(existingInviteErr, gorm.ErrRecordNotFound) {
-		// Database error checking invitations
-		l.Errorf("DB error checking existing invitations for email %s in team %s: %v", invitedEmail, teamUUID.String(), existingInviteErr)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to check existing invitations")
+	// 5. The email must not belong to a member or have a pending invitation, and the
+	// invitation takes a seat on the team's plan
+	if err := invitations.Check(l.svcCtx.DB, team, invitedEmail); err != nil {
+		return nil, invitationCheckError(l.Logger, team, invitedEmail, err)
 	}
-	// If ErrRecordNotFound, proceed
 
-	// 8. Create Invitation record
+	// 6. Create Invitation record
 	invitation := models.Invitation{
 		Email:     invitedEmail,
 		TeamID:    teamUUID, // Use parsed UUID
@@ -147,34 +105,13 @@ func (l *InviteMemberLogic) PostInviteMember(c echo.Context, req *types.InviteMe
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create invitation")
 	}
 
-	// 9. Send email with invitation link (Commented out - requires communication service setup)
-	/*
-		frontendURL := os.Getenv("FRONTEND_URL") // TODO: Get from config svcCtx.Config.FrontendURL
-		if frontendURL == "" {
-			frontendURL = "http://localhost:5173" // Fallback for local dev
-			l.Warnf("FRONTEND_URL not set, using default for invitation link.")
-		}
-		inviteLink := fmt.Sprintf("%s/accept-invite/%s", frontendURL, invitation.Token)
-		emailSubject := "You're Invited to Join a Team!"
-		// TODO: Include inviter name/email and team name in the body
-		emailBody := fmt.Sprintf("You have been invited to join a team as a %s.\n\nClick the link below to accept:\n\n%s\n\nThis link will expire on %s.",
-			invitation.Role,
-			inviteLink,
-			invitation.ExpiresAt.Format(time.RFC1123)) // Format expiry time nicely
-
-		// TODO: Replace with actual call to communication service via svcCtx if available
-		// go func() {
-		// 	err := communication.SendMessage(l.ctx, invitation.Email, emailSubject, emailBody, communication.CommunicationChannelEmail)
-		// 	if err != nil {
-		// 		l.Errorf("Failed to send invitation email to %s for team %s: %v", invitation.Email, teamUUID.String(), err)
-		// 	}
-		// }()
-	*/
-	l.Infof("Invitation created for %s to join team %s (Role: %s) by user %s. Token: %s", invitedEmail, teamUUID.String(), invitedRole, inviterID.String(), invitation.Token)
+	// 7. Send email with invitation link
+	l.svcCtx.SendInvitation(l.ctx, &invitation, team, authedUser)
+	l.Infof("Invitation created for %s to join team %s (Role: %s) by user %s", invitedEmail, teamUUID.String(), invitedRole, inviterID.String())
 
-	// 10. Map created invitation to response type
+	// 8. Map created invitation to response type
 	respInvitation := types.Invitation{
-		// ID is not typically part of the response for creating an invite
+		ID:        invitation.ID.String(),
 		Email:     invitation.Email,
 		TeamID:    invitation.TeamID.String(),
 		Role:      string(invitation.Role),
@@ -183,7 +120,7 @@ func (l *InviteMemberLogic) PostInviteMember(c echo.Context, req *types.InviteMe
 		ExpiresAt: invitation.ExpiresAt.Format(time.RFC3339), // Use standard format
 	}
 
-	// 11. Return success
+	// 9. Return success
 	resp = &types.InvitationResponse{
 		Success:    true,
 		Message:    "Invitation sent successfully",
@@ -0,0 +1,105 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+func toJoinRequest(r models.JoinRequest) types.JoinRequest {
+	return types.JoinRequest{
+		ID:        r.ID.String(),
+		UserID:    r.UserID.String(),
+		Email:     r.User.Email,
+		Status:    string(r.Status),
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// decideJoinRequest approves or rejects one of the team's pending join requests and tells
+// the requester. Approving adds the requester to the team as a member.
+func decideJoinRequest(c echo.Context, svcCtx *svc.ServiceContext, logger logx.Logger, teamIDStr, requestIDStr string, approve bool) (*types.JoinRequestResponse, error) {
+	// 1. Check the caller can admit members
+	user, team, err := requireTeamPermission(c, svcCtx, logger, teamIDStr, permissions.MembersInvite)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Load the request
+	requestID, err := uuid.Parse(requestIDStr)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid join request ID format")
+	}
+	request, err := models.FindJoinRequestByIDAndTeam(svcCtx.DB, requestID, team.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Join request not found in this team")
+		}
+		logger.Errorf("Failed to load join request %s of team %s: %v", requestID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find join request")
+	}
+
+	// 3. Record the decision; approving takes a seat on the team owner's plan and the
+	// approver must hold every permission of the member role
+	if approve {
+		if err := requireGrant(c, svcCtx, logger, user, team, models.RoleMember); err != nil {
+			return nil, err
+		}
+		if err := requireSeat(svcCtx, logger, team, false); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := models.DecideJoinRequest(svcCtx.DB, request, user.ID, approve); err != nil {
+		if errors.Is(err, models.ErrJoinRequestDecided) {
+			return nil, echo.NewHTTPError(http.StatusConflict, "This join request has already been answered")
+		}
+		logger.Errorf("Failed to decide join request %s of team %s: %v", request.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to answer join request")
+	}
+
+	if approve {
+		svcCtx.SyncSeats(team)
+	}
+
+	// 4. Tell the requester
+	notifyJoinRequestDecision(c.Request().Context(), svcCtx, logger, request, team)
+
+	message := "Join request rejected"
+	if approve {
+		message = "Join request approved"
+	}
+	logger.Infof("User %s %s join request %s of user %s in team %s", user.ID, request.Status, request.ID, request.UserID, team.ID)
+	return &types.JoinRequestResponse{
+		Success: true,
+		Message: message,
+		Request: toJoinRequest(*request),
+	}, nil
+}
+
+func notifyJoinRequestDecision(ctx context.Context, svcCtx *svc.ServiceContext, logger logx.Logger, request *models.JoinRequest, team *models.Team) {
+	notification := &models.Notification{
+		UserID: request.UserID,
+		Type:   "team",
+		Title:  fmt.Sprintf("Your request to join %s was declined", team.Name),
+		Body:   fmt.Sprintf("An admin of %s declined your request to join the team.", team.Name),
+	}
+	if request.Status == models.JoinRequestApproved {
+		notification.Title = fmt.Sprintf("You've joined %s", team.Name)
+		notification.Body = fmt.Sprintf("An admin of %s approved your request. You're now a member of the team.", team.Name)
+	}
+	if err := models.CreateNotification(svcCtx.DB.WithContext(ctx), notification); err != nil {
+		logger.Errorf("Failed to notify user %s of join request %s: %v", request.UserID, request.ID, err)
+	}
+}
@@ -0,0 +1,130 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type JoinTeamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewJoinTeamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *JoinTeamLogic {
+	return &JoinTeamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostJoinTeam joins the team through the verified domain of the user's email address.
+// Under the auto policy the user becomes a member right away; under the request policy a
+// join request is queued for the team's admins.
+func (l *JoinTeamLogic) PostJoinTeam(c echo.Context, req *types.TeamIDRequest) (resp *types.JoinTeamResponse, err error) {
+	// 1. Get authenticated User from context
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	teamID, err := uuid.Parse(req.TeamID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid team ID format")
+	}
+
+	// 2. The team must have opened the domain of the user's verified address
+	if !user.EmailVerified() {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Verify your email address before joining a team")
+	}
+	domain, err := models.FindJoinableTeamDomain(l.svcCtx.DB, user.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to look up joinable team of user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to join team")
+	}
+	if err != nil || domain.TeamID != teamID {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "This team can only be joined by invitation")
+	}
+	team := &domain.Team
+
+	// 3. Skip users who already belong to the team
+	if _, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, user.ID, team.ID); err == nil {
+		return nil, echo.NewHTTPError(http.StatusConflict, "You are already a member of this team")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to check membership of user %s in team %s: %v", user.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to join team")
+	}
+
+	// 4. Join right away, or ask the admins
+	if domain.JoinPolicy == models.DomainJoinAuto {
+		if _, _, err := seats.Join(l.svcCtx.DB, team, user.ID, models.RoleMember); err != nil {
+			var limitErr *seats.LimitError
+			if errors.As(err, &limitErr) {
+				l.Infof("Team %s reached the seat limit of its plan (%d)", team.ID, limitErr.MaxSeats)
+				return nil, echo.NewHTTPError(http.StatusPaymentRequired, limitErr.Error())
+			}
+			l.Errorf("Failed to add user %s to team %s: %v", user.ID, team.ID, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to join team")
+		}
+		l.svcCtx.SyncSeats(team)
+		l.Infof("User %s joined team %s through domain %s", user.ID, team.ID, domain.Domain)
+		return &types.JoinTeamResponse{
+			Success: true,
+			Message: "You've joined the team",
+			Status:  "joined",
+			Team:    toTeam(team),
+		}, nil
+	}
+
+	if _, err := models.FindPendingJoinRequest(l.svcCtx.DB, team.ID, user.ID); err == nil {
+		return nil, echo.NewHTTPError(http.StatusConflict, "You have already asked to join this team")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to check join requests of user %s in team %s: %v", user.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to join team")
+	}
+	request := &models.JoinRequest{TeamID: team.ID, UserID: user.ID}
+	if err := models.CreateJoinRequest(l.svcCtx.DB, request); err != nil {
+		l.Errorf("Failed to create join request of user %s for team %s: %v", user.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to join team")
+	}
+
+	// 5. Tell everyone who can approve the request
+	approvers, err := permissions.Holders(l.ctx, l.svcCtx.DB, team, permissions.MembersInvite)
+	if err != nil {
+		l.Errorf("Failed to load approvers of join request %s in team %s: %v", request.ID, team.ID, err)
+		approvers = []uuid.UUID{team.OwnerID}
+	}
+	for _, approverID := range approvers {
+		notification := &models.Notification{
+			UserID: approverID,
+			Type:   "team",
+			Title:  fmt.Sprintf("New request to join %s", team.Name),
+			Body:   fmt.Sprintf("%s asked to join %s. Approve or reject the request from the team's join requests.", user.Email, team.Name),
+		}
+		if err := models.CreateNotification(l.svcCtx.DB, notification); err != nil {
+			l.Errorf("Failed to notify user %s of join request %s: %v", approverID, request.ID, err)
+		}
+	}
+
+	l.Infof("User %s asked to join team %s through domain %s", user.ID, team.ID, domain.Domain)
+	return &types.JoinTeamResponse{
+		Success: true,
+		Message: "Your request to join the team was sent to its admins",
+		Status:  "requested",
+		Team:    toTeam(team),
+	}, nil
+}
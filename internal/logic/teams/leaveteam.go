@@ -0,0 +1,61 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type LeaveTeamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewLeaveTeamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LeaveTeamLogic {
+	return &LeaveTeamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostLeaveTeam removes the signed-in user from the team. Any member can leave except the
+// owner, who has to transfer ownership or delete the team first.
+func (l *LeaveTeamLogic) PostLeaveTeam(c echo.Context, req *types.TeamIDRequest) (resp *types.Response, err error) {
+	// 1. Load the caller's membership
+	user, team, membership, err := requireTeamMember(c, l.svcCtx, l.Logger, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. The owner cannot leave the team without an owner
+	if team.OwnerID == user.ID || membership.Role == models.RoleOwner {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "The team owner cannot leave the team. Transfer ownership or delete the team instead.")
+	}
+
+	// 3. Remove the membership
+	if err := models.DeleteMembershipByUserAndTeam(l.svcCtx.DB, user.ID, team.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "You are not a member of this team")
+		}
+		l.Errorf("Failed to remove user %s from team %s: %v", user.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to leave team")
+	}
+
+	l.svcCtx.SyncSeats(team)
+
+	l.Infof("User %s left team %s", user.ID, team.ID)
+	return &types.Response{
+		Success: true,
+		Message: "You have left the team",
+	}, nil
+}
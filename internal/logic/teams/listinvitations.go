@@ -2,10 +2,10 @@ package teams
 
 import (
 	"context"
-	"errors"
 	"net/http"
 	"time" // Added for time formatting
 
+	"github.com/solotoabillion/stab/core/permissions"
 	"github.com/solotoabillion/stab/middleware"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
@@ -14,7 +14,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/zeromicro/go-zero/core/logx"
-	"gorm.io/gorm"
 )
 
 type ListInvitationsLogic struct {
@@ -53,29 +52,13 @@ func (l *ListInvitationsLogic) GetListInvitations(c echo.Context, req *types.Tea
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid team ID format")
 	}
 
-	// 3. Verify requesting user is a member and get their role
-	// 3. Verify requesting user is a member using model function
-	requestingMembershipPtr, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, userID, teamUUID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			l.Infof("User %s attempted action on team %s but is not a member", userID.String(), teamUUID.String())
-			return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to access this team")
-		}
-		l.Errorf("DB error verifying membership for user %s in team %s: %v", userID.String(), teamUUID.String(), err)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
-	}
-	requestingMembership := *requestingMembershipPtr // Dereference pointer
-	requestingUserRole := requestingMembership.Role
-
-	// 4. Check permissions (Owner or Admin)
-	isAdminOrOwner := requestingUserRole == models.RoleOwner || requestingUserRole == models.RoleAdmin
-	if !isAdminOrOwner {
-		l.Infof("User %s (Role: %s) attempted to list invitations for team %s without permission", userID.String(), requestingUserRole, teamUUID.String())
-		return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to view invitations for this team")
+	// 3. Verify requesting user holds the members:invite permission
+	if _, _, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersInvite); err != nil {
+		return nil, err
 	}
 
-	// 5. Fetch pending Invitation records for the teamID
-	// 5. Fetch pending Invitation records using model function
+	// 4. Fetch pending Invitation records for the teamID
+	// 4. Fetch pending Invitation records using model function
 	invitations, err := models.FindPendingInvitationsByTeam(l.svcCtx.DB, teamUUID)
 	if err != nil {
 		l.Errorf("Failed to retrieve pending invitations for team %s: %v", teamUUID.String(), err)
@@ -83,11 +66,11 @@ func (l *ListInvitationsLogic) GetListInvitations(c echo.Context, req *types.Tea
 	}
 	// Model function ensures empty slice
 
-	// 6. Map models.Invitation to types.Invitation
+	// 5. Map models.Invitation to types.Invitation
 	invitationList := make([]types.Invitation, 0, len(invitations))
 	for _, inv := range invitations {
 		invitationList = append(invitationList, types.Invitation{
-			// ID is not in types.Invitation, only Token
+			ID:        inv.ID.String(),
 			Email:     inv.Email,
 			TeamID:    inv.TeamID.String(),
 			Role:      string(inv.Role),
@@ -99,7 +82,7 @@ func (l *ListInvitationsLogic) GetListInvitations(c echo.Context, req *types.Tea
 
 	// Model function ensures empty slice, no need for nil check here
 
-	// 7. Return list of invitations
+	// 6. Return list of invitations
 	l.Infof("Retrieved %d pending invitations for team %s for user %s", len(invitationList), teamUUID.String(), userID.String())
 	resp = &types.TeamInvitationsResponse{
 		Success:     true,
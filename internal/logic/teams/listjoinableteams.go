@@ -0,0 +1,73 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type ListJoinableTeamsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListJoinableTeamsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListJoinableTeamsLogic {
+	return &ListJoinableTeamsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListJoinableTeams lists the teams the user can join without an invitation because
+// they verified the domain of the user's email address and opened it for joining.
+func (l *ListJoinableTeamsLogic) GetListJoinableTeams(c echo.Context) (resp *types.JoinableTeamsResponse, err error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	resp = &types.JoinableTeamsResponse{Teams: []types.JoinableTeam{}}
+	// The address has to be proven before it can open a team
+	if !user.EmailVerified() {
+		return resp, nil
+	}
+
+	domain, err := models.FindJoinableTeamDomain(l.svcCtx.DB, user.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resp, nil
+		}
+		l.Errorf("Failed to look up joinable teams of user %s: %v", user.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load joinable teams")
+	}
+	if _, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, user.ID, domain.TeamID); err == nil {
+		return resp, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to check membership of user %s in team %s: %v", user.ID, domain.TeamID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load joinable teams")
+	}
+	_, err = models.FindPendingJoinRequest(l.svcCtx.DB, domain.TeamID, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Errorf("Failed to check join requests of user %s in team %s: %v", user.ID, domain.TeamID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load joinable teams")
+	}
+
+	resp.Teams = append(resp.Teams, types.JoinableTeam{
+		TeamID:         domain.TeamID.String(),
+		Name:           domain.Team.Name,
+		Domain:         domain.Domain,
+		JoinPolicy:     string(domain.JoinPolicy),
+		RequestPending: err == nil,
+	})
+	return resp, nil
+}
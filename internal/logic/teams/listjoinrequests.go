@@ -0,0 +1,47 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListJoinRequestsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListJoinRequestsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListJoinRequestsLogic {
+	return &ListJoinRequestsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListJoinRequests lists the team's pending join requests, oldest first.
+func (l *ListJoinRequestsLogic) GetListJoinRequests(c echo.Context, req *types.TeamIDRequest) (resp *types.JoinRequestsResponse, err error) {
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersInvite)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := models.FindPendingJoinRequestsByTeam(l.svcCtx.DB, team.ID)
+	if err != nil {
+		l.Errorf("Failed to load join requests of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load join requests")
+	}
+	resp = &types.JoinRequestsResponse{Requests: make([]types.JoinRequest, 0, len(requests))}
+	for _, r := range requests {
+		resp.Requests = append(resp.Requests, toJoinRequest(r))
+	}
+	return resp, nil
+}
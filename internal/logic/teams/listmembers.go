@@ -2,9 +2,9 @@ package teams
 
 import (
 	"context"
-	"errors"
 	"net/http"
 
+	"github.com/solotoabillion/stab/core/permissions"
 	"github.com/solotoabillion/stab/middleware"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
@@ -13,7 +13,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/zeromicro/go-zero/core/logx"
-	"gorm.io/gorm"
 )
 
 type ListMembersLogic struct {
@@ -52,18 +51,10 @@ func (l *ListMembersLogic) GetListMembers(c echo.Context, req *types.TeamRequest
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid team ID format")
 	}
 
-	// 3. Verify requesting user is a member of the team (Authorization check)
-	// 3. Verify requesting user is a member of the team using model function
-	_, err = models.FindMembershipByUserAndTeam(l.svcCtx.DB, userID, teamUUID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			l.Infof("User %s attempted to list members for team %s but is not a member", userID.String(), teamUUID.String())
-			return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to access this team's members")
-		}
-		l.Errorf("DB error verifying membership for user %s in team %s: %v", userID.String(), teamUUID.String(), err)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+	// 3. Verify requesting user holds the members:read permission
+	if _, _, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersRead); err != nil {
+		return nil, err
 	}
-	// If no error, user is a member
 
 	// 4. Fetch all Memberships for the teamID
 	// 4. Fetch all Memberships for the teamID using model function
@@ -77,11 +68,15 @@ func (l *ListMembersLogic) GetListMembers(c echo.Context, req *types.TeamRequest
 	// 5. Map models.Membership to types.Membership
 	memberList := make([]types.Membership, 0, len(memberships))
 	for _, m := range memberships {
-		memberList = append(memberList, types.Membership{
+		member := types.Membership{
 			UserID: m.UserID.String(), // Convert UUID to string
 			TeamID: m.TeamID.String(), // Convert UUID to string
 			Role:   string(m.Role),    // Convert models.Role to string
-		})
+		}
+		if m.RoleID != nil {
+			member.RoleID = m.RoleID.String()
+		}
+		memberList = append(memberList, member)
 	}
 
 	// Model function ensures empty slice, no need for nil check here
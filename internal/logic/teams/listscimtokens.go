@@ -0,0 +1,51 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListSCIMTokensLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListSCIMTokensLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListSCIMTokensLogic {
+	return &ListSCIMTokensLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListSCIMTokens lists the team's active SCIM provisioning tokens.
+func (l *ListSCIMTokensLogic) GetListSCIMTokens(c echo.Context, req *types.TeamRequest) (resp *types.ListSCIMTokensResponse, err error) {
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.SSOManage)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := models.FindActiveSCIMTokensByTeam(l.svcCtx.DB, team.ID)
+	if err != nil {
+		l.Errorf("Failed to load SCIM tokens of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load provisioning tokens")
+	}
+
+	resp = &types.ListSCIMTokensResponse{
+		BaseURL: l.svcCtx.SCIMBaseURL(),
+		Tokens:  make([]types.SCIMToken, 0, len(tokens)),
+	}
+	for _, t := range tokens {
+		resp.Tokens = append(resp.Tokens, toSCIMToken(t))
+	}
+	return resp, nil
+}
@@ -0,0 +1,51 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/apitoken"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListTeamAPITokensLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListTeamAPITokensLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTeamAPITokensLogic {
+	return &ListTeamAPITokensLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListTeamAPITokens returns the team's access tokens and the scopes they can be granted.
+func (l *ListTeamAPITokensLogic) GetListTeamAPITokens(c echo.Context, req *types.TeamRequest) (resp *types.ListAPITokensResponse, err error) {
+	_, team, err := requireTeamTokenManager(c, l.svcCtx, l.Logger, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := models.FindActiveAPITokensByTeam(l.svcCtx.DB, team.ID)
+	if err != nil {
+		l.Errorf("Failed to load access tokens for team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load access tokens")
+	}
+
+	resp = &types.ListAPITokensResponse{
+		Tokens:          make([]types.APIToken, 0, len(tokens)),
+		AvailableScopes: apitoken.ScopesFor(apitoken.ResourceTeams),
+	}
+	for _, t := range tokens {
+		resp.Tokens = append(resp.Tokens, apitoken.Describe(t))
+	}
+	return resp, nil
+}
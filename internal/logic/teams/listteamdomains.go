@@ -0,0 +1,47 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListTeamDomainsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListTeamDomainsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTeamDomainsLogic {
+	return &ListTeamDomainsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListTeamDomains lists the email domains claimed by the team with their join policies.
+func (l *ListTeamDomainsLogic) GetListTeamDomains(c echo.Context, req *types.TeamIDRequest) (resp *types.ListTeamDomainsResponse, err error) {
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.DomainsManage)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := models.FindTeamDomainsByTeam(l.svcCtx.DB, team.ID)
+	if err != nil {
+		l.Errorf("Failed to load domains of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load domains")
+	}
+	resp = &types.ListTeamDomainsResponse{Domains: make([]types.TeamDomain, 0, len(domains))}
+	for _, d := range domains {
+		resp.Domains = append(resp.Domains, toTeamDomain(d))
+	}
+	return resp, nil
+}
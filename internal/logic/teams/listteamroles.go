@@ -0,0 +1,84 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListTeamRolesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListTeamRolesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTeamRolesLogic {
+	return &ListTeamRolesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetListTeamRoles lists the built-in and custom roles of the team together with the
+// permissions that can be granted to custom roles.
+func (l *ListTeamRolesLogic) GetListTeamRoles(c echo.Context, req *types.TeamIDRequest) (resp *types.TeamRolesResponse, err error) {
+	// 1. Any member who can see the members can see their roles
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersRead)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Load the custom roles and count the members holding each role
+	roles, err := models.FindTeamRolesByTeam(l.svcCtx.DB, team.ID)
+	if err != nil {
+		l.Errorf("Failed to load roles of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load roles")
+	}
+	memberships, err := models.FindMembershipsByTeam(l.svcCtx.DB, team.ID)
+	if err != nil {
+		l.Errorf("Failed to load members of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve team members")
+	}
+	builtInCounts := make(map[models.Role]int64)
+	customCounts := make(map[string]int64)
+	for _, m := range memberships {
+		if m.RoleID != nil {
+			customCounts[m.RoleID.String()]++
+		} else {
+			builtInCounts[m.Role]++
+		}
+	}
+
+	// 3. Built-in roles first, then the team's own
+	resp = &types.TeamRolesResponse{
+		Roles:       make([]types.TeamRole, 0, len(roles)+3),
+		Permissions: make([]types.PermissionInfo, 0),
+	}
+	for _, role := range []models.Role{models.RoleOwner, models.RoleAdmin, models.RoleMember} {
+		resp.Roles = append(resp.Roles, types.TeamRole{
+			Name:        string(role),
+			Permissions: permissions.RolePermissions(role),
+			BuiltIn:     true,
+			MemberCount: builtInCounts[role],
+		})
+	}
+	for _, r := range roles {
+		resp.Roles = append(resp.Roles, toTeamRole(r, customCounts[r.ID.String()]))
+	}
+	for _, p := range permissions.All() {
+		resp.Permissions = append(resp.Permissions, types.PermissionInfo{
+			Name:        p.Name,
+			Description: p.Description,
+			OwnerOnly:   p.OwnerOnly,
+		})
+	}
+	return resp, nil
+}
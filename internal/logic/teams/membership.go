@@ -0,0 +1,105 @@
+package teams
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// requireTeamMember returns the signed-in user, the team and the user's membership of it.
+// Users who are not members get 403 so that team IDs cannot be probed.
+func requireTeamMember(c echo.Context, svcCtx *svc.ServiceContext, logger logx.Logger, teamIDStr string) (*models.User, *models.Team, *models.Membership, error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, nil, nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	teamID, err := uuid.Parse(teamIDStr)
+	if err != nil {
+		return nil, nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid team ID format")
+	}
+	membership, err := models.FindMembershipByUserAndTeam(svcCtx.DB, user.ID, teamID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Infof("User %s attempted action on team %s but is not a member", user.ID, teamID)
+			return nil, nil, nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to access this team")
+		}
+		logger.Errorf("Failed to load membership of user %s in team %s: %v", user.ID, teamID, err)
+		return nil, nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+	}
+	team, err := models.FindTeamByID(svcCtx.DB, teamID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, echo.NewHTTPError(http.StatusNotFound, "Team not found")
+		}
+		logger.Errorf("Failed to load team %s: %v", teamID, err)
+		return nil, nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load team")
+	}
+	return user, team, membership, nil
+}
+
+// requireTeamPermission returns the signed-in user and the team when the user holds the
+// permission in it.
+func requireTeamPermission(c echo.Context, svcCtx *svc.ServiceContext, logger logx.Logger, teamIDStr, permission string) (*models.User, *models.Team, error) {
+	user := session.UserFromContext(c)
+	if user == nil {
+		return nil, nil, echo.NewHTTPError(http.StatusUnauthorized, "User not authenticated")
+	}
+	teamID, err := uuid.Parse(teamIDStr)
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid team ID format")
+	}
+	team, err := models.FindTeamByID(svcCtx.DB, teamID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Team not found")
+		}
+		logger.Errorf("Failed to load team %s: %v", teamID, err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load team")
+	}
+	if err := svcCtx.Authorize(c.Request().Context(), user, team, permission); err != nil {
+		switch {
+		case errors.Is(err, permissions.ErrNotMember):
+			logger.Infof("User %s attempted action on team %s but is not a member", user.ID, team.ID)
+			return nil, nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to access this team")
+		case errors.Is(err, permissions.ErrForbidden):
+			logger.Infof("User %s lacks the %s permission in team %s", user.ID, permission, team.ID)
+			return nil, nil, echo.NewHTTPError(http.StatusForbidden, "Your role does not have the "+permission+" permission")
+		}
+		logger.Errorf("Failed to authorize user %s in team %s: %v", user.ID, team.ID, err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+	}
+	return user, team, nil
+}
+
+// requireGrant returns 403 when the role would give the new member permissions the user
+// does not hold.
+func requireGrant(c echo.Context, svcCtx *svc.ServiceContext, logger logx.Logger, user *models.User, team *models.Team, role models.Role) error {
+	err := permissions.CanGrant(c.Request().Context(), svcCtx.DB, user, team, role)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, permissions.ErrEscalation), errors.Is(err, permissions.ErrNotMember):
+		logger.Infof("User %s attempted to add a member to team %s with the %s role beyond their own permissions", user.ID, team.ID, role)
+		return echo.NewHTTPError(http.StatusForbidden, "You cannot add members with a role that has permissions you do not have")
+	}
+	logger.Errorf("Failed to load permissions of user %s in team %s: %v", user.ID, team.ID, err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+}
+
+func toTeam(team *models.Team) types.Team {
+	return types.Team{
+		ID:      team.ID.String(),
+		Name:    team.Name,
+		OwnerID: team.OwnerID.String(),
+	}
+}
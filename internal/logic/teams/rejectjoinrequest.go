@@ -0,0 +1,30 @@
+package teams
+
+import (
+	"context"
+
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RejectJoinRequestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRejectJoinRequestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RejectJoinRequestLogic {
+	return &RejectJoinRequestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostRejectJoinRequest turns down a request to join the team.
+func (l *RejectJoinRequestLogic) PostRejectJoinRequest(c echo.Context, req *types.JoinRequestRequest) (resp *types.JoinRequestResponse, err error) {
+	return decideJoinRequest(c, l.svcCtx, l.Logger, req.TeamID, req.RequestID, false)
+}
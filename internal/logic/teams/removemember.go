@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/solotoabillion/stab/core/permissions"
 	"github.com/solotoabillion/stab/middleware"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
@@ -57,31 +58,18 @@ func (l *RemoveMemberLogic) DeleteRemoveMember(c echo.Context, req *types.TeamMe
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid member ID format")
 	}
 
-	// 3. Verify requesting user is a member and get their role using model function
-	requestingMembership, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, requestingUserID, teamUUID)
+	// 3. Verify requesting user holds the members:remove permission
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersRemove)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			l.Infof("User %s attempted action on team %s but is not a member", requestingUserID.String(), teamUUID.String())
-			return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to access this team")
-		}
-		l.Errorf("DB error verifying membership for user %s in team %s: %v", requestingUserID.String(), teamUUID.String(), err)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+		return nil, err
 	}
-	requestingUserRole := requestingMembership.Role // Role is available from the returned membership
 
-	// 4. Check permissions (Owner or Admin)
-	isAdminOrOwner := requestingUserRole == models.RoleOwner || requestingUserRole == models.RoleAdmin
-	if !isAdminOrOwner {
-		l.Infof("User %s (Role: %s) attempted to remove member %s from team %s without permission", requestingUserID.String(), requestingUserRole, memberUUIDToRemove.String(), teamUUID.String())
-		return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to remove members from this team")
-	}
-
-	// 5. Prevent users from removing themselves
+	// 4. Prevent users from removing themselves
 	if requestingUserID == memberUUIDToRemove {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "You cannot remove yourself from the team using this endpoint")
 	}
 
-	// 6. Find the membership record of the user to be removed using model function
+	// 5. Find the membership record of the user to be removed using model function
 	membershipToRemove, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, memberUUIDToRemove, teamUUID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -91,12 +79,12 @@ func (l *RemoveMemberLogic) DeleteRemoveMember(c echo.Context, req *types.TeamMe
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find member")
 	}
 
-	// 7. Verify target member is not the owner
+	// 6. Verify target member is not the owner
 	if membershipToRemove.Role == models.RoleOwner {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "The team owner cannot be removed")
 	}
 
-	// 8. Delete the Membership record using model function
+	// 7. Delete the Membership record using model function
 	err = models.DeleteMembershipByUserAndTeam(l.svcCtx.DB, memberUUIDToRemove, teamUUID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -109,7 +97,9 @@ func (l *RemoveMemberLogic) DeleteRemoveMember(c echo.Context, req *types.TeamMe
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove member")
 	}
 
-	// 9. Return success
+	l.svcCtx.SyncSeats(team)
+
+	// 8. Return success
 	l.Infof("User %s successfully removed member %s from team %s", requestingUserID.String(), memberUUIDToRemove.String(), teamUUID.String())
 	// The signature expects TeamMemberResponse, but we don't have member details to return.
 	// Return a success message.
@@ -0,0 +1,58 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RenameTeamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRenameTeamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RenameTeamLogic {
+	return &RenameTeamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PatchRenameTeam changes the team's name. It requires the team:update permission.
+func (l *RenameTeamLogic) PatchRenameTeam(c echo.Context, req *types.RenameTeamRequest) (resp *types.TeamResponse, err error) {
+	// 1. Load the team and check the caller's permission
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.TeamUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate the new name
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Team name must be between 1 and 100 characters")
+	}
+
+	// 3. Save
+	if err := models.RenameTeam(l.svcCtx.DB, team.ID, name); err != nil {
+		l.Errorf("Failed to rename team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to rename team")
+	}
+	team.Name = name
+
+	l.Infof("User %s renamed team %s", user.ID, team.ID)
+	return &types.TeamResponse{
+		Success: true,
+		Message: "Team renamed successfully",
+		Team:    toTeam(team),
+	}, nil
+}
@@ -0,0 +1,95 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type ResendInvitationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResendInvitationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResendInvitationLogic {
+	return &ResendInvitationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostResendInvitation sends a pending or expired invitation again. The invitation gets a
+// new token and expiry, so the link sent before stops working.
+func (l *ResendInvitationLogic) PostResendInvitation(c echo.Context, req *types.TeamInvitationRequest) (resp *types.InvitationResponse, err error) {
+	// 1. Check the caller can invite members
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.MembersInvite)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Load the invitation
+	invitationID, err := uuid.Parse(req.InvitationID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid invitation ID format")
+	}
+	invitation, err := models.FindInvitationByIDAndTeam(l.svcCtx.DB, invitationID, team.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Invitation not found in this team")
+		}
+		l.Errorf("Failed to load invitation %s of team %s: %v", invitationID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find invitation")
+	}
+	switch invitation.Status {
+	case models.StatusPending:
+	case models.StatusExpired:
+		// Another invitation may have been sent to the address since this one expired
+		if _, err := models.FindPendingInvitationByTeamAndEmail(l.svcCtx.DB, team.ID, invitation.Email); err == nil {
+			return nil, echo.NewHTTPError(http.StatusConflict, "An invitation for this email address is already pending")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			l.Errorf("Failed to check pending invitations of %s in team %s: %v", invitation.Email, team.ID, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to check existing invitations")
+		}
+		// An expired invitation gave its seat back
+		if err := requireSeat(l.svcCtx, l.Logger, team, false); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, echo.NewHTTPError(http.StatusConflict, "This invitation has already been answered")
+	}
+
+	// 3. Rotate the token and send the new link
+	if err := models.RenewInvitation(l.svcCtx.DB, invitation); err != nil {
+		l.Errorf("Failed to renew invitation %s: %v", invitation.ID, err)
+		return nil, echo.NewHTTPError(http.StatusConflict, "This invitation has already been answered")
+	}
+	l.svcCtx.SendInvitation(l.ctx, invitation, team, user)
+
+	l.Infof("User %s resent invitation %s for team %s", user.ID, invitation.ID, team.ID)
+	return &types.InvitationResponse{
+		Success: true,
+		Message: "Invitation sent again",
+		Invitation: types.Invitation{
+			ID:        invitation.ID.String(),
+			Email:     invitation.Email,
+			TeamID:    invitation.TeamID.String(),
+			Role:      string(invitation.Role),
+			Token:     invitation.Token,
+			Status:    string(invitation.Status),
+			ExpiresAt: invitation.ExpiresAt.Format(time.RFC3339),
+		},
+	}, nil
+}
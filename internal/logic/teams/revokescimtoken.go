@@ -0,0 +1,54 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RevokeSCIMTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRevokeSCIMTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeSCIMTokenLogic {
+	return &RevokeSCIMTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteRevokeSCIMToken revokes a provisioning token. Members it provisioned are kept.
+func (l *RevokeSCIMTokenLogic) DeleteRevokeSCIMToken(c echo.Context, req *types.SCIMTokenRequest) (resp *types.Response, err error) {
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.SSOManage)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := uuid.Parse(req.TokenID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid token ID")
+	}
+
+	if err := models.RevokeSCIMToken(l.svcCtx.DB, tokenID, team.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Token not found")
+		}
+		l.Errorf("Failed to revoke SCIM token %s of team %s: %v", tokenID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to revoke provisioning token")
+	}
+
+	l.Infof("SCIM token %s of team %s revoked", tokenID, team.ID)
+	return &types.Response{Success: true, Message: "Provisioning token revoked"}, nil
+}
@@ -0,0 +1,53 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RevokeTeamAPITokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRevokeTeamAPITokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeTeamAPITokenLogic {
+	return &RevokeTeamAPITokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteRevokeTeamAPIToken revokes one of the team's access tokens.
+func (l *RevokeTeamAPITokenLogic) DeleteRevokeTeamAPIToken(c echo.Context, req *types.TeamAPITokenRequest) (resp *types.Response, err error) {
+	_, team, err := requireTeamTokenManager(c, l.svcCtx, l.Logger, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := uuid.Parse(req.TokenID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid token ID")
+	}
+
+	if err := models.RevokeTeamAPIToken(l.svcCtx.DB, tokenID, team.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Token not found")
+		}
+		l.Errorf("Failed to revoke access token %s of team %s: %v", tokenID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to revoke access token")
+	}
+
+	l.Infof("Access token %s of team %s revoked", tokenID, team.ID)
+	return &types.Response{Success: true, Message: "Access token revoked"}, nil
+}
@@ -0,0 +1,21 @@
+package teams
+
+import (
+	"time"
+
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/types"
+)
+
+func toSCIMToken(t models.SCIMToken) types.SCIMToken {
+	token := types.SCIMToken{
+		ID:        t.ID.String(),
+		Name:      t.Name,
+		Prefix:    t.Prefix,
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+	}
+	if t.LastUsedAt != nil {
+		token.LastUsedAt = t.LastUsedAt.Format(time.RFC3339)
+	}
+	return token
+}
@@ -0,0 +1,33 @@
+package teams
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// requireSeat returns 402 when the team's plan has no seat left for one more person.
+// accept is set when the person holds a pending invitation, whose seat is already taken.
+func requireSeat(svcCtx *svc.ServiceContext, logger logx.Logger, team *models.Team, accept bool) error {
+	check := seats.CheckInvite
+	if accept {
+		check = seats.CheckAccept
+	}
+	err := check(svcCtx.DB, team)
+	if err == nil {
+		return nil
+	}
+	var limitErr *seats.LimitError
+	if errors.As(err, &limitErr) {
+		logger.Infof("Team %s reached the seat limit of its plan (%d)", team.ID, limitErr.MaxSeats)
+		return echo.NewHTTPError(http.StatusPaymentRequired, limitErr.Error())
+	}
+	logger.Errorf("Failed to check seats of team %s: %v", team.ID, err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "Failed to check team seats")
+}
@@ -0,0 +1,46 @@
+package teams
+
+import (
+	"time"
+
+	"github.com/solotoabillion/stab/core/domainverify"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+)
+
+func toTeamDomain(d models.TeamDomain) types.TeamDomain {
+	domain := types.TeamDomain{
+		ID:                 d.ID.String(),
+		Domain:             d.Domain,
+		Verified:           d.Verified(),
+		VerificationRecord: domainverify.Record(d.VerificationToken),
+		JoinPolicy:         string(d.JoinPolicy),
+	}
+	if d.VerifiedAt != nil {
+		domain.VerifiedAt = d.VerifiedAt.Format(time.RFC3339)
+	}
+	return domain
+}
+
+// teamSSOResponse describes the team's single sign-on setup. The client secret is never returned.
+func teamSSOResponse(svcCtx *svc.ServiceContext, team *models.Team) (*types.TeamSSOResponse, error) {
+	domains, err := models.FindTeamDomainsByTeam(svcCtx.DB, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.TeamSSOResponse{
+		Enabled:         team.SSOEnabled,
+		Issuer:          team.SSOIssuer,
+		ClientID:        team.SSOClientID,
+		ClientSecretSet: team.SSOClientSecret != "",
+		DefaultRole:     string(team.SSODefaultRole),
+		Enforced:        team.SSOEnforced,
+		CallbackURL:     svcCtx.SSOCallbackURL(),
+		Domains:         make([]types.TeamDomain, 0, len(domains)),
+	}
+	for _, d := range domains {
+		resp.Domains = append(resp.Domains, toTeamDomain(d))
+	}
+	return resp, nil
+}
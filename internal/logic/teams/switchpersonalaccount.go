@@ -0,0 +1,36 @@
+package teams
+
+import (
+	"context"
+
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SwitchPersonalAccountLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSwitchPersonalAccountLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SwitchPersonalAccountLogic {
+	return &SwitchPersonalAccountLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteSwitchPersonalAccount clears the account cookie so the signed-in user acts for
+// their personal account again.
+func (l *SwitchPersonalAccountLogic) DeleteSwitchPersonalAccount(c echo.Context) (resp *types.Response, err error) {
+	session.ClearCookies(c, session.AccountCookieName(l.svcCtx.Config))
+	return &types.Response{
+		Success: true,
+		Message: "Switched to your personal account",
+	}, nil
+}
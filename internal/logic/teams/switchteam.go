@@ -0,0 +1,60 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/tokens"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SwitchTeamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSwitchTeamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SwitchTeamLogic {
+	return &SwitchTeamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostSwitchTeam makes the team the signed-in user's active account. The account cookie
+// selects it on later requests; API clients send the X-Team-ID header instead.
+func (l *SwitchTeamLogic) PostSwitchTeam(c echo.Context, req *types.TeamIDRequest) (resp *types.SwitchTeamResponse, err error) {
+	// 1. Only members can act for the team
+	user, team, membership, err := requireTeamMember(c, l.svcCtx, l.Logger, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Select the team in the account cookie
+	if err := tokens.SetAccountToken(c, l.svcCtx.Config, l.svcCtx.KeyRing, membership); err != nil {
+		l.Errorf("Failed to set account token for user %s and team %s: %v", user.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to switch team")
+	}
+
+	member := types.Membership{
+		UserID: membership.UserID.String(),
+		TeamID: membership.TeamID.String(),
+		Role:   string(membership.Role),
+	}
+	if membership.RoleID != nil {
+		member.RoleID = membership.RoleID.String()
+	}
+
+	l.Infof("User %s switched to team %s", user.ID, team.ID)
+	return &types.SwitchTeamResponse{
+		Success:    true,
+		Message:    "Switched to " + team.Name,
+		Team:       toTeam(team),
+		Membership: member,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package teams
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// teamRoleInput validates the name, description and permissions of a custom role. Users
+// can only grant permissions they hold themselves. The role's own ID is skipped when
+// checking that the name is unique.
+func teamRoleInput(c echo.Context, svcCtx *svc.ServiceContext, logger logx.Logger, user *models.User, team *models.Team, role *models.TeamRole, name, description string, names []string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 50 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Role name must be between 1 and 50 characters")
+	}
+	switch models.Role(strings.ToLower(name)) {
+	case models.RoleOwner, models.RoleAdmin, models.RoleMember:
+		return echo.NewHTTPError(http.StatusConflict, "A built-in role already has this name")
+	}
+	existing, err := models.FindTeamRolesByTeam(svcCtx.DB, team.ID)
+	if err != nil {
+		logger.Errorf("Failed to load roles of team %s: %v", team.ID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load roles")
+	}
+	for _, r := range existing {
+		if r.ID != role.ID && strings.EqualFold(r.Name, name) {
+			return echo.NewHTTPError(http.StatusConflict, "A role with this name already exists")
+		}
+	}
+
+	granted, err := permissions.Validate(names)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	held, err := permissions.Effective(c.Request().Context(), svcCtx.DB, user, team)
+	if err != nil {
+		logger.Errorf("Failed to load permissions of user %s in team %s: %v", user.ID, team.ID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+	}
+	if !permissions.Includes(held, granted) {
+		return echo.NewHTTPError(http.StatusForbidden, "You cannot grant permissions you do not have")
+	}
+
+	role.TeamID = team.ID
+	role.Name = name
+	role.Description = strings.TrimSpace(description)
+	return role.SetPermissions(granted)
+}
+
+func toTeamRole(r models.TeamRole, memberCount int64) types.TeamRole {
+	return types.TeamRole{
+		ID:          r.ID.String(),
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissions.RolePermissionList(&r),
+		MemberCount: memberCount,
+	}
+}
@@ -0,0 +1,90 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type TransferTeamOwnershipLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTransferTeamOwnershipLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TransferTeamOwnershipLogic {
+	return &TransferTeamOwnershipLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostTransferTeamOwnership hands the team to another member. The new owner's membership
+// becomes the owner membership, the previous owner stays on the team as an admin, and
+// Team.OwnerID follows, all in one transaction.
+func (l *TransferTeamOwnershipLogic) PostTransferTeamOwnership(c echo.Context, req *types.TransferTeamOwnershipRequest) (resp *types.TeamResponse, err error) {
+	// 1. Only the owner holds the team:transfer permission
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.TeamTransfer)
+	if err != nil {
+		return nil, err
+	}
+	if session.APITokenFromContext(c) != nil {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Teams can only be transferred from a signed-in session")
+	}
+
+	// 2. The new owner must be another active member
+	newOwnerID, err := uuid.Parse(req.NewOwnerID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid member ID format")
+	}
+	if newOwnerID == user.ID {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "You already own this team")
+	}
+	if _, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, newOwnerID, team.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "The new owner must be a member of the team")
+		}
+		l.Errorf("Failed to load membership of user %s in team %s: %v", newOwnerID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find member")
+	}
+	newOwner, err := models.FindUserByID(l.svcCtx.DB, newOwnerID)
+	if err != nil {
+		l.Errorf("Failed to load user %s: %v", newOwnerID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find member")
+	}
+	if newOwner.AccountStatus != models.AccountStatusActive {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "The new owner's account is not active")
+	}
+
+	// 3. Swap the roles and the owner in one transaction
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		return models.TransferTeamOwnership(tx, team.ID, user.ID, newOwnerID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusConflict, "The team changed while transferring it. Please try again.")
+		}
+		l.Errorf("Failed to transfer team %s from %s to %s: %v", team.ID, user.ID, newOwnerID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to transfer ownership")
+	}
+	team.OwnerID = newOwnerID
+
+	l.Infof("User %s transferred team %s to user %s", user.ID, team.ID, newOwnerID)
+	return &types.TeamResponse{
+		Success: true,
+		Message: "Team ownership transferred successfully",
+		Team:    toTeam(team),
+	}, nil
+}
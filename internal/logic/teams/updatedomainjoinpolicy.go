@@ -0,0 +1,79 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type UpdateDomainJoinPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateDomainJoinPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateDomainJoinPolicyLogic {
+	return &UpdateDomainJoinPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PutUpdateDomainJoinPolicy sets whether users with an address on the domain can join the
+// team without an invitation: right away (auto), after an admin approves (request), or not
+// at all (off). Only verified domains can be opened for joining.
+func (l *UpdateDomainJoinPolicyLogic) PutUpdateDomainJoinPolicy(c echo.Context, req *types.UpdateDomainJoinPolicyRequest) (resp *types.TeamDomainResponse, err error) {
+	// 1. Load the team's claim
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.DomainsManage)
+	if err != nil {
+		return nil, err
+	}
+	domainID, err := uuid.Parse(req.DomainID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid domain ID")
+	}
+	domain, err := models.FindTeamDomainByIDAndTeam(l.svcCtx.DB, domainID, team.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Domain not found")
+		}
+		l.Errorf("Failed to load domain %s: %v", domainID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to update domain")
+	}
+
+	// 2. Validate the policy
+	policy := models.DomainJoinPolicy(req.Policy)
+	switch policy {
+	case models.DomainJoinOff:
+	case models.DomainJoinAuto, models.DomainJoinRequest:
+		if !domain.Verified() {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Verify the domain before letting its users join")
+		}
+	default:
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Join policy must be 'off', 'auto' or 'request'")
+	}
+
+	// 3. Save
+	if err := models.UpdateTeamDomainJoinPolicy(l.svcCtx.DB, domain, policy); err != nil {
+		l.Errorf("Failed to update join policy of domain %s: %v", domain.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to update domain")
+	}
+
+	l.Infof("User %s set join policy of domain %s in team %s to %s", user.ID, domain.Domain, team.ID, policy)
+	return &types.TeamDomainResponse{
+		Success: true,
+		Message: "Join policy updated",
+		Domain:  toTeamDomain(*domain),
+	}, nil
+}
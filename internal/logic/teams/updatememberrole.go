@@ -6,6 +6,7 @@ import (
 	"fmt" // Added for error formatting
 	"net/http"
 
+	"github.com/solotoabillion/stab/core/permissions"
 	"github.com/solotoabillion/stab/middleware"
 	"github.com/solotoabillion/stab/models"
 	"github.com/solotoabillion/stab/svc"
@@ -31,10 +32,8 @@ func NewUpdateMemberRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
-// PatchUpdateMemberRole handles changing a member's role within a team.
-// TODO: Verify that the 'req *types.TeamMemberRequest' correctly includes the 'Role' field
-// from the request body, as defined in the corresponding .api file route.
-// If not, the .api definition or the generated handler might need adjustment.
+// PatchUpdateMemberRole handles changing a member's role within a team. The request names
+// either a built-in role or, with roleId, one of the team's custom roles.
 func (l *UpdateMemberRoleLogic) PatchUpdateMemberRole(c echo.Context, req *types.UpdateMemberRoleRequest) (resp *types.TeamMemberResponse, err error) { // Changed signature
 	// 1. Get authenticated User from context
 	userCtx := c.Get(middleware.ContextUserKey)
@@ -62,42 +61,49 @@ func (l *UpdateMemberRoleLogic) PatchUpdateMemberRole(c echo.Context, req *types
 		l.Errorf("Invalid MemberID format in path parameter: %s, error: %v", memberIDStr, err)
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid member ID format")
 	}
-	// 3. Get the new role from the request body object (`req`)
-	newRoleStr := req.Role // Get role from the request body struct
-	// Basic validation (framework should handle `oneof` tag)
-	if newRoleStr != string(models.RoleAdmin) && newRoleStr != string(models.RoleMember) {
-		// This check might be redundant if framework validation works, but good for safety.
-		err := fmt.Errorf("invalid role specified: '%s'. Must be 'admin' or 'member'", newRoleStr)
-		l.Error(err.Error())
-		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-	newRole := models.Role(newRoleStr)
-
-	// 4. Verify requesting user is a member and get their role using model function
-	requestingMembership, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, requestingUserID, teamUUID)
+	// 3. Verify requesting user holds the members:update permission
+	_, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, teamIDStr, permissions.MembersUpdate)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			l.Infof("User %s attempted action on team %s but is not a member", requestingUserID.String(), teamUUID.String())
-			return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to access this team")
-		}
-		l.Errorf("DB error verifying membership for user %s in team %s: %v", requestingUserID.String(), teamUUID.String(), err)
-		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+		return nil, err
 	}
-	requestingUserRole := requestingMembership.Role // Role is available from the returned membership
 
-	// 5. Check permissions (Owner or Admin)
-	isAdminOrOwner := requestingUserRole == models.RoleOwner || requestingUserRole == models.RoleAdmin
-	if !isAdminOrOwner {
-		l.Infof("User %s (Role: %s) attempted to update role for member %s in team %s without permission", requestingUserID.String(), requestingUserRole, memberUUIDToUpdate.String(), teamUUID.String())
-		return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to update roles in this team")
+	// 4. Resolve the new role from the request body: a custom role or a built-in one
+	var newRole models.Role
+	var newRoleID *uuid.UUID
+	var granted []string
+	if req.RoleID != "" {
+		roleUUID, err := uuid.Parse(req.RoleID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid role ID format")
+		}
+		customRole, err := models.FindTeamRole(l.svcCtx.DB, roleUUID, team.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, echo.NewHTTPError(http.StatusNotFound, "Role not found in this team")
+			}
+			l.Errorf("DB error finding role %s in team %s: %v", roleUUID, team.ID, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find role")
+		}
+		newRole = models.RoleMember
+		newRoleID = &customRole.ID
+		granted = permissions.RolePermissionList(customRole)
+	} else {
+		newRoleStr := req.Role // Get role from the request body struct
+		if newRoleStr != string(models.RoleAdmin) && newRoleStr != string(models.RoleMember) {
+			err := fmt.Errorf("invalid role specified: '%s'. Must be 'admin' or 'member'", newRoleStr)
+			l.Error(err.Error())
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		newRole = models.Role(newRoleStr)
+		granted = permissions.RolePermissions(newRole)
 	}
 
-	// 6. Prevent users from changing their own role
+	// 5. Prevent users from changing their own role
 	if requestingUserID == memberUUIDToUpdate {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "You cannot change your own role using this endpoint")
 	}
 
-	// 7. Find the membership record of the user whose role is being updated using model function
+	// 6. Find the membership record of the user whose role is being updated using model function
 	membershipToUpdate, err := models.FindMembershipByUserAndTeam(l.svcCtx.DB, memberUUIDToUpdate, teamUUID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -107,13 +113,29 @@ func (l *UpdateMemberRoleLogic) PatchUpdateMemberRole(c echo.Context, req *types
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find member")
 	}
 
-	// 8. Verify target member is not the owner
-	if membershipToUpdate.Role == models.RoleOwner {
+	// 7. Verify target member is not the owner
+	if membershipToUpdate.Role == models.RoleOwner || membershipToUpdate.UserID == team.OwnerID {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "The team owner's role cannot be changed")
 	}
 
+	// 8. Users can only hand out, or take away, permissions they hold themselves
+	held, err := permissions.Effective(l.ctx, l.svcCtx.DB, authedUser, team)
+	if err != nil {
+		l.Errorf("Failed to load permissions of user %s in team %s: %v", requestingUserID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+	}
+	current, err := permissions.MembershipPermissions(l.svcCtx.DB, team, membershipToUpdate)
+	if err != nil {
+		l.Errorf("Failed to load permissions of member %s in team %s: %v", memberUUIDToUpdate, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to find member")
+	}
+	if !permissions.Includes(held, granted) || !permissions.Includes(held, current) {
+		l.Infof("User %s attempted to change role of member %s in team %s beyond their own permissions", requestingUserID, memberUUIDToUpdate, team.ID)
+		return nil, echo.NewHTTPError(http.StatusForbidden, "You cannot change the role of a member to or from a role with permissions you do not have")
+	}
+
 	// 9. Update the Membership record with the new role using model function
-	err = models.UpdateMembershipRole(l.svcCtx.DB, membershipToUpdate.ID, newRole)
+	err = models.AssignMembershipRole(l.svcCtx.DB, membershipToUpdate, newRole, newRoleID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// This could happen if the membership was deleted between find and update
@@ -124,8 +146,6 @@ func (l *UpdateMemberRoleLogic) PatchUpdateMemberRole(c echo.Context, req *types
 		l.Errorf("Failed to update role for user %s (Membership ID: %s) in team %s: %v", memberUUIDToUpdate.String(), membershipToUpdate.ID, teamUUID.String(), err)
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to update member role")
 	}
-	// Refresh the role in the local variable after successful update
-	membershipToUpdate.Role = newRole
 
 	// 10. Map updated membership to response type
 	updatedMember := types.Membership{
@@ -133,6 +153,9 @@ func (l *UpdateMemberRoleLogic) PatchUpdateMemberRole(c echo.Context, req *types
 		TeamID: membershipToUpdate.TeamID.String(),
 		Role:   string(membershipToUpdate.Role),
 	}
+	if membershipToUpdate.RoleID != nil {
+		updatedMember.RoleID = membershipToUpdate.RoleID.String()
+	}
 
 	// 11. Return success with the updated membership
 	l.Infof("User %s successfully updated role for member %s in team %s to %s", requestingUserID.String(), memberUUIDToUpdate.String(), teamUUID.String(), newRole)
@@ -0,0 +1,98 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type UpdateTeamRoleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateTeamRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateTeamRoleLogic {
+	return &UpdateTeamRoleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PutUpdateTeamRole replaces the name, description and permissions of a custom role.
+// Members holding the role get the new permissions on their next request.
+func (l *UpdateTeamRoleLogic) PutUpdateTeamRole(c echo.Context, req *types.UpdateTeamRoleRequest) (resp *types.TeamRoleResponse, err error) {
+	// 1. Check the caller can manage roles
+	user, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.RolesManage)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Load the role; callers cannot take away permissions they do not hold either
+	role, err := findManagedTeamRole(c, l.svcCtx, l.Logger, user, team, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Validate the changes
+	if err := teamRoleInput(c, l.svcCtx, l.Logger, user, team, role, req.Name, req.Description, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	// 4. Save
+	if err := models.UpdateTeamRole(l.svcCtx.DB, role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Role not found in this team")
+		}
+		l.Errorf("Failed to update role %s in team %s: %v", role.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to update role")
+	}
+	count, err := models.CountMembershipsByTeamRole(l.svcCtx.DB, role.ID)
+	if err != nil {
+		l.Errorf("Failed to count members of role %s: %v", role.ID, err)
+	}
+
+	l.Infof("User %s updated role %s in team %s", user.ID, role.ID, team.ID)
+	return &types.TeamRoleResponse{
+		Success: true,
+		Message: "Role updated successfully",
+		Role:    toTeamRole(*role, count),
+	}, nil
+}
+
+// findManagedTeamRole loads a custom role of the team that the user may change: one whose
+// permissions the user holds.
+func findManagedTeamRole(c echo.Context, svcCtx *svc.ServiceContext, logger logx.Logger, user *models.User, team *models.Team, roleIDStr string) (*models.TeamRole, error) {
+	roleID, err := uuid.Parse(roleIDStr)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid role ID format")
+	}
+	role, err := models.FindTeamRole(svcCtx.DB, roleID, team.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Role not found in this team")
+		}
+		logger.Errorf("Failed to load role %s of team %s: %v", roleID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load role")
+	}
+	held, err := permissions.Effective(c.Request().Context(), svcCtx.DB, user, team)
+	if err != nil {
+		logger.Errorf("Failed to load permissions of user %s in team %s: %v", user.ID, team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify team membership")
+	}
+	if !permissions.Includes(held, permissions.RolePermissionList(role)) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "You cannot change a role with permissions you do not have")
+	}
+	return role, nil
+}
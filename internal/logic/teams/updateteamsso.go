@@ -0,0 +1,99 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/oauth"
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/seats"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateTeamSSOLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateTeamSSOLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateTeamSSOLogic {
+	return &UpdateTeamSSOLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PutUpdateTeamSSO saves the team's OpenID Connect provider. Enabling it checks the
+// issuer's discovery document so a typo is caught before members are sent there.
+func (l *UpdateTeamSSOLogic) PutUpdateTeamSSO(c echo.Context, req *types.UpdateTeamSSORequest) (resp *types.TeamSSOResponse, err error) {
+	// 1. Only the owner can configure single sign-on
+	owner, team, err := requireTeamPermission(c, l.svcCtx, l.Logger, req.TeamID, permissions.SSOManage)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Single sign-on is a paid feature
+	if req.Enabled {
+		planID, err := seats.PlanID(l.svcCtx.DB, team)
+		if err != nil {
+			l.Errorf("Failed to load plan of team %s: %v", team.ID, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to save single sign-on settings")
+		}
+		if !l.svcCtx.SSOAvailableForPlan(planID) {
+			return nil, echo.NewHTTPError(http.StatusPaymentRequired, "Single sign-on is not included in the team's plan")
+		}
+	}
+
+	// 3. Apply the changes; an empty secret keeps the stored one
+	team.SSOEnabled = req.Enabled
+	team.SSOIssuer = strings.TrimRight(strings.TrimSpace(req.Issuer), "/")
+	team.SSOClientID = strings.TrimSpace(req.ClientID)
+	if req.ClientSecret != "" {
+		team.SSOClientSecret = req.ClientSecret
+	}
+	if req.DefaultRole != "" {
+		team.SSODefaultRole = models.Role(req.DefaultRole)
+	}
+	if team.SSODefaultRole != models.RoleAdmin && team.SSODefaultRole != models.RoleMember {
+		team.SSODefaultRole = models.RoleMember
+	}
+	team.SSOEnforced = req.Enabled && req.Enforced
+
+	// 4. Validate the identity provider before enabling it
+	if team.SSOEnabled {
+		allowHTTP := l.svcCtx.Config.Environment != "production" // Lets a local stand-in IdP be used in development
+		u, err := url.Parse(team.SSOIssuer)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && !(allowHTTP && u.Scheme == "http")) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "The issuer must be an https URL")
+		}
+		if team.SSOClientID == "" {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "A client ID is required")
+		}
+		if _, err := oauth.NewTeamProvider(l.ctx, team, l.svcCtx.SSOCallbackURL()); err != nil {
+			l.Infof("Team %s identity provider %s failed discovery: %v", team.ID, team.SSOIssuer, err)
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Could not load the OpenID Connect configuration of the issuer")
+		}
+	}
+
+	// 5. Save
+	if err := models.UpdateTeamSSO(l.svcCtx.DB, team); err != nil {
+		l.Errorf("Failed to save single sign-on settings of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to save single sign-on settings")
+	}
+	l.Infof("Single sign-on of team %s updated by %s (enabled: %t, enforced: %t)", team.ID, owner.ID, team.SSOEnabled, team.SSOEnforced)
+
+	resp, err = teamSSOResponse(l.svcCtx, team)
+	if err != nil {
+		l.Errorf("Failed to load domains of team %s: %v", team.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load single sign-on settings")
+	}
+	return resp, nil
+}
@@ -0,0 +1,84 @@
+package teams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/solotoabillion/stab/core/domainverify"
+	"github.com/solotoabillion/stab/models"
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type VerifyTeamDomainLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewVerifyTeamDomainLogic(ctx context.Context, svcCtx *svc.ServiceContext) *VerifyTeamDomainLogic {
+	return &VerifyTeamDomainLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PostVerifyTeamDomain checks the domain's TXT record and marks it verified.
+func (l *VerifyTeamDomainLogic) PostVerifyTeamDomain(c echo.Context, req *types.TeamDomainRequest) (resp *types.TeamDomainResponse, err error) {
+	// 1. Load the team's claim
+	_, team, err := requireTeamDomainManager(c, l.svcCtx, l.Logger, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	domainID, err := uuid.Parse(req.DomainID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid domain ID")
+	}
+	domain, err := models.FindTeamDomainByIDAndTeam(l.svcCtx.DB, domainID, team.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Domain not found")
+		}
+		l.Errorf("Failed to load domain %s: %v", domainID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify domain")
+	}
+	if domain.Verified() {
+		return &types.TeamDomainResponse{Success: true, Message: "Domain already verified", Domain: toTeamDomain(*domain)}, nil
+	}
+
+	// 2. Look up the TXT record
+	ctx, cancel := context.WithTimeout(l.ctx, 10*time.Second)
+	defer cancel()
+	found, err := domainverify.Check(ctx, domain.Domain, domain.VerificationToken)
+	if err != nil {
+		l.Infof("DNS lookup for domain %s failed: %v", domain.Domain, err)
+		return nil, echo.NewHTTPError(http.StatusBadGateway, "Could not look up the domain's DNS records, try again later")
+	}
+	if !found {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "The verification TXT record was not found. DNS changes can take a while to appear.")
+	}
+
+	// 3. Mark it verified unless another team got there first
+	if err := models.MarkTeamDomainVerified(l.svcCtx.DB, domain); err != nil {
+		if errors.Is(err, models.ErrDomainVerifiedByOtherTeam) {
+			return nil, echo.NewHTTPError(http.StatusConflict, "This domain is already verified by another team")
+		}
+		l.Errorf("Failed to mark domain %s verified: %v", domain.ID, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify domain")
+	}
+
+	l.Infof("Domain %s verified for team %s", domain.Domain, team.ID)
+	return &types.TeamDomainResponse{
+		Success: true,
+		Message: "Domain verified",
+		Domain:  toTeamDomain(*domain),
+	}, nil
+}
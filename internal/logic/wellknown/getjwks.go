@@ -0,0 +1,54 @@
+package wellknown
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/solotoabillion/stab/svc"
+	"github.com/solotoabillion/stab/types"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetJWKSLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetJWKSLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetJWKSLogic {
+	return &GetJWKSLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetJWKS publishes the public keys that verify access tokens, including rotated-out
+// keys during their grace window. It is empty while tokens are signed with the shared secret.
+func (l *GetJWKSLogic) GetJWKS(c echo.Context) (resp *types.JWKSResponse, err error) {
+	set, err := l.svcCtx.KeyRing.JWKS()
+	if err != nil {
+		l.Errorf("Failed to build JWKS: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load signing keys")
+	}
+
+	resp = &types.JWKSResponse{Keys: make([]types.JSONWebKey, 0, len(set.Keys))}
+	for _, k := range set.Keys {
+		resp.Keys = append(resp.Keys, types.JSONWebKey{
+			Kty: k.Kty,
+			Use: k.Use,
+			Alg: k.Alg,
+			Kid: k.Kid,
+			N:   k.N,
+			E:   k.E,
+			Crv: k.Crv,
+			X:   k.X,
+		})
+	}
+
+	// Verifiers may cache the set; a rotated key is published well before its tokens expire
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return resp, nil
+}
@@ -1,10 +1,13 @@
 package middleware
 
 import (
-	"fmt"
+	"errors"
+	"net/http"
 
 	"github.com/solotoabillion/stab/config"
-	"github.com/solotoabillion/stab/core/security"
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/core/session"
 	"github.com/solotoabillion/stab/models"
 
 	"github.com/google/uuid"
@@ -12,64 +15,125 @@ import (
 	"gorm.io/gorm" // Added GORM import
 )
 
+// TeamIDHeader lets API clients choose the team they act for on each request instead of
+// keeping an account cookie.
+const TeamIDHeader = "X-Team-ID"
+
+// AccountGuardMiddleware loads the team the signed-in user is acting for into the
+// context; see session.AccountFromContext. The team comes from the X-Team-ID header,
+// the team of the access token, or the account cookie set by switching teams, in that
+// order. Requests without any act for the user's personal account.
 type AccountGuardMiddleware struct {
-	cfg *config.Config
-	db  *gorm.DB // Changed from models.DB
+	cfg  *config.Config
+	keys *keyring.Ring
+	load func(userID, teamID uuid.UUID) (*session.Account, error) // Looks up the team with the user's membership of it
 }
 
 // NewAccountGuardMiddleware creates a new AccountGuardMiddleware instance.
 // It now accepts a *gorm.DB connection instead of models.DB.
-func NewAccountGuardMiddleware(cfg *config.Config, db *gorm.DB) *AccountGuardMiddleware {
+func NewAccountGuardMiddleware(cfg *config.Config, db *gorm.DB, keys *keyring.Ring) *AccountGuardMiddleware {
 	return &AccountGuardMiddleware{
-		cfg: cfg,
-		db:  db, // Store the GORM DB instance
+		cfg:  cfg,
+		keys: keys,
+		load: func(userID, teamID uuid.UUID) (*session.Account, error) {
+			return loadAccount(db, userID, teamID)
+		},
 	}
 }
 
 func (m *AccountGuardMiddleware) Handle(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		tokenCookie, err := c.Request().Cookie(m.cfg.Auth.AccountCookieName)
-		if err != nil {
-			// fmt.Println("tokenCookie:", err)
+		user := session.UserFromContext(c)
+		if user == nil {
 			return next(c)
 		}
+		token := session.APITokenFromContext(c)
 
-		token := tokenCookie.Value
-
-		unverifiedClaims, err := security.ParseUnverifiedJWT(token)
-		if err != nil {
-			fmt.Println("ParseUnverifiedJWT:", err)
+		// An explicit header must name a team the user, and their token, belong to
+		if header := c.Request().Header.Get(TeamIDHeader); header != "" {
+			teamID, err := uuid.Parse(header)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error":   "Bad Request",
+					"message": "Invalid " + TeamIDHeader + " header",
+				})
+			}
+			if token != nil && token.TeamID != nil && *token.TeamID != teamID {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error":   "Forbidden",
+					"message": "This access token belongs to another team",
+				})
+			}
+			account, err := m.load(user.ID, teamID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return c.JSON(http.StatusForbidden, map[string]string{
+						"error":   "Forbidden",
+						"message": "You do not have permission to access this team",
+					})
+				}
+				c.Logger().Error("Account: failed to load team: ", err)
+				return echo.NewHTTPError(http.StatusInternalServerError)
+			}
+			c.Set(ContextAccountKey, account)
 			return next(c)
 		}
 
-		// check required claims
-		id, ok := unverifiedClaims["id"].(string)
-		if !ok {
-			fmt.Println("Error: 'id' claim is not a string")
+		// Team access tokens always act for their team
+		if token != nil {
+			if token.TeamID != nil {
+				account, err := m.load(user.ID, *token.TeamID)
+				if err != nil {
+					c.Logger().Error("Account: failed to load team of access token: ", err)
+					return echo.NewHTTPError(http.StatusInternalServerError)
+				}
+				c.Set(ContextAccountKey, account)
+			}
 			return next(c)
 		}
 
-		// find account by id
-		// find account by id using the new FindTeamByID function
-		accountID, err := uuid.Parse(id)
+		tokenCookie, err := c.Request().Cookie(session.AccountCookieName(m.cfg))
 		if err != nil {
-			fmt.Println("Error parsing account ID from token:", err)
 			return next(c)
 		}
-		account, err := models.FindTeamByID(m.db, accountID) // Use the GORM DB connection
+
+		// Only a valid account token issued to this user selects a team
+		claims, err := m.keys.Parse(tokenCookie.Value)
+		if err != nil || claims["typ"] != authsession.TypeAccount {
+			return next(c)
+		}
+		if userID, _ := claims["userID"].(string); userID != user.ID.String() {
+			return next(c)
+		}
+		id, _ := claims["id"].(string)
+		accountID, err := uuid.Parse(id)
 		if err != nil {
-			// fmt.Println(err)
 			return next(c)
 		}
 
-		// verify token signature
-		if _, err := security.ParseJWT(token, m.cfg.Auth.AccessSecret); err != nil {
-			// fmt.Println(err)
+		// Users who left the team fall back to their personal account
+		account, err := m.load(user.ID, accountID)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				c.Logger().Error("Account: failed to load team: ", err)
+			}
 			return next(c)
 		}
 		c.Set(ContextAccountKey, account)
-
-		// fmt.Println("auth middleware: token verified")
 		return next(c)
 	}
 }
+
+// loadAccount returns the team with the user's membership of it, or gorm.ErrRecordNotFound
+// when the team is gone or the user is not a member.
+func loadAccount(db *gorm.DB, userID, teamID uuid.UUID) (*session.Account, error) {
+	membership, err := models.FindMembershipByUserAndTeam(db, userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	team, err := models.FindTeamByID(db, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return &session.Account{Team: team, Membership: membership}, nil
+}
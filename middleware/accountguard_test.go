@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func TestAccountGuardSelectsTeam(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.AccessSecret = "test-secret"
+	cfg.Auth.AccessExpire = 60
+	cfg.Auth.SigningAlgorithm = keyring.AlgorithmHS256
+	keys, err := keyring.New(context.Background(), nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCfg := *cfg
+	otherCfg.Auth.AccessSecret = "other-secret"
+	otherKeys, err := keyring.New(context.Background(), nil, &otherCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{ID: uuid.New()}
+	cookieTeam, headerTeam, tokenTeam, formerTeam := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	member := map[uuid.UUID]bool{cookieTeam: true, headerTeam: true, tokenTeam: true}
+	guard := &AccountGuardMiddleware{
+		cfg:  cfg,
+		keys: keys,
+		load: func(userID, teamID uuid.UUID) (*session.Account, error) {
+			if userID != user.ID || !member[teamID] {
+				return nil, gorm.ErrRecordNotFound
+			}
+			return &session.Account{Team: &models.Team{ID: teamID}}, nil
+		},
+	}
+
+	sign := func(keys *keyring.Ring, claims jwt.MapClaims) string {
+		token, err := keys.Sign(claims, 60)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	accountToken := func(teamID uuid.UUID) string {
+		return sign(keys, jwt.MapClaims{"typ": authsession.TypeAccount, "id": teamID, "userID": user.ID})
+	}
+	personalToken := &models.APIToken{}
+	teamToken := &models.APIToken{TeamID: &tokenTeam}
+
+	for _, tc := range []struct {
+		name   string
+		user   *models.User
+		token  *models.APIToken
+		header string
+		cookie string
+		status int       // Zero when the request reaches the handler
+		team   uuid.UUID // uuid.Nil for the personal account
+	}{
+		{name: "signed out", cookie: accountToken(cookieTeam)},
+		{name: "personal account", user: user},
+		{name: "header", user: user, header: headerTeam.String(), team: headerTeam},
+		{name: "header wins over cookie", user: user, header: headerTeam.String(), cookie: accountToken(cookieTeam), team: headerTeam},
+		{name: "header with invalid team", user: user, header: "not-a-team", status: http.StatusBadRequest},
+		{name: "header naming another team", user: user, header: formerTeam.String(), status: http.StatusForbidden},
+		{name: "header with the token's team", user: user, token: teamToken, header: tokenTeam.String(), team: tokenTeam},
+		{name: "header outside the token's team", user: user, token: teamToken, header: headerTeam.String(), status: http.StatusForbidden},
+		{name: "team token", user: user, token: teamToken, cookie: accountToken(cookieTeam), team: tokenTeam},
+		{name: "personal token ignores cookie", user: user, token: personalToken, cookie: accountToken(cookieTeam)},
+		{name: "cookie", user: user, cookie: accountToken(cookieTeam), team: cookieTeam},
+		{name: "cookie of a team the user left", user: user, cookie: accountToken(formerTeam)},
+		{name: "cookie issued to another user", user: user, cookie: sign(keys, jwt.MapClaims{"typ": authsession.TypeAccount, "id": cookieTeam, "userID": uuid.New()})},
+		{name: "cookie holding an access token", user: user, cookie: sign(keys, jwt.MapClaims{"typ": authsession.TypeAccess, "id": cookieTeam, "userID": user.ID})},
+		{name: "cookie signed with other keys", user: user, cookie: sign(otherKeys, jwt.MapClaims{"typ": authsession.TypeAccount, "id": cookieTeam, "userID": user.ID})},
+		{name: "cookie that is not a token", user: user, cookie: "garbage"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(TeamIDHeader, tc.header)
+			}
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: session.AccountCookieName(cfg), Value: tc.cookie})
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			if tc.user != nil {
+				c.Set(session.ContextUserKey, tc.user)
+			}
+			if tc.token != nil {
+				c.Set(session.ContextAPITokenKey, tc.token)
+			}
+
+			var reached bool
+			var team uuid.UUID
+			err := guard.Handle(func(c echo.Context) error {
+				reached = true
+				if account := session.AccountFromContext(c); account != nil {
+					team = account.Team.ID
+				}
+				return nil
+			})(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.status != 0 {
+				if reached || rec.Code != tc.status {
+					t.Fatalf("expected status %d, got %d (handler reached: %v)", tc.status, rec.Code, reached)
+				}
+				return
+			}
+			if !reached {
+				t.Fatalf("request refused with status %d", rec.Code)
+			}
+			if team != tc.team {
+				t.Fatalf("acting for team %v, want %v", team, tc.team)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/apitoken"
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// HeaderAPIKey carries an access token. Access tokens are also accepted as a bearer token.
+const HeaderAPIKey = "X-API-Key"
+
+// tokenTouchInterval limits how often access token usage is written to the database.
+const tokenTouchInterval = time.Minute
+
+var (
+	errNoCredentials    = errors.New("no credentials")
+	errAccountSuspended = errors.New("account suspended")
+	errTokenExpired     = errors.New("access token expired")
+	errIPNotAllowed     = errors.New("address not in the token's allowlist")
+	errNotImpersonating = errors.New("impersonator is no longer an active admin")
+)
+
+// AuthenticatorMiddleware resolves the user behind a request from an access token, a
+// bearer JWT or the user cookie, in that order, and stores it for session.UserFromContext.
+// Requests made with an access token also carry it for session.APITokenFromContext.
+type AuthenticatorMiddleware struct {
+	cfg  *config.Config
+	db   *gorm.DB
+	keys *keyring.Ring
+}
+
+func NewAuthenticatorMiddleware(cfg *config.Config, db *gorm.DB, keys *keyring.Ring) *AuthenticatorMiddleware {
+	return &AuthenticatorMiddleware{
+		cfg:  cfg,
+		db:   db,
+		keys: keys,
+	}
+}
+
+// Handle rejects requests that are not authenticated as an active user.
+func (m *AuthenticatorMiddleware) Handle(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := m.authenticate(c); err != nil {
+			switch {
+			case errors.Is(err, errAccountSuspended):
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error":   "Forbidden",
+					"message": "Account suspended",
+				})
+			case errors.Is(err, errIPNotAllowed):
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error":   "Forbidden",
+					"message": "This token cannot be used from your IP address.",
+				})
+			case errors.Is(err, errTokenExpired):
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error":   "Unauthorized",
+					"message": "This access token has expired.",
+				})
+			}
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error":   "Unauthorized",
+				"message": "Please log in to access this resource.",
+			})
+		}
+		return m.serve(c, next)
+	}
+}
+
+// Optional authenticates the request when it carries valid credentials and lets
+// anonymous requests through.
+func (m *AuthenticatorMiddleware) Optional(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		_ = m.authenticate(c)
+		return m.serve(c, next)
+	}
+}
+
+// serve runs the handler, recording requests made while an admin impersonates the user.
+func (m *AuthenticatorMiddleware) serve(c echo.Context, next echo.HandlerFunc) error {
+	admin := session.ImpersonatorFromContext(c)
+	if admin == nil {
+		return next(c)
+	}
+
+	err := next(c)
+	status := c.Response().Status
+	if err != nil && !c.Response().Committed {
+		status = http.StatusInternalServerError
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			status = httpErr.Code
+		}
+	}
+	entry := &models.ImpersonationLog{
+		SessionID: session.SessionIDFromContext(c),
+		AdminID:   admin.ID,
+		UserID:    session.UserFromContext(c).ID,
+		Action:    models.ImpersonationActionRequest,
+		Method:    c.Request().Method,
+		Path:      c.Request().URL.Path,
+		Status:    status,
+		IPAddress: c.RealIP(),
+	}
+	if logErr := models.CreateImpersonationLog(m.db, entry); logErr != nil {
+		c.Logger().Error("Authenticator: failed to record impersonated request: ", logErr)
+	}
+	return err
+}
+
+func (m *AuthenticatorMiddleware) authenticate(c echo.Context) error {
+	req := c.Request()
+	if apiKey := req.Header.Get(HeaderAPIKey); apiKey != "" {
+		return m.authenticateAPIToken(c, apiKey)
+	}
+
+	token := ""
+	if bearer, ok := strings.CutPrefix(req.Header.Get(echo.HeaderAuthorization), "Bearer "); ok {
+		token = strings.TrimSpace(bearer)
+		if apitoken.IsToken(token) {
+			return m.authenticateAPIToken(c, token)
+		}
+	}
+	if token == "" {
+		if cookie, err := c.Cookie(m.cfg.Auth.UserCookieName); err == nil {
+			token = cookie.Value
+		}
+	}
+	if token == "" {
+		return errNoCredentials
+	}
+
+	claims, err := authsession.ParseClaims(m.keys, token)
+	if err != nil {
+		return err
+	}
+	userID, err := claims.User()
+	if err != nil {
+		return err
+	}
+	user, err := models.FindUserByID(m.db, userID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Logger().Error("Authenticator: failed to load user: ", err)
+		}
+		return err
+	}
+	if err := checkAccountStatus(user); err != nil {
+		return err
+	}
+
+	// reject tokens whose session has been revoked
+	sessionID, err := claims.Session()
+	if err != nil {
+		return err
+	}
+	if err := authsession.Validate(m.db, sessionID, user.ID, authsession.ClientFromContext(c)); err != nil {
+		if !errors.Is(err, authsession.ErrSessionRevoked) {
+			c.Logger().Error("Authenticator: failed to validate session: ", err)
+		}
+		return err
+	}
+	c.Set(session.ContextSessionIDKey, sessionID)
+
+	// impersonation tokens are only honoured while the admin still is one
+	if adminID, ok := claims.ActorID(); ok {
+		admin, err := models.FindUserByID(m.db, adminID)
+		if err != nil || admin.Role != models.SystemRoleAdmin || checkAccountStatus(admin) != nil {
+			return errNotImpersonating
+		}
+		c.Set(session.ContextImpersonatorKey, admin)
+	}
+	c.Set(session.ContextUserKey, user)
+	return nil
+}
+
+func (m *AuthenticatorMiddleware) authenticateAPIToken(c echo.Context, secret string) error {
+	token, err := models.FindActiveAPITokenByHash(m.db, apitoken.HashToken(secret))
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Logger().Error("Authenticator: failed to look up access token: ", err)
+		}
+		return err
+	}
+	if token.Expired() {
+		return errTokenExpired
+	}
+	ip := c.RealIP()
+	if !apitoken.AllowsIP(token.AllowedIPs(), ip) {
+		return errIPNotAllowed
+	}
+	user := &token.User
+	if err := checkAccountStatus(user); err != nil {
+		return err
+	}
+
+	// team tokens stop working when their creator leaves the team
+	if token.TeamID != nil {
+		if _, err := models.FindMembershipByUserAndTeam(m.db, user.ID, *token.TeamID); err != nil {
+			return err
+		}
+	}
+
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > tokenTouchInterval || token.LastUsedIP != ip {
+		if err := models.TouchAPIToken(m.db, token.ID, ip); err != nil {
+			c.Logger().Error("Authenticator: failed to record access token use: ", err)
+		}
+	}
+	c.Set(session.ContextAPITokenKey, token)
+	c.Set(session.ContextUserKey, user)
+	return nil
+}
+
+func checkAccountStatus(user *models.User) error {
+	if user.AccountStatus != models.AccountStatusActive {
+		return errAccountSuspended
+	}
+	return nil
+}
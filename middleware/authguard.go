@@ -3,23 +3,39 @@ package middleware
 import (
 	"strings"
 
+	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/authsession"
+	"github.com/solotoabillion/stab/core/keyring"
 	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 type AuthGuardMiddleware struct {
+	cfg  *config.Config
+	db   *gorm.DB
+	keys *keyring.Ring
 }
 
-func NewAuthGuardMiddleware() *AuthGuardMiddleware {
-	return &AuthGuardMiddleware{}
+func NewAuthGuardMiddleware(cfg *config.Config, db *gorm.DB, keys *keyring.Ring) *AuthGuardMiddleware {
+	return &AuthGuardMiddleware{
+		cfg:  cfg,
+		db:   db,
+		keys: keys,
+	}
 }
 
 func (m *AuthGuardMiddleware) Handle(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 
-		// if we're on the logout page, remove the cookies
+		// if we're on the logout page, revoke the session and remove the cookies
 		if strings.Contains(c.Request().RequestURI, "/logout") {
+			if err := authsession.RevokeRequest(m.db, m.cfg, m.keys, c, models.SessionRevokedLogout); err != nil {
+				c.Logger().Error(err)
+			}
+			authsession.ClearCookies(m.cfg, c)
 			session.ClearCookies(c, "auth", "account")
 			c.Redirect(302, "/")
 			return next(c)
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net"
+
+	"github.com/solotoabillion/stab/core/apitoken"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ClientIPExtractor returns how c.RealIP() finds the client address. Without trusted
+// proxies it is the address of the connection and forwarding headers are ignored, so
+// clients cannot choose the IP checked by access token allowlists and attempt limits.
+// Behind proxies the client is the last X-Forwarded-For entry not added by one of them.
+func ClientIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	ranges, err := apitoken.ParseAllowlist(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranges) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, r := range ranges {
+		_, network, _ := net.ParseCIDR(r)
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
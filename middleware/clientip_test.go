@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPExtractor(t *testing.T) {
+	direct, err := ClientIPExtractor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxied, err := ClientIPExtractor([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		remote  string
+		xff     string
+		direct  string
+		proxied string
+	}{
+		{"no header", "198.51.100.7:4000", "", "198.51.100.7", "198.51.100.7"},
+		{"spoofed by client", "198.51.100.7:4000", "203.0.113.9", "198.51.100.7", "198.51.100.7"},
+		{"through proxy", "10.1.2.3:4000", "203.0.113.9", "10.1.2.3", "203.0.113.9"},
+		{"spoofed through proxy", "192.0.2.1:4000", "203.0.113.66, 198.51.100.7", "192.0.2.1", "198.51.100.7"},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tc.remote
+		req.Header.Set("X-Real-IP", "203.0.113.66")
+		if tc.xff != "" {
+			req.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		if got := direct(req); got != tc.direct {
+			t.Errorf("%s: direct = %q, want %q", tc.name, got, tc.direct)
+		}
+		if got := proxied(req); got != tc.proxied {
+			t.Errorf("%s: behind proxies = %q, want %q", tc.name, got, tc.proxied)
+		}
+	}
+
+	if _, err := ClientIPExtractor([]string{"not-an-ip"}); err == nil {
+		t.Fatal("expected an invalid proxy address to be rejected")
+	}
+}
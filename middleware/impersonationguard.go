@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ImpersonationGuardMiddleware keeps admins who impersonate a user away from sensitive
+// actions such as changing credentials, billing or access tokens.
+type ImpersonationGuardMiddleware struct {
+}
+
+func NewImpersonationGuardMiddleware() *ImpersonationGuardMiddleware {
+	return &ImpersonationGuardMiddleware{}
+}
+
+// Handle blocks the route entirely while impersonating.
+func (m *ImpersonationGuardMiddleware) Handle(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if session.ImpersonatorFromContext(c) != nil {
+			return forbidImpersonation(c)
+		}
+		return next(c)
+	}
+}
+
+// ReadOnly allows only safe methods while impersonating.
+func (m *ImpersonationGuardMiddleware) ReadOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if session.ImpersonatorFromContext(c) != nil {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				return forbidImpersonation(c)
+			}
+		}
+		return next(c)
+	}
+}
+
+func forbidImpersonation(c echo.Context) error {
+	return c.JSON(http.StatusForbidden, map[string]string{
+		"error":   "Forbidden",
+		"message": "This action is not available while impersonating a user.",
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"os"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
@@ -13,15 +12,6 @@ const (
 	ContextUserKey    string = "userCtx"
 )
 
-// JWTCustomClaims defines the structure for JWT claims, including admin status.
-// This should match the claims structure used by the JWT middleware configured via `jwt: Auth` in the .api file.
-// TODO: Centralize this definition if possible (e.g., in types/types.go if soul generates it)
-type JWTCustomClaims struct {
-	Name  string `json:"name"`
-	Admin bool   `json:"admin"`
-	jwt.RegisteredClaims
-}
-
 // Note: adminrequired function moved to adminrequired.go
 
 // InternalAPIAuth checks for a shared secret header for internal API calls.
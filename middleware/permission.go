@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// PermissionMiddleware requires the signed-in user to hold a permission in the team named
+// by the route's :teamId parameter. Routes without one act on the team selected in the
+// account token, or on the user's own account, where the user holds every permission.
+// See permissions.Authorize.
+type PermissionMiddleware struct {
+	db         *gorm.DB
+	permission string
+}
+
+func NewPermissionMiddleware(db *gorm.DB, permission string) *PermissionMiddleware {
+	return &PermissionMiddleware{db: db, permission: permission}
+}
+
+func (m *PermissionMiddleware) Handle(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var team *models.Team
+		if account := session.AccountFromContext(c); account != nil {
+			team = account.Team
+		}
+		if teamIDStr := c.Param("teamId"); teamIDStr != "" {
+			teamID, err := uuid.Parse(teamIDStr)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error":   "Bad Request",
+					"message": "Invalid team ID format",
+				})
+			}
+			team, err = models.FindTeamByID(m.db, teamID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return c.JSON(http.StatusNotFound, map[string]string{
+						"error":   "Not Found",
+						"message": "Team not found",
+					})
+				}
+				c.Logger().Error("Permission: failed to load team: ", err)
+				return echo.NewHTTPError(http.StatusInternalServerError)
+			}
+		}
+
+		err := permissions.Authorize(c.Request().Context(), m.db, session.UserFromContext(c), team, m.permission)
+		switch {
+		case err == nil:
+			return next(c)
+		case errors.Is(err, permissions.ErrNotMember):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error":   "Forbidden",
+				"message": "You do not have permission to access this team",
+			})
+		case errors.Is(err, permissions.ErrForbidden):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error":   "Forbidden",
+				"message": "Your role does not have the " + m.permission + " permission.",
+			})
+		}
+		c.Logger().Error("Permission: failed to authorize request: ", err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/solotoabillion/stab/core/scim"
+	"github.com/solotoabillion/stab/core/session"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// SCIMAuthMiddleware authenticates a team's directory by its SCIM bearer token and
+// puts the team in the context. Failures are answered with SCIM error bodies.
+type SCIMAuthMiddleware struct {
+	db *gorm.DB
+}
+
+func NewSCIMAuthMiddleware(db *gorm.DB) *SCIMAuthMiddleware {
+	return &SCIMAuthMiddleware{
+		db: db,
+	}
+}
+
+func (m *SCIMAuthMiddleware) Handle(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		header := c.Request().Header.Get(echo.HeaderAuthorization)
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || !strings.HasPrefix(token, scim.TokenPrefix) {
+			return writeSCIMError(c, errInvalidSCIMToken)
+		}
+
+		record, err := models.FindActiveSCIMTokenByHash(m.db, scim.HashToken(token))
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				c.Logger().Errorf("SCIMAuth: failed to look up token: %v", err)
+				return writeSCIMError(c, scim.NewError(http.StatusInternalServerError, "", "Internal server error"))
+			}
+			return writeSCIMError(c, errInvalidSCIMToken)
+		}
+		if err := models.TouchSCIMToken(m.db, record.ID); err != nil {
+			c.Logger().Errorf("SCIMAuth: failed to record use of token %s: %v", record.ID, err)
+		}
+
+		c.Set(session.ContextSCIMTeamKey, &record.Team)
+		return next(c)
+	}
+}
+
+var errInvalidSCIMToken = scim.NewError(http.StatusUnauthorized, "", "Invalid or missing SCIM bearer token")
+
+func writeSCIMError(c echo.Context, err *scim.Error) error {
+	if err.StatusCode() == http.StatusUnauthorized {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="SCIM"`)
+	}
+	c.Response().Header().Set(echo.HeaderContentType, scim.ContentType)
+	return c.JSON(err.StatusCode(), err)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/apitoken"
+	"github.com/solotoabillion/stab/core/session"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TokenScopeMiddleware limits requests made with an access token to the token's scopes.
+// Safe methods need read access to the resource, everything else write access.
+// Team tokens are further limited to routes of their own team. Interactive sessions pass through.
+type TokenScopeMiddleware struct {
+	resource string
+}
+
+func NewTokenScopeMiddleware(resource string) *TokenScopeMiddleware {
+	return &TokenScopeMiddleware{resource: resource}
+}
+
+func (m *TokenScopeMiddleware) Handle(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := session.APITokenFromContext(c)
+		if token == nil {
+			return next(c)
+		}
+
+		required := apitoken.RequiredScope(m.resource, c.Request().Method)
+		if !apitoken.Allows(token.ScopeList(), required) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error":   "insufficient_scope",
+				"message": "This token needs the " + required + " scope.",
+			})
+		}
+
+		if token.TeamID != nil && (m.resource != apitoken.ResourceTeams || c.Param("teamId") != token.TeamID.String()) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error":   "Forbidden",
+				"message": "Team tokens can only access their own team.",
+			})
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/solotoabillion/stab/core/session"
+
+	"github.com/labstack/echo/v4"
+)
+
+// VerifiedEmailMiddleware rejects signed-in users whose email address is not verified
+// while the auth/require_email_verification setting is enabled.
+// Requests without a user are left to the other guards.
+type VerifiedEmailMiddleware struct {
+	required func() bool
+}
+
+// NewVerifiedEmailMiddleware takes a func so the setting can change at runtime.
+func NewVerifiedEmailMiddleware(required func() bool) *VerifiedEmailMiddleware {
+	return &VerifiedEmailMiddleware{
+		required: required,
+	}
+}
+
+func (m *VerifiedEmailMiddleware) Handle(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user := session.UserFromContext(c)
+		if user == nil || user.EmailVerified() || !m.required() {
+			return next(c)
+		}
+		return echo.NewHTTPError(http.StatusForbidden, "Please verify your email address to continue")
+	}
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// APIToken is a personal or team access token. Only the SHA-256 hash of the token is stored.
+// Requests made with a token act as the user who created it, limited by the token's scopes.
+type APIToken struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID      uuid.UUID      `gorm:"type:uuid;not null;index"`
+	TeamID      *uuid.UUID     `gorm:"type:uuid;index"` // Set for team tokens, which only act on that team
+	Name        string         `gorm:"size:100;not null"`
+	TokenHash   string         `gorm:"size:64;not null;uniqueIndex"`
+	Prefix      string         `gorm:"size:16;not null"` // Start of the token, shown to tell tokens apart
+	Scopes      datatypes.JSON `gorm:"type:jsonb"`       // e.g. ["billing:read", "teams:write"]
+	IPAllowlist datatypes.JSON `gorm:"type:jsonb"`       // CIDR ranges; empty allows any address
+	ExpiresAt   *time.Time     `gorm:"index"`            // Nil never expires
+	LastUsedAt  *time.Time     `gorm:""`
+	LastUsedIP  string         `gorm:"size:45"`
+	RevokedAt   *time.Time     `gorm:""`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (t *APIToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
+
+// ScopeList returns the token's scopes.
+func (t *APIToken) ScopeList() []string {
+	return unmarshalStrings(t.Scopes)
+}
+
+// AllowedIPs returns the token's IP allowlist.
+func (t *APIToken) AllowedIPs() []string {
+	return unmarshalStrings(t.IPAllowlist)
+}
+
+// Expired reports whether the token has passed its expiry.
+func (t *APIToken) Expired() bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.After(time.Now())
+}
+
+func unmarshalStrings(data datatypes.JSON) []string {
+	var values []string
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &values)
+	}
+	return values
+}
+
+// CreateAPIToken stores a new token.
+func CreateAPIToken(db *gorm.DB, token *APIToken) error {
+	return db.Create(token).Error
+}
+
+// FindActiveAPITokenByHash retrieves an unrevoked token with its user.
+// Expired tokens are returned too; callers check Expired.
+func FindActiveAPITokenByHash(db *gorm.DB, tokenHash string) (*APIToken, error) {
+	var token APIToken
+	err := db.Preload("User").Where("token_hash = ? AND revoked_at IS NULL", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindActiveAPITokensByUser lists a user's unrevoked personal tokens, newest first.
+func FindActiveAPITokensByUser(db *gorm.DB, userID uuid.UUID) ([]APIToken, error) {
+	var tokens []APIToken
+	if err := db.Where("user_id = ? AND team_id IS NULL AND revoked_at IS NULL", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// FindActiveAPITokensByTeam lists a team's unrevoked tokens, newest first.
+func FindActiveAPITokensByTeam(db *gorm.DB, teamID uuid.UUID) ([]APIToken, error) {
+	var tokens []APIToken
+	if err := db.Where("team_id = ? AND revoked_at IS NULL", teamID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// TouchAPIToken records that the token was used and from where.
+func TouchAPIToken(db *gorm.DB, id uuid.UUID, ip string) error {
+	return db.Model(&APIToken{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": time.Now(),
+		"last_used_ip": ip,
+	}).Error
+}
+
+// RevokeUserAPIToken revokes one of a user's personal tokens.
+func RevokeUserAPIToken(db *gorm.DB, id, userID uuid.UUID) error {
+	return revokeAPIToken(db.Where("user_id = ? AND team_id IS NULL", userID), id)
+}
+
+// RevokeTeamAPIToken revokes one of a team's tokens.
+func RevokeTeamAPIToken(db *gorm.DB, id, teamID uuid.UUID) error {
+	return revokeAPIToken(db.Where("team_id = ?", teamID), id)
+}
+
+func revokeAPIToken(scope *gorm.DB, id uuid.UUID) error {
+	result := scope.Model(&APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
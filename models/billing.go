@@ -3,6 +3,8 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +12,11 @@ import (
 	"gorm.io/gorm"
 )
 
+// PlanLimits caps what a plan's subscribers can use. Zero means unlimited.
+type PlanLimits struct {
+	MaxSeats int `gorm:"not null;default:0"` // Members per team owned by the subscriber, counting pending invitations
+}
+
 // Plan defines the structure for subscription plans.
 type Plan struct {
 	ID                  string         `gorm:"primaryKey;size:50"`
@@ -20,18 +27,91 @@ type Plan struct {
 	StripePriceIDYearly *string        `gorm:"size:100;index"` // Optional yearly price ID
 	PriceYearly         *float64       `gorm:"type:decimal(10,2)"`
 	Active              bool           `gorm:"default:true;index"`
+	Limits              PlanLimits     `gorm:"embedded;embeddedPrefix:limit_"`
+	PerSeat             bool           `gorm:"default:false"` // Subscription quantity follows the seats in use
 	CreatedAt           time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt           time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt           gorm.DeletedAt `gorm:"index"` // Add soft delete support
 }
 
-// Subscription tracks user subscriptions.
+// BillingSubjectType says what kind of account a subscription bills.
+type BillingSubjectType string
+
+const (
+	BillingSubjectUser BillingSubjectType = "user" // A user's personal account
+	BillingSubjectTeam BillingSubjectType = "team"
+)
+
+// BillingSubject is the account that subscriptions, invoices and add-ons belong to:
+// a user or a team. Each subject is its own Stripe customer.
+type BillingSubject struct {
+	Type             BillingSubjectType
+	ID               uuid.UUID
+	StripeCustomerID *string
+}
+
+// UserBillingSubject returns the user's personal billing account.
+func UserBillingSubject(user *User) BillingSubject {
+	return BillingSubject{Type: BillingSubjectUser, ID: user.ID, StripeCustomerID: user.StripeCustomerID}
+}
+
+// TeamBillingSubject returns the team's billing account.
+func TeamBillingSubject(team *Team) BillingSubject {
+	return BillingSubject{Type: BillingSubjectTeam, ID: team.ID, StripeCustomerID: team.StripeCustomerID}
+}
+
+// Reference encodes the subject for Stripe's client reference ID, e.g. "team:<uuid>".
+func (s BillingSubject) Reference() string {
+	return string(s.Type) + ":" + s.ID.String()
+}
+
+// HasStripeCustomer reports whether the subject has been billed through Stripe before.
+func (s BillingSubject) HasStripeCustomer() bool {
+	return s.StripeCustomerID != nil && *s.StripeCustomerID != ""
+}
+
+// ParseBillingReference decodes a reference made by BillingSubject.Reference. A bare
+// UUID, as sent by checkouts started before teams could subscribe, names a user.
+func ParseBillingReference(ref string) (BillingSubjectType, uuid.UUID, error) {
+	subjectType, id := BillingSubjectUser, ref
+	if prefix, rest, ok := strings.Cut(ref, ":"); ok {
+		subjectType, id = BillingSubjectType(prefix), rest
+	}
+	if subjectType != BillingSubjectUser && subjectType != BillingSubjectTeam {
+		return "", uuid.Nil, fmt.Errorf("unknown billing subject type %q", subjectType)
+	}
+	subjectID, err := uuid.Parse(id)
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("invalid billing subject ID: %w", err)
+	}
+	return subjectType, subjectID, nil
+}
+
+// SetBillingCustomerID records the Stripe customer of a user or team.
+func SetBillingCustomerID(db *gorm.DB, subjectType BillingSubjectType, subjectID uuid.UUID, customerID string) error {
+	var model interface{} = &User{}
+	if subjectType == BillingSubjectTeam {
+		model = &Team{}
+	}
+	result := db.Model(model).Where("id = ?", subjectID).Update("stripe_customer_id", customerID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Subscription tracks the subscriptions of users and teams.
 type Subscription struct {
-	ID                   uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	UserID               uuid.UUID `gorm:"type:uuid;not null;index"`
-	PlanID               string    `gorm:"size:50;not null;index"` // Foreign key to Plan.ID
-	StripeSubscriptionID string    `gorm:"size:100;uniqueIndex;not null"`
-	Status               string    `gorm:"size:50;not null;index"` // e.g., active, past_due, canceled, trialing
+	ID                   uuid.UUID          `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID               uuid.UUID          `gorm:"type:uuid;not null;index"` // User who subscribed
+	SubjectType          BillingSubjectType `gorm:"type:varchar(10);not null;default:'user';index:idx_subscription_subject"`
+	SubjectID            *uuid.UUID         `gorm:"type:uuid;index:idx_subscription_subject"` // User or team billed; set from UserID for older rows
+	PlanID               string             `gorm:"size:50;not null;index"`                   // Foreign key to Plan.ID
+	StripeSubscriptionID string             `gorm:"size:100;uniqueIndex;not null"`
+	Status               string             `gorm:"size:50;not null;index"` // e.g., active, past_due, canceled, trialing
 	CurrentPeriodStart   time.Time
 	CurrentPeriodEnd     time.Time
 	CancelAtPeriodEnd    bool      `gorm:"default:false"`
@@ -39,6 +119,14 @@ type Subscription struct {
 	UpdatedAt            time.Time `gorm:"autoUpdateTime"`
 }
 
+// BackfillSubscriptionSubjects bills the subscriptions created before teams could
+// subscribe to the users who started them.
+func BackfillSubscriptionSubjects(db *gorm.DB) error {
+	return db.Model(&Subscription{}).
+		Where("subject_id IS NULL").
+		Updates(map[string]interface{}{"subject_type": BillingSubjectUser, "subject_id": gorm.Expr("user_id")}).Error
+}
+
 // SubscriptionItem represents an item (add-on) on a subscription.
 type SubscriptionItem struct {
 	ID                       uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
@@ -53,8 +141,11 @@ type SubscriptionItem struct {
 }
 
 func CreateSubscription(ctx context.Context, db *gorm.DB, userID string, planID string, stripeSubscriptionID string, status string) (*Subscription, error) {
+	id := uuid.MustParse(userID)
 	subscription := &Subscription{
-		UserID:               uuid.MustParse(userID),
+		UserID:               id,
+		SubjectType:          BillingSubjectUser,
+		SubjectID:            &id,
 		PlanID:               planID,
 		StripeSubscriptionID: stripeSubscriptionID,
 		Status:               status,
@@ -116,12 +207,60 @@ func CreateSubscriptionItem(db *gorm.DB, item *SubscriptionItem) error {
 	return db.Create(item).Error
 }
 
-// FindLatestActiveSubscriptionByUserID fetches the most recent active subscription for a user.
+// FindLatestActiveSubscriptionByUserID fetches the most recent active subscription of a
+// user's personal account.
 func FindLatestActiveSubscriptionByUserID(db *gorm.DB, userID uuid.UUID) (*Subscription, error) {
+	return FindLatestActiveSubscriptionBySubject(db, BillingSubjectUser, userID)
+}
+
+// FindLatestActiveSubscriptionBySubject fetches the most recent active subscription of a
+// user or team.
+func FindLatestActiveSubscriptionBySubject(db *gorm.DB, subjectType BillingSubjectType, subjectID uuid.UUID) (*Subscription, error) {
 	var sub Subscription
-	err := db.Where("user_id = ? AND status = ?", userID, "active").Order("created_at DESC").First(&sub).Error
+	err := db.Where("subject_type = ? AND subject_id = ? AND status = ?", subjectType, subjectID, "active").
+		Order("created_at DESC").
+		First(&sub).Error
 	if err != nil {
 		return nil, err
 	}
 	return &sub, nil
 }
+
+// FindOpenSubscriptionsByUser lists the subscriptions of the user's personal account that
+// have not ended.
+func FindOpenSubscriptionsByUser(db *gorm.DB, userID uuid.UUID) ([]Subscription, error) {
+	return FindOpenSubscriptionsBySubject(db, BillingSubjectUser, userID)
+}
+
+// FindOpenSubscriptionsBySubject lists the subscriptions of a user or team that have not
+// ended.
+func FindOpenSubscriptionsBySubject(db *gorm.DB, subjectType BillingSubjectType, subjectID uuid.UUID) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := db.Where("subject_type = ? AND subject_id = ? AND status NOT IN ?", subjectType, subjectID, []string{"canceled", "incomplete_expired"}).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteSubscriptionsBySubject permanently deletes the subscriptions of a user or team
+// with their items.
+func DeleteSubscriptionsBySubject(db *gorm.DB, subjectType BillingSubjectType, subjectID uuid.UUID) error {
+	subscriptions := db.Model(&Subscription{}).Select("id").Where("subject_type = ? AND subject_id = ?", subjectType, subjectID)
+	if err := db.Where("subscription_id IN (?)", subscriptions).Delete(&SubscriptionItem{}).Error; err != nil {
+		return err
+	}
+	return db.Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).Delete(&Subscription{}).Error
+}
+
+// ReassignTeamSubscriptions hands the team subscriptions a user started to the current
+// owners of those teams, e.g. before the user is erased.
+func ReassignTeamSubscriptions(db *gorm.DB, userID uuid.UUID) error {
+	owner := db.Model(&Team{}).Unscoped().Select("owner_id").Where("teams.id = subscriptions.subject_id")
+	return db.Model(&Subscription{}).
+		Where("user_id = ? AND subject_type = ?", userID, BillingSubjectTeam).
+		Update("user_id", owner).Error
+}
+
+// UpdateSubscriptionCancelAtPeriodEnd records whether the subscription ends with its current period.
+func UpdateSubscriptionCancelAtPeriodEnd(db *gorm.DB, subscriptionID uuid.UUID, cancel bool) error {
+	return db.Model(&Subscription{}).Where("id = ?", subscriptionID).Update("cancel_at_period_end", cancel).Error
+}
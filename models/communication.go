@@ -17,6 +17,9 @@ const (
 	CommunicationTypeNote  CommunicationType = "note" // e.g., an internal note added by an admin
 )
 
+// CommunicationRedacted replaces the body of communications about an erased account.
+const CommunicationRedacted = "[redacted: account deleted]"
+
 // Communication represents a record of communication involving a user, often initiated by an admin.
 type Communication struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
@@ -24,12 +27,11 @@ type Communication struct {
 	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
-	// The user this communication is about/to
-	UserID uuid.UUID `gorm:"type:uuid;not null;index"`
+	// The user this communication is about/to; nil once the user's account was erased
+	UserID *uuid.UUID `gorm:"type:uuid;index"`
 
-	// The admin who sent/created this communication (nullable if system-generated?)
-	// Consider if AdminID should be nullable depending on use cases. Assuming not null for now.
-	AdminID uuid.UUID `gorm:"type:uuid;not null;index"`
+	// The admin who sent/created this communication; nil once the admin's account was erased
+	AdminID *uuid.UUID `gorm:"type:uuid;index"`
 
 	Type     CommunicationType `gorm:"type:varchar(20);not null;index"`
 	Subject  string            `gorm:"type:varchar(255)"` // Subject might be optional for notes
@@ -48,8 +50,8 @@ type Communication struct {
 	// Tags pq.StringArray `gorm:"type:text[]"` // Requires importing "github.com/lib/pq"
 
 	// --- Relationships ---
-	User  User `gorm:"foreignKey:UserID"`
-	Admin User `gorm:"foreignKey:AdminID"` // Assumes Admins are also Users
+	User  *User `gorm:"foreignKey:UserID"`
+	Admin *User `gorm:"foreignKey:AdminID"` // Assumes Admins are also Users
 }
 
 // BeforeCreate hook to generate UUID if not set
@@ -59,3 +61,10 @@ func (c *Communication) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return nil
 }
+
+// FindCommunicationsByUser lists the communications about a user, newest first.
+func FindCommunicationsByUser(db *gorm.DB, userID uuid.UUID) ([]Communication, error) {
+	var communications []Communication
+	err := db.Where("user_id = ?", userID).Order("created_at DESC").Find(&communications).Error
+	return communications, err
+}
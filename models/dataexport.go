@@ -0,0 +1,128 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DataExportStatus is the progress of a data export.
+type DataExportStatus string
+
+const (
+	DataExportPending    DataExportStatus = "pending"
+	DataExportProcessing DataExportStatus = "processing"
+	DataExportReady      DataExportStatus = "ready"
+	DataExportFailed     DataExportStatus = "failed"
+)
+
+// DataExport is a user's request for a copy of their data. A background job builds the
+// archive; it can be downloaded until ExpiresAt, after which it is deleted.
+type DataExport struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID      uuid.UUID        `gorm:"type:uuid;not null;index"`
+	Status      DataExportStatus `gorm:"type:varchar(20);not null;default:'pending';index"`
+	Archive     []byte           `gorm:"type:bytea"` // ZIP archive; set once ready
+	Size        int64            `gorm:"not null;default:0"`
+	Error       string           `gorm:"size:255"`
+	CompletedAt *time.Time       `gorm:""`
+	ExpiresAt   *time.Time       `gorm:"index"` // Set once ready
+	CreatedAt   time.Time        `gorm:"autoCreateTime;index"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (e *DataExport) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
+
+// CreateDataExport queues a new export.
+func CreateDataExport(db *gorm.DB, export *DataExport) error {
+	return db.Create(export).Error
+}
+
+// FindLatestDataExportByUser returns the user's most recent export without its archive.
+func FindLatestDataExportByUser(db *gorm.DB, userID uuid.UUID) (*DataExport, error) {
+	var export DataExport
+	err := db.Omit("archive").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindReadyDataExport returns an export with its archive while it can be downloaded.
+func FindReadyDataExport(db *gorm.DB, id uuid.UUID) (*DataExport, error) {
+	var export DataExport
+	err := db.Where("id = ? AND status = ? AND expires_at > ?", id, DataExportReady, time.Now()).
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ClaimPendingDataExport marks the oldest pending export as processing and returns it.
+// Exports claimed by another instance are skipped; gorm.ErrRecordNotFound means none is left.
+func ClaimPendingDataExport(db *gorm.DB) (*DataExport, error) {
+	var export DataExport
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Omit("archive").
+			Where("status = ?", DataExportPending).
+			Order("created_at").
+			First(&export).Error
+		if err != nil {
+			return err
+		}
+		export.Status = DataExportProcessing
+		return tx.Model(&DataExport{}).Where("id = ?", export.ID).Update("status", DataExportProcessing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// CompleteDataExport stores the archive and makes it downloadable until expiresAt.
+func CompleteDataExport(db *gorm.DB, id uuid.UUID, archive []byte, expiresAt time.Time) error {
+	return db.Model(&DataExport{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       DataExportReady,
+		"archive":      archive,
+		"size":         len(archive),
+		"completed_at": time.Now(),
+		"expires_at":   expiresAt,
+	}).Error
+}
+
+// FailDataExport records why an export could not be built.
+func FailDataExport(db *gorm.DB, id uuid.UUID, reason string) error {
+	return db.Model(&DataExport{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       DataExportFailed,
+		"error":        reason,
+		"completed_at": time.Now(),
+	}).Error
+}
+
+// RequeueStaleDataExports returns exports stuck in processing since before the cutoff,
+// e.g. after a restart, to the queue.
+func RequeueStaleDataExports(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Model(&DataExport{}).
+		Where("status = ? AND updated_at < ?", DataExportProcessing, before).
+		Update("status", DataExportPending)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpiredDataExports deletes exports whose download window has passed.
+func DeleteExpiredDataExports(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("expires_at IS NOT NULL AND expires_at <= ?", now).Delete(&DataExport{})
+	return result.RowsAffected, result.Error
+}
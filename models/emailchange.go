@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailChange is a requested change of a user's email address. The change is applied only
+// once the new address confirms it, and the old address can revert it for a while afterwards.
+// Users without a password must first approve the change from their current address.
+type EmailChange struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID           uuid.UUID  `gorm:"type:uuid;not null;index"`
+	OldEmail         string     `gorm:"size:255;not null"`
+	NewEmail         string     `gorm:"size:255;not null"`
+	ApproveTokenHash *string    `gorm:"size:64;uniqueIndex"` // SHA-256 of the approval token sent to the old address; nil when a password was given
+	ConfirmTokenHash *string    `gorm:"size:64;uniqueIndex"` // SHA-256 of the confirmation token sent to the new address; set once approved
+	RevertTokenHash  string     `gorm:"size:64;not null;uniqueIndex"`
+	ApprovedAt       *time.Time `gorm:""`
+	ConfirmedAt      *time.Time `gorm:""`               // Set when the change is applied
+	RevertedAt       *time.Time `gorm:""`               // Set when the change is cancelled or undone from the old address
+	ExpiresAt        time.Time  `gorm:"not null;index"` // End of the approval/confirmation window
+	RevertExpiresAt  time.Time  `gorm:"not null"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (ec *EmailChange) BeforeCreate(tx *gorm.DB) (err error) {
+	if ec.ID == uuid.Nil {
+		ec.ID = uuid.New()
+	}
+	return
+}
+
+// Pending reports whether the change still awaits approval or confirmation.
+func (ec *EmailChange) Pending() bool {
+	return ec.ConfirmedAt == nil && ec.RevertedAt == nil && time.Now().Before(ec.ExpiresAt)
+}
+
+// FindPendingEmailChangeByUser retrieves the user's most recent change that is neither applied nor cancelled.
+func FindPendingEmailChangeByUser(db *gorm.DB, userID uuid.UUID) (*EmailChange, error) {
+	var change EmailChange
+	err := db.Where("user_id = ? AND confirmed_at IS NULL AND reverted_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at desc").
+		First(&change).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ReplaceEmailChange deletes the user's unapplied changes and stores the new one.
+// Applied changes are kept so they can still be reverted.
+func ReplaceEmailChange(db *gorm.DB, change *EmailChange) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND confirmed_at IS NULL", change.UserID).Delete(&EmailChange{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+}
+
+// FindEmailChangeByTokenHashForUpdate retrieves and locks the change whose token of the given
+// kind ("approve", "confirm" or "revert") matches the hash. It must be called inside a transaction.
+func FindEmailChangeByTokenHashForUpdate(tx *gorm.DB, kind, tokenHash string) (*EmailChange, error) {
+	var column string
+	switch kind {
+	case "approve":
+		column = "approve_token_hash"
+	case "confirm":
+		column = "confirm_token_hash"
+	case "revert":
+		column = "revert_token_hash"
+	default:
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var change EmailChange
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(column+" = ?", tokenHash).First(&change).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+// UpdateEmailChange persists the token and state fields of a change.
+func UpdateEmailChange(db *gorm.DB, change *EmailChange) error {
+	return db.Model(change).Updates(map[string]interface{}{
+		"approve_token_hash": change.ApproveTokenHash,
+		"confirm_token_hash": change.ConfirmTokenHash,
+		"approved_at":        change.ApprovedAt,
+		"confirmed_at":       change.ConfirmedAt,
+		"reverted_at":        change.RevertedAt,
+		"expires_at":         change.ExpiresAt,
+	}).Error
+}
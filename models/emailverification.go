@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailVerificationToken stores a hashed single-use link token proving ownership of an email address.
+// The address is recorded so that a token sent before an email change cannot verify the new address.
+type EmailVerificationToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Email     string     `gorm:"size:255;not null"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"` // SHA-256 of the token, hex encoded
+	ExpiresAt time.Time  `gorm:"not null;index"`
+	UsedAt    *time.Time `gorm:""`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (evt *EmailVerificationToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if evt.ID == uuid.Nil {
+		evt.ID = uuid.New()
+	}
+	return
+}
+
+// ReplaceEmailVerificationToken deletes any existing verification tokens for the user and stores the new one.
+func ReplaceEmailVerificationToken(db *gorm.DB, token *EmailVerificationToken) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&EmailVerificationToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// FindEmailVerificationTokenByHash retrieves a verification token by its hash.
+func FindEmailVerificationTokenByHash(db *gorm.DB, tokenHash string) (*EmailVerificationToken, error) {
+	var token EmailVerificationToken
+	err := db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindEmailVerificationTokenByHashForUpdate is like FindEmailVerificationTokenByHash but locks the row.
+// It must be called inside a transaction.
+func FindEmailVerificationTokenByHashForUpdate(tx *gorm.DB, tokenHash string) (*EmailVerificationToken, error) {
+	return FindEmailVerificationTokenByHash(tx.Clauses(clause.Locking{Strength: "UPDATE"}), tokenHash)
+}
+
+// MarkEmailVerificationTokenUsed consumes a verification token.
+func MarkEmailVerificationTokenUsed(db *gorm.DB, token *EmailVerificationToken) error {
+	now := time.Now()
+	if err := db.Model(token).Update("used_at", now).Error; err != nil {
+		return err
+	}
+	token.UsedAt = &now
+	return nil
+}
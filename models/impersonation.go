@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the impersonation log.
+const (
+	ImpersonationActionStart   = "start"
+	ImpersonationActionRequest = "request"
+)
+
+// ImpersonationLog is the audit trail of an admin acting as a user: one entry when the
+// impersonation starts and one for every request made with it.
+type ImpersonationLog struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	AdminID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Action    string    `gorm:"size:16;not null"`
+	Reason    string    `gorm:"size:500"` // Why the admin started impersonating; set on the start entry
+	Method    string    `gorm:"size:10"`
+	Path      string    `gorm:"size:512"`
+	Status    int       `gorm:""`
+	IPAddress string    `gorm:"size:64"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+
+	Admin User `gorm:"foreignKey:AdminID"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (il *ImpersonationLog) BeforeCreate(tx *gorm.DB) (err error) {
+	if il.ID == uuid.Nil {
+		il.ID = uuid.New()
+	}
+	return
+}
+
+// CreateImpersonationLog appends an entry to the audit trail.
+func CreateImpersonationLog(db *gorm.DB, entry *ImpersonationLog) error {
+	return db.Create(entry).Error
+}
+
+// FindImpersonationLogsByUser lists the most recent entries for an impersonated user, newest first.
+func FindImpersonationLogsByUser(db *gorm.DB, userID uuid.UUID, limit int) ([]ImpersonationLog, error) {
+	var entries []ImpersonationLog
+	err := db.Preload("Admin").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -20,6 +20,9 @@ const (
 	StatusExpired  InvitationStatus = "expired"
 )
 
+// InvitationTTL is how long an invitation link stays valid.
+const InvitationTTL = 7 * 24 * time.Hour
+
 // Invitation represents a request for a user (identified by email) to join a team.
 type Invitation struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
@@ -65,9 +68,9 @@ func (inv *Invitation) BeforeCreate(tx *gorm.DB) (err error) {
 		inv.Token = token
 	}
 
-	// Set default expiry (e.g., 7 days from now) if not set
+	// Set default expiry if not set
 	if inv.ExpiresAt.IsZero() {
-		inv.ExpiresAt = time.Now().Add(7 * 24 * time.Hour)
+		inv.ExpiresAt = time.Now().Add(InvitationTTL)
 	}
 
 	// Set default status if not set
@@ -117,6 +120,13 @@ func FindPendingInvitationsByTeam(db *gorm.DB, teamID uuid.UUID) ([]Invitation,
 	return invitations, nil
 }
 
+// CountPendingInvitationsByTeam counts the team's invitations awaiting an answer.
+func CountPendingInvitationsByTeam(db *gorm.DB, teamID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&Invitation{}).Where("team_id = ? AND status = ?", teamID, StatusPending).Count(&count).Error
+	return count, err
+}
+
 // FindPendingInvitationByTeamAndEmail retrieves a pending invitation for a specific email and team.
 func FindPendingInvitationByTeamAndEmail(db *gorm.DB, teamID uuid.UUID, email string) (*Invitation, error) {
 	var invitation Invitation
@@ -300,3 +310,46 @@ func UpdateInvitationStatus(db *gorm.DB, invitationID uuid.UUID, newStatus Invit
 	invitation.Status = newStatus
 	return &invitation, nil
 }
+
+// RenewInvitation gives an invitation a new token and expiry and makes it pending again,
+// so links sent earlier stop working.
+func RenewInvitation(db *gorm.DB, invitation *Invitation) error {
+	token, err := GenerateInvitationToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(InvitationTTL)
+	result := db.Model(invitation).
+		Where("status IN ?", []InvitationStatus{StatusPending, StatusExpired}).
+		Updates(map[string]interface{}{"token": token, "expires_at": expiresAt, "status": StatusPending})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invitation is no longer pending")
+	}
+	invitation.Token = token
+	invitation.ExpiresAt = expiresAt
+	invitation.Status = StatusPending
+	return nil
+}
+
+// FindExpiredPendingInvitations retrieves pending invitations whose link expired before
+// now, preloading the team and the inviter.
+func FindExpiredPendingInvitations(db *gorm.DB, now time.Time, limit int) ([]Invitation, error) {
+	var invitations []Invitation
+	err := db.Preload("Team").Preload("Inviter").
+		Where("status = ? AND expires_at < ?", StatusPending, now).
+		Order("expires_at").Limit(limit).
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// ExpireInvitation marks a pending invitation expired. It reports false when the
+// invitation was answered or expired in the meantime.
+func ExpireInvitation(db *gorm.DB, invitationID uuid.UUID) (bool, error) {
+	result := db.Model(&Invitation{}).
+		Where("id = ? AND status = ?", invitationID, StatusPending).
+		Update("status", StatusExpired)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InvitationImportStatus is the progress of a bulk invitation import.
+type InvitationImportStatus string
+
+const (
+	InvitationImportPending    InvitationImportStatus = "pending"
+	InvitationImportProcessing InvitationImportStatus = "processing"
+	InvitationImportCompleted  InvitationImportStatus = "completed"
+	InvitationImportFailed     InvitationImportStatus = "failed"
+)
+
+// ImportRowStatus is the outcome for one person listed in an invitation import.
+type ImportRowStatus string
+
+const (
+	ImportRowInvited ImportRowStatus = "invited"
+	ImportRowSkipped ImportRowStatus = "skipped" // Already a member, already invited or listed twice
+	ImportRowFailed  ImportRowStatus = "failed"
+)
+
+// InvitationImportRow reports what happened to one line of an imported CSV file.
+type InvitationImportRow struct {
+	Line    int             `json:"line"`
+	Email   string          `json:"email"`
+	Role    string          `json:"role"`
+	Status  ImportRowStatus `json:"status"`
+	Message string          `json:"message,omitempty"`
+}
+
+// InvitationImport is a CSV file of people to invite to a team. A background job checks
+// every row like a single invitation and records the outcome in Results.
+type InvitationImport struct {
+	ID          uuid.UUID              `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TeamID      uuid.UUID              `gorm:"type:uuid;not null;index"`
+	InviterID   uuid.UUID              `gorm:"type:uuid;not null;index"` // User who uploaded the file
+	Status      InvitationImportStatus `gorm:"type:varchar(20);not null;default:'pending';index"`
+	FileName    string                 `gorm:"size:255"`
+	File        []byte                 `gorm:"type:bytea"`
+	Results     datatypes.JSON         `gorm:"type:jsonb"` // []InvitationImportRow; set once completed
+	Total       int                    `gorm:"not null;default:0"`
+	Invited     int                    `gorm:"not null;default:0"`
+	Skipped     int                    `gorm:"not null;default:0"`
+	Failed      int                    `gorm:"not null;default:0"`
+	Error       string                 `gorm:"size:255"`
+	CompletedAt *time.Time             `gorm:""`
+	CreatedAt   time.Time              `gorm:"autoCreateTime;index"`
+	UpdatedAt   time.Time              `gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (i *InvitationImport) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return
+}
+
+// Rows returns the outcome of each row once the import completed.
+func (i *InvitationImport) Rows() []InvitationImportRow {
+	rows := []InvitationImportRow{}
+	if len(i.Results) > 0 {
+		_ = json.Unmarshal(i.Results, &rows)
+	}
+	return rows
+}
+
+// CreateInvitationImport queues a new import.
+func CreateInvitationImport(db *gorm.DB, imp *InvitationImport) error {
+	return db.Create(imp).Error
+}
+
+// FindInvitationImportByIDAndTeam retrieves one of the team's imports without its file.
+func FindInvitationImportByIDAndTeam(db *gorm.DB, id, teamID uuid.UUID) (*InvitationImport, error) {
+	var imp InvitationImport
+	err := db.Omit("file").Where("id = ? AND team_id = ?", id, teamID).First(&imp).Error
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// ClaimPendingInvitationImport marks the oldest pending import as processing and returns
+// it with its file. Imports claimed by another instance are skipped; gorm.ErrRecordNotFound
+// means none is left.
+func ClaimPendingInvitationImport(db *gorm.DB) (*InvitationImport, error) {
+	var imp InvitationImport
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", InvitationImportPending).
+			Order("created_at").
+			First(&imp).Error
+		if err != nil {
+			return err
+		}
+		imp.Status = InvitationImportProcessing
+		return tx.Model(&InvitationImport{}).Where("id = ?", imp.ID).Update("status", InvitationImportProcessing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// CompleteInvitationImport stores the outcome of every row and its totals on the import.
+func CompleteInvitationImport(db *gorm.DB, imp *InvitationImport, rows []InvitationImportRow) error {
+	results, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	imp.Status = InvitationImportCompleted
+	imp.Results = results
+	imp.Total = len(rows)
+	imp.Invited, imp.Skipped, imp.Failed = 0, 0, 0
+	for _, row := range rows {
+		switch row.Status {
+		case ImportRowInvited:
+			imp.Invited++
+		case ImportRowSkipped:
+			imp.Skipped++
+		case ImportRowFailed:
+			imp.Failed++
+		}
+	}
+	imp.CompletedAt = &now
+	return db.Model(&InvitationImport{}).Where("id = ?", imp.ID).Updates(map[string]interface{}{
+		"status":       imp.Status,
+		"results":      imp.Results,
+		"total":        imp.Total,
+		"invited":      imp.Invited,
+		"skipped":      imp.Skipped,
+		"failed":       imp.Failed,
+		"completed_at": now,
+	}).Error
+}
+
+// FailInvitationImport records why an import could not be processed.
+func FailInvitationImport(db *gorm.DB, id uuid.UUID, reason string) error {
+	return db.Model(&InvitationImport{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       InvitationImportFailed,
+		"error":        reason,
+		"completed_at": time.Now(),
+	}).Error
+}
+
+// RequeueStaleInvitationImports returns imports stuck in processing since before the
+// cutoff, e.g. after a restart, to the queue. Rows invited before the restart are skipped
+// as already invited on the next run.
+func RequeueStaleInvitationImports(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Model(&InvitationImport{}).
+		Where("status = ? AND updated_at < ?", InvitationImportProcessing, before).
+		Update("status", InvitationImportPending)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JoinRequestStatus defines the possible states of a join request.
+type JoinRequestStatus string
+
+const (
+	JoinRequestPending  JoinRequestStatus = "pending"
+	JoinRequestApproved JoinRequestStatus = "approved"
+	JoinRequestRejected JoinRequestStatus = "rejected"
+)
+
+// ErrJoinRequestDecided is returned when a join request was already approved or rejected.
+var ErrJoinRequestDecided = errors.New("join request is no longer pending")
+
+// JoinRequest is a user's request to join a team through a verified email domain whose
+// join policy asks for approval. A user has at most one pending request per team.
+type JoinRequest struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TeamID      uuid.UUID         `gorm:"type:uuid;not null;index;uniqueIndex:idx_join_request_pending,where:status = 'pending'"`
+	UserID      uuid.UUID         `gorm:"type:uuid;not null;index;uniqueIndex:idx_join_request_pending,where:status = 'pending'"`
+	Status      JoinRequestStatus `gorm:"type:varchar(20);not null;default:pending"`
+	DecidedByID *uuid.UUID        `gorm:"type:uuid"` // Admin who approved or rejected the request
+	DecidedAt   *time.Time        `gorm:""`
+	CreatedAt   time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time         `gorm:"autoUpdateTime"`
+
+	User User `gorm:"foreignKey:UserID"`
+	Team Team `gorm:"foreignKey:TeamID"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (r *JoinRequest) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.Status == "" {
+		r.Status = JoinRequestPending
+	}
+	return
+}
+
+// CreateJoinRequest stores a new pending join request.
+func CreateJoinRequest(db *gorm.DB, request *JoinRequest) error {
+	return db.Create(request).Error
+}
+
+// FindPendingJoinRequest retrieves the user's pending request to join the team.
+func FindPendingJoinRequest(db *gorm.DB, teamID, userID uuid.UUID) (*JoinRequest, error) {
+	var request JoinRequest
+	err := db.Where("team_id = ? AND user_id = ? AND status = ?", teamID, userID, JoinRequestPending).First(&request).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindPendingJoinRequestsByTeam lists the team's pending join requests, oldest first,
+// with the requesting users.
+func FindPendingJoinRequestsByTeam(db *gorm.DB, teamID uuid.UUID) ([]JoinRequest, error) {
+	var requests []JoinRequest
+	err := db.Preload("User").
+		Where("team_id = ? AND status = ?", teamID, JoinRequestPending).
+		Order("created_at").
+		Find(&requests).Error
+	return requests, err
+}
+
+// FindJoinRequestByIDAndTeam retrieves one of the team's join requests with the user.
+func FindJoinRequestByIDAndTeam(db *gorm.DB, id, teamID uuid.UUID) (*JoinRequest, error) {
+	var request JoinRequest
+	err := db.Preload("User").Where("id = ? AND team_id = ?", id, teamID).First(&request).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// DecideJoinRequest approves or rejects a pending join request. Approving adds the user
+// to the team as a member in the same transaction. It returns ErrJoinRequestDecided when
+// the request was decided in the meantime.
+func DecideJoinRequest(db *gorm.DB, request *JoinRequest, deciderID uuid.UUID, approve bool) (*Membership, error) {
+	status := JoinRequestRejected
+	if approve {
+		status = JoinRequestApproved
+	}
+	now := time.Now()
+	var membership *Membership
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&JoinRequest{}).
+			Where("id = ? AND status = ?", request.ID, JoinRequestPending).
+			Updates(map[string]interface{}{"status": status, "decided_by_id": deciderID, "decided_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrJoinRequestDecided
+		}
+		if !approve {
+			return nil
+		}
+		var err error
+		membership, _, err = EnsureMembership(tx, request.UserID, request.TeamID, RoleMember)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	request.Status = status
+	request.DecidedByID = &deciderID
+	request.DecidedAt = &now
+	return membership, nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginCode stores a hashed one-time passwordless login code.
+// It is only used when Redis is not configured; otherwise codes live in Redis.
+type LoginCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	CodeHash  string     `gorm:"size:64;not null"` // HMAC-SHA256 of the code, hex encoded
+	Attempts  int        `gorm:"not null;default:0"`
+	ExpiresAt time.Time  `gorm:"not null;index"`
+	UsedAt    *time.Time `gorm:""` // Set once the code is consumed or burned after too many attempts
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (lc *LoginCode) BeforeCreate(tx *gorm.DB) (err error) {
+	if lc.ID == uuid.Nil {
+		lc.ID = uuid.New()
+	}
+	return
+}
+
+// FindActiveLoginCodeByUser retrieves the most recent unused, unexpired login code for a user.
+func FindActiveLoginCodeByUser(db *gorm.DB, userID uuid.UUID) (*LoginCode, error) {
+	var code LoginCode
+	err := db.Where("user_id = ? AND used_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at desc").
+		First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// FindActiveLoginCodeByUserForUpdate is like FindActiveLoginCodeByUser but locks the row.
+// It must be called inside a transaction.
+func FindActiveLoginCodeByUserForUpdate(tx *gorm.DB, userID uuid.UUID) (*LoginCode, error) {
+	return FindActiveLoginCodeByUser(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+}
+
+// ReplaceLoginCode deletes any existing login codes for the user and stores the new one.
+func ReplaceLoginCode(db *gorm.DB, code *LoginCode) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", code.UserID).Delete(&LoginCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+}
+
+// UpdateLoginCodeAttempt persists the attempt counter and used marker of a login code.
+func UpdateLoginCodeAttempt(db *gorm.DB, code *LoginCode) error {
+	return db.Model(code).Updates(map[string]interface{}{
+		"attempts": code.Attempts,
+		"used_at":  code.UsedAt,
+	}).Error
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Methods a login attempt can use.
+const (
+	LoginMethodPassword = "password"
+	LoginMethodCode     = "code"
+	LoginMethodOAuth    = "oauth"
+	LoginMethodSSO      = "sso"
+	LoginMethodPasskey  = "passkey"
+)
+
+// Reasons recorded when a login attempt fails.
+const (
+	LoginFailureUnknownAccount      = "unknown_account"
+	LoginFailureInvalidCredentials  = "invalid_credentials"
+	LoginFailureInvalidSecondFactor = "invalid_second_factor"
+	LoginFailureSuspended           = "account_suspended"
+	LoginFailureThrottled           = "throttled"
+)
+
+// LoginEvent records one login attempt, successful or not. Attempts for an unknown
+// email have no UserID. Successful attempts are recorded when the session is issued,
+// so logins waiting on a second factor show up once it is completed.
+type LoginEvent struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID            *uuid.UUID `gorm:"type:uuid;index"`
+	Email             string     `gorm:"size:255;index"`
+	Method            string     `gorm:"size:16;not null"` // First factor of the login
+	Success           bool       `gorm:"not null;default:false"`
+	FailureReason     string     `gorm:"size:32"`
+	IPAddress         string     `gorm:"size:64"`
+	UserAgent         string     `gorm:"size:512"`
+	DeviceFingerprint string     `gorm:"size:64;index"`
+	DeviceLabel       string     `gorm:"size:100"` // e.g. "Firefox on Windows"
+	CreatedAt         time.Time  `gorm:"autoCreateTime;index"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (e *LoginEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
+
+// CreateLoginEvent records a login attempt.
+func CreateLoginEvent(db *gorm.DB, event *LoginEvent) error {
+	return db.Create(event).Error
+}
+
+// FindLoginEventsByUser returns the user's most recent login attempts, newest first.
+func FindLoginEventsByUser(db *gorm.DB, userID uuid.UUID, limit int) ([]LoginEvent, error) {
+	var events []LoginEvent
+	err := db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// FindKnownDevice reports whether the user has signed in successfully before, and whether
+// one of those logins came from a device with the given fingerprint.
+func FindKnownDevice(db *gorm.DB, userID uuid.UUID, fingerprint string) (hasLogins, known bool, err error) {
+	var fingerprints []string
+	err = db.Model(&LoginEvent{}).
+		Where("user_id = ? AND success = ?", userID, true).
+		Distinct("device_fingerprint").
+		Pluck("device_fingerprint", &fingerprints).Error
+	if err != nil {
+		return false, false, err
+	}
+	for _, f := range fingerprints {
+		if f == fingerprint {
+			return true, true, nil
+		}
+	}
+	return len(fingerprints) > 0, false, nil
+}
@@ -24,9 +24,12 @@ type Membership struct {
 	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
-	UserID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_user_team;not null"` // Part of composite unique index
-	TeamID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_user_team;not null"` // Part of composite unique index
-	Role   Role      `gorm:"type:varchar(20);not null"`
+	UserID uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_user_team;not null"` // Part of composite unique index
+	TeamID uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_user_team;not null"` // Part of composite unique index
+	Role   Role       `gorm:"type:varchar(20);not null"`
+	RoleID *uuid.UUID `gorm:"type:uuid;index"` // Custom TeamRole replacing the permissions of Role
+
+	SCIMExternalID *string `gorm:"size:255"` // The directory's ID for the user, set by SCIM provisioning
 
 	// --- Relationships ---
 	User User `gorm:"foreignKey:UserID"` // Belongs To User
@@ -90,7 +93,8 @@ func (m *Membership) BeforeUpdate(tx *gorm.DB) (err error) {
 	return nil
 }
 
-// UpdateMembershipRole updates the role for a specific membership record.
+// UpdateMembershipRole gives a membership a built-in role. Any custom role is dropped, so
+// the member holds exactly the permissions of the new role.
 // It relies on the BeforeUpdate hook to validate the new role.
 func UpdateMembershipRole(db *gorm.DB, membershipID uuid.UUID, newRole Role) error {
 	// Validate role before attempting update (though BeforeUpdate hook also does this)
@@ -101,7 +105,10 @@ func UpdateMembershipRole(db *gorm.DB, membershipID uuid.UUID, newRole Role) err
 		return errors.New("invalid target role for update")
 	}
 
-	result := db.Model(&Membership{}).Where("id = ?", membershipID).Update("role", newRole)
+	result := db.Model(&Membership{}).Where("id = ?", membershipID).Updates(map[string]interface{}{
+		"role":    newRole,
+		"role_id": nil,
+	})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -111,6 +118,29 @@ func UpdateMembershipRole(db *gorm.DB, membershipID uuid.UUID, newRole Role) err
 	return nil
 }
 
+// AssignMembershipRole gives a member a built-in role, or a custom role when roleID is set.
+// Members with a custom role keep the member role underneath.
+func AssignMembershipRole(db *gorm.DB, membership *Membership, role Role, roleID *uuid.UUID) error {
+	switch role {
+	case RoleAdmin, RoleMember:
+	default:
+		return errors.New("invalid target role for update")
+	}
+	if roleID != nil {
+		role = RoleMember
+	}
+	result := db.Model(membership).Updates(map[string]interface{}{"role": role, "role_id": roleID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	membership.Role = role
+	membership.RoleID = roleID
+	return nil
+}
+
 // DeleteMembershipByUserAndTeam performs a soft delete on a membership record
 // based on the user ID and team ID. It finds the record first.
 func DeleteMembershipByUserAndTeam(db *gorm.DB, userID uuid.UUID, teamID uuid.UUID) error {
@@ -136,3 +166,97 @@ func DeleteMembershipByUserAndTeam(db *gorm.DB, userID uuid.UUID, teamID uuid.UU
 	}
 	return nil
 }
+
+// EnsureMembership returns the user's membership of the team, creating it with the given
+// role when there is none. A removed (soft-deleted) membership is restored with the role
+// instead, since the unique user/team index still covers it. created reports whether the
+// user was not a member before.
+func EnsureMembership(db *gorm.DB, userID, teamID uuid.UUID, role Role) (membership *Membership, created bool, err error) {
+	var existing Membership
+	err = db.Transaction(func(tx *gorm.DB) error {
+		findErr := tx.Unscoped().Where("user_id = ? AND team_id = ?", userID, teamID).First(&existing).Error
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			existing = Membership{UserID: userID, TeamID: teamID, Role: role}
+			created = true
+			return tx.Create(&existing).Error
+		}
+		if findErr != nil {
+			return findErr
+		}
+		if !existing.DeletedAt.Valid {
+			return nil
+		}
+		created = true
+		existing.Role = role
+		existing.RoleID = nil
+		existing.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&existing).Updates(map[string]interface{}{"role": role, "role_id": nil, "deleted_at": nil}).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &existing, created, nil
+}
+
+// FindMembershipsByTeamWithUsers retrieves a team's memberships with their users. With
+// includeRemoved, soft-deleted memberships are returned too.
+func FindMembershipsByTeamWithUsers(db *gorm.DB, teamID uuid.UUID, includeRemoved bool) ([]Membership, error) {
+	query := db.Preload("User")
+	if includeRemoved {
+		query = query.Unscoped()
+	}
+	var memberships []Membership
+	if err := query.Where("team_id = ?", teamID).Order("created_at").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// FindMembershipByUserAndTeamUnscoped is FindMembershipByUserAndTeam including soft-deleted memberships.
+func FindMembershipByUserAndTeamUnscoped(db *gorm.DB, userID, teamID uuid.UUID) (*Membership, error) {
+	var membership Membership
+	err := db.Unscoped().Preload("User").Where("user_id = ? AND team_id = ?", userID, teamID).First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// UpdateMembershipSCIMExternalID stores the directory's ID for a member, including removed members.
+func UpdateMembershipSCIMExternalID(db *gorm.DB, membershipID uuid.UUID, externalID *string) error {
+	return db.Unscoped().Model(&Membership{}).Where("id = ?", membershipID).Update("scim_external_id", externalID).Error
+}
+
+// PurgeMembershipByUserAndTeam permanently deletes a membership, including a soft-deleted one.
+func PurgeMembershipByUserAndTeam(db *gorm.DB, userID, teamID uuid.UUID) error {
+	return db.Unscoped().Where("user_id = ? AND team_id = ?", userID, teamID).Delete(&Membership{}).Error
+}
+
+// CountMembershipsByUser counts the teams the user currently belongs to.
+func CountMembershipsByUser(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&Membership{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// CountMembershipsByTeam counts the team's current members.
+func CountMembershipsByTeam(db *gorm.DB, teamID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&Membership{}).Where("team_id = ?", teamID).Count(&count).Error
+	return count, err
+}
+
+// CountMembershipsInTeamsOwnedBy counts the members of the teams the user owns, the owner
+// included. Teams with an active subscription in their own name are left out because they
+// are billed separately.
+func CountMembershipsInTeamsOwnedBy(db *gorm.DB, ownerID uuid.UUID) (int64, error) {
+	var count int64
+	billed := db.Model(&Subscription{}).Select("subject_id").Where("subject_type = ? AND status = ?", BillingSubjectTeam, "active")
+	err := db.Model(&Membership{}).
+		Where("team_id IN (?)", db.Model(&Team{}).Select("id").Where("owner_id = ? AND id NOT IN (?)", ownerID, billed)).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecoveryCode is a hashed one-time code that can replace a TOTP code when the authenticator is lost.
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	CodeHash  string     `gorm:"size:64;not null;index"` // SHA-256 of the normalized code, hex encoded
+	UsedAt    *time.Time `gorm:""`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (rc *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	if rc.ID == uuid.Nil {
+		rc.ID = uuid.New()
+	}
+	return
+}
+
+// UpdateUserTOTPSecret stores (or clears, when nil) the user's TOTP secret and resets the replay marker.
+func UpdateUserTOTPSecret(db *gorm.DB, userID uuid.UUID, secret *string) error {
+	return db.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}).Error
+}
+
+// AdvanceUserTOTPStep records a used TOTP time step.
+// It returns false if the step was already used (or superseded), so concurrent replays are rejected.
+func AdvanceUserTOTPStep(db *gorm.DB, userID uuid.UUID, step int64) (bool, error) {
+	result := db.Model(&User{}).Where("id = ? AND totp_last_step < ?", userID, step).Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReplaceRecoveryCodes deletes the user's existing recovery codes and stores the given hashes.
+func ReplaceRecoveryCodes(db *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]RecoveryCode, len(codeHashes))
+		for i, h := range codeHashes {
+			codes[i] = RecoveryCode{UserID: userID, CodeHash: h}
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ConsumeRecoveryCode marks a matching unused recovery code as used.
+// It returns false if no unused code matches.
+func ConsumeRecoveryCode(db *gorm.DB, userID uuid.UUID, codeHash string) (bool, error) {
+	result := db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnusedRecoveryCodes counts the recovery codes the user can still use.
+func CountUnusedRecoveryCodes(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var count int64
+	result := db.Model(&RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count)
+	return count, result.Error
+}
+
+// DeleteRecoveryCodesByUser removes all recovery codes for the user.
+func DeleteRecoveryCodesByUser(db *gorm.DB, userID uuid.UUID) error {
+	return db.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error
+}
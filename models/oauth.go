@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserIdentity links a user to an account at an external OAuth/OIDC provider.
+// A user can have one identity per provider account, and several providers.
+type UserIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Provider    string     `gorm:"size:64;not null;uniqueIndex:idx_user_identity_provider_subject"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_user_identity_provider_subject"` // The provider's stable user ID
+	Email       string     `gorm:"size:255"`                                                         // Email reported by the provider, if any
+	LastLoginAt *time.Time `gorm:""`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (ui *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	if ui.ID == uuid.Nil {
+		ui.ID = uuid.New()
+	}
+	return
+}
+
+// FindUserIdentity retrieves the identity of a provider account.
+func FindUserIdentity(db *gorm.DB, provider, subject string) (*UserIdentity, error) {
+	var identity UserIdentity
+	err := db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// FindUserIdentitiesByUser lists the provider accounts linked to a user.
+func FindUserIdentitiesByUser(db *gorm.DB, userID uuid.UUID) ([]UserIdentity, error) {
+	var identities []UserIdentity
+	err := db.Where("user_id = ?", userID).Order("created_at asc").Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// CreateUserIdentity links a provider account to a user.
+func CreateUserIdentity(db *gorm.DB, identity *UserIdentity) error {
+	return db.Create(identity).Error
+}
+
+// TouchUserIdentity records a sign-in through the identity and refreshes its email.
+func TouchUserIdentity(db *gorm.DB, id uuid.UUID, email string) error {
+	return db.Model(&UserIdentity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":         email,
+		"last_login_at": time.Now(),
+	}).Error
+}
+
+// DeleteUserIdentity unlinks a provider account owned by the given user.
+func DeleteUserIdentity(db *gorm.DB, id, userID uuid.UUID) error {
+	result := db.Where("id = ? AND user_id = ?", id, userID).Delete(&UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// OAuthState is a pending OAuth/OIDC authorization request.
+// It is only used when Redis is not configured; otherwise states live in Redis.
+type OAuthState struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	StateHash    string     `gorm:"size:64;not null;uniqueIndex"` // SHA-256 of the state parameter, hex encoded
+	Provider     string     `gorm:"size:64;not null"`
+	CodeVerifier string     `gorm:"size:128;not null"` // PKCE verifier
+	Nonce        string     `gorm:"size:64;not null"`
+	LinkUserID   *uuid.UUID `gorm:"type:uuid"` // Set when a signed-in user connects another provider
+	TeamID       *uuid.UUID `gorm:"type:uuid"` // Set for sign-ins through a team's identity provider
+	ExpiresAt    time.Time  `gorm:"not null;index"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (st *OAuthState) BeforeCreate(tx *gorm.DB) (err error) {
+	if st.ID == uuid.Nil {
+		st.ID = uuid.New()
+	}
+	return
+}
+
+// CreateOAuthState stores a pending authorization request.
+func CreateOAuthState(db *gorm.DB, state *OAuthState) error {
+	return db.Create(state).Error
+}
+
+// ConsumeOAuthState deletes and returns the unexpired pending request with the given state hash.
+// Expired requests are removed along the way.
+func ConsumeOAuthState(db *gorm.DB, stateHash string) (*OAuthState, error) {
+	var state OAuthState
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", time.Now()).Delete(&OAuthState{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&state).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &state, nil
+}
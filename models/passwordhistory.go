@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory keeps the hashes of a user's previous passwords so they are not reused.
+type PasswordHistory struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	PasswordHash string    `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (h *PasswordHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return
+}
+
+// AddPasswordHistory records a previous password hash and keeps only the newest keep entries.
+func AddPasswordHistory(db *gorm.DB, userID uuid.UUID, hash string, keep int) error {
+	if err := db.Create(&PasswordHistory{UserID: userID, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+	newest := db.Model(&PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(keep)
+	return db.Where("user_id = ? AND id NOT IN (?)", userID, newest).Delete(&PasswordHistory{}).Error
+}
+
+// FindRecentPasswordHashes returns the user's most recent previous password hashes, newest first.
+func FindRecentPasswordHashes(db *gorm.DB, userID uuid.UUID, limit int) ([]string, error) {
+	var hashes []string
+	err := db.Model(&PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("password_hash", &hashes).Error
+	return hashes, err
+}
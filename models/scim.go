@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SCIMToken is a bearer token a team's directory uses to call the SCIM endpoints.
+// Only the SHA-256 hash of the token is stored.
+type SCIMToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TeamID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Name       string     `gorm:"size:100;not null"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex"`
+	Prefix     string     `gorm:"size:16;not null"` // Start of the token, shown to tell tokens apart
+	LastUsedAt *time.Time `gorm:""`
+	RevokedAt  *time.Time `gorm:""`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+
+	Team Team `gorm:"foreignKey:TeamID"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (st *SCIMToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if st.ID == uuid.Nil {
+		st.ID = uuid.New()
+	}
+	return
+}
+
+// CreateSCIMToken stores a new token.
+func CreateSCIMToken(db *gorm.DB, token *SCIMToken) error {
+	return db.Create(token).Error
+}
+
+// FindActiveSCIMTokenByHash retrieves an unrevoked token with its team.
+func FindActiveSCIMTokenByHash(db *gorm.DB, tokenHash string) (*SCIMToken, error) {
+	var token SCIMToken
+	err := db.Preload("Team").Where("token_hash = ? AND revoked_at IS NULL", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindActiveSCIMTokensByTeam lists a team's unrevoked tokens, newest first.
+func FindActiveSCIMTokensByTeam(db *gorm.DB, teamID uuid.UUID) ([]SCIMToken, error) {
+	var tokens []SCIMToken
+	if err := db.Where("team_id = ? AND revoked_at IS NULL", teamID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// TouchSCIMToken records that the token was used.
+func TouchSCIMToken(db *gorm.DB, id uuid.UUID) error {
+	return db.Model(&SCIMToken{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
+
+// RevokeSCIMToken revokes one of a team's tokens.
+func RevokeSCIMToken(db *gorm.DB, id, teamID uuid.UUID) error {
+	result := db.Model(&SCIMToken{}).
+		Where("id = ? AND team_id = ? AND revoked_at IS NULL", id, teamID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
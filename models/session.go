@@ -0,0 +1,179 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reasons recorded when a session is revoked.
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedByUser         = "revoked"
+	SessionRevokedTokenReuse     = "token_reuse"
+	SessionRevokedPasswordReset  = "password_reset"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevokedEmailChange    = "email_change"
+	SessionRevokedSuspended      = "suspended"
+	SessionRevokedImpersonation  = "impersonation_ended"
+)
+
+// UserSession is a signed-in device. Access tokens carry its ID in the "sid" claim
+// and stop being accepted as soon as the session is revoked.
+type UserSession struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index"`
+	IPAddress      string     `gorm:"size:64"`
+	UserAgent      string     `gorm:"size:512"`
+	LastSeenAt     time.Time  `gorm:"not null"`
+	ExpiresAt      time.Time  `gorm:"not null;index"` // Absolute end of the refresh token chain
+	RevokedAt      *time.Time `gorm:"index"`
+	RevokedReason  string     `gorm:"size:32"`
+	ImpersonatorID *uuid.UUID `gorm:"type:uuid;index"` // Admin acting as the user; nil for the user's own sessions
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (us *UserSession) BeforeCreate(tx *gorm.DB) (err error) {
+	if us.ID == uuid.Nil {
+		us.ID = uuid.New()
+	}
+	return
+}
+
+// Active reports whether the session can still be used.
+func (us *UserSession) Active() bool {
+	return us.RevokedAt == nil && time.Now().Before(us.ExpiresAt)
+}
+
+// RefreshToken is one link in a session's refresh token chain.
+// Rotated tokens are kept so that replaying one can be detected.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	SessionID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"` // SHA-256 of the token, hex encoded
+	RotatedAt *time.Time `gorm:""`                             // Set once the token has been exchanged
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (rt *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if rt.ID == uuid.Nil {
+		rt.ID = uuid.New()
+	}
+	return
+}
+
+// CreateUserSession stores a new session together with its first refresh token.
+func CreateUserSession(db *gorm.DB, sess *UserSession, tokenHash string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(sess).Error; err != nil {
+			return err
+		}
+		return tx.Create(&RefreshToken{SessionID: sess.ID, TokenHash: tokenHash}).Error
+	})
+}
+
+// CreateImpersonationSession stores a session an admin uses to act as the user.
+// It has no refresh token: it ends when its only access token expires.
+func CreateImpersonationSession(db *gorm.DB, sess *UserSession) error {
+	if sess.ImpersonatorID == nil {
+		return errors.New("impersonation session without an impersonator")
+	}
+	return db.Create(sess).Error
+}
+
+// FindUserSessionByID retrieves a session by ID.
+func FindUserSessionByID(db *gorm.DB, id uuid.UUID) (*UserSession, error) {
+	var sess UserSession
+	err := db.Where("id = ?", id).First(&sess).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// FindActiveUserSessionsByUser lists the user's unrevoked, unexpired sessions, most recently used first.
+func FindActiveUserSessionsByUser(db *gorm.DB, userID uuid.UUID) ([]UserSession, error) {
+	var sessions []UserSession
+	err := db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// FindRefreshTokenByHash retrieves a refresh token by its hash.
+func FindRefreshTokenByHash(db *gorm.DB, tokenHash string) (*RefreshToken, error) {
+	var token RefreshToken
+	err := db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindRefreshTokenByHashForUpdate is like FindRefreshTokenByHash but locks the row.
+// It must be called inside a transaction.
+func FindRefreshTokenByHashForUpdate(tx *gorm.DB, tokenHash string) (*RefreshToken, error) {
+	return FindRefreshTokenByHash(tx.Clauses(clause.Locking{Strength: "UPDATE"}), tokenHash)
+}
+
+// RotateRefreshToken marks a token as exchanged and appends its successor to the session's chain.
+// It must be called inside a transaction.
+func RotateRefreshToken(tx *gorm.DB, token *RefreshToken, nextHash string) error {
+	now := time.Now()
+	if err := tx.Model(token).Update("rotated_at", now).Error; err != nil {
+		return err
+	}
+	token.RotatedAt = &now
+	return tx.Create(&RefreshToken{SessionID: token.SessionID, TokenHash: nextHash}).Error
+}
+
+// TouchUserSession records activity on a session.
+func TouchUserSession(db *gorm.DB, id uuid.UUID, ipAddress, userAgent string) error {
+	return db.Model(&UserSession{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"ip_address":   ipAddress,
+		"user_agent":   userAgent,
+		"last_seen_at": time.Now(),
+	}).Error
+}
+
+// RevokeUserSession revokes a session owned by the given user.
+func RevokeUserSession(db *gorm.DB, id, userID uuid.UUID, reason string) error {
+	result := db.Model(&UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user except the one given (uuid.Nil keeps none).
+// It returns the number of sessions revoked.
+func RevokeUserSessions(db *gorm.DB, userID, exceptID uuid.UUID, reason string) (int64, error) {
+	query := db.Model(&UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID != uuid.Nil {
+		query = query.Where("id <> ?", exceptID)
+	}
+	result := query.Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
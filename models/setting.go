@@ -99,6 +99,15 @@ var DefaultSettings = []Setting{
 	// Billing - PayPal (future)
 	{Category: "billing/paypal", Key: "client_id", DataType: "string", Description: "PayPal Client ID", Visibility: "admin"},
 	{Category: "billing/paypal", Key: "client_secret", DataType: "string", Description: "PayPal Client Secret", Visibility: "admin"},
+	// Authentication
+	{Category: "auth", Key: "require_email_verification", DataType: "bool", Description: "Block users with an unverified email address from billing and teams", Visibility: "both"},
+	{Category: "auth", Key: "sso_plans", DataType: "string", Description: "Comma separated plan IDs whose team owners can set up single sign-on (all plans when empty)", Visibility: "admin"},
+	{Category: "auth", Key: "password_min_length", DataType: "int", Description: "Minimum password length (defaults to 8)", Visibility: "both"},
+	{Category: "auth", Key: "password_require_uppercase", DataType: "bool", Description: "Require an uppercase letter in passwords", Visibility: "both"},
+	{Category: "auth", Key: "password_require_lowercase", DataType: "bool", Description: "Require a lowercase letter in passwords", Visibility: "both"},
+	{Category: "auth", Key: "password_require_digit", DataType: "bool", Description: "Require a digit in passwords", Visibility: "both"},
+	{Category: "auth", Key: "password_require_symbol", DataType: "bool", Description: "Require a symbol in passwords", Visibility: "both"},
+	{Category: "auth", Key: "password_history", DataType: "int", Description: "Number of recent passwords that cannot be reused (defaults to 5, 0 allows reuse)", Visibility: "admin"},
 	// Email
 	{Category: "email/ses", Key: "from_address", DataType: "string", Description: "Sender email address", Visibility: "admin"},
 	{Category: "email/ses", Key: "aws_region", DataType: "string", Description: "AWS SES region", Visibility: "admin"},
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SigningKey is a key pair that signs access tokens. The newest unretired key signs;
+// retired keys keep verifying tokens until ExpiresAt. PrivateKey is a secret: anyone who
+// reads it can sign tokens for any user. It is encrypted with Auth.KeyEncryptionSecret
+// when one is configured, and plain PEM otherwise.
+type SigningKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	KID        string     `gorm:"column:kid;size:64;not null;uniqueIndex"` // Sent in the "kid" header of tokens
+	Algorithm  string     `gorm:"size:16;not null"`                        // RS256 or EdDSA
+	PrivateKey string     `gorm:"type:text;not null"`                      // PKCS #8 PEM, encrypted when prefixed "enc:v1:"
+	PublicKey  string     `gorm:"type:text;not null"`                      // PKIX PEM
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	RetiredAt  *time.Time `gorm:""`      // When a newer key took over signing
+	ExpiresAt  *time.Time `gorm:"index"` // When tokens signed with it stop being accepted
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (k *SigningKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return
+}
+
+// CreateSigningKey stores a new key.
+func CreateSigningKey(db *gorm.DB, key *SigningKey) error {
+	return db.Create(key).Error
+}
+
+// FindUsableSigningKeys retrieves the keys that have not expired, newest first.
+func FindUsableSigningKeys(db *gorm.DB) ([]SigningKey, error) {
+	var keys []SigningKey
+	err := db.Where("expires_at IS NULL OR expires_at > ?", time.Now()).Order("created_at DESC").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RetireSigningKeys retires every signing key except the given one. They expire at expiresAt.
+func RetireSigningKeys(db *gorm.DB, exceptID uuid.UUID, expiresAt time.Time) error {
+	return db.Model(&SigningKey{}).
+		Where("id <> ? AND retired_at IS NULL", exceptID).
+		Updates(map[string]interface{}{"retired_at": time.Now(), "expires_at": expiresAt}).Error
+}
+
+// SetSigningKeyPrivateKey replaces the stored private key of a signing key.
+func SetSigningKeyPrivateKey(db *gorm.DB, id uuid.UUID, privateKey string) error {
+	return db.Model(&SigningKey{}).Where("id = ?", id).Update("private_key", privateKey).Error
+}
@@ -1,11 +1,13 @@
 package models
 
 import (
+	"errors"
 	"fmt" // Import fmt for error wrapping
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Team represents a group of users collaborating.
@@ -18,6 +20,16 @@ type Team struct {
 	Name    string    `gorm:"size:100;not null"`
 	OwnerID uuid.UUID `gorm:"type:uuid;not null;index"` // Foreign key to the User who owns the team
 
+	StripeCustomerID *string `gorm:"size:100;uniqueIndex"` // Set when the team subscribes in its own name
+
+	// --- Enterprise SSO (OpenID Connect) ---
+	SSOEnabled      bool   `gorm:"not null;default:false"`
+	SSOIssuer       string `gorm:"size:255"` // Issuer URL; endpoints come from its discovery document
+	SSOClientID     string `gorm:"size:255"`
+	SSOClientSecret string `gorm:"size:512"`
+	SSODefaultRole  Role   `gorm:"type:varchar(20);not null;default:'member'"` // Role of members created on first SSO sign-in
+	SSOEnforced     bool   `gorm:"not null;default:false"`                     // Block password login for the team's verified domains
+
 	// --- Relationships ---
 	Owner User `gorm:"foreignKey:OwnerID"` // Belongs To User (Owner)
 	// Memberships []Membership `gorm:"foreignKey:TeamID"` // Has Many Memberships - Uncomment when Membership model is defined
@@ -46,6 +58,12 @@ func FindTeamByID(db *gorm.DB, teamID uuid.UUID) (*Team, error) {
 	return &team, nil
 }
 
+// LockTeam locks the team's row until the end of the transaction, e.g. to serialize
+// changes to its seats. It returns gorm.ErrRecordNotFound when the team is gone.
+func LockTeam(tx *gorm.DB, teamID uuid.UUID) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", teamID).First(&Team{}).Error
+}
+
 // CreateTeamWithOwner creates a new team and its owner membership within a transaction.
 func CreateTeamWithOwner(db *gorm.DB, teamName string, ownerID uuid.UUID) (*Team, error) {
 	var newTeam Team
@@ -92,3 +110,125 @@ func CountTeams(db *gorm.DB) (int64, error) {
 	result := db.Model(&Team{}).Count(&count)
 	return count, result.Error
 }
+
+// UpdateTeamSSO saves the team's single sign-on configuration.
+func UpdateTeamSSO(db *gorm.DB, team *Team) error {
+	result := db.Model(&Team{}).Where("id = ?", team.ID).Updates(map[string]interface{}{
+		"sso_enabled":       team.SSOEnabled,
+		"sso_issuer":        team.SSOIssuer,
+		"sso_client_id":     team.SSOClientID,
+		"sso_client_secret": team.SSOClientSecret,
+		"sso_default_role":  team.SSODefaultRole,
+		"sso_enforced":      team.SSOEnforced,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RenameTeam changes the team's name.
+func RenameTeam(db *gorm.DB, teamID uuid.UUID, name string) error {
+	result := db.Model(&Team{}).Where("id = ?", teamID).Update("name", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteTeam soft-deletes a team together with its memberships and pending invitations,
+// and drops its pending join requests.
+// Its access and SCIM tokens are revoked and its domains released so another team can
+// verify them.
+func DeleteTeam(db *gorm.DB, teamID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", teamID).Delete(&Team{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&Membership{}).Error; err != nil {
+			return fmt.Errorf("failed to delete memberships: %w", err)
+		}
+		if err := tx.Where("team_id = ? AND status = ?", teamID, StatusPending).Delete(&Invitation{}).Error; err != nil {
+			return fmt.Errorf("failed to delete invitations: %w", err)
+		}
+		if err := tx.Where("team_id = ? AND status = ?", teamID, JoinRequestPending).Delete(&JoinRequest{}).Error; err != nil {
+			return fmt.Errorf("failed to delete join requests: %w", err)
+		}
+		now := time.Now()
+		for _, model := range []interface{}{&APIToken{}, &SCIMToken{}} {
+			if err := tx.Model(model).Where("team_id = ? AND revoked_at IS NULL", teamID).Update("revoked_at", now).Error; err != nil {
+				return fmt.Errorf("failed to revoke %T: %w", model, err)
+			}
+		}
+		return tx.Where("team_id = ?", teamID).Delete(&TeamDomain{}).Error
+	})
+}
+
+// FindTeamsOwnedByUser lists the teams the user owns.
+func FindTeamsOwnedByUser(db *gorm.DB, userID uuid.UUID) ([]Team, error) {
+	var teams []Team
+	err := db.Where("owner_id = ?", userID).Order("created_at").Find(&teams).Error
+	return teams, err
+}
+
+// TransferTeamOwnership makes another member the team's owner. The previous owner stays
+// on the team as an admin. newOwnerID must already be a member; otherwise
+// gorm.ErrRecordNotFound is returned. Run it inside a transaction.
+func TransferTeamOwnership(db *gorm.DB, teamID, fromUserID, newOwnerID uuid.UUID) error {
+	newOwner, err := FindMembershipByUserAndTeam(db, newOwnerID, teamID)
+	if err != nil {
+		return err
+	}
+	result := db.Model(&Team{}).Where("id = ? AND owner_id = ?", teamID, fromUserID).Update("owner_id", newOwnerID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	if err := db.Model(newOwner).Updates(map[string]interface{}{"role": RoleOwner, "role_id": nil}).Error; err != nil {
+		return err
+	}
+	previous, err := FindMembershipByUserAndTeam(db, fromUserID, teamID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // The owner had already left the team
+		}
+		return err
+	}
+	return db.Model(previous).Updates(map[string]interface{}{"role": RoleAdmin, "role_id": nil}).Error
+}
+
+// PurgeTeam permanently deletes a team with its memberships, invitations, domains, tokens
+// and subscription records.
+func PurgeTeam(db *gorm.DB, teamID uuid.UUID) error {
+	if err := DeleteSubscriptionsBySubject(db, BillingSubjectTeam, teamID); err != nil {
+		return fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+	owned := []interface{}{
+		&Membership{},
+		&TeamRole{},
+		&Invitation{},
+		&InvitationImport{},
+		&JoinRequest{},
+		&TeamDomain{},
+		&SCIMToken{},
+		&APIToken{},
+	}
+	for _, model := range owned {
+		if err := db.Unscoped().Where("team_id = ?", teamID).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to delete %T: %w", model, err)
+		}
+	}
+	return db.Unscoped().Where("id = ?", teamID).Delete(&Team{}).Error
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DomainJoinPolicy controls whether users with an email address on a verified domain can
+// join the team without an invitation.
+type DomainJoinPolicy string
+
+const (
+	DomainJoinOff     DomainJoinPolicy = "off"     // Users need an invitation
+	DomainJoinAuto    DomainJoinPolicy = "auto"    // Users join as members right away
+	DomainJoinRequest DomainJoinPolicy = "request" // Users ask to join and an admin approves
+)
+
+// TeamDomain is an email domain claimed by a team.
+// A domain routes sign-ins to the team's identity provider once it is verified through DNS,
+// and lets users with an address on it join the team according to JoinPolicy;
+// any team may claim a domain but only one can verify it.
+type TeamDomain struct {
+	ID                uuid.UUID        `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TeamID            uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_team_domain"`
+	Domain            string           `gorm:"size:255;not null;uniqueIndex:idx_team_domain;uniqueIndex:idx_team_domain_verified,where:verified_at IS NOT NULL"`
+	VerificationToken string           `gorm:"size:64;not null"` // Published in a DNS TXT record to prove ownership
+	VerifiedAt        *time.Time       `gorm:""`
+	JoinPolicy        DomainJoinPolicy `gorm:"type:varchar(20);not null;default:'off'"`
+	CreatedAt         time.Time        `gorm:"autoCreateTime"`
+
+	Team Team `gorm:"foreignKey:TeamID"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (td *TeamDomain) BeforeCreate(tx *gorm.DB) (err error) {
+	if td.ID == uuid.Nil {
+		td.ID = uuid.New()
+	}
+	return
+}
+
+// Verified reports whether the team proved it owns the domain.
+func (td *TeamDomain) Verified() bool {
+	return td.VerifiedAt != nil
+}
+
+// CreateTeamDomain claims a domain for a team.
+func CreateTeamDomain(db *gorm.DB, domain *TeamDomain) error {
+	return db.Create(domain).Error
+}
+
+// FindTeamDomainsByTeam returns the domains claimed by a team.
+func FindTeamDomainsByTeam(db *gorm.DB, teamID uuid.UUID) ([]TeamDomain, error) {
+	var domains []TeamDomain
+	if err := db.Where("team_id = ?", teamID).Order("domain").Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// FindTeamDomainByIDAndTeam retrieves one of a team's domains.
+func FindTeamDomainByIDAndTeam(db *gorm.DB, id, teamID uuid.UUID) (*TeamDomain, error) {
+	var domain TeamDomain
+	if err := db.Where("id = ? AND team_id = ?", id, teamID).First(&domain).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// FindVerifiedTeamDomain retrieves the verified claim on a domain, with its team.
+func FindVerifiedTeamDomain(db *gorm.DB, domain string) (*TeamDomain, error) {
+	var td TeamDomain
+	err := db.Preload("Team").
+		Where("domain = ? AND verified_at IS NOT NULL", strings.ToLower(domain)).
+		First(&td).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &td, nil
+}
+
+// FindSSOTeamByEmail returns the team with single sign-on enabled that verified the
+// domain of the email address.
+func FindSSOTeamByEmail(db *gorm.DB, email string) (*Team, error) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	td, err := FindVerifiedTeamDomain(db, email[at+1:])
+	if err != nil {
+		return nil, err
+	}
+	if !td.Team.SSOEnabled || td.Team.ID == uuid.Nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &td.Team, nil
+}
+
+// FindJoinableTeamDomain returns the verified claim on the domain of the email address
+// when its team lets users join through it, with the team.
+func FindJoinableTeamDomain(db *gorm.DB, email string) (*TeamDomain, error) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	td, err := FindVerifiedTeamDomain(db, email[at+1:])
+	if err != nil {
+		return nil, err
+	}
+	if td.JoinPolicy != DomainJoinAuto && td.JoinPolicy != DomainJoinRequest || td.Team.ID == uuid.Nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return td, nil
+}
+
+// UpdateTeamDomainJoinPolicy sets how users on a domain can join its team.
+func UpdateTeamDomainJoinPolicy(db *gorm.DB, domain *TeamDomain, policy DomainJoinPolicy) error {
+	switch policy {
+	case DomainJoinOff, DomainJoinAuto, DomainJoinRequest:
+	default:
+		return errors.New("invalid join policy")
+	}
+	if err := db.Model(domain).Update("join_policy", policy).Error; err != nil {
+		return err
+	}
+	domain.JoinPolicy = policy
+	return nil
+}
+
+// ErrDomainVerifiedByOtherTeam is returned when another team already verified the domain.
+var ErrDomainVerifiedByOtherTeam = errors.New("domain is verified by another team")
+
+// MarkTeamDomainVerified records a successful DNS check. The partial unique index on
+// verified domains backs up the check for a concurrent verification by another team.
+func MarkTeamDomainVerified(db *gorm.DB, domain *TeamDomain) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&TeamDomain{}).
+			Where("domain = ? AND verified_at IS NOT NULL AND id <> ?", domain.Domain, domain.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDomainVerifiedByOtherTeam
+		}
+		now := time.Now()
+		result := tx.Model(&TeamDomain{}).Where("id = ? AND verified_at IS NULL", domain.ID).Update("verified_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		domain.VerifiedAt = &now
+		return nil
+	})
+}
+
+// DeleteTeamDomain removes a team's claim on a domain.
+func DeleteTeamDomain(db *gorm.DB, id, teamID uuid.UUID) error {
+	result := db.Where("id = ? AND team_id = ?", id, teamID).Delete(&TeamDomain{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
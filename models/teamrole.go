@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// TeamRole is a role defined by a team on top of the built-in owner, admin and member
+// roles. Members holding it get exactly its permissions.
+type TeamRole struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TeamID      uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_team_role_name"`
+	Name        string         `gorm:"size:50;not null;uniqueIndex:idx_team_role_name"`
+	Description string         `gorm:"size:255"`
+	Permissions datatypes.JSON `gorm:"type:jsonb;not null"` // JSON array of permission names
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (r *TeamRole) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
+
+// PermissionList returns the role's permissions.
+func (r *TeamRole) PermissionList() []string {
+	var permissions []string
+	_ = json.Unmarshal(r.Permissions, &permissions)
+	return permissions
+}
+
+// SetPermissions stores the role's permissions.
+func (r *TeamRole) SetPermissions(permissions []string) error {
+	if permissions == nil {
+		permissions = []string{}
+	}
+	raw, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+	r.Permissions = raw
+	return nil
+}
+
+// CreateTeamRole stores a new custom role.
+func CreateTeamRole(db *gorm.DB, role *TeamRole) error {
+	return db.Create(role).Error
+}
+
+// FindTeamRolesByTeam lists the team's custom roles by name.
+func FindTeamRolesByTeam(db *gorm.DB, teamID uuid.UUID) ([]TeamRole, error) {
+	var roles []TeamRole
+	err := db.Where("team_id = ?", teamID).Order("name").Find(&roles).Error
+	return roles, err
+}
+
+// FindTeamRole retrieves one of the team's custom roles.
+func FindTeamRole(db *gorm.DB, id, teamID uuid.UUID) (*TeamRole, error) {
+	var role TeamRole
+	err := db.Where("id = ? AND team_id = ?", id, teamID).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// UpdateTeamRole saves the role's name, description and permissions.
+func UpdateTeamRole(db *gorm.DB, role *TeamRole) error {
+	result := db.Model(&TeamRole{}).Where("id = ? AND team_id = ?", role.ID, role.TeamID).Updates(map[string]interface{}{
+		"name":        role.Name,
+		"description": role.Description,
+		"permissions": role.Permissions,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteTeamRole deletes a custom role. Members holding it fall back to the member role.
+func DeleteTeamRole(db *gorm.DB, id, teamID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&Membership{}).Where("team_id = ? AND role_id = ?", teamID, id).Update("role_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND team_id = ?", id, teamID).Delete(&TeamRole{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// CountMembershipsByTeamRole counts the members holding a custom role.
+func CountMembershipsByTeamRole(db *gorm.DB, roleID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&Membership{}).Where("role_id = ?", roleID).Count(&count).Error
+	return count, err
+}
@@ -1,16 +1,16 @@
 package models
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
 	"errors" // Import errors
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/datatypes" // Added for JSON type
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SystemRole defines global roles within the application.
@@ -30,15 +30,18 @@ const (
 	// Add other statuses like "pending_verification", "deactivated" if needed
 )
 
+// SuspensionReasonDeprovisioned marks accounts suspended because a directory removed
+// them from their last team. Provisioning them again lifts the suspension.
+const SuspensionReasonDeprovisioned = "deprovisioned"
+
 // User represents a user in the system mapped to the database schema.
 type User struct {
 	ID                     uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Email                  string         `gorm:"uniqueIndex;not null;size:255"` // Added size limit
-	Password               *string        `gorm:""`                              // Nullable for OAuth users
-	CreatedAt              time.Time      `gorm:"autoCreateTime"`                // GORM handles this
-	UpdatedAt              time.Time      `gorm:"autoUpdateTime"`                // GORM handles this
-	DeletedAt              gorm.DeletedAt `gorm:"index"`                         // Support soft deletes
-	ApiKey                 string         `gorm:"uniqueIndex;size:64"`
+	Email                  string         `gorm:"uniqueIndex;not null;size:255"`   // Added size limit
+	Password               *string        `gorm:""`                                // Nullable for OAuth users
+	CreatedAt              time.Time      `gorm:"autoCreateTime"`                  // GORM handles this
+	UpdatedAt              time.Time      `gorm:"autoUpdateTime"`                  // GORM handles this
+	DeletedAt              gorm.DeletedAt `gorm:"index"`                           // Support soft deletes
 	PasswordResetToken     *string        `gorm:"index;size:64"`                   // Nullable password reset token
 	PasswordResetExpiresAt *time.Time     ``                                       // Nullable expiry time for the token
 	Plan                   string         `gorm:"not null;default:'free';size:50"` // Increased size slightly
@@ -48,6 +51,13 @@ type User struct {
 	Role                   SystemRole     `gorm:"type:varchar(20);not null;default:'user'"`   // User's global system role
 	AccountStatus          AccountStatus  `gorm:"type:varchar(20);not null;default:'active'"` // User account status
 	Settings               datatypes.JSON `gorm:"type:jsonb"`
+	TOTPSecret             *string        `gorm:"size:64"`            // Base32 TOTP secret; set on enrollment, active once TwoFactorEnabled
+	TOTPLastStep           int64          `gorm:"not null;default:0"` // Last accepted TOTP time step, prevents code replay
+	EmailVerifiedAt        *time.Time     `gorm:""`                   // Set once the user proved they own Email; cleared when it changes
+	SuspendedAt            *time.Time     `gorm:""`                   // When AccountStatus last became suspended
+	SuspensionReason       string         `gorm:"size:64"`            // Why the account is suspended, e.g. SuspensionReasonDeprovisioned
+	DeletionScheduledAt    *time.Time     `gorm:"index"`              // When the account will be erased; set while a deletion request is pending
+	PasswordChangedAt      *time.Time     `gorm:""`                   // When the password was last set
 
 	// --- Associations ---
 	// Define associations here if needed, e.g.:
@@ -100,7 +110,9 @@ func (u *User) SetPassword(password string) error {
 		return err
 	}
 	hashedStr := string(hashedPassword)
+	now := time.Now()
 	u.Password = &hashedStr
+	u.PasswordChangedAt = &now
 	return nil
 }
 
@@ -139,32 +151,74 @@ func FindUserByEmail(db *gorm.DB, email string) (*User, error) {
 	return &user, nil
 }
 
-// GenerateAPIKey creates a secure random API key (e.g., 64 hex characters)
-// Note: This might be better placed in a utility/service package if used elsewhere.
-func GenerateAPIKey() (string, error) {
-	bytes := make([]byte, 32) // 32 bytes = 256 bits
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(bytes), nil
-}
-
-// BeforeCreate hook to generate API key if not present
+// BeforeCreate hook to set UUID if not already set
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New() // Generate UUID if not set
 	}
-	if u.ApiKey == "" {
-		apiKey, err := GenerateAPIKey()
-		if err != nil {
-			return err
-		}
-		u.ApiKey = apiKey
-	}
 	// You might want to generate DefaultSubdomain here too if it's meant to be random
 	return nil
 }
 
+// EmailVerified reports whether the user has proved they own their email address.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// MarkUserEmailVerified records that the user owns their current email address.
+// Users that are already verified keep their original timestamp.
+func MarkUserEmailVerified(db *gorm.DB, userID uuid.UUID) error {
+	return db.Model(&User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", time.Now()).Error
+}
+
+// UpdateUserEmail sets the user's email address and its verification time.
+func UpdateUserEmail(db *gorm.DB, userID uuid.UUID, email string, verifiedAt *time.Time) error {
+	result := db.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email":             email,
+		"email_verified_at": verifiedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateUserProfileData replaces the user's profile JSON.
+func UpdateUserProfileData(db *gorm.DB, userID uuid.UUID, profileData datatypes.JSON) error {
+	return db.Model(&User{}).Where("id = ?", userID).Update("profile_data", profileData).Error
+}
+
+// SuspendUser suspends an active account and records why. It reports whether the
+// account was active.
+func SuspendUser(db *gorm.DB, userID uuid.UUID, reason string) (bool, error) {
+	result := db.Model(&User{}).
+		Where("id = ? AND account_status = ?", userID, AccountStatusActive).
+		Updates(map[string]interface{}{
+			"account_status":    AccountStatusSuspended,
+			"suspended_at":      time.Now(),
+			"suspension_reason": reason,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReactivateUser lifts a suspension made for the given reason; suspensions for other
+// reasons stay in place. It reports whether the account was reactivated.
+func ReactivateUser(db *gorm.DB, userID uuid.UUID, reason string) (bool, error) {
+	result := db.Model(&User{}).
+		Where("id = ? AND account_status = ? AND suspension_reason = ?", userID, AccountStatusSuspended, reason).
+		Updates(map[string]interface{}{
+			"account_status":    AccountStatusActive,
+			"suspended_at":      nil,
+			"suspension_reason": "",
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // UpdateUserPasswordResetToken sets the password reset token and expiry for a user.
 func UpdateUserPasswordResetToken(db *gorm.DB, userID uuid.UUID, token string, expiresAt time.Time) error {
 	result := db.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
@@ -175,19 +229,13 @@ func UpdateUserPasswordResetToken(db *gorm.DB, userID uuid.UUID, token string, e
 }
 
 // CreateUser creates a new user record in the database.
-// It relies on the BeforeCreate hook to set defaults (ID, ApiKey).
+// It relies on the BeforeCreate hook to set defaults (ID).
 // It assumes the password has already been hashed using user.SetPassword().
 func CreateUser(db *gorm.DB, user *User) error {
 	result := db.Create(user)
 	return result.Error
 }
 
-// UpdateUserAPIKey updates the API key for a specific user.
-func UpdateUserAPIKey(db *gorm.DB, userID uuid.UUID, newAPIKey string) error {
-	result := db.Model(&User{}).Where("id = ?", userID).Update("api_key", newAPIKey)
-	return result.Error
-}
-
 // FindUserByValidPasswordResetToken retrieves a user by a non-expired password reset token.
 func FindUserByValidPasswordResetToken(db *gorm.DB, token string) (*User, error) {
 	var user User
@@ -206,6 +254,7 @@ func UpdateUserPasswordAndClearToken(db *gorm.DB, userID uuid.UUID, newHashedPas
 	// Use map to explicitly set token fields to NULL and handle nullable password
 	updates := map[string]interface{}{
 		"password":                  newHashedPassword, // Can be nil
+		"password_changed_at":       time.Now(),
 		"password_reset_token":      nil,
 		"password_reset_expires_at": nil,
 	}
@@ -219,7 +268,7 @@ func FindAllUsersForAdmin(db *gorm.DB) ([]User, error) {
 	var users []User
 	// Select specific fields to avoid exposing sensitive data like password hash
 	// Adjust fields based on what the admin list actually needs
-	result := db.Select("id", "email", "profile_data", "role", "account_status", "created_at", "updated_at", "api_key", "default_subdomain", "plan", "stripe_customer_id").
+	result := db.Select("id", "email", "profile_data", "role", "account_status", "created_at", "updated_at", "default_subdomain", "plan", "stripe_customer_id").
 		Order("created_at asc"). // Order by creation time, or ID, or email?
 		Find(&users)
 
@@ -259,3 +308,112 @@ func CountNonAdminUsers(db *gorm.DB) (int64, error) {
 	result := db.Model(&User{}).Where("role <> ?", SystemRoleAdmin).Count(&count)
 	return count, result.Error
 }
+
+// ScheduleUserDeletion records that the user's account will be erased at the given time.
+func ScheduleUserDeletion(db *gorm.DB, userID uuid.UUID, at time.Time) error {
+	result := db.Model(&User{}).Where("id = ?", userID).Update("deletion_scheduled_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CancelUserDeletion withdraws a pending deletion. It reports whether one was pending.
+func CancelUserDeletion(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	result := db.Model(&User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+		Update("deletion_scheduled_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindUserIDsDueForDeletion lists users whose deletion grace period ended before now.
+func FindUserIDsDueForDeletion(db *gorm.DB, now time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := db.Model(&User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindUserDueForDeletionForUpdate locks a user whose deletion is due. It returns
+// gorm.ErrRecordNotFound when the deletion was cancelled or another instance holds the lock.
+func FindUserDueForDeletionForUpdate(db *gorm.DB, userID uuid.UUID, now time.Time) (*User, error) {
+	var user User
+	err := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", userID, now).
+		First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// PurgeUser permanently erases a user and the records that only concern them. Records
+// other people rely on are kept without the reference: communications about the user lose
+// their content, and blog posts and communications they wrote lose their author.
+// Subscriptions they started for a team stay with the team and pass to its owner, and the
+// impersonation audit log is kept as is. Teams the user owns must have been transferred or
+// purged first.
+func PurgeUser(db *gorm.DB, userID uuid.UUID) error {
+	// Keep shared records but drop the reference
+	if err := db.Model(&BlogPost{}).Unscoped().Where("author_id = ?", userID).UpdateColumn("author_id", nil).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&Communication{}).Unscoped().Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"user_id":    nil,
+		"subject":    "",
+		"body":       CommunicationRedacted,
+		"error_info": "",
+	}).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&Communication{}).Unscoped().Where("admin_id = ?", userID).Update("admin_id", nil).Error; err != nil {
+		return err
+	}
+
+	// Rows hanging off the user's sessions, subscriptions and invitations
+	if err := db.Where("session_id IN (?)", db.Model(&UserSession{}).Select("id").Where("user_id = ?", userID)).Delete(&RefreshToken{}).Error; err != nil {
+		return err
+	}
+	if err := DeleteSubscriptionsBySubject(db, BillingSubjectUser, userID); err != nil {
+		return err
+	}
+	if err := ReassignTeamSubscriptions(db, userID); err != nil {
+		return err
+	}
+	if err := db.Unscoped().Where("inviter_id = ?", userID).Delete(&Invitation{}).Error; err != nil {
+		return err
+	}
+	if err := db.Unscoped().Where("inviter_id = ?", userID).Delete(&InvitationImport{}).Error; err != nil {
+		return err
+	}
+
+	owned := []interface{}{
+		&UserSession{},
+		&APIToken{},
+		&Notification{},
+		&RecoveryCode{},
+		&WebAuthnCredential{},
+		&WebAuthnSession{},
+		&EmailVerificationToken{},
+		&EmailChange{},
+		&LoginCode{},
+		&UserIdentity{},
+		&DataExport{},
+		&PasswordHistory{},
+		&LoginEvent{},
+		&JoinRequest{},
+		&Membership{},
+	}
+	for _, model := range owned {
+		if err := db.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to delete %T: %w", model, err)
+		}
+	}
+	return db.Unscoped().Where("id = ?", userID).Delete(&User{}).Error
+}
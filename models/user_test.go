@@ -0,0 +1,95 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// statementRecorder collects the SQL a dry run would have executed.
+type statementRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *statementRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+func dryRunDB(t *testing.T) (*gorm.DB, *statementRecorder) {
+	t.Helper()
+	recorder := &statementRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, recorder
+}
+
+// index returns the position of the first statement containing all parts, or -1.
+func (r *statementRecorder) index(parts ...string) int {
+	for i, sql := range r.statements {
+		matched := true
+		for _, part := range parts {
+			if !strings.Contains(sql, part) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestPurgeUserKeepsTeamSubscriptions(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	if err := PurgeUser(db, uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Team subscriptions reference the user, so they must pass to the team owner first
+	reassign := recorder.index(`UPDATE "subscriptions" SET "user_id"=(SELECT "owner_id" FROM "teams" WHERE teams.id = subscriptions.subject_id)`, `subject_type = 'team'`)
+	if reassign < 0 {
+		t.Fatalf("team subscriptions are not reassigned:\n%s", strings.Join(recorder.statements, "\n"))
+	}
+	deleteUser := recorder.index(`DELETE FROM "users"`)
+	if deleteUser < 0 || deleteUser < reassign {
+		t.Fatalf("the user is deleted before their team subscriptions are reassigned:\n%s", strings.Join(recorder.statements, "\n"))
+	}
+	if i := recorder.index(`DELETE FROM "subscriptions"`, `subject_type = 'team'`); i >= 0 {
+		t.Fatalf("team subscriptions are deleted: %s", recorder.statements[i])
+	}
+}
+
+func TestPurgeUserKeepsSharedRecords(t *testing.T) {
+	db, recorder := dryRunDB(t)
+	userID := uuid.New()
+	if err := PurgeUser(db, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	i := recorder.index(`UPDATE "blog_posts" SET "author_id"=NULL`)
+	if i < 0 {
+		t.Fatalf("blog posts keep their author:\n%s", strings.Join(recorder.statements, "\n"))
+	}
+	if want := `WHERE author_id = '` + userID.String() + `'`; !strings.HasSuffix(recorder.statements[i], want) {
+		t.Fatalf("blog posts are not matched by author alone: %s", recorder.statements[i])
+	}
+	if i := recorder.index(`DELETE FROM "impersonation_logs"`); i >= 0 {
+		t.Fatalf("the impersonation audit log is deleted: %s", recorder.statements[i])
+	}
+}
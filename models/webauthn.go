@@ -0,0 +1,169 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebAuthnCredential is a passkey registered by a user.
+type WebAuthnCredential struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Name           string     `gorm:"size:100;not null"`
+	CredentialID   []byte     `gorm:"type:bytea;not null;uniqueIndex"`
+	PublicKey      []byte     `gorm:"type:bytea;not null"` // CBOR encoded COSE_Key
+	SignCount      int64      `gorm:"not null;default:0"`
+	AAGUID         []byte     `gorm:"type:bytea"`
+	Transports     string     `gorm:"size:255"` // Comma separated hints, e.g. "internal,hybrid"
+	BackupEligible bool       `gorm:"not null;default:false"`
+	BackedUp       bool       `gorm:"not null;default:false"`
+	LastUsedAt     *time.Time `gorm:""`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (wc *WebAuthnCredential) BeforeCreate(tx *gorm.DB) (err error) {
+	if wc.ID == uuid.Nil {
+		wc.ID = uuid.New()
+	}
+	return
+}
+
+// TransportList returns the stored transport hints.
+func (wc *WebAuthnCredential) TransportList() []string {
+	if wc.Transports == "" {
+		return nil
+	}
+	return strings.Split(wc.Transports, ",")
+}
+
+// WebAuthnSession holds the challenge of an in-flight passkey ceremony.
+// It is only used when Redis is not configured.
+type WebAuthnSession struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID    *uuid.UUID `gorm:"type:uuid;index"` // Nil for discoverable (username-less) login
+	Purpose   string     `gorm:"size:32;not null"`
+	Challenge string     `gorm:"size:128;not null"`
+	ExpiresAt time.Time  `gorm:"not null;index"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// BeforeCreate hook to set UUID if not already set
+func (ws *WebAuthnSession) BeforeCreate(tx *gorm.DB) (err error) {
+	if ws.ID == uuid.Nil {
+		ws.ID = uuid.New()
+	}
+	return
+}
+
+// CreateWebAuthnCredential stores a newly registered passkey.
+func CreateWebAuthnCredential(db *gorm.DB, cred *WebAuthnCredential) error {
+	return db.Create(cred).Error
+}
+
+// FindWebAuthnCredentialsByUser lists a user's passkeys, oldest first.
+func FindWebAuthnCredentialsByUser(db *gorm.DB, userID uuid.UUID) ([]WebAuthnCredential, error) {
+	var creds []WebAuthnCredential
+	err := db.Where("user_id = ?", userID).Order("created_at asc").Find(&creds).Error
+	if err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// FindWebAuthnCredentialByCredentialID looks up a passkey by the authenticator's credential ID.
+func FindWebAuthnCredentialByCredentialID(db *gorm.DB, credentialID []byte) (*WebAuthnCredential, error) {
+	var cred WebAuthnCredential
+	err := db.Where("credential_id = ?", credentialID).First(&cred).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &cred, nil
+}
+
+// FindWebAuthnCredentialByIDAndUser retrieves a passkey owned by the given user.
+func FindWebAuthnCredentialByIDAndUser(db *gorm.DB, id, userID uuid.UUID) (*WebAuthnCredential, error) {
+	var cred WebAuthnCredential
+	err := db.Where("id = ? AND user_id = ?", id, userID).First(&cred).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &cred, nil
+}
+
+// UpdateWebAuthnCredentialName renames a passkey owned by the given user.
+func UpdateWebAuthnCredentialName(db *gorm.DB, id, userID uuid.UUID, name string) error {
+	result := db.Model(&WebAuthnCredential{}).Where("id = ? AND user_id = ?", id, userID).Update("name", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateWebAuthnCredentialUsage records a successful assertion.
+func UpdateWebAuthnCredentialUsage(db *gorm.DB, id uuid.UUID, signCount int64, backedUp bool) error {
+	return db.Model(&WebAuthnCredential{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"sign_count":   signCount,
+		"backed_up":    backedUp,
+		"last_used_at": time.Now(),
+	}).Error
+}
+
+// DeleteWebAuthnCredential removes a passkey owned by the given user.
+func DeleteWebAuthnCredential(db *gorm.DB, id, userID uuid.UUID) error {
+	result := db.Where("id = ? AND user_id = ?", id, userID).Delete(&WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateWebAuthnSession stores the challenge of a new passkey ceremony.
+func CreateWebAuthnSession(db *gorm.DB, session *WebAuthnSession) error {
+	return db.Create(session).Error
+}
+
+// TakeWebAuthnSession loads and deletes a ceremony so its challenge can only be used once.
+// Expired ceremonies are reported as gorm.ErrRecordNotFound.
+func TakeWebAuthnSession(db *gorm.DB, id uuid.UUID) (*WebAuthnSession, error) {
+	var session WebAuthnSession
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&session).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&WebAuthnSession{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound // Taken concurrently
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
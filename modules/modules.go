@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync" // Keep mutex for global slice
 
+	"github.com/solotoabillion/stab/core/permissions"
 	"github.com/solotoabillion/stab/svc"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -23,6 +24,14 @@ type Initializer interface {
 	Name() string
 }
 
+// PermissionProvider is implemented by modules whose routes require their own team
+// permissions. The permissions are added to the catalog when the module registers, so
+// teams can grant them to custom roles and routes can require them with
+// svcCtx.RequirePermission.
+type PermissionProvider interface {
+	Permissions() []permissions.Permission
+}
+
 // --- Global Registration (Reverted) ---
 
 var (
@@ -47,6 +56,9 @@ func Register(initializer Initializer) { // Renamed back to Register
 	// }
 	logx.Infof("Registering global initializer for module: %s", name)
 	registeredInitializers = append(registeredInitializers, initializer)
+	if provider, ok := initializer.(PermissionProvider); ok {
+		permissions.Register(provider.Permissions()...)
+	}
 }
 
 // RunInitializers executes the Initialize method for all globally registered modules.
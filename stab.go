@@ -7,7 +7,8 @@ import (
 	// Import necessary packages from within the module
 	"github.com/solotoabillion/stab/config"
 	"github.com/solotoabillion/stab/internal/handler" // Added back handler import
-	"github.com/solotoabillion/stab/modules"          // Use the modules package for initialization functions
+	"github.com/solotoabillion/stab/middleware"
+	"github.com/solotoabillion/stab/modules" // Use the modules package for initialization functions
 	"github.com/solotoabillion/stab/svc"
 
 	"github.com/labstack/echo/v4" // Added back echo import
@@ -38,16 +39,29 @@ func New(cfg config.Config) (*svc.ServiceContext, error) {
 		return nil, fmt.Errorf("failed during globally registered module initialization: %w", err) // Make fatal
 	}
 
+	// Background jobs such as data exports and account deletion
+	svcCtx.StartJobs()
+
 	log.Println("stab module initialization complete (including registered modules).")
 	return svcCtx, nil
 }
 
 // RegisterRoutes registers the core API routes provided by the stab module
 // onto an existing Echo instance provided by the consuming application.
-// It calls the internal handler registration logic.
+// It calls the internal handler registration logic and sets the instance's IPExtractor
+// from Config.TrustedProxies.
 func RegisterRoutes(e *echo.Echo, svcCtx *svc.ServiceContext) { // Added function back
 	log.Println("Registering stab module core routes...")
 
+	// Client IPs feed access token allowlists and attempt limits, so only trust forwarding
+	// headers set by the configured proxies
+	extractor, err := middleware.ClientIPExtractor(svcCtx.Config.TrustedProxies)
+	if err != nil {
+		log.Printf("ERROR: Invalid TrustedProxies, ignoring forwarding headers: %v", err)
+		extractor = echo.ExtractIPDirect()
+	}
+	e.IPExtractor = extractor
+
 	// --- Register Handlers ---
 	// The handler.RegisterHandlers function defines all the core API groups and routes.
 	handler.RegisterHandlers(e, svcCtx) // Call the internal registration
@@ -89,9 +103,7 @@ func Shutdown(cfg *config.Config, svcCtx *svc.ServiceContext) { // cfg might not
 
 	// Stop Job Manager
 	if svcCtx.JobManager != nil {
-		// Assuming JobManager has a Stop method - adjust if needed
-		// svcCtx.JobManager.Stop()
-		log.Println("Job manager stopped.") // Placeholder - Add actual stop call if available
+		svcCtx.JobManager.Stop()
 	}
 
 	// Close PubSub Broker
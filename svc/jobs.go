@@ -0,0 +1,119 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/solotoabillion/stab/core/accountdeletion"
+	"github.com/solotoabillion/stab/core/dataexport"
+	"github.com/solotoabillion/stab/core/invitations"
+	"github.com/solotoabillion/stab/models"
+
+	"github.com/google/uuid"
+)
+
+// StartJobs registers the core background jobs and starts the job manager.
+// Jobs coordinate through the database, so every instance can run them.
+func (svc *ServiceContext) StartJobs() {
+	svc.JobManager.AddJob("*/30 * * * * *", svc.processDataExports)
+	svc.JobManager.AddJob("0 */15 * * * *", svc.deleteExpiredDataExports)
+	svc.JobManager.AddJob("0 0 * * * *", svc.purgeDeletedAccounts)
+	svc.JobManager.AddJob("0 */10 * * * *", svc.expireInvitations)
+	svc.JobManager.AddJob("*/15 * * * * *", svc.processInvitationImports)
+	svc.JobManager.Start()
+}
+
+// DataExportLink returns the signed link an export is downloaded from.
+func (svc *ServiceContext) DataExportLink(export *models.DataExport) string {
+	return dataexport.Link(svc.FrontendBaseURL(), svc.Config.Auth.AccessSecret, export.ID, *export.ExpiresAt)
+}
+
+func (svc *ServiceContext) processDataExports() {
+	err := dataexport.Process(svc.DB, svc.Config.Stripe.SecretKey, func(user *models.User, export *models.DataExport) {
+		body := fmt.Sprintf("The copy of your account data you requested is ready. Download it from the link below:\n\n%s\n\nThe link expires in %d days.",
+			svc.DataExportLink(export), int(dataexport.TTL.Hours()/24))
+		if err := svc.SendAccountEmail(context.Background(), user.Email, "Your Data Export Is Ready", body); err != nil {
+			log.Printf("ERROR: Failed to send data export email to user %s: %v", user.ID, err)
+		}
+	})
+	if err != nil {
+		log.Printf("ERROR: Data export job: %v", err)
+	}
+}
+
+func (svc *ServiceContext) deleteExpiredDataExports() {
+	n, err := models.DeleteExpiredDataExports(svc.DB, time.Now())
+	if err != nil {
+		log.Printf("ERROR: Failed to delete expired data exports: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("INFO: Deleted %d expired data exports", n)
+	}
+}
+
+func (svc *ServiceContext) purgeDeletedAccounts() {
+	n, err := accountdeletion.Purge(svc.DB, svc.Config, time.Now(), func(email string) {
+		body := "Your account and its data have been permanently deleted, as you requested. Thank you for having been with us."
+		if err := svc.SendAccountEmail(context.Background(), email, "Your Account Has Been Deleted", body); err != nil {
+			log.Printf("ERROR: Failed to send account deletion email: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("ERROR: Account deletion job: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("INFO: Erased %d deleted accounts", n)
+	}
+}
+
+func (svc *ServiceContext) expireInvitations() {
+	n, err := invitations.Expire(svc.DB, time.Now(), func(invitation *models.Invitation) {
+		if invitation.Inviter.ID == uuid.Nil {
+			return // The inviter's account is gone
+		}
+		notification := &models.Notification{
+			UserID: invitation.InviterID,
+			Type:   "team",
+			Title:  "Invitation expired",
+			Body: fmt.Sprintf("The invitation you sent to %s to join %s expired before it was accepted. You can resend it from the team's invitations.",
+				invitation.Email, invitation.Team.Name),
+		}
+		if err := models.CreateNotification(svc.DB, notification); err != nil {
+			log.Printf("ERROR: Failed to notify user %s of expired invitation %s: %v", invitation.InviterID, invitation.ID, err)
+		}
+	})
+	if err != nil {
+		log.Printf("ERROR: Invitation expiry job: %v", err)
+	}
+	if n > 0 {
+		log.Printf("INFO: Expired %d invitations", n)
+	}
+}
+
+func (svc *ServiceContext) processInvitationImports() {
+	err := invitations.ProcessImports(svc.DB, func(invitation *models.Invitation, team *models.Team, inviter *models.User) {
+		svc.SendInvitation(context.Background(), invitation, team, inviter)
+	}, func(imp *models.InvitationImport, team *models.Team) {
+		notification := &models.Notification{
+			UserID: imp.InviterID,
+			Type:   "team",
+			Title:  "Invitation import finished",
+			Body: fmt.Sprintf("We went through the %d people in %s for %s: %d invited, %d skipped and %d failed. Open the import to see each row.",
+				imp.Total, imp.FileName, team.Name, imp.Invited, imp.Skipped, imp.Failed),
+		}
+		if imp.Status == models.InvitationImportFailed {
+			notification.Title = "Invitation import failed"
+			notification.Body = fmt.Sprintf("We could not invite the people in %s to %s: %s", imp.FileName, team.Name, imp.Error)
+		}
+		if err := models.CreateNotification(svc.DB, notification); err != nil {
+			log.Printf("ERROR: Failed to notify user %s of invitation import %s: %v", imp.InviterID, imp.ID, err)
+		}
+	})
+	if err != nil {
+		log.Printf("ERROR: Invitation import job: %v", err)
+	}
+}
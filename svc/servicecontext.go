@@ -1,16 +1,26 @@
 package svc
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync" // Added for ModuleServices mutex
 	"time" // Needed for DB connection pool settings
 
 	// Use new top-level package paths
 	"github.com/solotoabillion/stab/config"
+	"github.com/solotoabillion/stab/core/bruteforce"
 	"github.com/solotoabillion/stab/core/cache"
 	"github.com/solotoabillion/stab/core/communication"
+	"github.com/solotoabillion/stab/core/email"
+	"github.com/solotoabillion/stab/core/invitations"
 	"github.com/solotoabillion/stab/core/jobs"
+	"github.com/solotoabillion/stab/core/keyring"
+	"github.com/solotoabillion/stab/core/password"
+	"github.com/solotoabillion/stab/core/permissions"
+	"github.com/solotoabillion/stab/core/seats"
 	"github.com/solotoabillion/stab/core/session"
 	"github.com/solotoabillion/stab/db"
 	"github.com/solotoabillion/stab/middleware"
@@ -38,21 +48,29 @@ type ServiceContext struct {
 	CommunicationClient communication.Service    // Communication service (e.g., SES/SNS) (can be nil)
 	Session             *session.Session         // Session manager
 	RateLimiter         *ratelimiter.RateLimiter // Rate limiter instance
+	AuthLimiter         *bruteforce.Limiter      // Failed authentication attempt tracking (Redis or in-memory)
+	KeyRing             *keyring.Ring            // Access token signing keys
+	BreachedPasswords   *password.Corpus         // Breached password screening (nil when not configured)
 	JobManager          *jobs.JobManager         // Background job manager
 	PubSubBroker        pubsub.Broker            // Pub/Sub broker (e.g., NATS or NoOp)
 	EventHub            *sse.EventHub            // Server-Sent Events hub
-	// EmailSender         email.EmailSender     // Removed: Handled by consumer
+	EmailSender         email.EmailSender        // Email sender (can be nil; set by consumer or built from email/ses settings)
 
 	moduleServicesMu sync.RWMutex           // Mutex for concurrent access to ModuleServices
 	ModuleServices   map[string]interface{} // Registry for services provided by enabled modules
 	Settings         models.SettingsMap     // Changed type to match LoadAllSettings return type
 	// Removed Middleware fields: CustomStatic, NoCache, AdminRequired
 	// Removed Settings field: Rely on Config directly
-	UserGuardMiddleware     echo.MiddlewareFunc
-	AdminGuardMiddleware    echo.MiddlewareFunc
-	AuthGuardMiddleware     echo.MiddlewareFunc
-	NoCacheMiddleware       echo.MiddlewareFunc
-	AdminRequiredMiddleware echo.MiddlewareFunc
+	AuthMiddleware                  echo.MiddlewareFunc // Requires an authenticated user
+	OptionalAuthMiddleware          echo.MiddlewareFunc // Loads the user when the request is authenticated
+	AccountMiddleware               echo.MiddlewareFunc // Loads the active team; see session.AccountFromContext
+	AuthGuardMiddleware             echo.MiddlewareFunc
+	NoCacheMiddleware               echo.MiddlewareFunc
+	AdminRequiredMiddleware         echo.MiddlewareFunc
+	VerifiedEmailMiddleware         echo.MiddlewareFunc
+	SCIMAuthMiddleware              echo.MiddlewareFunc
+	NoImpersonationMiddleware       echo.MiddlewareFunc // Blocks the route while an admin impersonates the user
+	ImpersonationReadOnlyMiddleware echo.MiddlewareFunc // Allows only safe methods while impersonating
 }
 
 // --- modules.ModuleContext Implementation ---
@@ -225,8 +243,28 @@ func NewServiceContext(c *config.Config) (*ServiceContext, error) {
 	// 	log.Println("INFO: AWS Region not provided in config. Skipping communication service initialization.")
 	// }
 
-	// --- Email Sender Initialization Removed ---
-	// The consuming application is responsible for initializing its own email sender.
+	// --- Email Sender Initialization ---
+	// The consuming application may set svcCtx.EmailSender itself; otherwise it is
+	// built from the email/ses settings when they are loaded below.
+
+	// --- Signing Keys Initialization ---
+	keyRing, err := keyring.New(context.Background(), keyring.NewDBStore(gormDB), c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize signing keys: %w", err)
+	}
+	log.Printf("INFO: Signing access tokens with %s", keyRing.Algorithm())
+	authenticator := middleware.NewAuthenticatorMiddleware(c, gormDB, keyRing)
+	impersonationGuard := middleware.NewImpersonationGuardMiddleware()
+
+	// --- Breached Password Screening ---
+	var breachedPasswords *password.Corpus
+	if c.Auth.BreachedPasswordsDir != "" {
+		breachedPasswords, err = password.NewCorpus(c.Auth.BreachedPasswordsDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open breached password corpus: %w", err)
+		}
+		log.Printf("INFO: Screening passwords against breached password files in %s", c.Auth.BreachedPasswordsDir)
+	}
 
 	// --- Session Manager Initialization ---
 	sessionManager := session.NewSession(c) // Assuming session.NewSession takes *config.Config
@@ -242,20 +280,34 @@ func NewServiceContext(c *config.Config) (*ServiceContext, error) {
 		DB:          gormDB, // Assign the initialized DB
 		RedisClient: redisClient,
 		// CommunicationClient: commClient,
-		Session:      sessionManager,
-		RateLimiter:  rateLimiter,
-		JobManager:   jobManager,
-		PubSubBroker: pubSubBroker,
-		EventHub:     sse.NewEventHub(), // Assuming sse.NewEventHub needs no args
+		Session:           sessionManager,
+		RateLimiter:       rateLimiter,
+		AuthLimiter:       bruteforce.NewLimiter(redisClient),
+		KeyRing:           keyRing,
+		BreachedPasswords: breachedPasswords,
+		JobManager:        jobManager,
+		PubSubBroker:      pubSubBroker,
+		EventHub:          sse.NewEventHub(), // Assuming sse.NewEventHub needs no args
 		// EmailSender:         emailSender, // Removed
 		ModuleServices: make(map[string]interface{}), // Initialize empty map
 		Settings:       make(models.SettingsMap),     // Initialize with correct type
 
-		UserGuardMiddleware:     middleware.NewUserGuardMiddleware(c, gormDB).Handle,
-		AdminGuardMiddleware:    middleware.NewAccountGuardMiddleware(c, gormDB).Handle,
-		AuthGuardMiddleware:     middleware.NewAuthGuardMiddleware().Handle,
+		AuthMiddleware:          authenticator.Handle,
+		OptionalAuthMiddleware:  authenticator.Optional,
+		AccountMiddleware:       middleware.NewAccountGuardMiddleware(c, gormDB, keyRing).Handle,
+		AuthGuardMiddleware:     middleware.NewAuthGuardMiddleware(c, gormDB, keyRing).Handle,
 		NoCacheMiddleware:       middleware.NewNoCacheMiddleware().Handle,
 		AdminRequiredMiddleware: middleware.NewAdminRequiredMiddleware().Handle,
+		SCIMAuthMiddleware:      middleware.NewSCIMAuthMiddleware(gormDB).Handle,
+
+		NoImpersonationMiddleware:       impersonationGuard.Handle,
+		ImpersonationReadOnlyMiddleware: impersonationGuard.ReadOnly,
+	}
+	svcCtx.VerifiedEmailMiddleware = middleware.NewVerifiedEmailMiddleware(svcCtx.EmailVerificationRequired).Handle
+
+	// --- Dynamic Settings ---
+	if err := svcCtx.ReloadAllSettings(); err != nil {
+		log.Printf("WARN: Failed to load settings at startup: %v. Proceeding with empty settings.", err)
 	}
 
 	return svcCtx, nil
@@ -272,7 +324,147 @@ func (svc *ServiceContext) ReloadAllSettings() error {
 	svc.Settings = settingsMap                            // Uncommented
 	log.Println("INFO: Settings reloaded from database.") // Added logging
 
-	// Re-initialization of specific services (like email, AI clients)
+	// Build an email sender from settings unless the consumer provided one.
+	// Re-initialization of other services (like AI clients)
 	// is the responsibility of the consuming application or specific modules.
+	if svc.EmailSender == nil {
+		if sesSettings := settingsMap["email/ses"]; sesSettings["from_address"] != "" {
+			sender, err := email.GetEmailSenderFromSettings(sesSettings)
+			if err != nil {
+				log.Printf("WARN: Failed to initialize email sender from settings: %v", err)
+			} else {
+				svc.EmailSender = sender
+				log.Println("INFO: Email sender initialized from email/ses settings.")
+			}
+		}
+	}
 	return nil
 }
+
+// EmailVerificationRequired reports whether unverified users are kept out of billing and teams.
+func (svc *ServiceContext) EmailVerificationRequired() bool {
+	return svc.Settings["auth"]["require_email_verification"] == "true"
+}
+
+// PasswordPolicy returns the rules new passwords must meet, from the auth settings.
+func (svc *ServiceContext) PasswordPolicy() password.Policy {
+	return password.PolicyFromSettings(svc.Settings["auth"])
+}
+
+// SSOAvailableForPlan reports whether teams on the plan can set up single sign-on.
+// The auth/sso_plans setting lists the plan IDs; when it is empty every plan can.
+func (svc *ServiceContext) SSOAvailableForPlan(plan string) bool {
+	plans := strings.TrimSpace(svc.Settings["auth"]["sso_plans"])
+	if plans == "" {
+		return true
+	}
+	for _, p := range strings.Split(plans, ",") {
+		if strings.TrimSpace(p) == plan {
+			return true
+		}
+	}
+	return false
+}
+
+// SendEmail delivers an email through the configured EmailSender.
+// It returns email.ErrNoEmailSender when no sender is configured so callers can decide how to degrade.
+func (svc *ServiceContext) SendEmail(ctx context.Context, to, subject, body string) error {
+	if svc.EmailSender == nil {
+		return email.ErrNoEmailSender
+	}
+	return svc.EmailSender.SendEmail(ctx, to, subject, body)
+}
+
+// FrontendBaseURL returns the base URL of the frontend used for links in emails.
+func (svc *ServiceContext) FrontendBaseURL() string {
+	if svc.Config.FrontendURL == "" {
+		return "http://localhost:5173" // Default for local dev
+	}
+	return svc.Config.FrontendURL
+}
+
+// SSOCallbackURL returns the redirect URL of team single sign-on, which team owners
+// register with their identity provider.
+func (svc *ServiceContext) SSOCallbackURL() string {
+	if svc.Config.Auth.SSORedirectURL != "" {
+		return svc.Config.Auth.SSORedirectURL
+	}
+	return strings.TrimRight(svc.FrontendBaseURL(), "/") + "/api/auth/sso/callback"
+}
+
+// RequireScope limits requests made with an access token to tokens holding a scope
+// for the resource. See middleware.TokenScopeMiddleware.
+func (svc *ServiceContext) RequireScope(resource string) echo.MiddlewareFunc {
+	return middleware.NewTokenScopeMiddleware(resource).Handle
+}
+
+// RequirePermission limits the route to users holding the permission in the team of the
+// route's :teamId parameter. See middleware.PermissionMiddleware.
+func (svc *ServiceContext) RequirePermission(permission string) echo.MiddlewareFunc {
+	return middleware.NewPermissionMiddleware(svc.DB, permission).Handle
+}
+
+// Authorize returns nil when the user holds the permission in the team.
+// See permissions.Authorize for the errors it returns.
+func (svc *ServiceContext) Authorize(ctx context.Context, user *models.User, team *models.Team, permission string) error {
+	return permissions.Authorize(ctx, svc.DB, user, team, permission)
+}
+
+// SCIMBaseURL returns the SCIM 2.0 base URL that team owners enter in their directory.
+func (svc *ServiceContext) SCIMBaseURL() string {
+	return strings.TrimRight(svc.FrontendBaseURL(), "/") + "/api/scim/v2"
+}
+
+// SendAccountEmail sends a transactional account email such as a verification link.
+// Outside production a missing sender is not an error: the body is logged instead so the
+// links can still be followed during local development.
+func (svc *ServiceContext) SendAccountEmail(ctx context.Context, to, subject, body string) error {
+	err := svc.SendEmail(ctx, to, subject, body)
+	if errors.Is(err, email.ErrNoEmailSender) && svc.Config.Environment != "production" {
+		log.Printf("INFO: No email sender configured. Email to %s (%s):\n%s", to, subject, body)
+		return nil
+	}
+	return err
+}
+
+// SendInvitation emails the invitation link to the invited address. When the address
+// belongs to an account, the user also gets an in-app notification. Delivery errors are
+// logged; the invitation stays valid and can be resent.
+func (svc *ServiceContext) SendInvitation(ctx context.Context, invitation *models.Invitation, team *models.Team, inviter *models.User) {
+	message, err := invitations.Compose(svc.FrontendBaseURL(), invitation, team, inviter)
+	if err != nil {
+		log.Printf("ERROR: Failed to compose invitation email for invitation %s: %v", invitation.ID, err)
+		return
+	}
+	if err := svc.SendAccountEmail(ctx, invitation.Email, message.Subject, message.Body); err != nil {
+		log.Printf("ERROR: Failed to send invitation email to %s for team %s: %v", invitation.Email, team.ID, err)
+	}
+
+	invitedUser, err := models.FindUserByEmail(svc.DB, invitation.Email)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("ERROR: Failed to look up invited user %s: %v", invitation.Email, err)
+		}
+		return
+	}
+	notification := &models.Notification{
+		UserID: invitedUser.ID,
+		Type:   "team",
+		Title:  fmt.Sprintf("You're invited to join %s", team.Name),
+		Body:   fmt.Sprintf("%s has invited you to join %s. Open your invitations to accept or decline.", invitations.DisplayName(inviter), team.Name),
+	}
+	if err := models.CreateNotification(svc.DB, notification); err != nil {
+		log.Printf("ERROR: Failed to create invitation notification for user %s: %v", invitedUser.ID, err)
+	}
+}
+
+// SyncSeats updates the per-seat subscription paying for the team to the seats in use
+// after its membership changed. It runs in the background; failures are logged and picked
+// up by the next change.
+func (svc *ServiceContext) SyncSeats(team *models.Team) {
+	go func() {
+		if err := seats.Sync(svc.DB, svc.Config.Stripe.SecretKey, team); err != nil {
+			log.Printf("ERROR: Failed to sync seats of team %s: %v", team.ID, err)
+		}
+	}()
+}
@@ -15,11 +15,20 @@ type User struct {
 	Email            string          `json:"email" validate:"required,email"`
 	ProfileData      UserProfileData `json:"profileData,optional,omitempty"` // Embed the profile data struct
 	Role             string          `json:"role,optional,omitempty"`
-	ApiKey           string          `json:"apiKey,optional,omitempty"`
 	DefaultSubdomain string          `json:"defaultSubdomain"`
 	AccountStatus    string          `json:"accountStatus,optional,omitempty"`
+	EmailVerified    bool            `json:"emailVerified"`
 	CreatedAt        string          `json:"createdAt"`
 	UpdatedAt        string          `json:"updatedAt"`
+	Impersonation    *Impersonation  `json:"impersonation,omitempty"` // Set while an admin acts as the user; show a banner
+
+	DeletionScheduledAt string `json:"deletionScheduledAt,omitempty"` // Set while the account is scheduled for deletion
+}
+
+type Impersonation struct {
+	AdminID    string `json:"adminId"`
+	AdminEmail string `json:"adminEmail"`
+	ExpiresAt  string `json:"expiresAt"`
 }
 
 type LoginRequest struct {
@@ -28,10 +37,34 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Token   string `json:"token"`
-	User    User   `json:"user"`
+	Success      bool   `json:"success"`
+	Message      string `json:"message"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int64  `json:"expiresIn,omitempty"`
+	User         User   `json:"user"`
+	MfaRequired  bool   `json:"mfaRequired,omitempty"`
+	MfaToken     string `json:"mfaToken,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken,optional,omitempty"` // Falls back to the refresh cookie
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int64  `json:"expiresIn"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken,optional,omitempty"` // Falls back to the refresh cookie or access token
+}
+
+type MfaLoginRequest struct {
+	MfaToken     string `json:"mfaToken" validate:"required"`
+	Code         string `json:"code,optional,omitempty"`
+	RecoveryCode string `json:"recoveryCode,optional,omitempty"`
 }
 
 type RegisterRequest struct {
@@ -57,14 +90,31 @@ type VerifyCodeRequest struct {
 	Code  string `json:"code" validate:"required"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 type ResetPasswordRequest struct {
 	Token    string `json:"token" validate:"required"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required"` // Checked against the password policy
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword,optional,omitempty"` // Required unless the account has no password yet
+	NewPassword     string `json:"newPassword" validate:"required"`
 }
 
 type ChangeEmailRequest struct {
 	NewEmail string `json:"newEmail" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Password string `json:"password,optional,omitempty"` // Required unless the account only signs in through an OAuth provider
+}
+
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
 }
 
 type BlogPost struct {
@@ -109,9 +159,11 @@ type Membership struct {
 	UserID string `json:"userId"`
 	TeamID string `json:"teamId"`
 	Role   string `json:"role"`
+	RoleID string `json:"roleId,omitempty"` // Custom role, when one is assigned
 }
 
 type Invitation struct {
+	ID        string `json:"id,omitempty"`
 	Email     string `json:"email"`
 	TeamID    string `json:"teamId"`
 	Role      string `json:"role"`
@@ -126,11 +178,15 @@ type Plan struct {
 	PriceMonthly float64  `json:"priceMonthly"`
 	PriceYearly  float64  `json:"priceYearly,optional,omitempty"`
 	Features     []string `json:"features"`
+	MaxSeats     int      `json:"maxSeats"` // 0 means unlimited
+	PerSeat      bool     `json:"perSeat"`
 }
 
 type Subscription struct {
 	ID                 string `json:"id"`
 	UserID             string `json:"userId"`
+	SubjectType        string `json:"subjectType"` // "user" or "team"
+	SubjectID          string `json:"subjectId"`
 	PlanID             string `json:"planId"`
 	Status             string `json:"status"`
 	CurrentPeriodStart string `json:"currentPeriodStart"`
@@ -152,8 +208,94 @@ type Response struct {
 	Message string `json:"message"`
 }
 
-type ApiKeyResponse struct {
-	ApiKey string `json:"apiKey"`
+type APIToken struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Prefix      string   `json:"prefix"`
+	TeamID      string   `json:"teamId,omitempty"`
+	Scopes      []string `json:"scopes"`
+	IPAllowlist []string `json:"ipAllowlist"`
+	ExpiresAt   string   `json:"expiresAt,omitempty"`
+	Expired     bool     `json:"expired"`
+	LastUsedAt  string   `json:"lastUsedAt,omitempty"`
+	LastUsedIP  string   `json:"lastUsedIp,omitempty"`
+	CreatedAt   string   `json:"createdAt"`
+}
+
+type ListAPITokensResponse struct {
+	Tokens          []APIToken `json:"tokens"`
+	AvailableScopes []string   `json:"availableScopes"`
+}
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" validate:"required"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	ExpiresInDays int      `json:"expiresInDays,optional"` // 0 never expires
+	IPAllowlist   []string `json:"ipAllowlist,optional"`   // IP addresses or CIDR ranges
+}
+
+type CreateAPITokenResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Token   APIToken `json:"token"`
+	Secret  string   `json:"secret"` // Shown once; only its hash is stored
+}
+
+type APITokenRequest struct {
+	TokenID string `path:"tokenId"`
+}
+
+type CreateTeamAPITokenRequest struct {
+	TeamID        string   `path:"teamId"`
+	Name          string   `json:"name" validate:"required"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	ExpiresInDays int      `json:"expiresInDays,optional"`
+	IPAllowlist   []string `json:"ipAllowlist,optional"`
+}
+
+type TeamAPITokenRequest struct {
+	TeamID  string `path:"teamId"`
+	TokenID string `path:"tokenId"`
+}
+
+type DataExport struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"` // pending, processing, ready or failed
+	Size        int64  `json:"size"`
+	Error       string `json:"error,omitempty"`
+	DownloadURL string `json:"downloadUrl,omitempty"` // Signed link; set while the export is ready
+	ExpiresAt   string `json:"expiresAt,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+	CompletedAt string `json:"completedAt,omitempty"`
+}
+
+type DataExportResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Export  *DataExport `json:"export"` // Nil when no export was requested yet
+}
+
+type DownloadDataExportRequest struct {
+	ExportID  string `path:"exportId"`
+	Expires   string `query:"expires"`
+	Signature string `query:"signature"`
+}
+
+type TeamTransfer struct {
+	TeamID     string `json:"teamId" validate:"required"`
+	NewOwnerID string `json:"newOwnerId" validate:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password  string         `json:"password,optional,omitempty"` // Required unless the account only signs in through a provider
+	Confirm   string         `json:"confirm" validate:"required"` // Must be the account's email address
+	Transfers []TeamTransfer `json:"transfers,optional"`          // New owners of owned teams that have other members
+}
+
+type DeleteAccountResponse struct {
+	Success             bool   `json:"success"`
+	Message             string `json:"message"`
+	DeletionScheduledAt string `json:"deletionScheduledAt,omitempty"`
 }
 
 type EmailPreferences struct {
@@ -163,8 +305,125 @@ type EmailPreferences struct {
 }
 
 type SecuritySettings struct {
-	TwoFactorEnabled   bool   `json:"twoFactorEnabled"`
-	LastPasswordChange string `json:"lastPasswordChange"`
+	TwoFactorEnabled       bool         `json:"twoFactorEnabled"`
+	LastPasswordChange     string       `json:"lastPasswordChange"`
+	RecoveryCodesRemaining int          `json:"recoveryCodesRemaining"`
+	LoginHistory           []LoginEvent `json:"loginHistory"`
+}
+
+type LoginEvent struct {
+	ID            string `json:"id"`
+	Method        string `json:"method"`
+	Success       bool   `json:"success"`
+	FailureReason string `json:"failureReason,omitempty"`
+	IPAddress     string `json:"ipAddress"`
+	UserAgent     string `json:"userAgent"`
+	Device        string `json:"device"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type PasskeyCredential struct {
+	ID       string                    `json:"id" validate:"required"`
+	Type     string                    `json:"type" validate:"required"`
+	Response PasskeyCredentialResponse `json:"response"`
+}
+
+type PasskeyCredentialResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON" validate:"required"`
+	AttestationObject string   `json:"attestationObject,optional,omitempty"`
+	Transports        []string `json:"transports,optional,omitempty"`
+	AuthenticatorData string   `json:"authenticatorData,optional,omitempty"`
+	Signature         string   `json:"signature,optional,omitempty"`
+	UserHandle        string   `json:"userHandle,optional,omitempty"`
+}
+
+type PasskeyOptionsResponse struct {
+	SessionID string      `json:"sessionId"`
+	PublicKey interface{} `json:"publicKey"`
+}
+
+type RegisterPasskeyRequest struct {
+	SessionID  string            `json:"sessionId" validate:"required"`
+	Name       string            `json:"name,optional,omitempty" validate:"max=100"`
+	Credential PasskeyCredential `json:"credential"`
+}
+
+type PasskeyLoginOptionsRequest struct {
+	Email string `json:"email,optional,omitempty"`
+}
+
+type PasskeyLoginRequest struct {
+	SessionID  string            `json:"sessionId" validate:"required"`
+	Credential PasskeyCredential `json:"credential"`
+	MfaToken   string            `json:"mfaToken,optional,omitempty"`
+}
+
+type Passkey struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	BackupEligible bool   `json:"backupEligible"`
+	BackedUp       bool   `json:"backedUp"`
+	CreatedAt      string `json:"createdAt"`
+	LastUsedAt     string `json:"lastUsedAt,omitempty"`
+}
+
+type PasskeysResponse struct {
+	Passkeys []Passkey `json:"passkeys"`
+}
+
+type PasskeyRequest struct {
+	PasskeyID string `path:"passkeyId"`
+}
+
+type RenamePasskeyRequest struct {
+	PasskeyID string `path:"passkeyId"`
+	Name      string `json:"name" validate:"required,max=100"`
+}
+
+type UserSession struct {
+	ID         string `json:"id"`
+	IPAddress  string `json:"ipAddress"`
+	UserAgent  string `json:"userAgent"`
+	LastSeenAt string `json:"lastSeenAt"`
+	CreatedAt  string `json:"createdAt"`
+	Current    bool   `json:"current"`
+}
+
+type UserSessionsResponse struct {
+	Sessions []UserSession `json:"sessions"`
+}
+
+type UserSessionRequest struct {
+	SessionID string `path:"sessionId"`
+}
+
+type LinkedIdentity struct {
+	ID          string `json:"id"`
+	Provider    string `json:"provider"`
+	Email       string `json:"email,omitempty"`
+	LastLoginAt string `json:"lastLoginAt,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type LinkedIdentitiesResponse struct {
+	Identities []LinkedIdentity `json:"identities"`
+}
+
+type LinkedIdentityRequest struct {
+	IdentityID string `path:"identityId"`
 }
 
 type APIUsageStats struct {
@@ -233,6 +492,20 @@ type TeamRequest struct {
 	Role   string `json:"role" validate:"required"`
 }
 
+type TeamIDRequest struct {
+	TeamID string `path:"teamId"`
+}
+
+type RenameTeamRequest struct {
+	TeamID string `path:"teamId"`
+	Name   string `json:"name" validate:"required,max=100"`
+}
+
+type TransferTeamOwnershipRequest struct {
+	TeamID     string `path:"teamId"`
+	NewOwnerID string `json:"newOwnerId" validate:"required"`
+}
+
 type TeamMemberRequest struct {
 	TeamID   string `path:"teamId"`
 	MemberID string `path:"memberId"`
@@ -252,13 +525,224 @@ type InviteMemberRequest struct {
 type UpdateMemberRoleRequest struct {
 	TeamID   string `path:"teamId"`
 	MemberID string `path:"memberId"`
-	Role     string `json:"role" validate:"required,oneof=admin member"`
+	Role     string `json:"role,optional" validate:"oneof=admin member"`
+	RoleID   string `json:"roleId,optional"` // Custom role; the member keeps the member role underneath
 }
 
 type InvitationTokenRequest struct {
 	Token string `path:"token"`
 }
 
+type TeamDomain struct {
+	ID                 string `json:"id"`
+	Domain             string `json:"domain"`
+	Verified           bool   `json:"verified"`
+	VerifiedAt         string `json:"verifiedAt,omitempty"`
+	VerificationRecord string `json:"verificationRecord"` // TXT record value to publish on the domain
+	JoinPolicy         string `json:"joinPolicy"`         // off, auto or request
+}
+
+type TeamSSOResponse struct {
+	Enabled         bool         `json:"enabled"`
+	Issuer          string       `json:"issuer"`
+	ClientID        string       `json:"clientId"`
+	ClientSecretSet bool         `json:"clientSecretSet"`
+	DefaultRole     string       `json:"defaultRole"`
+	Enforced        bool         `json:"enforced"`
+	CallbackURL     string       `json:"callbackUrl"` // Redirect URL to register with the identity provider
+	Domains         []TeamDomain `json:"domains"`
+}
+
+type UpdateTeamSSORequest struct {
+	TeamID       string `path:"teamId"`
+	Enabled      bool   `json:"enabled"`
+	Issuer       string `json:"issuer,optional"`
+	ClientID     string `json:"clientId,optional"`
+	ClientSecret string `json:"clientSecret,optional"` // Empty keeps the stored secret
+	DefaultRole  string `json:"defaultRole,optional" validate:"omitempty,oneof=admin member"`
+	Enforced     bool   `json:"enforced"`
+}
+
+type AddTeamDomainRequest struct {
+	TeamID string `path:"teamId"`
+	Domain string `json:"domain" validate:"required"`
+}
+
+type TeamDomainRequest struct {
+	TeamID   string `path:"teamId"`
+	DomainID string `path:"domainId"`
+}
+
+type TeamDomainResponse struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message"`
+	Domain  TeamDomain `json:"domain"`
+}
+
+type ListTeamDomainsResponse struct {
+	Domains []TeamDomain `json:"domains"`
+}
+
+type UpdateDomainJoinPolicyRequest struct {
+	TeamID   string `path:"teamId"`
+	DomainID string `path:"domainId"`
+	Policy   string `json:"policy" validate:"required"`
+}
+
+type JoinableTeam struct {
+	TeamID         string `json:"teamId"`
+	Name           string `json:"name"`
+	Domain         string `json:"domain"`
+	JoinPolicy     string `json:"joinPolicy"`
+	RequestPending bool   `json:"requestPending"`
+}
+
+type JoinableTeamsResponse struct {
+	Teams []JoinableTeam `json:"teams"`
+}
+
+type JoinTeamResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Status  string `json:"status"` // joined, or requested when an admin has to approve
+	Team    Team   `json:"team"`
+}
+
+type JoinRequest struct {
+	ID        string `json:"id"`
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type JoinRequestsResponse struct {
+	Requests []JoinRequest `json:"requests"`
+}
+
+type JoinRequestRequest struct {
+	TeamID    string `path:"teamId"`
+	RequestID string `path:"requestId"`
+}
+
+type JoinRequestResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Request JoinRequest `json:"request"`
+}
+
+type SCIMToken struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Prefix     string `json:"prefix"`
+	LastUsedAt string `json:"lastUsedAt,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type ListSCIMTokensResponse struct {
+	BaseURL string      `json:"baseUrl"`
+	Tokens  []SCIMToken `json:"tokens"`
+}
+
+type CreateSCIMTokenRequest struct {
+	TeamID string `path:"teamId"`
+	Name   string `json:"name" validate:"required"`
+}
+
+type CreateSCIMTokenResponse struct {
+	Success bool      `json:"success"`
+	Message string    `json:"message"`
+	BaseURL string    `json:"baseUrl"`
+	Token   SCIMToken `json:"token"`
+	Secret  string    `json:"secret"` // Shown once; only its hash is stored
+}
+
+type SCIMTokenRequest struct {
+	TeamID  string `path:"teamId"`
+	TokenID string `path:"tokenId"`
+}
+
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	OwnerOnly   bool   `json:"ownerOnly"`
+}
+
+type TeamRole struct {
+	ID          string   `json:"id,omitempty"` // Empty for built-in roles
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"builtIn"`
+	MemberCount int64    `json:"memberCount"`
+}
+
+type TeamRolesResponse struct {
+	Roles       []TeamRole       `json:"roles"`
+	Permissions []PermissionInfo `json:"permissions"`
+}
+
+type CreateTeamRoleRequest struct {
+	TeamID      string   `path:"teamId"`
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description,optional" validate:"max=255"`
+	Permissions []string `json:"permissions,optional"`
+}
+
+type UpdateTeamRoleRequest struct {
+	TeamID      string   `path:"teamId"`
+	RoleID      string   `path:"roleId"`
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description,optional" validate:"max=255"`
+	Permissions []string `json:"permissions,optional"`
+}
+
+type TeamRoleRequest struct {
+	TeamID string `path:"teamId"`
+	RoleID string `path:"roleId"`
+}
+
+type TeamRoleResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Role    TeamRole `json:"role"`
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSResponse struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type SigningKey struct {
+	KID       string `json:"kid"`
+	Algorithm string `json:"algorithm"`
+	Current   bool   `json:"current"`
+	CreatedAt string `json:"createdAt"`
+	RetiredAt string `json:"retiredAt,omitempty"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+type ListSigningKeysResponse struct {
+	Algorithm string       `json:"algorithm"`
+	Keys      []SigningKey `json:"keys"`
+}
+
+type RotateSigningKeysResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	KID     string `json:"kid"`
+}
+
 type NotificationRequest struct {
 	NotificationID string `path:"notificationId"`
 }
@@ -267,6 +751,39 @@ type AdminUserRequest struct {
 	UserID string `path:"userId"`
 }
 
+type ImpersonateUserRequest struct {
+	UserID  string `path:"userId"`
+	Reason  string `json:"reason" validate:"required"`
+	Minutes int    `json:"minutes,optional"` // Defaults to 30, at most 60
+}
+
+type ImpersonateUserResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	Token     string `json:"token"` // Bearer token acting as the user; there is no refresh token
+	ExpiresIn int64  `json:"expiresIn"`
+	ExpiresAt string `json:"expiresAt"`
+	User      User   `json:"user"`
+}
+
+type ImpersonationLogEntry struct {
+	ID         string `json:"id"`
+	SessionID  string `json:"sessionId"`
+	AdminID    string `json:"adminId"`
+	AdminEmail string `json:"adminEmail"`
+	Action     string `json:"action"`
+	Reason     string `json:"reason,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Status     int    `json:"status,omitempty"`
+	IPAddress  string `json:"ipAddress"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type ImpersonationLogResponse struct {
+	Entries []ImpersonationLogEntry `json:"entries"`
+}
+
 type SendCommunicationCombinedRequest struct {
 	UserID string `path:"userId"`                   // From path
 	Type   string `json:"type" validate:"required"` // e.g., "email", "note", "system"
@@ -280,6 +797,13 @@ type TeamResponse struct {
 	Team    Team   `json:"team,optional,omitempty"`
 }
 
+type SwitchTeamResponse struct {
+	Success    bool       `json:"success"`
+	Message    string     `json:"message"`
+	Team       Team       `json:"team"`
+	Membership Membership `json:"membership"` // The user's role in the team they switched to
+}
+
 type TeamMembersResponse struct {
 	Success bool         `json:"success"`
 	Message string       `json:"message"`
@@ -304,6 +828,40 @@ type InvitationResponse struct {
 	Invitation Invitation `json:"invitation,optional,omitempty"`
 }
 
+type InvitationImportRow struct {
+	Line    int    `json:"line"` // Line of the CSV file
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Status  string `json:"status"` // invited, skipped or failed
+	Message string `json:"message,omitempty"`
+}
+
+type InvitationImport struct {
+	ID          string                `json:"id"`
+	TeamID      string                `json:"teamId"`
+	Status      string                `json:"status"` // pending, processing, completed or failed
+	FileName    string                `json:"fileName,omitempty"`
+	Total       int                   `json:"total"`
+	Invited     int                   `json:"invited"`
+	Skipped     int                   `json:"skipped"`
+	Failed      int                   `json:"failed"`
+	Error       string                `json:"error,omitempty"`
+	Rows        []InvitationImportRow `json:"rows"` // Set once completed
+	CreatedAt   string                `json:"createdAt"`
+	CompletedAt string                `json:"completedAt,omitempty"`
+}
+
+type InvitationImportResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Import  InvitationImport `json:"import"`
+}
+
+type InvitationImportRequest struct {
+	TeamID   string `path:"teamId"`
+	ImportID string `path:"importId"`
+}
+
 type NotificationResponse struct {
 	Success      bool         `json:"success"`
 	Message      string       `json:"message"`
@@ -354,12 +912,37 @@ type BlogCategoryRequest struct {
 	PerPage      int    `query:"perPage" validate:"min=1,max=100"`
 }
 
-type GoogleResponse struct {
+type OAuthResponse struct {
 	Success     bool   `json:"success"`
 	Message     string `json:"message"`
 	RedirectURL string `json:"redirectUrl"`
 }
 
+type OAuthProviderRequest struct {
+	Provider string `path:"provider"`
+}
+
+type SSOLoginRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type SSOCallbackRequest struct {
+	State string `form:"state,optional"`
+	Code  string `form:"code,optional"`
+	Error string `form:"error,optional"`
+}
+
+type OAuthCallbackRequest struct {
+	Provider string `path:"provider"`
+	State    string `form:"state,optional"`
+	Code     string `form:"code,optional"`
+	Error    string `form:"error,optional"` // Set by the provider when the user declined
+}
+
+type OAuthProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
 
 
 type BlogPostResponse struct {